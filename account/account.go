@@ -2,12 +2,14 @@ package account
 
 import (
 	"crypto/ecdsa"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"math/big"
 	"os"
+	"path/filepath"
 	"strings"
 
-	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -33,7 +35,7 @@ func New(config *Config) (Account, error) {
 	case privateKeyMode:
 		return newPrivateKeyAccount(config.PrivateKey)
 	case keystoreMode:
-		return newKeystoreAccount(config.KeystorePath, config.PasswordFilePath, config.Address)
+		return newRotatingKeystoreAccount(*config)
 	default:
 		return nil, errors.New("invalid pay account mode")
 	}
@@ -60,15 +62,16 @@ func (a *baseAccount) Address() common.Address {
 }
 
 type keystoreAccount struct {
-	keystore *keystore.KeyStore
-	account  accounts.Account
+	key *keystore.Key
 	*baseAccount
 }
 
+// newKeystoreAccount decrypts the key of the account from its file in the keystore directory. The file is read once
+// rather than through a keystore.KeyStore, which watches the directory for as long as it's around, i.e. past each
+// rotation.
 func newKeystoreAccount(keystorePath, passwordFilePath, opAccount string) (*keystoreAccount, error) {
 	address := common.HexToAddress(opAccount)
-	ks := keystore.NewKeyStore(keystorePath, keystore.StandardScryptN, keystore.StandardScryptP)
-	account, err := ks.Find(accounts.Account{Address: address})
+	keyJSON, err := readKeyFile(keystorePath, address)
 	if err != nil {
 		log.Errorw("failed to create key store account", "err", err)
 		return nil, err
@@ -76,22 +79,65 @@ func newKeystoreAccount(keystorePath, passwordFilePath, opAccount string) (*keys
 
 	password := MakePasswordFromPath(passwordFilePath)
 
-	err = ks.Unlock(account, password)
+	key, err := keystore.DecryptKey(keyJSON, password)
 	if err != nil {
 		log.Errorw("failed to unlock account", "err", err)
 		return nil, err
 	}
+	if key.Address != address {
+		log.Errorw("key file of another account", "address", address, "key", key.Address)
+		return nil, keystore.ErrNoMatch
+	}
 
 	err = os.Remove(passwordFilePath)
 	if err != nil {
 		log.Errorw("failed to remove password file", "err", err)
 	}
 
-	return &keystoreAccount{ks, account, &baseAccount{address: address}}, nil
+	return &keystoreAccount{key, &baseAccount{address: address}}, nil
+}
+
+// readKeyFile returns the content of the key file of the address in the keystore directory, skipping the files the
+// keystore skips too: hidden ones, editor backups and the README.
+func readKeyFile(dir string, address common.Address) ([]byte, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var found []byte
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") || strings.HasSuffix(name, "~") || name == "README" {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+
+		var key struct {
+			Address string `json:"address"`
+		}
+		if json.Unmarshal(data, &key) != nil || common.HexToAddress(key.Address) != address {
+			continue
+		}
+		if found != nil {
+			return nil, fmt.Errorf("multiple key files of %s in %s", address, dir)
+		}
+		found = data
+	}
+
+	if found == nil {
+		return nil, keystore.ErrNoMatch
+	}
+
+	return found, nil
 }
 
 func (k *keystoreAccount) SignTx(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
-	signedTx, err := k.keystore.SignTx(k.account, tx, chainID)
+	signedTx, err := types.SignTx(tx, types.LatestSignerForChainID(chainID), k.key.PrivateKey)
 	if err != nil {
 		log.Errorw("failed to sign tx", "err", err)
 		return nil, err
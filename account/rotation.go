@@ -0,0 +1,101 @@
+package account
+
+import (
+	"errors"
+	"math/big"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/bnb-chain/bsc-mev-sentry/log"
+	"github.com/bnb-chain/bsc-mev-sentry/metrics"
+	"github.com/bnb-chain/bsc-mev-sentry/utils"
+)
+
+// Rotatable is implemented by accounts whose key material can be rotated on disk.
+type Rotatable interface {
+	// CheckRotation reloads the key material if it changed since last loaded.
+	CheckRotation()
+}
+
+// rotatingKeystoreAccount unlocks the keystore account again once the keystore or the
+// password file changes, so that keys can be rotated without restarting the sentry.
+type rotatingKeystoreAccount struct {
+	cfg Config
+
+	mu      sync.Mutex // serializes rotations
+	modTime time.Time
+	current atomic.Pointer[keystoreAccount]
+}
+
+func newRotatingKeystoreAccount(cfg Config) (*rotatingKeystoreAccount, error) {
+	modTime, err := utils.LatestModTime(cfg.KeystorePath)
+	if err != nil {
+		return nil, err
+	}
+
+	acc, err := newKeystoreAccount(cfg.KeystorePath, cfg.PasswordFilePath, cfg.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &rotatingKeystoreAccount{cfg: cfg, modTime: modTime}
+	r.current.Store(acc)
+
+	return r, nil
+}
+
+func (r *rotatingKeystoreAccount) Address() common.Address {
+	return r.current.Load().Address()
+}
+
+func (r *rotatingKeystoreAccount) SignTx(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return r.current.Load().SignTx(tx, chainID)
+}
+
+func (r *rotatingKeystoreAccount) CheckRotation() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// the password file is removed once used, a new one must be provided along with the new keystore
+	if r.cfg.PasswordFilePath != "" {
+		if _, err := os.Stat(r.cfg.PasswordFilePath); errors.Is(err, os.ErrNotExist) {
+			return
+		}
+	}
+
+	paths := []string{r.cfg.KeystorePath}
+	if r.cfg.PasswordFilePath != "" {
+		paths = append(paths, r.cfg.PasswordFilePath)
+	}
+
+	modTime, err := utils.LatestModTime(paths...)
+	if err != nil {
+		metrics.SecretRotationCounter.WithLabelValues("pay_account", "failure").Inc()
+		log.Errorw("failed to stat keystore files", "keystore", r.cfg.KeystorePath, "err", err)
+		return
+	}
+
+	if !modTime.After(r.modTime) {
+		return
+	}
+
+	acc, err := newKeystoreAccount(r.cfg.KeystorePath, r.cfg.PasswordFilePath, r.cfg.Address)
+	if err != nil {
+		metrics.SecretRotationCounter.WithLabelValues("pay_account", "failure").Inc()
+		log.Errorw("failed to rotate keystore account, keep the old one", "keystore", r.cfg.KeystorePath, "err", err)
+		// don't retry until the files change again
+		r.modTime = modTime
+		return
+	}
+
+	r.modTime = modTime
+	r.current.Store(acc)
+
+	metrics.SecretRotationCounter.WithLabelValues("pay_account", "success").Inc()
+	log.Infow("keystore account rotated", "keystore", r.cfg.KeystorePath, "address", acc.Address())
+}
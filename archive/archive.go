@@ -0,0 +1,74 @@
+package archive
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bnb-chain/bsc-mev-sentry/utils"
+)
+
+const (
+	defaultPrefix    = "bsc-mev-sentry"
+	defaultInterval  = 10 * time.Minute
+	defaultDelay     = 5 * time.Minute
+	defaultBatchSize = 10000
+)
+
+type Config struct {
+	// Backend object storage the bids are exported to, s3, disabled if empty
+	Backend string
+	// Endpoint of the s3 compatible storage, e.g. https://s3.us-east-1.amazonaws.com or http://127.0.0.1:9000 of minio
+	Endpoint string
+	// Region of the bucket, defaults to us-east-1
+	Region string
+	// Bucket the bids are exported to
+	Bucket string
+	// AccessKey and SecretKey the credentials of the storage
+	AccessKey string
+	SecretKey string
+	// Prefix of the exported objects, defaults to bsc-mev-sentry
+	Prefix string
+	// Interval how often the bids are exported, defaults to 10m
+	Interval utils.Duration
+	// Delay bids are exported once received at least this long ago, so that their outcomes are settled, defaults
+	// to 5m
+	Delay utils.Duration
+	// BatchSize bids exported per object at most, defaults to 10000
+	BatchSize int
+	// Retention how long the exported objects are kept, kept forever if 0
+	Retention utils.Duration
+	// IncludeArgs exports the bids as signed by their builders too, txs included and in plaintext, if they're kept
+	IncludeArgs bool
+}
+
+// Object is an object stored in a bucket.
+type Object struct {
+	Key          string
+	LastModified time.Time
+}
+
+// Bucket stores the exported objects.
+type Bucket interface {
+	Name() string
+	Put(ctx context.Context, key string, body []byte, contentType string) error
+	// List returns the objects whose key starts with the prefix.
+	List(ctx context.Context, prefix string) ([]Object, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// NewBucket creates the bucket of the config, a nil bucket is returned if no backend is configured.
+func NewBucket(cfg Config) (Bucket, error) {
+	switch cfg.Backend {
+	case "":
+		return nil, nil
+	case "s3":
+		bucket, err := newS3(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return bucket, nil
+	default:
+		return nil, fmt.Errorf("unsupported archive backend %s", cfg.Backend)
+	}
+}
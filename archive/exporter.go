@@ -0,0 +1,239 @@
+package archive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/bnb-chain/bsc-mev-sentry/log"
+	"github.com/bnb-chain/bsc-mev-sentry/metrics"
+	"github.com/bnb-chain/bsc-mev-sentry/store"
+)
+
+const (
+	// cursorName names the cursor of the exported bids in the bid store
+	cursorName = "archive"
+	// bucketTimeout bounds each call to the bucket
+	bucketTimeout = time.Minute
+	// pruneInterval how often the objects past the retention are deleted
+	pruneInterval = time.Hour
+)
+
+// Source is the bid store the bids are exported from.
+type Source interface {
+	ExportCursor(name string) (int64, error)
+	SetExportCursor(name string, id int64) error
+	BidsAfter(cursor int64, before time.Time, limit int) ([]*store.Bid, error)
+	// KeepUnexported keeps the bids not exported yet by the named exporter from being pruned.
+	KeepUnexported(name string)
+}
+
+// Exporter periodically writes the bids of the bid store, along with their outcomes, to the bucket as gzipped json
+// lines, one object per batch keyed by the day the first bid is received and the ids of the bids, e.g.
+// bsc-mev-sentry/bids/2024/01/02/000000000001-000000010000.jsonl.gz. The id of the last exported bid is kept in the
+// bid store, a batch uploaded but not recorded is uploaded again under the same key.
+type Exporter struct {
+	bucket    Bucket
+	source    Source
+	prefix    string
+	interval  time.Duration
+	delay     time.Duration
+	batchSize int
+	retention time.Duration
+	withArgs  bool
+
+	prunedAt time.Time // accessed by the loop only
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewExporter creates the exporter of the config, a nil exporter is returned if no backend is configured.
+func NewExporter(cfg Config, source Source) (*Exporter, error) {
+	bucket, err := NewBucket(cfg)
+	if err != nil || bucket == nil {
+		return nil, err
+	}
+
+	return NewExporterWithBucket(bucket, cfg, source), nil
+}
+
+// NewExporterWithBucket creates an exporter writing to the given bucket.
+func NewExporterWithBucket(bucket Bucket, cfg Config, source Source) *Exporter {
+	e := &Exporter{
+		bucket:    bucket,
+		source:    source,
+		prefix:    cfg.Prefix,
+		interval:  time.Duration(cfg.Interval),
+		delay:     time.Duration(cfg.Delay),
+		batchSize: cfg.BatchSize,
+		retention: time.Duration(cfg.Retention),
+		withArgs:  cfg.IncludeArgs,
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	if e.prefix == "" {
+		e.prefix = defaultPrefix
+	}
+	if e.interval <= 0 {
+		e.interval = defaultInterval
+	}
+	if e.delay <= 0 {
+		e.delay = defaultDelay
+	}
+	if e.batchSize <= 0 {
+		e.batchSize = defaultBatchSize
+	}
+	source.KeepUnexported(cursorName)
+
+	return e
+}
+
+// Start exports the bids in the background.
+func (e *Exporter) Start() error {
+	go e.loop()
+
+	return nil
+}
+
+func (e *Exporter) loop() {
+	defer close(e.done)
+
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-e.stop:
+			return
+		case <-ticker.C:
+			e.run()
+		}
+	}
+}
+
+func (e *Exporter) run() {
+	if err := e.Export(); err != nil {
+		metrics.ArchiveExportCounter.WithLabelValues("failed").Inc()
+		log.Errorw("failed to export bids", "bucket", e.bucket.Name(), "err", err)
+	}
+
+	if e.retention > 0 && time.Since(e.prunedAt) >= pruneInterval {
+		if err := e.Prune(); err != nil {
+			log.Errorw("failed to prune exported bids", "bucket", e.bucket.Name(), "err", err)
+			return
+		}
+		e.prunedAt = time.Now()
+	}
+}
+
+// Export writes the bids received before the delay and not exported yet, in batches.
+func (e *Exporter) Export() error {
+	cursor, err := e.source.ExportCursor(cursorName)
+	if err != nil {
+		return err
+	}
+
+	before := time.Now().Add(-e.delay)
+	for {
+		bids, err := e.source.BidsAfter(cursor, before, e.batchSize)
+		if err != nil || len(bids) == 0 {
+			return err
+		}
+
+		if err = e.upload(bids); err != nil {
+			return err
+		}
+		cursor = bids[len(bids)-1].ID
+		if err = e.source.SetExportCursor(cursorName, cursor); err != nil {
+			return err
+		}
+
+		if len(bids) < e.batchSize {
+			return nil
+		}
+
+		select {
+		case <-e.stop:
+			return nil
+		default:
+		}
+	}
+}
+
+func (e *Exporter) upload(bids []*store.Bid) error {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	enc := json.NewEncoder(zw)
+	for _, bid := range bids {
+		// the txs of builders are left out of the bucket unless asked for
+		if !e.withArgs {
+			bid.Args = nil
+		}
+		if err := enc.Encode(bid); err != nil {
+			return err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+
+	first, last := bids[0], bids[len(bids)-1]
+	key := fmt.Sprintf("%s/bids/%s/%012d-%012d.jsonl.gz", e.prefix, first.ReceivedAt.UTC().Format("2006/01/02"),
+		first.ID, last.ID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), bucketTimeout)
+	defer cancel()
+
+	if err := e.bucket.Put(ctx, key, buf.Bytes(), "application/gzip"); err != nil {
+		return err
+	}
+
+	metrics.ArchiveExportCounter.WithLabelValues("ok").Inc()
+	metrics.ArchiveExportedBidsCounter.Add(float64(len(bids)))
+	metrics.ArchiveExportedBytesCounter.Add(float64(buf.Len()))
+	metrics.ArchiveExportedReceivedAt.Set(float64(last.ReceivedAt.Unix()))
+	log.Infow("bids exported", "bucket", e.bucket.Name(), "key", key, "count", len(bids), "size", buf.Len())
+
+	return nil
+}
+
+// Prune deletes the exported objects older than the retention.
+func (e *Exporter) Prune() error {
+	ctx, cancel := context.WithTimeout(context.Background(), bucketTimeout)
+	defer cancel()
+
+	objects, err := e.bucket.List(ctx, e.prefix+"/bids/")
+	if err != nil {
+		return err
+	}
+
+	expiry := time.Now().Add(-e.retention)
+	for _, object := range objects {
+		if !object.LastModified.Before(expiry) {
+			continue
+		}
+
+		if err = e.bucket.Delete(ctx, object.Key); err != nil {
+			return err
+		}
+		metrics.ArchivePrunedCounter.Inc()
+	}
+
+	return nil
+}
+
+// Stop stops exporting, waiting for the export in progress.
+func (e *Exporter) Stop(ctx context.Context) error {
+	close(e.stop)
+
+	select {
+	case <-e.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,135 @@
+package archive
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math/big"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/bsc-mev-sentry/store"
+	"github.com/bnb-chain/bsc-mev-sentry/utils"
+)
+
+type memoryBucket struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	times   map[string]time.Time
+}
+
+func newMemoryBucket() *memoryBucket {
+	return &memoryBucket{objects: make(map[string][]byte), times: make(map[string]time.Time)}
+}
+
+func (b *memoryBucket) Name() string {
+	return "memory"
+}
+
+func (b *memoryBucket) Put(_ context.Context, key string, body []byte, _ string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.objects[key], b.times[key] = body, time.Now()
+	return nil
+}
+
+func (b *memoryBucket) List(_ context.Context, _ string) ([]Object, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var objects []Object
+	for key, t := range b.times {
+		objects = append(objects, Object{Key: key, LastModified: t})
+	}
+	return objects, nil
+}
+
+func (b *memoryBucket) Delete(_ context.Context, key string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.objects, key)
+	delete(b.times, key)
+	return nil
+}
+
+func (b *memoryBucket) keys() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	keys := make([]string, 0, len(b.objects))
+	for key := range b.objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestExporter(t *testing.T) {
+	s, err := store.OpenBidStore(store.BidStoreConfig{Driver: "sqlite", DSN: filepath.Join(t.TempDir(), "bids.db"),
+		KeepArgs: true}, nil)
+	require.NoError(t, err)
+	defer s.Close()
+
+	received := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		var args json.RawMessage
+		if i == 0 {
+			args = json.RawMessage(`{"signature": "0x01"}`)
+		}
+		s.Record(&store.Bid{
+			Hash:       common.BigToHash(big.NewInt(int64(i))),
+			Builder:    common.HexToAddress("0x01"),
+			Validator:  "validator",
+			GasFee:     big.NewInt(1),
+			BuilderFee: big.NewInt(0),
+			ReceivedAt: received,
+			Outcome:    store.BidWon,
+			Args:       args,
+		})
+	}
+	// a recent bid waits for its outcome to be settled
+	s.Record(&store.Bid{Hash: common.HexToHash("0x10"), ReceivedAt: time.Now(), Outcome: store.BidAccepted})
+	require.Eventually(t, func() bool {
+		bids, err := s.BidsAfter(0, time.Now().Add(time.Minute), 10)
+		return err == nil && len(bids) == 6
+	}, time.Second, 10*time.Millisecond)
+
+	bucket := newMemoryBucket()
+	cfg := Config{BatchSize: 2, Delay: utils.Duration(time.Minute), Retention: utils.Duration(time.Hour),
+		IncludeArgs: true}
+	e := NewExporterWithBucket(bucket, cfg, s)
+
+	require.NoError(t, e.Export())
+	assert.Equal(t, []string{
+		"bsc-mev-sentry/bids/2024/01/02/000000000001-000000000002.jsonl.gz",
+		"bsc-mev-sentry/bids/2024/01/02/000000000003-000000000004.jsonl.gz",
+		"bsc-mev-sentry/bids/2024/01/02/000000000005-000000000005.jsonl.gz",
+	}, bucket.keys())
+
+	lines, err := ReadBids(bytes.NewReader(bucket.objects[bucket.keys()[0]]))
+	require.NoError(t, err)
+	require.Len(t, lines, 2)
+	assert.Equal(t, int64(2), lines[1].ID)
+	assert.Equal(t, store.BidWon, lines[1].Outcome)
+	assert.JSONEq(t, `{"signature": "0x01"}`, string(lines[0].Args))
+	assert.Nil(t, lines[1].Args)
+
+	// exported bids aren't exported again
+	require.NoError(t, e.Export())
+	assert.Len(t, bucket.keys(), 3)
+	cursor, err := s.ExportCursor(cursorName)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), cursor)
+
+	// objects past the retention are deleted
+	key := bucket.keys()[0]
+	bucket.times[key] = time.Now().Add(-2 * time.Hour)
+	require.NoError(t, e.Prune())
+	assert.NotContains(t, bucket.keys(), key)
+	assert.Len(t, bucket.keys(), 2)
+}
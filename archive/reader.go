@@ -0,0 +1,32 @@
+package archive
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+
+	"github.com/bnb-chain/bsc-mev-sentry/store"
+)
+
+// ReadBids reads the bids of an exported object, i.e. gzipped json lines, in the order exported.
+func ReadBids(r io.Reader) ([]*store.Bid, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	var bids []*store.Bid
+	dec := json.NewDecoder(bufio.NewReader(zr))
+	for {
+		var bid store.Bid
+		if err = dec.Decode(&bid); err == io.EOF {
+			return bids, nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		bids = append(bids, &bid)
+	}
+}
@@ -0,0 +1,252 @@
+package archive
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	defaultRegion = "us-east-1"
+	amzDateLayout = "20060102T150405Z"
+)
+
+// s3Bucket is a bucket of s3 compatible storage, addressed path style, i.e. <endpoint>/<bucket>/<key>, and signed with
+// signature version 4.
+type s3Bucket struct {
+	client    *http.Client
+	endpoint  *url.URL
+	region    string
+	bucket    string
+	accessKey string
+	secretKey string
+}
+
+func newS3(cfg Config) (*s3Bucket, error) {
+	if cfg.Endpoint == "" || cfg.Bucket == "" {
+		return nil, errors.New("s3: Endpoint and Bucket are required")
+	}
+
+	endpoint, err := url.Parse(cfg.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("s3: invalid Endpoint, %w", err)
+	}
+	if endpoint.Scheme != "http" && endpoint.Scheme != "https" {
+		return nil, fmt.Errorf("s3: unsupported Endpoint scheme %s", endpoint.Scheme)
+	}
+
+	region := cfg.Region
+	if region == "" {
+		region = defaultRegion
+	}
+
+	return &s3Bucket{
+		client:    &http.Client{},
+		endpoint:  endpoint,
+		region:    region,
+		bucket:    cfg.Bucket,
+		accessKey: cfg.AccessKey,
+		secretKey: cfg.SecretKey,
+	}, nil
+}
+
+func (b *s3Bucket) Name() string {
+	return "s3"
+}
+
+func (b *s3Bucket) Put(ctx context.Context, key string, body []byte, contentType string) error {
+	header := http.Header{}
+	header.Set("Content-Type", contentType)
+
+	_, err := b.do(ctx, http.MethodPut, key, nil, header, body)
+	return err
+}
+
+// listResult is the part of a ListObjectsV2 response the bucket reads.
+type listResult struct {
+	Contents []struct {
+		Key          string    `xml:"Key"`
+		LastModified time.Time `xml:"LastModified"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+func (b *s3Bucket) List(ctx context.Context, prefix string) ([]Object, error) {
+	var (
+		objects []Object
+		token   string
+	)
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+
+		body, err := b.do(ctx, http.MethodGet, "", query, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var result listResult
+		if err = xml.Unmarshal(body, &result); err != nil {
+			return nil, fmt.Errorf("s3: invalid list response, %w", err)
+		}
+		for _, c := range result.Contents {
+			objects = append(objects, Object{Key: c.Key, LastModified: c.LastModified})
+		}
+
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return objects, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+func (b *s3Bucket) Delete(ctx context.Context, key string) error {
+	_, err := b.do(ctx, http.MethodDelete, key, nil, nil, nil)
+	return err
+}
+
+// do sends the signed request for the key of the bucket, or the bucket itself if the key is empty, and returns the
+// response body.
+func (b *s3Bucket) do(ctx context.Context, method, key string, query url.Values, header http.Header,
+	body []byte) ([]byte, error) {
+	u := *b.endpoint
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + b.bucket
+	if key != "" {
+		u.Path += "/" + key
+	}
+	u.RawPath = escapePath(u.Path)
+	u.RawQuery = canonicalQuery(query)
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	b.sign(req, body, time.Now())
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		if len(data) > 512 {
+			data = data[:512]
+		}
+		return nil, fmt.Errorf("s3 %s %s: %s %s", method, u.Path, resp.Status, bytes.TrimSpace(data))
+	}
+
+	return data, nil
+}
+
+// sign signs the request with signature version 4, covering the host and every header set on the request.
+func (b *s3Bucket) sign(req *http.Request, body []byte, now time.Time) {
+	sum := sha256.Sum256(body)
+	payloadHash := hex.EncodeToString(sum[:])
+	amzDate := now.UTC().Format(amzDateLayout)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for k, v := range req.Header {
+		headers[strings.ToLower(k)] = strings.TrimSpace(strings.Join(v, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := amzDate[:8] + "/" + b.region + "/s3/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+b.secretKey), amzDate[:8])
+	for _, part := range []string{b.region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+b.accessKey+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// escapePath escapes the path as signature version 4 requires, every byte but the unreserved ones and the slashes.
+func escapePath(path string) string {
+	return strings.ReplaceAll(escape(path), "%2F", "/")
+}
+
+// canonicalQuery encodes the query sorted by key, escaped as signature version 4 requires.
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		for _, v := range query[k] {
+			pairs = append(pairs, escape(k)+"="+escape(v))
+		}
+	}
+
+	return strings.Join(pairs, "&")
+}
+
+func escape(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || strings.IndexByte("-_.~", c) >= 0 {
+			sb.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&sb, "%%%02X", c)
+	}
+
+	return sb.String()
+}
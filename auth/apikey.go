@@ -0,0 +1,57 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+)
+
+// APIKeyHeader is the header carrying the api key of a builder.
+const APIKeyHeader = "X-API-Key"
+
+type apiKeyContextKey struct{}
+
+func WithAPIKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, apiKeyContextKey{}, key)
+}
+
+// APIKeyFromContext returns the api key of the request, empty if not given.
+func APIKeyFromContext(ctx context.Context) string {
+	key, _ := ctx.Value(apiKeyContextKey{}).(string)
+	return key
+}
+
+// HashAPIKey returns the hex encoded sha256 hash of the key, only hashes are kept in config.
+func HashAPIKey(key string) string {
+	h := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(h[:])
+}
+
+// MatchAPIKey tells whether the key matches any of the hashes.
+func MatchAPIKey(key string, hashes []string) bool {
+	if key == "" {
+		return false
+	}
+
+	h := HashAPIKey(key)
+	matched := false
+	for _, hash := range hashes {
+		if subtle.ConstantTimeCompare([]byte(h), []byte(hash)) == 1 {
+			matched = true
+		}
+	}
+
+	return matched
+}
+
+// NewAPIKey generates a random api key.
+func NewAPIKey() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
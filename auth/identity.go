@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Identity is an authenticated identity of the caller of a request.
+type Identity struct {
+	// Method how the caller is authenticated, e.g. mtls, jwt
+	Method string
+	// Names of the caller, e.g. common name and DNS names of a client certificate
+	Names []string
+	// Builder address of the caller, if the authentication method proves it directly
+	Builder *common.Address
+}
+
+type identityContextKey struct{}
+
+// WithIdentity adds an identity to the request context, a request may be authenticated by several methods.
+func WithIdentity(ctx context.Context, id *Identity) context.Context {
+	ids := IdentitiesFromContext(ctx)
+	ids = append(ids[:len(ids):len(ids)], id)
+	return context.WithValue(ctx, identityContextKey{}, ids)
+}
+
+// IdentitiesFromContext returns the authenticated identities of the request.
+func IdentitiesFromContext(ctx context.Context) []*Identity {
+	ids, _ := ctx.Value(identityContextKey{}).([]*Identity)
+	return ids
+}
+
+// Matches tells whether the identity is the builder, which is known by the given names.
+func (id *Identity) Matches(builder common.Address, names []string) bool {
+	if id.Builder != nil {
+		return *id.Builder == builder
+	}
+
+	for _, name := range id.Names {
+		for _, n := range names {
+			if name == n {
+				return true
+			}
+		}
+	}
+
+	return false
+}
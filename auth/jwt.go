@@ -0,0 +1,102 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+type JWTConfig struct {
+	// Enabled requires a valid bearer token on every request
+	Enabled bool
+	// Algorithm signing algorithm of tokens, HS256 or RS256
+	Algorithm string
+	// SecretFile file of the HS256 shared secret
+	SecretFile string
+	// PublicKeyFile PEM file of the RS256 public key
+	PublicKeyFile string
+	// Issuer expected iss claim, not checked if empty
+	Issuer string
+	// Audience expected aud claim, not checked if empty
+	Audience string
+}
+
+// JWTVerifier verifies bearer tokens whose subject is the builder address.
+type JWTVerifier struct {
+	cfg    JWTConfig
+	key    interface{}
+	parser *jwt.Parser
+}
+
+func NewJWTVerifier(cfg JWTConfig) (*JWTVerifier, error) {
+	v := &JWTVerifier{
+		cfg:    cfg,
+		parser: jwt.NewParser(jwt.WithValidMethods([]string{cfg.Algorithm})),
+	}
+
+	switch cfg.Algorithm {
+	case jwt.SigningMethodHS256.Alg():
+		secret, err := os.ReadFile(cfg.SecretFile)
+		if err != nil {
+			return nil, err
+		}
+
+		v.key = []byte(strings.TrimSpace(string(secret)))
+	case jwt.SigningMethodRS256.Alg():
+		pem, err := os.ReadFile(cfg.PublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+
+		var key *rsa.PublicKey
+		if key, err = jwt.ParseRSAPublicKeyFromPEM(pem); err != nil {
+			return nil, err
+		}
+
+		v.key = key
+	default:
+		return nil, fmt.Errorf("unsupported jwt algorithm %q", cfg.Algorithm)
+	}
+
+	return v, nil
+}
+
+// Verify verifies the token and returns the identity of the builder it's issued to.
+func (v *JWTVerifier) Verify(token string) (*Identity, error) {
+	claims := new(jwt.RegisteredClaims)
+	_, err := v.parser.ParseWithClaims(token, claims, func(*jwt.Token) (interface{}, error) {
+		return v.key, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.ExpiresAt == nil {
+		return nil, errors.New("token without expiry")
+	}
+
+	if v.cfg.Issuer != "" && !claims.VerifyIssuer(v.cfg.Issuer, true) {
+		return nil, errors.New("unexpected token issuer")
+	}
+
+	if v.cfg.Audience != "" && !claims.VerifyAudience(v.cfg.Audience, true) {
+		return nil, errors.New("unexpected token audience")
+	}
+
+	if !common.IsHexAddress(claims.Subject) {
+		return nil, errors.New("token subject is not a builder address")
+	}
+
+	builder := common.HexToAddress(claims.Subject)
+
+	return &Identity{
+		Method:  "jwt",
+		Names:   []string{claims.Subject},
+		Builder: &builder,
+	}, nil
+}
@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJWTVerifier(t *testing.T) {
+	secretFile := filepath.Join(t.TempDir(), "secret")
+	require.NoError(t, os.WriteFile(secretFile, []byte("secret\n"), 0600))
+
+	v, err := NewJWTVerifier(JWTConfig{
+		Enabled:    true,
+		Algorithm:  "HS256",
+		SecretFile: secretFile,
+		Issuer:     "operator",
+		Audience:   "sentry",
+	})
+	require.NoError(t, err)
+
+	builder := common.HexToAddress("0x980A75eCd1309eA12fa2ED87A8744fBfc9b863D5")
+	sign := func(claims jwt.RegisteredClaims, secret string) string {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+		require.NoError(t, err)
+		return token
+	}
+	valid := jwt.RegisteredClaims{
+		Issuer:    "operator",
+		Audience:  jwt.ClaimStrings{"sentry"},
+		Subject:   builder.Hex(),
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+	}
+
+	id, err := v.Verify(sign(valid, "secret"))
+	require.NoError(t, err)
+	assert.Equal(t, builder, *id.Builder)
+	assert.True(t, id.Matches(builder, nil))
+
+	_, err = v.Verify(sign(valid, "other"))
+	assert.Error(t, err)
+
+	expired := valid
+	expired.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+	_, err = v.Verify(sign(expired, "secret"))
+	assert.Error(t, err)
+
+	otherAudience := valid
+	otherAudience.Audience = jwt.ClaimStrings{"other"}
+	_, err = v.Verify(sign(otherAudience, "secret"))
+	assert.Error(t, err)
+}
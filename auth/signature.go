@@ -0,0 +1,79 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// SignatureHeader carries the signature of the request body by the builder key.
+const SignatureHeader = "X-Builder-Signature"
+
+// FlashbotsSignatureHeader carries the signature of the request body by the builder key as sent by Flashbots
+// tooling, i.e. "<address>:<signature of the hex encoded body hash as an ethereum signed message>".
+const FlashbotsSignatureHeader = "X-Flashbots-Signature"
+
+// RequestHash returns the hash a builder signs to authenticate a request body.
+func RequestHash(body []byte) common.Hash {
+	return crypto.Keccak256Hash(body)
+}
+
+// VerifyRequestSignature recovers the builder signing the request body from the hex encoded signature.
+func VerifyRequestSignature(body []byte, signature string) (*Identity, error) {
+	sig, err := hexutil.Decode(signature)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sig) != crypto.SignatureLength {
+		return nil, errors.New("invalid signature length")
+	}
+
+	pk, err := crypto.SigToPub(RequestHash(body).Bytes(), sig)
+	if err != nil {
+		return nil, err
+	}
+
+	builder := crypto.PubkeyToAddress(*pk)
+
+	return &Identity{Method: "signature", Builder: &builder}, nil
+}
+
+// VerifyFlashbotsSignature verifies the Flashbots signature header of the request body, the signer must be the
+// address it names.
+func VerifyFlashbotsSignature(body []byte, header string) (*Identity, error) {
+	address, signature, ok := strings.Cut(header, ":")
+	if !ok || !common.IsHexAddress(address) {
+		return nil, errors.New("invalid flashbots signature header")
+	}
+
+	sig, err := hexutil.Decode(signature)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sig) != crypto.SignatureLength {
+		return nil, errors.New("invalid signature length")
+	}
+
+	// personal_sign signatures end with v of 27 or 28
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+
+	pk, err := crypto.SigToPub(accounts.TextHash([]byte(RequestHash(body).Hex())), sig)
+	if err != nil {
+		return nil, err
+	}
+
+	builder := crypto.PubkeyToAddress(*pk)
+	if builder != common.HexToAddress(address) {
+		return nil, errors.New("flashbots signature doesn't match the address")
+	}
+
+	return &Identity{Method: "flashbots_signature", Builder: &builder}, nil
+}
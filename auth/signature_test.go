@@ -0,0 +1,57 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyRequestSignature(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	builder := crypto.PubkeyToAddress(key.PublicKey)
+
+	body := []byte(`{"jsonrpc":"2.0","id":1,"method":"mev_sendBid","params":[]}`)
+	sig, err := crypto.Sign(RequestHash(body).Bytes(), key)
+	require.NoError(t, err)
+
+	id, err := VerifyRequestSignature(body, hexutil.Encode(sig))
+	require.NoError(t, err)
+	assert.Equal(t, "signature", id.Method)
+	assert.True(t, id.Matches(builder, nil))
+
+	// a tampered body recovers another signer
+	id, err = VerifyRequestSignature(append(body, ' '), hexutil.Encode(sig))
+	require.NoError(t, err)
+	assert.False(t, id.Matches(builder, nil))
+
+	_, err = VerifyRequestSignature(body, "0x1234")
+	assert.Error(t, err)
+}
+
+func TestVerifyFlashbotsSignature(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	builder := crypto.PubkeyToAddress(key.PublicKey)
+
+	body := []byte(`{"jsonrpc":"2.0","id":1,"method":"eth_sendBundle","params":[]}`)
+	sig, err := crypto.Sign(accounts.TextHash([]byte(RequestHash(body).Hex())), key)
+	require.NoError(t, err)
+	sig[crypto.RecoveryIDOffset] += 27
+
+	id, err := VerifyFlashbotsSignature(body, builder.Hex()+":"+hexutil.Encode(sig))
+	require.NoError(t, err)
+	assert.Equal(t, "flashbots_signature", id.Method)
+	assert.True(t, id.Matches(builder, nil))
+
+	// a tampered body recovers another signer than the address
+	_, err = VerifyFlashbotsSignature(append(body, ' '), builder.Hex()+":"+hexutil.Encode(sig))
+	assert.Error(t, err)
+
+	_, err = VerifyFlashbotsSignature(body, hexutil.Encode(sig))
+	assert.Error(t, err)
+}
@@ -0,0 +1,101 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bnb-chain/bsc-mev-sentry/utils"
+)
+
+const (
+	defaultPrefix       = "bsc-mev-sentry"
+	defaultSyncInterval = 200 * time.Millisecond
+	defaultBidTTL       = time.Minute
+	defaultNonceTTL     = 10 * time.Minute
+)
+
+type Config struct {
+	// Backend of the state shared by the replicas, redis, disabled if empty
+	Backend string
+	// URL of the redis server, e.g. redis://127.0.0.1:6379/0
+	URL string
+	// Prefix of the keys of the shared state, replicas with the same prefix share the state, defaults to
+	// bsc-mev-sentry
+	Prefix string
+	// SyncInterval how often the pay account nonces and the builder bans are synced, defaults to 200ms
+	SyncInterval utils.Duration
+	// BidTTL how long a bid sent by any replica is remembered to reject duplicates, defaults to 1m
+	BidTTL utils.Duration
+	// NonceTTL how long the highest nonce of a pay account is kept once it's last raised, defaults to 10m
+	NonceTTL utils.Duration
+}
+
+// Ban is a builder banned until the time, for the offense.
+type Ban struct {
+	Builder common.Address `json:"builder"`
+	Reason  string         `json:"reason"`
+	Until   time.Time      `json:"until"`
+}
+
+// Store holds the state shared by the replicas of a sentry behind a load balancer, so that they sign pay bid txs
+// with the same nonces, reject the bids sent to any of them already, and ban builders together.
+type Store interface {
+	Name() string
+	// SyncNonce records the nonce of the pay account if it's higher than the recorded one, and returns the highest
+	// one recorded. The recorded nonce expires ttl after it's last raised, e.g. once the pay account is replaced.
+	SyncNonce(ctx context.Context, payAccount common.Address, nonce uint64, ttl time.Duration) (uint64, error)
+	// MarkBid records the bid sent to the validator for ttl, false if it's recorded already.
+	MarkBid(ctx context.Context, bid common.Hash, validator string, ttl time.Duration) (bool, error)
+	// ForgetBid removes the record of the bid, e.g. once it fails to be sent.
+	ForgetBid(ctx context.Context, bid common.Hash, validator string) error
+	// CountOffense counts the offense of the builder within the window starting at its first offense, and returns
+	// the count of the offense.
+	CountOffense(ctx context.Context, builder common.Address, offense string, window time.Duration) (int, error)
+	// Ban bans the builder and resets its offenses.
+	Ban(ctx context.Context, ban Ban) error
+	// Unban lifts the ban of the builder and resets its offenses, false if it's not banned.
+	Unban(ctx context.Context, builder common.Address) (bool, error)
+	// Bans returns the builders banned at now.
+	Bans(ctx context.Context, now time.Time) ([]Ban, error)
+	Close() error
+}
+
+// New connects the store of the config, a nil store is returned if no backend is configured.
+func New(cfg Config) (Store, error) {
+	if cfg.Prefix == "" {
+		cfg.Prefix = defaultPrefix
+	}
+
+	switch cfg.Backend {
+	case "":
+		return nil, nil
+	case "redis":
+		store, err := newRedis(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return store, nil
+	default:
+		return nil, fmt.Errorf("unsupported cluster backend %s", cfg.Backend)
+	}
+}
+
+// Intervals returns the interval of syncing the nonces and the bans, how long bids are remembered and how long the
+// nonces are kept, defaulted.
+func (c Config) Intervals() (syncInterval, bidTTL, nonceTTL time.Duration) {
+	syncInterval, bidTTL, nonceTTL = time.Duration(c.SyncInterval), time.Duration(c.BidTTL), time.Duration(c.NonceTTL)
+	if syncInterval <= 0 {
+		syncInterval = defaultSyncInterval
+	}
+	if bidTTL <= 0 {
+		bidTTL = defaultBidTTL
+	}
+	if nonceTTL <= 0 {
+		nonceTTL = defaultNonceTTL
+	}
+
+	return syncInterval, bidTTL, nonceTTL
+}
@@ -0,0 +1,212 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/bnb-chain/bsc-mev-sentry/log"
+	"github.com/bnb-chain/bsc-mev-sentry/utils"
+)
+
+const (
+	defaultLockName      = "bsc-mev-sentry-leader"
+	defaultLeaseDuration = 15 * time.Second
+	defaultRenewInterval = 5 * time.Second
+)
+
+type ElectionConfig struct {
+	// Backend of the lock held by the leader, redis or kubernetes, disabled if empty
+	Backend string
+	// URL of the redis server, e.g. redis://127.0.0.1:6379/0
+	URL string
+	// Name of the redis key or the kubernetes lease, defaults to bsc-mev-sentry-leader
+	Name string
+	// Namespace of the kubernetes lease, defaults to the namespace of the pod
+	Namespace string
+	// Identity of the replica, defaults to the hostname
+	Identity string
+	// LeaseDuration how long the leadership lasts unless renewed, defaults to 15s
+	LeaseDuration utils.Duration
+	// RenewInterval how often the leader renews the lease and the standbys try to take it, defaults to 5s
+	RenewInterval utils.Duration
+}
+
+// Lock is held by one replica at a time, the leader.
+type Lock interface {
+	Name() string
+	// Acquire takes the lock for ttl if it's free, expired or held by identity already, and tells whether identity
+	// holds it.
+	Acquire(ctx context.Context, identity string, ttl time.Duration) (bool, error)
+	// Release frees the lock if identity holds it.
+	Release(ctx context.Context, identity string) error
+	Close() error
+}
+
+// Elector elects the replica holding the lock as the leader, the others are standbys taking over once the lease of
+// the leader ends.
+type Elector struct {
+	lock     Lock
+	identity string
+	ttl      time.Duration
+	interval time.Duration
+	onChange func(leader bool)
+
+	leader    atomic.Bool
+	renewedAt time.Time // accessed by the loop only
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewElector creates the elector of the config, a nil elector is returned if no backend is configured. onChange is
+// called once the replica becomes the leader or a standby.
+func NewElector(cfg ElectionConfig, onChange func(leader bool)) (*Elector, error) {
+	if cfg.Name == "" {
+		cfg.Name = defaultLockName
+	}
+
+	var (
+		lock Lock
+		err  error
+	)
+	switch cfg.Backend {
+	case "":
+		return nil, nil
+	case "redis":
+		lock, err = newRedisLock(cfg)
+	case "kubernetes":
+		lock, err = newKubernetesLock(cfg)
+	default:
+		return nil, fmt.Errorf("unsupported election backend %s", cfg.Backend)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return NewElectorWithLock(lock, cfg, onChange)
+}
+
+// NewElectorWithLock creates an elector taking the given lock.
+func NewElectorWithLock(lock Lock, cfg ElectionConfig, onChange func(leader bool)) (*Elector, error) {
+	identity := cfg.Identity
+	if identity == "" {
+		var err error
+		if identity, err = os.Hostname(); err != nil {
+			return nil, err
+		}
+	}
+
+	e := &Elector{
+		lock:     lock,
+		identity: identity,
+		ttl:      time.Duration(cfg.LeaseDuration),
+		interval: time.Duration(cfg.RenewInterval),
+		onChange: onChange,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	if e.ttl <= 0 {
+		e.ttl = defaultLeaseDuration
+	}
+	if e.interval <= 0 {
+		e.interval = defaultRenewInterval
+	}
+	if e.interval >= e.ttl {
+		return nil, fmt.Errorf("election: RenewInterval %v must be shorter than LeaseDuration %v", e.interval, e.ttl)
+	}
+
+	return e, nil
+}
+
+// Leader tells whether the replica is the leader.
+func (e *Elector) Leader() bool {
+	return e.leader.Load()
+}
+
+// Identity returns the identity the replica holds the lock with.
+func (e *Elector) Identity() string {
+	return e.identity
+}
+
+// Start tries to take the lock, and keeps renewing or trying to take it in the background.
+func (e *Elector) Start() error {
+	e.elect()
+	go e.loop()
+
+	return nil
+}
+
+func (e *Elector) loop() {
+	defer close(e.done)
+
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-e.stop:
+			return
+		case <-ticker.C:
+			e.elect()
+		}
+	}
+}
+
+func (e *Elector) elect() {
+	ctx, cancel := context.WithTimeout(context.Background(), e.interval)
+	defer cancel()
+
+	// the lease runs from the request at the latest, however long the lock takes to respond
+	requestedAt := time.Now()
+	acquired, err := e.lock.Acquire(ctx, e.identity, e.ttl)
+	if err != nil {
+		log.Errorw("failed to acquire leader lock", "lock", e.lock.Name(), "identity", e.identity, "err", err)
+
+		// the lease may have ended while the lock is unreachable, another replica may take it over before the next try
+		if e.leader.Load() && time.Since(e.renewedAt) >= e.ttl-e.interval {
+			e.set(false)
+		}
+		return
+	}
+
+	if acquired {
+		e.renewedAt = requestedAt
+	}
+	e.set(acquired)
+}
+
+func (e *Elector) set(leader bool) {
+	if e.leader.Swap(leader) == leader {
+		return
+	}
+
+	if e.onChange != nil {
+		e.onChange(leader)
+	}
+}
+
+// Stop stops renewing the lock and releases it, so that a standby takes over without waiting for the lease to end.
+func (e *Elector) Stop(ctx context.Context) error {
+	close(e.stop)
+
+	select {
+	case <-e.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	var err error
+	if e.leader.Load() {
+		err = e.lock.Release(ctx, e.identity)
+		e.set(false)
+	}
+
+	if closeErr := e.lock.Close(); err == nil {
+		err = closeErr
+	}
+
+	return err
+}
@@ -0,0 +1,163 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/bsc-mev-sentry/utils"
+)
+
+// memoryLock is a Lock within the process.
+type memoryLock struct {
+	mu     sync.Mutex
+	holder string
+	until  time.Time
+	err    error
+}
+
+func (l *memoryLock) Name() string {
+	return "memory"
+}
+
+func (l *memoryLock) Acquire(_ context.Context, identity string, ttl time.Duration) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.err != nil {
+		return false, l.err
+	}
+	if l.holder != "" && l.holder != identity && time.Now().Before(l.until) {
+		return false, nil
+	}
+	l.holder, l.until = identity, time.Now().Add(ttl)
+
+	return true, nil
+}
+
+func (l *memoryLock) Release(_ context.Context, identity string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.holder == identity {
+		l.holder = ""
+	}
+
+	return nil
+}
+
+func (l *memoryLock) Close() error {
+	return nil
+}
+
+func TestElector(t *testing.T) {
+	lock := &memoryLock{}
+	cfg := ElectionConfig{
+		LeaseDuration: utils.Duration(time.Minute),
+		RenewInterval: utils.Duration(10 * time.Millisecond),
+	}
+
+	var (
+		mu      sync.Mutex
+		changes []string
+	)
+	elector := func(identity string) *Elector {
+		cfg.Identity = identity
+		e, err := NewElectorWithLock(lock, cfg, func(leader bool) {
+			mu.Lock()
+			defer mu.Unlock()
+			changes = append(changes, identity+":"+strconv.FormatBool(leader))
+		})
+		require.NoError(t, err)
+		return e
+	}
+
+	first, second := elector("first"), elector("second")
+	require.NoError(t, first.Start())
+	require.NoError(t, second.Start())
+
+	assert.True(t, first.Leader())
+	assert.False(t, second.Leader())
+
+	// the leader hands over on stop
+	require.NoError(t, first.Stop(context.Background()))
+	assert.Eventually(t, second.Leader, time.Second, 5*time.Millisecond)
+	require.NoError(t, second.Stop(context.Background()))
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"first:true", "first:false", "second:true", "second:false"}, changes)
+
+	_, err := NewElectorWithLock(lock, ElectionConfig{
+		Identity:      "third",
+		LeaseDuration: utils.Duration(time.Second),
+		RenewInterval: utils.Duration(time.Second),
+	}, nil)
+	assert.Error(t, err)
+}
+
+func TestKubernetesLock(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		current *lease
+		version int
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+
+		switch r.Method {
+		case http.MethodGet:
+			if current == nil {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(current)
+		case http.MethodPost, http.MethodPut:
+			var next lease
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&next))
+			if current != nil && next.Metadata.ResourceVersion != current.Metadata.ResourceVersion {
+				w.WriteHeader(http.StatusConflict)
+				return
+			}
+			version++
+			next.Metadata.ResourceVersion = strconv.Itoa(version)
+			current = &next
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer srv.Close()
+
+	lock := newKubernetesLockWithClient(srv.Client(), srv.URL, "token", "mev", "leader")
+	ctx := context.Background()
+
+	acquired, err := lock.Acquire(ctx, "first", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+	assert.Equal(t, "first", *current.Spec.HolderIdentity)
+
+	acquired, err = lock.Acquire(ctx, "second", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, acquired)
+
+	// the holder renews
+	acquired, err = lock.Acquire(ctx, "first", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+
+	require.NoError(t, lock.Release(ctx, "first"))
+	acquired, err = lock.Acquire(ctx, "second", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+	assert.Equal(t, "second", *current.Spec.HolderIdentity)
+}
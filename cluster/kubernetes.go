@@ -0,0 +1,246 @@
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// serviceAccountDir holds the credentials of the pod to call the kubernetes api.
+const serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+// microTimeLayout is the layout of the MicroTime fields of a lease.
+const microTimeLayout = "2006-01-02T15:04:05.000000Z07:00"
+
+// lease is the part of a coordination.k8s.io/v1 Lease the lock reads and writes.
+type lease struct {
+	APIVersion string        `json:"apiVersion"`
+	Kind       string        `json:"kind"`
+	Metadata   leaseMetadata `json:"metadata"`
+	Spec       leaseSpec     `json:"spec"`
+}
+
+type leaseMetadata struct {
+	Name            string `json:"name"`
+	Namespace       string `json:"namespace"`
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+}
+
+type leaseSpec struct {
+	HolderIdentity       *string `json:"holderIdentity"`
+	LeaseDurationSeconds *int    `json:"leaseDurationSeconds,omitempty"`
+	AcquireTime          *string `json:"acquireTime,omitempty"`
+	RenewTime            *string `json:"renewTime,omitempty"`
+}
+
+// expired tells whether the lease is free at now, i.e. not held or not renewed within its duration.
+func (l *lease) expired(now time.Time) bool {
+	if l.Spec.HolderIdentity == nil || *l.Spec.HolderIdentity == "" {
+		return true
+	}
+	if l.Spec.RenewTime == nil || l.Spec.LeaseDurationSeconds == nil {
+		return true
+	}
+
+	renewed, err := time.Parse(microTimeLayout, *l.Spec.RenewTime)
+	if err != nil {
+		return true
+	}
+
+	return now.After(renewed.Add(time.Duration(*l.Spec.LeaseDurationSeconds) * time.Second))
+}
+
+// kubernetesLock is a Lease of the kubernetes api, updated with optimistic concurrency so that only one replica
+// takes it.
+type kubernetesLock struct {
+	client    *http.Client
+	url       string // of the leases of the namespace
+	token     string
+	name      string
+	namespace string
+}
+
+// newKubernetesLock creates the lock with the service account of the pod.
+func newKubernetesLock(cfg ElectionConfig) (*kubernetesLock, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, errors.New("kubernetes election must run in a pod")
+	}
+
+	token, err := os.ReadFile(serviceAccountDir + "/token")
+	if err != nil {
+		return nil, err
+	}
+
+	ca, err := os.ReadFile(serviceAccountDir + "/ca.crt")
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, errors.New("invalid service account ca.crt")
+	}
+
+	namespace := cfg.Namespace
+	if namespace == "" {
+		ns, err := os.ReadFile(serviceAccountDir + "/namespace")
+		if err != nil {
+			return nil, err
+		}
+		namespace = strings.TrimSpace(string(ns))
+	}
+
+	client := &http.Client{
+		Timeout:   10 * time.Second,
+		Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}},
+	}
+
+	return newKubernetesLockWithClient(client, "https://"+net.JoinHostPort(host, port), strings.TrimSpace(string(token)),
+		namespace, cfg.Name), nil
+}
+
+func newKubernetesLockWithClient(client *http.Client, server, token, namespace, name string) *kubernetesLock {
+	return &kubernetesLock{
+		client:    client,
+		url:       fmt.Sprintf("%s/apis/coordination.k8s.io/v1/namespaces/%s/leases", server, namespace),
+		token:     token,
+		name:      name,
+		namespace: namespace,
+	}
+}
+
+func (l *kubernetesLock) Name() string {
+	return "kubernetes"
+}
+
+func (l *kubernetesLock) Acquire(ctx context.Context, identity string, ttl time.Duration) (bool, error) {
+	current, err := l.get(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	now := time.Now()
+	if current != nil && !current.expired(now) && *current.Spec.HolderIdentity != identity {
+		return false, nil
+	}
+
+	renewTime := now.UTC().Format(microTimeLayout)
+	seconds := int((ttl + time.Second - 1) / time.Second)
+	next := &lease{
+		APIVersion: "coordination.k8s.io/v1",
+		Kind:       "Lease",
+		Metadata:   leaseMetadata{Name: l.name, Namespace: l.namespace},
+		Spec: leaseSpec{
+			HolderIdentity:       &identity,
+			LeaseDurationSeconds: &seconds,
+			AcquireTime:          &renewTime,
+			RenewTime:            &renewTime,
+		},
+	}
+
+	if current == nil {
+		return l.write(ctx, http.MethodPost, l.url, next)
+	}
+
+	if current.Spec.HolderIdentity != nil && *current.Spec.HolderIdentity == identity {
+		next.Spec.AcquireTime = current.Spec.AcquireTime
+	}
+	next.Metadata.ResourceVersion = current.Metadata.ResourceVersion
+
+	return l.write(ctx, http.MethodPut, l.url+"/"+l.name, next)
+}
+
+func (l *kubernetesLock) Release(ctx context.Context, identity string) error {
+	current, err := l.get(ctx)
+	if err != nil || current == nil {
+		return err
+	}
+	if current.Spec.HolderIdentity == nil || *current.Spec.HolderIdentity != identity {
+		return nil
+	}
+
+	free := ""
+	current.Spec.HolderIdentity = &free
+	_, err = l.write(ctx, http.MethodPut, l.url+"/"+l.name, current)
+
+	return err
+}
+
+func (l *kubernetesLock) Close() error {
+	l.client.CloseIdleConnections()
+	return nil
+}
+
+// get returns the lease, nil if it doesn't exist.
+func (l *kubernetesLock) get(ctx context.Context) (*lease, error) {
+	resp, err := l.do(ctx, http.MethodGet, l.url+"/"+l.name, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		var current lease
+		if err = json.NewDecoder(resp.Body).Decode(&current); err != nil {
+			return nil, err
+		}
+		return &current, nil
+	case http.StatusNotFound:
+		return nil, nil
+	default:
+		return nil, statusError(resp)
+	}
+}
+
+// write creates or updates the lease, false if another replica has written it in between.
+func (l *kubernetesLock) write(ctx context.Context, method, url string, next *lease) (bool, error) {
+	body, err := json.Marshal(next)
+	if err != nil {
+		return false, err
+	}
+
+	resp, err := l.do(ctx, method, url, body)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated:
+		return true, nil
+	case http.StatusConflict:
+		return false, nil
+	default:
+		return false, statusError(resp)
+	}
+}
+
+func (l *kubernetesLock) do(ctx context.Context, method, url string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+l.token)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	return l.client.Do(req)
+}
+
+func statusError(resp *http.Response) error {
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("unexpected status %s: %s", resp.Status, bytes.TrimSpace(msg))
+}
@@ -0,0 +1,144 @@
+package cluster
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+type bidKey struct {
+	bid       common.Hash
+	validator string
+}
+
+type recordedNonce struct {
+	nonce uint64
+	until time.Time
+}
+
+type offenses struct {
+	until  time.Time
+	counts map[string]int
+}
+
+// memoryStore is a Store within the process, for the replicas of tests.
+type memoryStore struct {
+	mu       sync.Mutex
+	nonces   map[common.Address]recordedNonce
+	bids     map[bidKey]time.Time // bid -> when it's forgotten
+	offenses map[common.Address]*offenses
+	bans     map[common.Address]Ban
+}
+
+// NewMemory returns a store shared by the replicas of one process, e.g. in tests.
+func NewMemory() Store {
+	return &memoryStore{
+		nonces:   make(map[common.Address]recordedNonce),
+		bids:     make(map[bidKey]time.Time),
+		offenses: make(map[common.Address]*offenses),
+		bans:     make(map[common.Address]Ban),
+	}
+}
+
+func (s *memoryStore) Name() string {
+	return "memory"
+}
+
+func (s *memoryStore) SyncNonce(_ context.Context, payAccount common.Address, nonce uint64,
+	ttl time.Duration,
+) (uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	recorded, ok := s.nonces[payAccount]
+	if !ok || !now.Before(recorded.until) || nonce > recorded.nonce {
+		recorded = recordedNonce{nonce: nonce, until: now.Add(ttl)}
+		s.nonces[payAccount] = recorded
+	}
+
+	return recorded.nonce, nil
+}
+
+func (s *memoryStore) MarkBid(_ context.Context, bid common.Hash, validator string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	key := bidKey{bid: bid, validator: validator}
+	if until, ok := s.bids[key]; ok && now.Before(until) {
+		return false, nil
+	}
+	s.bids[key] = now.Add(ttl)
+
+	return true, nil
+}
+
+func (s *memoryStore) ForgetBid(_ context.Context, bid common.Hash, validator string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.bids, bidKey{bid: bid, validator: validator})
+
+	return nil
+}
+
+func (s *memoryStore) CountOffense(_ context.Context, builder common.Address, offense string,
+	window time.Duration,
+) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	o, ok := s.offenses[builder]
+	if !ok || !now.Before(o.until) {
+		o = &offenses{until: now.Add(window), counts: make(map[string]int)}
+		s.offenses[builder] = o
+	}
+	o.counts[offense]++
+
+	return o.counts[offense], nil
+}
+
+func (s *memoryStore) Ban(_ context.Context, ban Ban) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.bans[ban.Builder] = ban
+	delete(s.offenses, ban.Builder)
+
+	return nil
+}
+
+func (s *memoryStore) Unban(_ context.Context, builder common.Address) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.bans[builder]
+	delete(s.bans, builder)
+	delete(s.offenses, builder)
+
+	return ok, nil
+}
+
+func (s *memoryStore) Bans(_ context.Context, now time.Time) ([]Ban, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	bans := make([]Ban, 0, len(s.bans))
+	for builder, ban := range s.bans {
+		if !now.Before(ban.Until) {
+			delete(s.bans, builder)
+			continue
+		}
+		bans = append(bans, ban)
+	}
+
+	return bans, nil
+}
+
+func (s *memoryStore) Close() error {
+	return nil
+}
@@ -0,0 +1,64 @@
+package cluster
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemory()
+
+	payAccount := common.HexToAddress("0x0a")
+	nonce, err := store.SyncNonce(ctx, payAccount, 10, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(10), nonce)
+	// a replica behind the chain gets the highest nonce
+	nonce, err = store.SyncNonce(ctx, payAccount, 9, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(10), nonce)
+	// another pay account has its own nonce
+	nonce, _ = store.SyncNonce(ctx, common.HexToAddress("0x0b"), 3, time.Minute)
+	assert.Equal(t, uint64(3), nonce)
+	// the highest nonce expires once it's not raised for the ttl
+	_, _ = store.SyncNonce(ctx, payAccount, 20, time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	nonce, _ = store.SyncNonce(ctx, payAccount, 9, time.Minute)
+	assert.Equal(t, uint64(9), nonce)
+
+	bid := common.HexToHash("0x01")
+	first, err := store.MarkBid(ctx, bid, "bsc-fuji", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, first)
+	first, _ = store.MarkBid(ctx, bid, "bsc-fuji", time.Minute)
+	assert.False(t, first)
+	// the same bid may be sent to another validator
+	first, _ = store.MarkBid(ctx, bid, "bsc-chapel", time.Minute)
+	assert.True(t, first)
+	require.NoError(t, store.ForgetBid(ctx, bid, "bsc-fuji"))
+	first, _ = store.MarkBid(ctx, bid, "bsc-fuji", time.Minute)
+	assert.True(t, first)
+
+	builder := common.HexToAddress("0x02")
+	count, _ := store.CountOffense(ctx, builder, "duplicate", time.Minute)
+	assert.Equal(t, 1, count)
+	count, _ = store.CountOffense(ctx, builder, "duplicate", time.Minute)
+	assert.Equal(t, 2, count)
+
+	now := time.Now()
+	require.NoError(t, store.Ban(ctx, Ban{Builder: builder, Reason: "duplicate", Until: now.Add(time.Minute)}))
+	// the offenses restart after a ban
+	count, _ = store.CountOffense(ctx, builder, "duplicate", time.Minute)
+	assert.Equal(t, 1, count)
+
+	bans, err := store.Bans(ctx, now)
+	require.NoError(t, err)
+	assert.Len(t, bans, 1)
+	bans, _ = store.Bans(ctx, now.Add(time.Minute))
+	assert.Empty(t, bans)
+}
@@ -0,0 +1,192 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/redis/go-redis/v9"
+)
+
+// syncNonceScript keeps the highest nonce of the pay account in the key, expiring once it's not raised for the ttl,
+// and returns it.
+var syncNonceScript = redis.NewScript(`
+local recorded = tonumber(redis.call('GET', KEYS[1]) or '0')
+local nonce = tonumber(ARGV[1])
+if nonce > recorded then
+	redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+	return nonce
+end
+return recorded
+`)
+
+// countOffenseScript counts the offense in the hash of the builder, which expires once the window ends.
+var countOffenseScript = redis.NewScript(`
+local count = redis.call('HINCRBY', KEYS[1], ARGV[1], 1)
+if redis.call('PTTL', KEYS[1]) < 0 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return count
+`)
+
+// redisStore keeps the bans in a hash, and the nonces, the bids and the offenses in keys expiring with them.
+type redisStore struct {
+	client *redis.Client
+	prefix string
+}
+
+func newRedis(cfg Config) (*redisStore, error) {
+	opts, err := redis.ParseURL(cfg.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &redisStore{client: redis.NewClient(opts), prefix: cfg.Prefix}, nil
+}
+
+func (s *redisStore) Name() string {
+	return "redis"
+}
+
+func (s *redisStore) key(parts ...string) string {
+	key := s.prefix
+	for _, part := range parts {
+		key += ":" + part
+	}
+
+	return key
+}
+
+func (s *redisStore) SyncNonce(ctx context.Context, payAccount common.Address, nonce uint64,
+	ttl time.Duration,
+) (uint64, error) {
+	return syncNonceScript.Run(ctx, s.client, []string{s.key("nonce", payAccount.Hex())}, nonce,
+		ttl.Milliseconds()).Uint64()
+}
+
+func (s *redisStore) MarkBid(ctx context.Context, bid common.Hash, validator string, ttl time.Duration) (bool, error) {
+	return s.client.SetNX(ctx, s.key("bid", bid.Hex(), validator), 1, ttl).Result()
+}
+
+func (s *redisStore) ForgetBid(ctx context.Context, bid common.Hash, validator string) error {
+	return s.client.Del(ctx, s.key("bid", bid.Hex(), validator)).Err()
+}
+
+func (s *redisStore) CountOffense(ctx context.Context, builder common.Address, offense string,
+	window time.Duration,
+) (int, error) {
+	return countOffenseScript.Run(ctx, s.client, []string{s.key("offenses", builder.Hex())}, offense,
+		window.Milliseconds()).Int()
+}
+
+func (s *redisStore) Ban(ctx context.Context, ban Ban) error {
+	data, err := json.Marshal(ban)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, s.key("bans"), ban.Builder.Hex(), data)
+		pipe.Del(ctx, s.key("offenses", ban.Builder.Hex()))
+		return nil
+	})
+
+	return err
+}
+
+func (s *redisStore) Unban(ctx context.Context, builder common.Address) (bool, error) {
+	var removed *redis.IntCmd
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		removed = pipe.HDel(ctx, s.key("bans"), builder.Hex())
+		pipe.Del(ctx, s.key("offenses", builder.Hex()))
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return removed.Val() > 0, nil
+}
+
+func (s *redisStore) Bans(ctx context.Context, now time.Time) ([]Ban, error) {
+	values, err := s.client.HGetAll(ctx, s.key("bans")).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	bans := make([]Ban, 0, len(values))
+	var expired []string
+	for field, value := range values {
+		var ban Ban
+		if err = json.Unmarshal([]byte(value), &ban); err != nil {
+			return nil, fmt.Errorf("invalid ban of %s, %w", field, err)
+		}
+
+		if !now.Before(ban.Until) {
+			expired = append(expired, field)
+			continue
+		}
+		bans = append(bans, ban)
+	}
+
+	if len(expired) > 0 {
+		s.client.HDel(ctx, s.key("bans"), expired...)
+	}
+
+	return bans, nil
+}
+
+func (s *redisStore) Close() error {
+	return s.client.Close()
+}
+
+// acquireLockScript sets the key to the identity for ttl if it's free or held by the identity already.
+var acquireLockScript = redis.NewScript(`
+local holder = redis.call('GET', KEYS[1])
+if holder == false or holder == ARGV[1] then
+	redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+	return 1
+end
+return 0
+`)
+
+// releaseLockScript deletes the key if it's held by the identity.
+var releaseLockScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// redisLock is a key holding the identity of the leader, expiring unless renewed.
+type redisLock struct {
+	client *redis.Client
+	key    string
+}
+
+func newRedisLock(cfg ElectionConfig) (*redisLock, error) {
+	opts, err := redis.ParseURL(cfg.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &redisLock{client: redis.NewClient(opts), key: cfg.Name}, nil
+}
+
+func (l *redisLock) Name() string {
+	return "redis"
+}
+
+func (l *redisLock) Acquire(ctx context.Context, identity string, ttl time.Duration) (bool, error) {
+	return acquireLockScript.Run(ctx, l.client, []string{l.key}, identity, ttl.Milliseconds()).Bool()
+}
+
+func (l *redisLock) Release(ctx context.Context, identity string) error {
+	return releaseLockScript.Run(ctx, l.client, []string{l.key}, identity).Err()
+}
+
+func (l *redisLock) Close() error {
+	return l.client.Close()
+}
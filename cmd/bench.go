@@ -0,0 +1,355 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// benchTimeout bounds each bid sent by the bench
+const benchTimeout = 5 * time.Second
+
+type benchOptions struct {
+	target      string
+	host        string
+	apiKey      string
+	key         *ecdsa.PrivateKey
+	rate        float64
+	duration    time.Duration
+	concurrency int
+	txs         int
+	chainID     *big.Int
+	chainRPC    string
+	block       uint64
+	parent      common.Hash
+}
+
+// bench sends synthetic bids signed by a builder key to a sentry at a fixed rate, and reports the latency
+// percentiles and the errors, to size the RPCConcurrency and the transport settings of the sentry.
+func bench(args []string) int {
+	fs := flag.NewFlagSet("bench", flag.ExitOnError)
+	target := fs.String("target", "", "url of the sentry the bids are sent to")
+	host := fs.String("host", "", "host header of the requests, i.e. the public hostname of the validator bid to")
+	apiKey := fs.String("api-key", "", "api key sent in the X-API-Key header, e.g. to a sentry requiring one")
+	key := fs.String("key", "", "hex private key of the builder signing the bids and their txs, a random one if empty")
+	rate := fs.Float64("rate", 100, "bids sent per second")
+	duration := fs.Duration("duration", 30*time.Second, "how long the bids are sent")
+	concurrency := fs.Int("concurrency", 64, "bids in flight at most, the bids beyond are skipped and counted")
+	txs := fs.Int("txs", 1, "txs of each bid, transfers of the builder to itself")
+	chainID := fs.Int64("chain-id", 56, "chain id the txs of the bids are signed for")
+	chainRPC := fs.String("chain-rpc", "", "rpc url of the chain whose head the bids follow, e.g. for the bid window")
+	block := fs.Uint64("block", 0, "block number of the bids if -chain-rpc isn't set")
+	parent := fs.String("parent", "", "parent hash of the bids if -chain-rpc isn't set")
+	_ = fs.Parse(args)
+
+	if *target == "" {
+		fmt.Println("-target is required")
+		return 2
+	}
+	if *rate <= 0 || *concurrency <= 0 {
+		fmt.Println("-rate and -concurrency must be positive")
+		return 2
+	}
+
+	opts := benchOptions{
+		target:      *target,
+		host:        *host,
+		apiKey:      *apiKey,
+		rate:        *rate,
+		duration:    *duration,
+		concurrency: *concurrency,
+		txs:         *txs,
+		chainID:     big.NewInt(*chainID),
+		chainRPC:    *chainRPC,
+		block:       *block,
+		parent:      common.HexToHash(*parent),
+	}
+
+	var err error
+	if *key == "" {
+		opts.key, err = crypto.GenerateKey()
+	} else {
+		opts.key, err = crypto.HexToECDSA(strings.TrimPrefix(*key, "0x"))
+	}
+	if err != nil {
+		fmt.Printf("invalid key: %v\n", err)
+		return 2
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	report, err := runBench(ctx, opts)
+	if err != nil {
+		fmt.Printf("failed to bench: %v\n", err)
+		return 1
+	}
+	report.print(os.Stdout)
+
+	return 0
+}
+
+// hostTransport sets the host header of the requests, which the sentry routes bids by.
+type hostTransport struct {
+	host string
+	next http.RoundTripper
+}
+
+func (t hostTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Host = t.host
+	return t.next.RoundTrip(req)
+}
+
+// benchHead is the block the bids are built on.
+type benchHead struct {
+	number uint64
+	parent common.Hash
+}
+
+func runBench(ctx context.Context, opts benchOptions) (*benchReport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = opts.concurrency
+	clientOpts := []rpc.ClientOption{rpc.WithHTTPClient(&http.Client{Transport: transport})}
+	if opts.host != "" {
+		clientOpts[0] = rpc.WithHTTPClient(&http.Client{Transport: hostTransport{host: opts.host, next: transport}})
+	}
+	if opts.apiKey != "" {
+		clientOpts = append(clientOpts, rpc.WithHeader("X-API-Key", opts.apiKey))
+	}
+
+	client, err := rpc.DialOptions(ctx, opts.target, clientOpts...)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, opts.duration)
+	defer cancel()
+
+	var head atomic.Pointer[benchHead]
+	head.Store(&benchHead{number: opts.block, parent: opts.parent})
+	if opts.chainRPC != "" {
+		chain, err := ethclient.DialContext(ctx, opts.chainRPC)
+		if err != nil {
+			return nil, err
+		}
+		defer chain.Close()
+
+		if err = followHead(ctx, chain, &head); err != nil {
+			return nil, err
+		}
+		go func() {
+			ticker := time.NewTicker(time.Second)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					_ = followHead(ctx, chain, &head)
+				}
+			}
+		}()
+	}
+
+	builder := crypto.PubkeyToAddress(opts.key.PublicKey)
+	signer := types.LatestSignerForChainID(opts.chainID)
+	report := newBenchReport()
+
+	var (
+		wg    sync.WaitGroup
+		slots = make(chan struct{}, opts.concurrency)
+		nonce uint64
+	)
+	ticker := time.NewTicker(time.Duration(float64(time.Second) / opts.rate))
+	defer ticker.Stop()
+
+	start := time.Now()
+loop:
+	for {
+		select {
+		case <-ctx.Done():
+			break loop
+		case <-ticker.C:
+		}
+
+		select {
+		case slots <- struct{}{}:
+		default:
+			report.skip()
+			continue
+		}
+
+		h := head.Load()
+		args, err := newBenchBid(opts.key, signer, builder, h, opts.txs, nonce)
+		if err != nil {
+			return nil, err
+		}
+		nonce += uint64(opts.txs)
+
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+
+			sendCtx, cancel := context.WithTimeout(context.Background(), benchTimeout)
+			defer cancel()
+
+			sent := time.Now()
+			var hash common.Hash
+			err := client.CallContext(sendCtx, &hash, "mev_sendBid", args)
+			report.add(time.Since(sent), err)
+		}()
+	}
+
+	wg.Wait()
+	report.elapsed = time.Since(start)
+
+	return report, nil
+}
+
+func followHead(ctx context.Context, chain *ethclient.Client, head *atomic.Pointer[benchHead]) error {
+	header, err := chain.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	head.Store(&benchHead{number: header.Number.Uint64() + 1, parent: header.Hash()})
+	return nil
+}
+
+// newBenchBid builds a bid of self transfers of the builder on the head, signed by the builder.
+func newBenchBid(key *ecdsa.PrivateKey, signer types.Signer, builder common.Address, head *benchHead, txs int,
+	nonce uint64) (*types.BidArgs, error) {
+	gasPrice := big.NewInt(1e9)
+	bid := &types.RawBid{
+		BlockNumber: head.number,
+		ParentHash:  head.parent,
+		Txs:         make([]hexutil.Bytes, 0, txs),
+		GasUsed:     uint64(txs) * 21000,
+		BuilderFee:  big.NewInt(0),
+	}
+	bid.GasFee = new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(bid.GasUsed))
+
+	for i := 0; i < txs; i++ {
+		tx, err := types.SignNewTx(key, signer, &types.LegacyTx{
+			Nonce:    nonce + uint64(i),
+			GasPrice: gasPrice,
+			Gas:      21000,
+			To:       &builder,
+		})
+		if err != nil {
+			return nil, err
+		}
+		raw, err := tx.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		bid.Txs = append(bid.Txs, raw)
+	}
+
+	signature, err := crypto.Sign(bid.Hash().Bytes(), key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.BidArgs{RawBid: bid, Signature: signature}, nil
+}
+
+// benchReport collects the latencies of the bids answered and the errors.
+type benchReport struct {
+	mu        sync.Mutex
+	latencies []time.Duration
+	errors    map[string]int
+	ok        int
+	skipped   int
+	elapsed   time.Duration
+}
+
+func newBenchReport() *benchReport {
+	return &benchReport{errors: make(map[string]int)}
+}
+
+func (r *benchReport) add(latency time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.latencies = append(r.latencies, latency)
+	if err == nil {
+		r.ok++
+		return
+	}
+
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) {
+		r.errors[fmt.Sprintf("%d %s", rpcErr.ErrorCode(), rpcErr.Error())]++
+	} else {
+		r.errors[err.Error()]++
+	}
+}
+
+func (r *benchReport) skip() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.skipped++
+}
+
+func (r *benchReport) percentile(p float64) time.Duration {
+	if len(r.latencies) == 0 {
+		return 0
+	}
+
+	return r.latencies[int(p*float64(len(r.latencies)-1))]
+}
+
+func (r *benchReport) print(w io.Writer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	sort.Slice(r.latencies, func(i, j int) bool { return r.latencies[i] < r.latencies[j] })
+
+	sent := len(r.latencies)
+	failed := sent - r.ok
+	fmt.Fprintf(w, "%d bids sent in %s, %.1f/s, %d skipped at the concurrency limit\n", sent,
+		r.elapsed.Round(time.Millisecond), float64(sent)/r.elapsed.Seconds(), r.skipped)
+	if sent == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "%d ok, %d failed (%.2f%%)\n", r.ok, failed, 100*float64(failed)/float64(sent))
+	fmt.Fprintf(w, "latency p50 %s p90 %s p99 %s max %s\n", r.percentile(0.5), r.percentile(0.9),
+		r.percentile(0.99), r.latencies[sent-1])
+
+	messages := make([]string, 0, len(r.errors))
+	for message := range r.errors {
+		messages = append(messages, message)
+	}
+	sort.Slice(messages, func(i, j int) bool { return r.errors[messages[i]] > r.errors[messages[j]] })
+	for _, message := range messages {
+		fmt.Fprintf(w, "%8d %s\n", r.errors[message], message)
+	}
+}
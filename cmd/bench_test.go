@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type benchTarget struct {
+	builder common.Address
+	calls   atomic.Int64
+}
+
+func (b *benchTarget) SendBid(args types.BidArgs) (common.Hash, error) {
+	if b.calls.Add(1)%2 == 0 {
+		return common.Hash{}, errors.New("bid rejected")
+	}
+
+	builder, err := args.EcrecoverSender()
+	if err != nil || builder != b.builder {
+		return common.Hash{}, errors.New("invalid signature")
+	}
+	return args.RawBid.Hash(), nil
+}
+
+func TestBench(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	target := &benchTarget{builder: crypto.PubkeyToAddress(key.PublicKey)}
+	server := rpc.NewServer()
+	require.NoError(t, server.RegisterName("mev", target))
+	srv := httptest.NewServer(server)
+	defer srv.Close()
+
+	report, err := runBench(context.Background(), benchOptions{
+		target:      srv.URL,
+		host:        "bsc-fuji",
+		key:         key,
+		rate:        100,
+		duration:    300 * time.Millisecond,
+		concurrency: 8,
+		txs:         2,
+		chainID:     big.NewInt(56),
+		block:       1,
+	})
+	require.NoError(t, err)
+
+	sent := len(report.latencies)
+	assert.Greater(t, sent, 5)
+	assert.Equal(t, int64(sent), target.calls.Load())
+	assert.Equal(t, sent-sent/2, report.ok)
+	assert.Equal(t, sent/2, report.errors["-32000 bid rejected"])
+
+	var out strings.Builder
+	report.print(&out)
+	assert.Contains(t, out.String(), "latency p50")
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/bnb-chain/bsc-mev-sentry/config"
+)
+
+// checkConfig reports the problems of the config file, it exits nonzero if any is found.
+func checkConfig(args []string) int {
+	fs := flag.NewFlagSet("check-config", flag.ExitOnError)
+	configPath := fs.String("config", "./configs/config.toml", "mev-sentry config file path")
+	noDial := fs.Bool("no-dial", false, "skip dialing the validators and builders")
+	dialTimeout := fs.Duration("dial-timeout", 3*time.Second, "timeout of dialing each validator and builder")
+	_ = fs.Parse(args)
+
+	report := config.Check(*configPath, config.CheckOptions{Dial: !*noDial, DialTimeout: *dialTimeout})
+	fmt.Print(report)
+
+	if !report.OK() {
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+)
+
+const configTemplate = `# Generated by sentry init, see configs/config-example.toml for every setting.
+# Check it with: sentry check-config -config <this file>
+
+[Service]
+HTTPListenAddr = "localhost:8555" # The address to listen on for HTTP requests.
+RPCConcurrency = 100 # The maximum number of concurrent requests.
+RPCTimeout = "10s" # The timeout of RPC requests.
+AdminListenAddr = "" # The address to listen on for admin requests, e.g. "localhost:8556", admin service is disabled if empty.
+AdminToken = "" # The bearer token required by admin requests, set it along with AdminListenAddr, no auth if empty.
+{{range .Validators}}
+[[Validators]]
+PrivateURL = "{{.PrivateURL}}" # The private rpc url of the validator, only reachable by the sentry.
+PublicHostName = "{{.PublicHostName}}" # The hostname builders send the bids of this validator to.
+PayAccountMode = "{{.PayAccountMode}}" # How the pay account paying builders is loaded, keystore or privateKey.
+{{- if eq .PayAccountMode "keystore"}}
+KeystorePath = "{{.KeystorePath}}" # The keystore directory of the pay account.
+PasswordFilePath = "{{.PasswordFilePath}}" # The keystore password file, removed once the account is unlocked on start.
+PayAccountAddress = "{{.PayAccountAddress}}" # The address of the pay account, fund it before taking bids.
+{{- else}}
+PrivateKey = "" # The hex private key of a new pay account, never the key of the validator.
+{{- end}}
+{{end}}
+# [[Builders]]
+# Address = "0x..." # The address of the builder.
+# URL = "http://..." # The public URL of the builder.
+
+[Log]
+RootDir = "./logs" # The directory of the log files.
+Level = "info" # The log level, debug, info, warn or error.
+MaxAge = "168h" # Remove the rotated log files older, kept if 0.
+`
+
+type initOptions struct {
+	output      string
+	validators  int
+	accountMode string
+	keystoreDir string
+	force       bool
+}
+
+type initValidator struct {
+	PublicHostName    string
+	PrivateURL        string
+	PayAccountMode    string
+	KeystorePath      string
+	PasswordFilePath  string
+	PayAccountAddress string
+}
+
+// initConfig scaffolds a commented config, generating a keystore for the pay account of every validator.
+func initConfig(args []string) int {
+	var opts initOptions
+	fs := flag.NewFlagSet("init", flag.ExitOnError)
+	fs.StringVar(&opts.output, "output", "./configs/config.toml", "path of the generated config file")
+	fs.IntVar(&opts.validators, "validators", 1, "number of validators")
+	fs.StringVar(&opts.accountMode, "account-mode", "keystore", "pay account mode, keystore or privateKey")
+	fs.StringVar(&opts.keystoreDir, "keystore-dir", "./keystore", "directory of the generated pay account keystores")
+	fs.BoolVar(&opts.force, "force", false, "overwrite the config file if it exists")
+	interactive := fs.Bool("interactive", false, "prompt for the validators and the pay account mode")
+	_ = fs.Parse(args)
+
+	var prompt func(label, value string) string
+	if *interactive {
+		prompt = newPrompt(os.Stdin, os.Stdout)
+	}
+
+	if err := scaffold(opts, prompt); err != nil {
+		fmt.Fprintf(os.Stderr, "init: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("config written to %s\n", opts.output)
+	if opts.accountMode == "keystore" {
+		fmt.Printf("pay account keystores written to %s, fund the pay accounts before taking bids\n", opts.keystoreDir)
+	}
+
+	return 0
+}
+
+// scaffold writes the config of the options, asking for them by prompt if set.
+func scaffold(opts initOptions, prompt func(label, value string) string) error {
+	if prompt != nil {
+		n, err := strconv.Atoi(prompt("number of validators", strconv.Itoa(opts.validators)))
+		if err != nil {
+			return fmt.Errorf("invalid number of validators: %w", err)
+		}
+		opts.validators = n
+		opts.accountMode = prompt("pay account mode, keystore or privateKey", opts.accountMode)
+	}
+
+	if opts.validators < 1 {
+		return errors.New("at least one validator is required")
+	}
+	if opts.accountMode != "keystore" && opts.accountMode != "privateKey" {
+		return fmt.Errorf("unsupported pay account mode %s", opts.accountMode)
+	}
+
+	if _, err := os.Stat(opts.output); err == nil && !opts.force {
+		return fmt.Errorf("%s exists, use -force to overwrite it", opts.output)
+	}
+
+	validators := make([]initValidator, opts.validators)
+	for i := range validators {
+		v := &validators[i]
+		v.PublicHostName = fmt.Sprintf("bsc-validator-%d", i+1)
+		v.PrivateURL = fmt.Sprintf("http://10.0.0.%d:8545", i+1)
+		v.PayAccountMode = opts.accountMode
+
+		if prompt != nil {
+			v.PublicHostName = prompt(fmt.Sprintf("validator #%d public hostname", i+1), v.PublicHostName)
+			v.PrivateURL = prompt(fmt.Sprintf("validator #%d private url", i+1), v.PrivateURL)
+		}
+
+		if opts.accountMode == "keystore" {
+			if err := newPayAccount(v, opts.keystoreDir); err != nil {
+				return err
+			}
+		}
+	}
+
+	tmpl, err := template.New("config").Parse(configTemplate)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(opts.output), 0o755); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(opts.output, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return tmpl.Execute(f, struct{ Validators []initValidator }{validators})
+}
+
+// newPayAccount generates the pay account of the validator into the keystore, with a random password.
+func newPayAccount(v *initValidator, keystoreDir string) error {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return err
+	}
+	password := hex.EncodeToString(secret)
+
+	ks := keystore.NewKeyStore(keystoreDir, keystore.StandardScryptN, keystore.StandardScryptP)
+	acc, err := ks.NewAccount(password)
+	if err != nil {
+		return fmt.Errorf("failed to generate pay account: %w", err)
+	}
+
+	v.KeystorePath = keystoreDir
+	v.PasswordFilePath = filepath.Join(keystoreDir, v.PublicHostName+".password")
+	v.PayAccountAddress = acc.Address.Hex()
+
+	return os.WriteFile(v.PasswordFilePath, []byte(password), 0o600)
+}
+
+// newPrompt returns a prompt reading the answers by line, the given value is kept if the answer is empty.
+func newPrompt(in io.Reader, out io.Writer) func(label, value string) string {
+	reader := bufio.NewReader(in)
+
+	return func(label, value string) string {
+		fmt.Fprintf(out, "%s [%s]: ", label, value)
+
+		answer, _ := reader.ReadString('\n')
+		if answer = strings.TrimSpace(answer); answer != "" {
+			return answer
+		}
+
+		return value
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/bsc-mev-sentry/config"
+)
+
+func TestScaffold(t *testing.T) {
+	dir := t.TempDir()
+	opts := initOptions{
+		output:      filepath.Join(dir, "configs", "config.toml"),
+		validators:  1,
+		accountMode: "keystore",
+		keystoreDir: filepath.Join(dir, "keystore"),
+	}
+
+	answers := strings.NewReader("2\n\nbsc-fuji\n\n\n\n")
+	require.NoError(t, scaffold(opts, newPrompt(answers, &strings.Builder{})))
+
+	// the generated keystores unlock with the generated passwords
+	report := config.Check(opts.output, config.CheckOptions{})
+	require.True(t, report.OK(), report.String())
+
+	cfg, err := config.Read(opts.output)
+	require.NoError(t, err)
+	require.Len(t, cfg.Validators, 2)
+	assert.Equal(t, "bsc-fuji", cfg.Validators[0].PublicHostName)
+	assert.Equal(t, "bsc-validator-2", cfg.Validators[1].PublicHostName)
+	assert.NotEqual(t, cfg.Validators[0].PayAccountAddress, cfg.Validators[1].PayAccountAddress)
+	assert.FileExists(t, cfg.Validators[1].PasswordFilePath)
+
+	// an existing config is kept
+	assert.Error(t, scaffold(opts, nil))
+
+	opts.force, opts.accountMode = true, "privateKey"
+	require.NoError(t, scaffold(opts, nil))
+	cfg, err = config.Read(opts.output)
+	require.NoError(t, err)
+	assert.Equal(t, "", cfg.Validators[0].PrivateKey)
+}
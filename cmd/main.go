@@ -1,92 +1,160 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
-	"net/http"
-	_ "net/http/pprof"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
 
-	"github.com/cockroachdb/errors"
-	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/rpc"
-	"github.com/gin-gonic/contrib/gzip"
 	"github.com/gin-gonic/gin"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
 
 	"github.com/bnb-chain/bsc-mev-sentry/config"
-	ginutils "github.com/bnb-chain/bsc-mev-sentry/gin"
 	"github.com/bnb-chain/bsc-mev-sentry/log"
-	"github.com/bnb-chain/bsc-mev-sentry/node"
-	"github.com/bnb-chain/bsc-mev-sentry/service"
+	"github.com/bnb-chain/bsc-mev-sentry/sentry"
 )
 
-const serviceName = "bsc-mev-sentry"
+// command is a subcommand of the sentry, taking its own flags.
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) int
+}
 
-var configPath = flag.String("config", "./configs/config.toml", "mev-sentry config file path")
+// commands are listed in the usage in this order, run is the default without a command.
+var commands = []command{
+	{"run", "serve the bids, the default without a command", run},
+	{"init", "scaffold a commented config", initConfig},
+	{"check-config", "report every problem of a config", checkConfig},
+	{"bench", "load test a sentry", bench},
+	{"replay", "send the stored or exported bids to a target again", replay},
+	{"verify-audit", "verify the chain of an audit log", verifyAudit},
+}
 
 func init() {
 	gin.SetMode(gin.ReleaseMode)
 }
 
 func main() {
-	defer log.Stop()
+	code := execute(os.Args[1:])
+	log.Stop()
+	os.Exit(code)
+}
 
-	flag.Parse()
+// execute runs the command of the args, run if none is given. The flags of run given before the command, e.g.
+// sentry -config x check-config, are passed on to it, and rejected by a command not taking them.
+func execute(args []string) int {
+	root := flag.NewFlagSet("sentry", flag.ContinueOnError)
+	root.Usage = func() { usage(root) }
+	newRunFlags(root)
+	if err := root.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
 
-	cfg := config.Load(*configPath)
-	initLogger(&cfg.Log)
+	name, rest := "run", root.Args()
+	if len(rest) > 0 {
+		name, rest = rest[0], rest[1:]
+	}
 
-	openPrometheusAndPprof(cfg.Debug.ListenAddr)
+	for _, cmd := range commands {
+		if cmd.name != name {
+			continue
+		}
 
-	log.Infow("bsc mev-sentry start", "configPath", *configPath,
-		"validator_count", len(cfg.Validators), "builder_count", len(cfg.Builders))
+		var passed []string
+		root.Visit(func(f *flag.Flag) {
+			passed = append(passed, "-"+f.Name+"="+f.Value.String())
+		})
 
-	validators := make(map[string]node.Validator)
-	for _, v := range cfg.Validators {
-		validator := node.NewValidator(v)
-		if validator != nil {
-			validators[v.PublicHostName] = validator
-		}
+		return cmd.run(append(passed, rest...))
 	}
 
-	builders := make(map[common.Address]node.Builder)
-	for _, b := range cfg.Builders {
-		builder := node.NewBuilder(b)
-		if builder != nil {
-			builders[b.Address] = builder
-		}
+	fmt.Fprintf(root.Output(), "unknown command %s\n\n", name)
+	root.Usage()
+
+	return 2
+}
+
+func usage(root *flag.FlagSet) {
+	out := root.Output()
+	fmt.Fprintln(out, "usage: sentry [flags] [command] [command flags]")
+	fmt.Fprintln(out, "\ncommands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(out, "  %-14s%s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(out, "\nflags of run:")
+	root.PrintDefaults()
+	fmt.Fprintln(out, "\nrun 'sentry <command> -help' for the flags of a command")
+}
+
+// runFlags are the flags of run, overriding the most common settings of the config.
+type runFlags struct {
+	fs         *flag.FlagSet
+	configPath *string
+	listenAddr *string
+	debugAddr  *string
+	logLevel   *string
+	logDir     *string
+}
+
+func newRunFlags(fs *flag.FlagSet) *runFlags {
+	return &runFlags{
+		fs:         fs,
+		configPath: fs.String("config", "./configs/config.toml", "mev-sentry config file path"),
+		listenAddr: fs.String("listen-addr", "", "address the service listens on, overrides Service.HTTPListenAddr"),
+		debugAddr:  fs.String("debug-addr", "", "address the debug server listens on, overrides Debug.ListenAddr"),
+		logLevel:   fs.String("log-level", "", "log level, overrides Log.Level"),
+		logDir:     fs.String("log-dir", "", "log directory, overrides Log.RootDir"),
 	}
+}
 
-	rpcServer := rpc.NewServer()
-	sentryService := service.NewMevSentry(&cfg.Service, validators, builders)
-	if err := rpcServer.RegisterName("mev", sentryService); err != nil {
-		panic(err)
+// run serves the bids until SIGINT or SIGTERM.
+func run(args []string) int {
+	fs := flag.NewFlagSet("run", flag.ExitOnError)
+	flags := newRunFlags(fs)
+	_ = fs.Parse(args)
+	if fs.NArg() > 0 {
+		fmt.Fprintf(fs.Output(), "unexpected arguments %v\n", fs.Args())
+		fs.Usage()
+		return 2
 	}
 
-	app := gin.New()
-	app.Use(
-		ginutils.ConcurrencyLimiter(cfg.Service.RPCConcurrency),
-		ginutils.PanicRecovery(),
-		gzip.Gzip(gzip.DefaultCompression),
-	)
+	s, err := sentry.New(sentry.WithConfigFile(*flags.configPath), sentry.WithConfigOverride(flags.override))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "fail to create sentry, err:%v\n", err)
+		return 1
+	}
+	defer s.Close()
 
-	app.POST("/", gin.WrapH(rpcServer))
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
-	if err := app.Run(cfg.Service.HTTPListenAddr); err != nil {
-		log.Errorf("fail to run rpc server, err:%v", err)
+	if err = s.Run(ctx); err != nil {
+		log.Errorf("fail to run sentry, err:%v", err)
+		return 1
 	}
-}
 
-func initLogger(cfg *config.LogConfig) {
-	lvl, _ := log.ParseLevel(cfg.Level)
-	log.Init(lvl, log.StandardizePath(cfg.RootDir, serviceName))
+	return 0
 }
 
-func openPrometheusAndPprof(addr string) {
-	http.Handle("/debug/metrics/prometheus", promhttp.Handler())
-	log.Infof("prometheus and pprof listen on: %v", addr)
-	go func() {
-		if err := http.ListenAndServe(addr, nil); err != http.ErrServerClosed {
-			log.Errorf("failed to serving prometheus and pprof, err:%v", errors.WithStack(err))
+// override overrides the config with the flags set on the command line, which take precedence over both the
+// config file and the environment variables.
+func (r *runFlags) override(cfg *config.Config) {
+	r.fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "listen-addr":
+			cfg.Service.HTTPListenAddr = *r.listenAddr
+		case "debug-addr":
+			cfg.Debug.ListenAddr = *r.debugAddr
+		case "log-level":
+			cfg.Log.Level = *r.logLevel
+		case "log-dir":
+			cfg.Log.RootDir = *r.logDir
 		}
-	}()
+	})
 }
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecute(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.toml")
+	require.NoError(t, os.WriteFile(file, []byte(`
+[[Validators]]
+PublicHostName = "bsc-fuji"
+`), 0o600))
+
+	// the flags before the command are passed on to it rather than running the sentry
+	assert.Equal(t, 1, execute([]string{"-config", file, "check-config", "-no-dial"}))
+	assert.Equal(t, 1, execute([]string{"check-config", "-config", file, "-no-dial"}))
+
+	assert.Equal(t, 2, execute([]string{"-config", file, "check-cfg"}))
+	assert.Equal(t, 0, execute([]string{"-help"}))
+}
@@ -0,0 +1,323 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/bnb-chain/bsc-mev-sentry/archive"
+	"github.com/bnb-chain/bsc-mev-sentry/config"
+	"github.com/bnb-chain/bsc-mev-sentry/store"
+)
+
+// replayTimeout bounds each bid replayed
+const replayTimeout = 5 * time.Second
+
+// replay sends the bids kept by the bid store, or those of the exported archive files given as arguments, to the
+// target again, e.g. a sentry or a validator, to debug or regression test how it handles them. It exits nonzero if
+// the bids can't be read or the target can't be dialed.
+func replay(args []string) int {
+	fs := flag.NewFlagSet("replay", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: replay -target url [flags] [exported archive files...]")
+		fs.PrintDefaults()
+	}
+	configPath := fs.String("config", "./configs/config.toml", "mev-sentry config file path, whose bid store is read")
+	target := fs.String("target", "", "url of the sentry or the validator the bids are sent to")
+	apiKey := fs.String("api-key", "", "api key sent in the X-API-Key header, e.g. to a sentry requiring one")
+	rate := fs.Float64("rate", 10, "bids sent per second, unlimited if 0")
+	dryRun := fs.Bool("dry-run", false, "print the bids instead of sending them")
+	limit := fs.Int("limit", 0, "bids replayed at most, all if 0")
+	validator := fs.String("validator", "", "replay the bids to the validator only")
+	builder := fs.String("builder", "", "replay the bids of the builder only")
+	outcome := fs.String("outcome", "", "replay the bids of the outcome only, e.g. accepted")
+	fromBlock := fs.Uint64("from-block", 0, "replay the bids from the block")
+	toBlock := fs.Uint64("to-block", 0, "replay the bids up to the block")
+	from := fs.String("from", "", "replay the bids received from the time, RFC 3339")
+	to := fs.String("to", "", "replay the bids received up to the time, RFC 3339")
+	_ = fs.Parse(args)
+
+	if *target == "" && !*dryRun {
+		fmt.Println("-target is required unless -dry-run")
+		return 2
+	}
+
+	filter := store.BidFilter{
+		Validator: *validator,
+		FromBlock: *fromBlock,
+		ToBlock:   *toBlock,
+		Outcome:   *outcome,
+		WithArgs:  true,
+	}
+	if *builder != "" {
+		address := common.HexToAddress(*builder)
+		filter.Builder = &address
+	}
+	for _, t := range []struct {
+		value string
+		unix  *int64
+	}{{*from, &filter.FromTime}, {*to, &filter.ToTime}} {
+		if t.value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, t.value)
+		if err != nil {
+			fmt.Printf("invalid time %s: %v\n", t.value, err)
+			return 2
+		}
+		*t.unix = parsed.Unix()
+	}
+
+	var (
+		source bidSource
+		err    error
+	)
+	if fs.NArg() > 0 {
+		source, err = newArchiveSource(fs.Args(), filter)
+	} else {
+		source, err = newStoreSource(*configPath, filter)
+	}
+	if err != nil {
+		fmt.Printf("failed to read bids: %v\n", err)
+		return 1
+	}
+	defer source.close()
+
+	var client *rpc.Client
+	if !*dryRun {
+		var opts []rpc.ClientOption
+		if *apiKey != "" {
+			opts = append(opts, rpc.WithHeader("X-API-Key", *apiKey))
+		}
+		if client, err = rpc.DialOptions(context.Background(), *target, opts...); err != nil {
+			fmt.Printf("failed to dial %s: %v\n", *target, err)
+			return 1
+		}
+		defer client.Close()
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	var tick <-chan time.Time
+	if *rate > 0 {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / *rate))
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	var stats replayStats
+	for *limit <= 0 || stats.read < *limit {
+		bid, err := source.next()
+		if err != nil {
+			fmt.Printf("failed to read bids: %v\n", err)
+			return 1
+		}
+		if bid == nil {
+			break
+		}
+		stats.read++
+
+		if len(bid.Args) == 0 {
+			// stored without Service.BidStore.KeepArgs
+			stats.skipped++
+			continue
+		}
+
+		if *dryRun {
+			fmt.Printf("%d %s validator %s block %d builder %s was %s\n", bid.ID, bid.Hash, bid.Validator,
+				bid.BlockNumber, bid.Builder, bid.Outcome)
+			continue
+		}
+
+		var bidArgs types.BidArgs
+		if err = json.Unmarshal(bid.Args, &bidArgs); err != nil {
+			fmt.Printf("%d %s invalid args: %v\n", bid.ID, bid.Hash, err)
+			stats.skipped++
+			continue
+		}
+
+		if tick != nil {
+			select {
+			case <-ctx.Done():
+			case <-tick:
+			}
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		stats.add(bid, sendReplayed(ctx, client, bidArgs))
+	}
+
+	fmt.Println(stats)
+
+	return 0
+}
+
+func sendReplayed(ctx context.Context, client *rpc.Client, args types.BidArgs) error {
+	ctx, cancel := context.WithTimeout(ctx, replayTimeout)
+	defer cancel()
+
+	var hash common.Hash
+	return client.CallContext(ctx, &hash, "mev_sendBid", args)
+}
+
+// replayStats compares what became of the bids replayed with what became of them originally.
+type replayStats struct {
+	read, skipped int
+	// accepted and rejected on replay
+	accepted, rejected int
+	// changed bids whose acceptance differs from the original one
+	changed int
+}
+
+func (s *replayStats) add(bid *store.Bid, err error) {
+	was := bid.Outcome != store.BidRejected
+	if err == nil {
+		s.accepted++
+	} else {
+		s.rejected++
+	}
+	if was != (err == nil) {
+		s.changed++
+	}
+
+	result := "accepted"
+	if err != nil {
+		result = "rejected: " + err.Error()
+	}
+	fmt.Printf("%d %s validator %s block %d was %s, now %s\n", bid.ID, bid.Hash, bid.Validator, bid.BlockNumber,
+		bid.Outcome, result)
+}
+
+func (s replayStats) String() string {
+	return fmt.Sprintf("%d bids read, %d skipped, %d accepted, %d rejected, %d changed", s.read, s.skipped,
+		s.accepted, s.rejected, s.changed)
+}
+
+// bidSource returns the bids replayed in order, nil once there's no more.
+type bidSource interface {
+	next() (*store.Bid, error)
+	close()
+}
+
+// storeSource pages through the bids of the bid store of the config.
+type storeSource struct {
+	bids   *store.BidStore
+	filter store.BidFilter
+	page   []*store.Bid
+	done   bool
+}
+
+func newStoreSource(configPath string, filter store.BidFilter) (*storeSource, error) {
+	cfg, err := config.Read(configPath)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Service.BidStore.Driver == "" {
+		return nil, fmt.Errorf("no bid store is configured in %s", configPath)
+	}
+
+	// the store is read only, it's up to the sentry to prune it
+	cfg.Service.BidStore.MaxAge, cfg.Service.BidStore.MaxRows = 0, 0
+	// the args of the bids are sealed with the keys of their validators
+	keyring, err := store.LoadKeyring(cfg.Service.EncryptionKeyFiles)
+	if err != nil {
+		return nil, err
+	}
+	bids, err := store.OpenBidStore(cfg.Service.BidStore, keyring)
+	if err != nil {
+		return nil, err
+	}
+
+	filter.Limit = store.MaxBidQueryLimit
+	return &storeSource{bids: bids, filter: filter}, nil
+}
+
+func (s *storeSource) next() (*store.Bid, error) {
+	if len(s.page) == 0 && !s.done {
+		page, err := s.bids.Query(s.filter)
+		if err != nil {
+			return nil, err
+		}
+		s.page, s.filter.Cursor, s.done = page.Bids, page.NextCursor, page.NextCursor == 0
+	}
+	if len(s.page) == 0 {
+		return nil, nil
+	}
+
+	bid := s.page[0]
+	s.page = s.page[1:]
+	return bid, nil
+}
+
+func (s *storeSource) close() {
+	_ = s.bids.Close()
+}
+
+// archiveSource reads the bids of the exported files, keeping those selected by the filter.
+type archiveSource struct {
+	bids []*store.Bid
+}
+
+func newArchiveSource(files []string, filter store.BidFilter) (*archiveSource, error) {
+	s := &archiveSource{}
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		bids, err := archive.ReadBids(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+
+		for _, bid := range bids {
+			if matchBid(bid, filter) {
+				s.bids = append(s.bids, bid)
+			}
+		}
+	}
+
+	return s, nil
+}
+
+func (s *archiveSource) next() (*store.Bid, error) {
+	if len(s.bids) == 0 {
+		return nil, nil
+	}
+
+	bid := s.bids[0]
+	s.bids = s.bids[1:]
+	return bid, nil
+}
+
+func (s *archiveSource) close() {}
+
+// matchBid tells whether the filter selects the bid, as the bid store does.
+func matchBid(bid *store.Bid, f store.BidFilter) bool {
+	received := bid.ReceivedAt.Unix()
+	switch {
+	case f.Builder != nil && bid.Builder != *f.Builder,
+		f.Validator != "" && bid.Validator != f.Validator,
+		f.FromBlock > 0 && bid.BlockNumber < f.FromBlock,
+		f.ToBlock > 0 && bid.BlockNumber > f.ToBlock,
+		f.Outcome != "" && bid.Outcome != f.Outcome,
+		f.FromTime > 0 && received < f.FromTime,
+		f.ToTime > 0 && received > f.ToTime:
+		return false
+	default:
+		return true
+	}
+}
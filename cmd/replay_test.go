@@ -0,0 +1,80 @@
+package main
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/bsc-mev-sentry/store"
+)
+
+type replayTarget struct {
+	mu   sync.Mutex
+	bids []types.BidArgs
+}
+
+func (r *replayTarget) SendBid(args types.BidArgs) (common.Hash, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.bids = append(r.bids, args)
+	if args.RawBid.BlockNumber > 1 {
+		return common.Hash{}, errors.New("stale bid")
+	}
+	return args.RawBid.Hash(), nil
+}
+
+func TestReplayArchive(t *testing.T) {
+	target := &replayTarget{}
+	server := rpc.NewServer()
+	require.NoError(t, server.RegisterName("mev", target))
+	srv := httptest.NewServer(server)
+	defer srv.Close()
+
+	argsOf := func(block uint64) json.RawMessage {
+		args, err := json.Marshal(types.BidArgs{
+			RawBid:    &types.RawBid{BlockNumber: block, GasFee: big.NewInt(1), BuilderFee: big.NewInt(0)},
+			Signature: []byte{0x01},
+		})
+		require.NoError(t, err)
+		return args
+	}
+
+	file := filepath.Join(t.TempDir(), "bids.jsonl.gz")
+	f, err := os.Create(file)
+	require.NoError(t, err)
+	zw := gzip.NewWriter(f)
+	enc := json.NewEncoder(zw)
+	for _, bid := range []*store.Bid{
+		{ID: 1, Validator: "bsc-fuji", BlockNumber: 1, Outcome: store.BidWon, Args: argsOf(1)},
+		{ID: 2, Validator: "bsc-fuji", BlockNumber: 2, Outcome: store.BidAccepted, Args: argsOf(2)},
+		// stored without the args
+		{ID: 3, Validator: "bsc-fuji", BlockNumber: 3, Outcome: store.BidAccepted},
+		{ID: 4, Validator: "bsc-chapel", BlockNumber: 1, Outcome: store.BidAccepted, Args: argsOf(1)},
+	} {
+		require.NoError(t, enc.Encode(bid))
+	}
+	require.NoError(t, zw.Close())
+	require.NoError(t, f.Close())
+
+	assert.Equal(t, 0, replay([]string{"-dry-run", file}))
+	assert.Empty(t, target.bids)
+
+	assert.Equal(t, 0, replay([]string{"-target", srv.URL, "-rate", "0", "-validator", "bsc-fuji", file}))
+	require.Len(t, target.bids, 2)
+	assert.Equal(t, uint64(1), target.bids[0].RawBid.BlockNumber)
+	assert.Equal(t, []byte{0x01}, []byte(target.bids[0].Signature))
+	assert.Empty(t, target.bids[0].PayBidTx)
+}
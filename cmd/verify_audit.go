@@ -0,0 +1,25 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/bnb-chain/bsc-mev-sentry/store"
+)
+
+// verifyAudit verifies the hash chain of the audit log of the signed pay bid txs, it exits nonzero if it's broken.
+func verifyAudit(args []string) int {
+	fs := flag.NewFlagSet("verify-audit", flag.ExitOnError)
+	path := fs.String("path", "./data/audit.log", "audit log file path")
+	_ = fs.Parse(args)
+
+	head, err := store.VerifyAuditLog(*path)
+	if err != nil {
+		fmt.Printf("audit log is broken: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("%d entries verified, head %s\n", head.Seq, head.Hash)
+
+	return 0
+}
@@ -0,0 +1,220 @@
+package config
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/bnb-chain/bsc-mev-sentry/account"
+	"github.com/bnb-chain/bsc-mev-sentry/node"
+	"github.com/bnb-chain/bsc-mev-sentry/store"
+)
+
+// CheckOptions how thoroughly Check goes beyond the config file itself.
+type CheckOptions struct {
+	// Dial dials the private urls of the validators and the urls of the builders
+	Dial bool
+	// DialTimeout timeout of each dial, defaults to 3s
+	DialTimeout time.Duration
+}
+
+// Problem is a misconfiguration found by Check.
+type Problem struct {
+	// Scope what's misconfigured, e.g. validator bsc-fuji
+	Scope   string
+	Message string
+}
+
+// Report lists the problems found by Check, the config is usable if there is none.
+type Report struct {
+	Problems []Problem
+}
+
+func (r *Report) add(scope, format string, args ...interface{}) {
+	r.Problems = append(r.Problems, Problem{Scope: scope, Message: fmt.Sprintf(format, args...)})
+}
+
+// OK tells whether no problem is found.
+func (r *Report) OK() bool {
+	return len(r.Problems) == 0
+}
+
+func (r *Report) String() string {
+	if r.OK() {
+		return "config ok\n"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d problem(s) found:\n", len(r.Problems))
+	for _, p := range r.Problems {
+		fmt.Fprintf(&b, "  %s: %s\n", p.Scope, p.Message)
+	}
+
+	return b.String()
+}
+
+// Check reads the config file and reports every problem found, beyond Validate: the key material is loaded and
+// decrypted, the tls files are loaded and, as an option, the upstream urls are dialed.
+func Check(file string, opts CheckOptions) *Report {
+	report := &Report{}
+
+	cfg, err := decode(file)
+	if err != nil {
+		report.add("config", "%v", err)
+		return report
+	}
+
+	for _, err := range cfg.problems() {
+		report.add("config", "%v", err)
+	}
+
+	if opts.DialTimeout <= 0 {
+		opts.DialTimeout = 3 * time.Second
+	}
+
+	for i, v := range cfg.Validators {
+		scope := fmt.Sprintf("validator #%d", i)
+		if v.PublicHostName != "" {
+			scope = "validator " + v.PublicHostName
+		}
+
+		if err := checkPayAccount(v); err != nil {
+			report.add(scope, "pay account: %v", err)
+		}
+
+		checkUpstreamTLS(report, scope, v.TLS)
+
+		if opts.Dial {
+			for _, u := range append([]string{v.PrivateURL}, v.BackupPrivateURLs...) {
+				checkDial(report, scope, u, opts.DialTimeout)
+			}
+		}
+
+		if shadow := v.Shadow; shadow.PrivateURL != "" {
+			if shadow.PayAccountMode != "" {
+				err := checkPayAccount(node.ValidatorConfig{
+					PayAccountMode:    shadow.PayAccountMode,
+					PrivateKey:        shadow.PrivateKey,
+					KeystorePath:      shadow.KeystorePath,
+					PasswordFilePath:  shadow.PasswordFilePath,
+					PayAccountAddress: shadow.PayAccountAddress,
+				})
+				if err != nil {
+					report.add(scope, "shadow pay account: %v", err)
+				}
+			}
+			if opts.Dial {
+				checkDial(report, scope, shadow.PrivateURL, opts.DialTimeout)
+			}
+		}
+	}
+
+	for _, b := range cfg.Builders {
+		scope := "builder " + b.Address.String()
+
+		checkUpstreamTLS(report, scope, b.TLS)
+
+		if opts.Dial {
+			checkDial(report, scope, b.URL, opts.DialTimeout)
+		}
+	}
+
+	if s := cfg.Service; s.TLSCertFile != "" {
+		if _, err := tls.LoadX509KeyPair(s.TLSCertFile, s.TLSKeyFile); err != nil {
+			report.add("service", "TLSCertFile: %v", err)
+		}
+	}
+	if f := cfg.Service.TLSClientCAFile; f != "" {
+		checkFile(report, "service", "TLSClientCAFile", f)
+	}
+
+	if _, err := store.LoadKeyring(cfg.Service.EncryptionKeyFiles); err != nil {
+		report.add("service", "EncryptionKeyFiles: %v", err)
+	}
+
+	return report
+}
+
+// checkPayAccount loads the pay account of the validator, unlocking a copy of the password file since the file
+// is removed once the account is unlocked.
+func checkPayAccount(v node.ValidatorConfig) error {
+	passwordFile := v.PasswordFilePath
+	if passwordFile != "" {
+		password, err := os.ReadFile(passwordFile)
+		if err != nil {
+			return err
+		}
+
+		dir, err := os.MkdirTemp("", "check-config")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(dir)
+
+		passwordFile = filepath.Join(dir, "password")
+		if err = os.WriteFile(passwordFile, password, 0o600); err != nil {
+			return err
+		}
+	}
+
+	_, err := account.New(&account.Config{
+		Mode:             v.PayAccountMode,
+		PrivateKey:       v.PrivateKey,
+		KeystorePath:     v.KeystorePath,
+		PasswordFilePath: passwordFile,
+		Address:          v.PayAccountAddress,
+	})
+	return err
+}
+
+func checkUpstreamTLS(report *Report, scope string, cfg node.UpstreamTLSConfig) {
+	if cfg.CertFile != "" && cfg.KeyFile != "" {
+		if _, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile); err != nil {
+			report.add(scope, "TLS CertFile: %v", err)
+		}
+	}
+	if cfg.CAFile != "" {
+		checkFile(report, scope, "TLS CAFile", cfg.CAFile)
+	}
+}
+
+func checkFile(report *Report, scope, field, file string) {
+	if _, err := os.Stat(file); err != nil {
+		report.add(scope, "%s: %v", field, err)
+	}
+}
+
+// checkDial dials the host of the url over tcp, the default port of its scheme if it has none.
+func checkDial(report *Report, scope, rawURL string, timeout time.Duration) {
+	if rawURL == "" {
+		return
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Hostname() == "" {
+		report.add(scope, "invalid url %s", rawURL)
+		return
+	}
+
+	port := u.Port()
+	if port == "" {
+		switch u.Scheme {
+		case "https", "wss":
+			port = "443"
+		default:
+			port = "80"
+		}
+	}
+
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(u.Hostname(), port), timeout)
+	if err != nil {
+		report.add(scope, "failed to dial %s: %v", rawURL, err)
+		return
+	}
+	_ = conn.Close()
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheck(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	file := filepath.Join(t.TempDir(), "config.toml")
+	require.NoError(t, os.WriteFile(file, []byte(`
+[[Validators]]
+PublicHostName = "bsc-fuji"
+PrivateURL = "http://`+ln.Addr().String()+`"
+PayAccountMode = "privateKey"
+PrivateKey = "4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"
+
+[[Validators]]
+PublicHostName = "bsc-fuji"
+PrivateURL = "http://`+ln.Addr().String()+`"
+PayAccountMode = "keystore"
+KeystorePath = "./missing"
+
+[[Builders]]
+Address = "0x980A75eCd1309eA12fa2ED87A8744fBfc9b863D5"
+URL = "http://127.0.0.1:1"
+AllowedCIDRs = ["not-a-cidr"]
+`), 0o600))
+
+	report := Check(file, CheckOptions{Dial: true})
+	require.Len(t, report.Problems, 4, report.String())
+	// every problem of the config itself is reported, not only the first
+	assert.Equal(t, Problem{Scope: "config", Message: "validator bsc-fuji: duplicated PublicHostName"}, report.Problems[0])
+	assert.Equal(t, "config", report.Problems[1].Scope)
+	assert.Contains(t, report.Problems[1].Message, "invalid AllowedCIDRs")
+	assert.Equal(t, "validator bsc-fuji", report.Problems[2].Scope)
+	assert.Contains(t, report.Problems[2].Message, "pay account")
+	assert.Equal(t, "builder 0x980A75eCd1309eA12fa2ED87A8744fBfc9b863D5", report.Problems[3].Scope)
+	assert.Contains(t, report.Problems[3].Message, "failed to dial")
+
+	report = Check(file, CheckOptions{})
+	assert.Len(t, report.Problems, 3)
+
+	report = Check(filepath.Join(t.TempDir(), "missing.toml"), CheckOptions{})
+	assert.False(t, report.OK())
+}
+
+func TestCheckKeepsPasswordFile(t *testing.T) {
+	dir := t.TempDir()
+	ks := keystore.NewKeyStore(dir, keystore.LightScryptN, keystore.LightScryptP)
+	acc, err := ks.NewAccount("secret")
+	require.NoError(t, err)
+
+	passwordFile := filepath.Join(dir, "password.txt")
+	require.NoError(t, os.WriteFile(passwordFile, []byte("secret"), 0o600))
+
+	file := filepath.Join(dir, "config.toml")
+	require.NoError(t, os.WriteFile(file, []byte(`
+[[Validators]]
+PublicHostName = "bsc-fuji"
+PrivateURL = "http://127.0.0.1:8545"
+PayAccountMode = "keystore"
+KeystorePath = "`+dir+`"
+PasswordFilePath = "`+passwordFile+`"
+PayAccountAddress = "`+acc.Address.Hex()+`"
+`), 0o600))
+
+	report := Check(file, CheckOptions{})
+	assert.True(t, report.OK(), report.String())
+
+	// the sentry still needs it to unlock the account on start
+	assert.FileExists(t, passwordFile)
+}
@@ -1,42 +1,273 @@
 package config
 
 import (
-	"bufio"
+	"errors"
 	"fmt"
 	"os"
 	"reflect"
+	"time"
 	"unicode"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/naoina/toml"
 
+	"github.com/bnb-chain/bsc-mev-sentry/errreport"
+	"github.com/bnb-chain/bsc-mev-sentry/hints"
+	"github.com/bnb-chain/bsc-mev-sentry/log"
+	"github.com/bnb-chain/bsc-mev-sentry/metrics"
 	"github.com/bnb-chain/bsc-mev-sentry/node"
+	"github.com/bnb-chain/bsc-mev-sentry/notification"
+	"github.com/bnb-chain/bsc-mev-sentry/routing"
 	"github.com/bnb-chain/bsc-mev-sentry/service"
+	"github.com/bnb-chain/bsc-mev-sentry/utils"
 )
 
 type Config struct {
 	Service    service.Config
 	Validators []node.ValidatorConfig
 	Builders   []node.BuilderConfig
+	Transport  node.TransportConfig
 
-	Debug DebugConfig
-	Log   LogConfig
+	Notification notification.Config
+	ErrorReport  errreport.Config
+
+	Debug       DebugConfig
+	Pushgateway metrics.PushConfig
+	Log         LogConfig
 }
 
 func Load(file string) *Config {
-	f, err := os.Open(file)
+	cfg, err := Read(file)
 	if err != nil {
 		panic(err)
 	}
 
+	return cfg
+}
+
+// Read reads and validates the config file, unlike Load it returns an error instead of panicking.
+func Read(file string) (*Config, error) {
+	cfg, err := decode(file)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// decode reads the config file over the defaults, then the environment variables over the config file.
+func decode(file string) (*Config, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+
 	defer f.Close()
 
 	cfg := defaultConfig
-	err = tomlSettings.NewDecoder(bufio.NewReader(f)).Decode(&cfg)
-	// Add file name to errors that have a line number.
-	if _, ok := err.(*toml.LineError); ok {
-		panic(err)
+	if err = decodeFile(file, f, &cfg); err != nil {
+		return nil, err
 	}
-	return &cfg
+
+	if err = applyEnv(&cfg, os.Environ()); err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
+
+// Validate checks the required fields and duplicated validators and builders, and returns every problem found, joined.
+func (c *Config) Validate() error {
+	return errors.Join(c.problems()...)
+}
+
+// problems returns the problems found by Validate, checking on past the first one.
+func (c *Config) problems() []error {
+	var errs []error
+
+	chains := make(map[string]struct{}, len(c.Service.Chains))
+	for i, chain := range c.Service.Chains {
+		if chain.Name == "" {
+			errs = append(errs, fmt.Errorf("chain #%d: Name is required", i))
+			continue
+		}
+		if _, ok := chains[chain.Name]; ok {
+			errs = append(errs, fmt.Errorf("chain %s: duplicated Name", chain.Name))
+		}
+		chains[chain.Name] = struct{}{}
+	}
+
+	if _, err := node.ParseCIDRs(c.Service.TrustedProxies); err != nil {
+		errs = append(errs, fmt.Errorf("invalid TrustedProxies, %w", err))
+	}
+
+	// these read a single chain, the one of the validators of another chain would be taken for theirs
+	if len(c.Service.Chains) > 0 {
+		if c.Service.Routing.ProposerSchedule.ChainRPC != "" {
+			errs = append(errs, errors.New("chains: Routing.ProposerSchedule reads a single chain, it can't be combined "+
+				"with Chains"))
+		}
+		if c.Service.BuilderRegistry.ChainRPC != "" {
+			errs = append(errs, errors.New("chains: BuilderRegistry reads a single chain, it can't be combined with Chains"))
+		}
+		if c.Service.BuilderStake.ChainRPC != "" {
+			errs = append(errs, errors.New("chains: BuilderStake reads a single chain, it can't be combined with Chains"))
+		}
+	}
+
+	hostnames := make(map[string]struct{}, len(c.Validators))
+	for i, v := range c.Validators {
+		if v.PublicHostName == "" {
+			errs = append(errs, fmt.Errorf("validator #%d: PublicHostName is required", i))
+			continue
+		}
+		if v.PrivateURL == "" {
+			errs = append(errs, fmt.Errorf("validator %s: PrivateURL is required", v.PublicHostName))
+		}
+		if _, ok := chains[v.Chain]; v.Chain != "" && !ok {
+			errs = append(errs, fmt.Errorf("validator %s: unknown Chain %s", v.PublicHostName, v.Chain))
+		}
+		if err := v.TLS.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("validator %s: %w", v.PublicHostName, err))
+		}
+		if v.Shadow.PrivateURL != "" && v.Shadow.PrivateURL == v.PrivateURL {
+			errs = append(errs, fmt.Errorf("validator %s: Shadow.PrivateURL is the PrivateURL", v.PublicHostName))
+		}
+		if _, ok := hostnames[v.PublicHostName]; ok {
+			errs = append(errs, fmt.Errorf("validator %s: duplicated PublicHostName", v.PublicHostName))
+		}
+		hostnames[v.PublicHostName] = struct{}{}
+	}
+
+	addresses := make(map[common.Address]struct{}, len(c.Builders))
+	for i, b := range c.Builders {
+		if b.Address == (common.Address{}) {
+			errs = append(errs, fmt.Errorf("builder #%d: Address is required", i))
+			continue
+		}
+		if _, ok := addresses[b.Address]; ok {
+			errs = append(errs, fmt.Errorf("builder %s: duplicated Address", b.Address))
+		}
+		if err := b.TLS.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("builder %s: %w", b.Address, err))
+		}
+		if _, err := node.ParseCIDRs(b.AllowedCIDRs); err != nil {
+			errs = append(errs, fmt.Errorf("builder %s: invalid AllowedCIDRs, %w", b.Address, err))
+		}
+		for _, chain := range b.Chains {
+			if _, ok := chains[chain]; !ok {
+				errs = append(errs, fmt.Errorf("builder %s: unknown chain %s in Chains", b.Address, chain))
+			}
+		}
+		addresses[b.Address] = struct{}{}
+	}
+
+	if f := c.Service.Failover; f.Enabled {
+		if f.PeerURL == "" {
+			errs = append(errs, errors.New("failover: PeerURL is required"))
+		}
+		if c.Service.AdminListenAddr == "" {
+			errs = append(errs, errors.New("failover: AdminListenAddr is required to answer the peer"))
+		}
+	}
+
+	if d := c.Service.DecisionLog; d.Enabled && (d.SampleRate <= 0 || d.SampleRate > 1) {
+		errs = append(errs, errors.New("decision log: SampleRate must be in (0, 1]"))
+	}
+
+	if h := c.Service.Hints; h.Backend == "ws" {
+		if h.Token == "" && !c.Service.JWT.Enabled {
+			errs = append(errs, errors.New("hints: Token is required to authenticate the subscribers unless JWT is enabled"))
+		}
+		subscribers := h.MaxSubscribers
+		if subscribers <= 0 {
+			subscribers = hints.DefaultMaxSubscribers
+		}
+		if c.Service.RPCConcurrency > 0 && int64(subscribers) >= c.Service.RPCConcurrency {
+			errs = append(errs, errors.New("hints: MaxSubscribers must be below RPCConcurrency, each subscriber holds one"))
+		}
+	}
+
+	if e := c.Service.Events; e.Backend != "" {
+		if e.Backend != "nats" && e.Backend != "redis" {
+			errs = append(errs, fmt.Errorf("events: unsupported Backend %s", e.Backend))
+		}
+		if e.URL == "" {
+			errs = append(errs, errors.New("events: URL is required"))
+		}
+	}
+
+	if a := c.Service.Archive; a.Backend != "" {
+		if a.Backend != "s3" {
+			errs = append(errs, fmt.Errorf("archive: unsupported Backend %s", a.Backend))
+		}
+		if a.Endpoint == "" || a.Bucket == "" {
+			errs = append(errs, errors.New("archive: Endpoint and Bucket are required"))
+		}
+		if c.Service.BidStore.Driver == "" {
+			errs = append(errs, errors.New("archive: BidStore is required"))
+		}
+	}
+
+	if cl := c.Service.Cluster; cl.Backend != "" {
+		if cl.Backend != "redis" {
+			errs = append(errs, fmt.Errorf("cluster: unsupported Backend %s", cl.Backend))
+		}
+		if cl.URL == "" {
+			errs = append(errs, errors.New("cluster: URL is required"))
+		}
+	}
+
+	if e := c.Service.Election; e.Backend != "" {
+		if e.Backend != "redis" && e.Backend != "kubernetes" {
+			errs = append(errs, fmt.Errorf("election: unsupported Backend %s", e.Backend))
+		}
+		if e.Backend == "redis" && e.URL == "" {
+			errs = append(errs, errors.New("election: URL is required"))
+		}
+		if c.Service.Failover.Enabled {
+			errs = append(errs, errors.New("election: can't be combined with failover"))
+		}
+	}
+
+	if r := c.Service.ValidatorRegistration; r.Enabled {
+		if len(r.Operators) == 0 {
+			errs = append(errs, errors.New("validator registration: Operators is required"))
+		}
+		if r.Identity == "" {
+			errs = append(errs, errors.New("validator registration: Identity is required"))
+		}
+		if r.KeystoreDir == "" {
+			errs = append(errs, errors.New("validator registration: KeystoreDir is required"))
+		}
+	}
+
+	if err := c.Service.Routing.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	for _, source := range c.Service.Routing.Sources() {
+		if source == routing.SNI && c.Service.TLSCertFile == "" {
+			errs = append(errs, errors.New("routing: TLSCertFile is required to route by sni"))
+		}
+	}
+
+	if c.Pushgateway.Enabled && c.Pushgateway.URL == "" {
+		errs = append(errs, errors.New("pushgateway: URL is required"))
+	}
+
+	if err := c.ErrorReport.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if _, err := log.ParseLevel(c.Log.Level); c.Log.Level != "" && err != nil {
+		errs = append(errs, fmt.Errorf("invalid log level %s", c.Log.Level))
+	}
+
+	return errs
 }
 
 // TomlSettings - These settings ensure that TOML keys use the same names as Go struct fields.
@@ -64,9 +295,51 @@ type DebugConfig struct {
 type LogConfig struct {
 	RootDir string
 	Level   string
+	// MaxSizeMB megabytes a log file grows to before the next one is started within the hour, unlimited if 0
+	MaxSizeMB int
+	// MaxAge rotated log files older are removed, kept if 0
+	MaxAge utils.Duration
+	// MaxBackups number of the rotated log files kept, the oldest are removed, all kept if 0
+	MaxBackups int
+	// Compress gzips the rotated log files
+	Compress bool
+	// Sampling drops repeated warn and error entries, e.g. of an upstream down, summarizing the dropped ones
+	Sampling LogSamplingConfig
+}
+
+type LogSamplingConfig struct {
+	// Interval the repeated entries, i.e. of the same level, message and caller, are counted in, disabled if 0
+	Interval utils.Duration
+	// First number of the repeated entries logged as is within an interval
+	First int
+	// Thereafter logs every Thereafter-th of the repeated entries beyond First, none if 0
+	Thereafter int
+}
+
+// Rotate returns the rotation settings of the log files.
+func (c LogConfig) Rotate() log.RotateConfig {
+	return log.RotateConfig{
+		MaxSize:    int64(c.MaxSizeMB) * 1024 * 1024,
+		MaxAge:     time.Duration(c.MaxAge),
+		MaxBackups: c.MaxBackups,
+		Compress:   c.Compress,
+	}
+}
+
+// SamplingConfig returns the sampling settings of the log entries.
+func (c LogConfig) SamplingConfig() log.SamplingConfig {
+	return log.SamplingConfig{
+		Interval:   time.Duration(c.Sampling.Interval),
+		First:      c.Sampling.First,
+		Thereafter: c.Sampling.Thereafter,
+	}
 }
 
 var defaultConfig = Config{
+	Service: service.Config{
+		RPCQueueSize:    1000,
+		RPCQueueTimeout: utils.Duration(time.Second),
+	},
 	Debug: DebugConfig{
 		ListenAddr: ":6060",
 	},
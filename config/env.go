@@ -0,0 +1,215 @@
+package config
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EnvPrefix prefixes the environment variables overriding the config values.
+const EnvPrefix = "SENTRY"
+
+// applyEnv overrides the config values with the environment variables named after the path of the field, upper cased
+// and joined by underscores, e.g. SENTRY_SERVICE_HTTPLISTENADDR for Service.HTTPListenAddr. Elements of slices of
+// structs are addressed by index, e.g. SENTRY_VALIDATORS_0_PRIVATEURL, appending the one right after the last.
+// Other slices are comma separated and maps are comma separated key=value pairs.
+func applyEnv(cfg *Config, environ []string) error {
+	env := make(envVars)
+	for _, kv := range environ {
+		if k, v, ok := strings.Cut(kv, "="); ok && strings.HasPrefix(k, EnvPrefix+"_") {
+			env[k] = v
+		}
+	}
+
+	return env.apply(reflect.ValueOf(cfg).Elem(), EnvPrefix)
+}
+
+// envVars the environment variables by name.
+type envVars map[string]string
+
+// hasPrefix tells whether any of the variables starts with the prefix.
+func (e envVars) hasPrefix(prefix string) bool {
+	for k := range e {
+		if strings.HasPrefix(k, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (e envVars) apply(v reflect.Value, name string) error {
+	if value, ok := e[name]; ok && !isNested(v.Type()) {
+		if err := setEnvValue(v, value); err != nil {
+			return fmt.Errorf("env %s: %w", name, err)
+		}
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		if v.Addr().Type().Implements(textUnmarshalerType) {
+			return nil
+		}
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			if err := e.apply(v.Field(i), name+"_"+strings.ToUpper(field.Name)); err != nil {
+				return err
+			}
+		}
+	case reflect.Ptr:
+		if v.Type().Elem().Kind() != reflect.Struct || v.Type().Implements(textUnmarshalerType) {
+			return nil
+		}
+		if v.IsNil() {
+			if !e.hasPrefix(name + "_") {
+				return nil
+			}
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return e.apply(v.Elem(), name)
+	case reflect.Slice:
+		if !isNested(v.Type()) {
+			return nil
+		}
+		for i := 0; ; i++ {
+			elemName := name + "_" + strconv.Itoa(i)
+			if i == v.Len() {
+				if !e.hasPrefix(elemName + "_") {
+					return nil
+				}
+				v.Set(reflect.Append(v, reflect.Zero(v.Type().Elem())))
+			}
+			if err := e.apply(v.Index(i), elemName); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+var (
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	durationType        = reflect.TypeOf(time.Duration(0))
+)
+
+// isNested tells whether values of the type are overridden field by field, or element by element, rather than as a
+// whole.
+func isNested(t reflect.Type) bool {
+	if reflect.PtrTo(t).Implements(textUnmarshalerType) || t.Implements(textUnmarshalerType) {
+		return false
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		return true
+	case reflect.Ptr, reflect.Slice:
+		return isNested(t.Elem())
+	}
+
+	return false
+}
+
+func setEnvValue(v reflect.Value, value string) error {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		if u, ok := v.Interface().(encoding.TextUnmarshaler); ok {
+			return u.UnmarshalText([]byte(value))
+		}
+		return setEnvValue(v.Elem(), value)
+	}
+
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(value))
+	}
+
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(value, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(value, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		elems := splitEnvList(value)
+		s := reflect.MakeSlice(v.Type(), len(elems), len(elems))
+		for i, elem := range elems {
+			if err := setEnvValue(s.Index(i), elem); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+	case reflect.Map:
+		m := reflect.MakeMap(v.Type())
+		for _, pair := range splitEnvList(value) {
+			k, e, ok := strings.Cut(pair, "=")
+			if !ok {
+				return fmt.Errorf("invalid key=value pair %s", pair)
+			}
+			key, elem := reflect.New(v.Type().Key()).Elem(), reflect.New(v.Type().Elem()).Elem()
+			if err := setEnvValue(key, k); err != nil {
+				return err
+			}
+			if err := setEnvValue(elem, e); err != nil {
+				return err
+			}
+			m.SetMapIndex(key, elem)
+		}
+		v.Set(m)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+
+	return nil
+}
+
+func splitEnvList(value string) []string {
+	if value == "" {
+		return nil
+	}
+
+	elems := strings.Split(value, ",")
+	for i := range elems {
+		elems[i] = strings.TrimSpace(elems[i])
+	}
+
+	return elems
+}
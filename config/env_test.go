@@ -0,0 +1,65 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/bsc-mev-sentry/node"
+	"github.com/bnb-chain/bsc-mev-sentry/utils"
+)
+
+func TestApplyEnv(t *testing.T) {
+	cfg := defaultConfig
+	cfg.Validators = []node.ValidatorConfig{{PublicHostName: "bsc-fuji", PrivateURL: "http://10.0.0.1:8545"}}
+	operators := []common.Address{
+		common.HexToAddress("0x980A75eCd1309eA12fa2ED87A8744fBfc9b863D5"),
+		common.HexToAddress("0x4B20993Bc481177ec7E8f571ceCaE8A9e22C02db"),
+	}
+
+	require.NoError(t, applyEnv(&cfg, []string{
+		"SENTRY_SERVICE_HTTPLISTENADDR=:8555",
+		"SENTRY_SERVICE_RPCQUEUETIMEOUT=2s",
+		"SENTRY_SERVICE_VALIDATORREGISTRATION_OPERATORS=" + operators[0].Hex() + ", " + operators[1].Hex(),
+		"SENTRY_VALIDATORS_0_PRIVATEURL=http://10.0.0.2:8545",
+		"SENTRY_VALIDATORS_1_PUBLICHOSTNAME=bsc-chapel",
+		"SENTRY_PUSHGATEWAY_LABELS=region=ap,zone=a",
+		"SENTRY_DEBUG_LISTENADDR",
+		"PATH=/usr/bin",
+	}))
+
+	assert.Equal(t, ":8555", cfg.Service.HTTPListenAddr)
+	assert.Equal(t, utils.Duration(2*time.Second), cfg.Service.RPCQueueTimeout)
+	assert.Equal(t, operators, cfg.Service.ValidatorRegistration.Operators)
+	require.Len(t, cfg.Validators, 2)
+	assert.Equal(t, "bsc-fuji", cfg.Validators[0].PublicHostName)
+	assert.Equal(t, "http://10.0.0.2:8545", cfg.Validators[0].PrivateURL)
+	assert.Equal(t, "bsc-chapel", cfg.Validators[1].PublicHostName)
+	assert.Equal(t, map[string]string{"region": "ap", "zone": "a"}, cfg.Pushgateway.Labels)
+	assert.Equal(t, ":6060", cfg.Debug.ListenAddr)
+
+	assert.ErrorContains(t, applyEnv(&cfg, []string{"SENTRY_SERVICE_RPCQUEUESIZE=many"}), "SENTRY_SERVICE_RPCQUEUESIZE")
+}
+
+func TestReadEnv(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.toml")
+	require.NoError(t, os.WriteFile(file, []byte(`
+[Service]
+HTTPListenAddr = ":8555"
+
+[Log]
+Level = "info"
+`), 0o600))
+
+	t.Setenv("SENTRY_LOG_LEVEL", "warn")
+
+	cfg, err := Read(file)
+	require.NoError(t, err)
+	assert.Equal(t, ":8555", cfg.Service.HTTPListenAddr)
+	assert.Equal(t, "warn", cfg.Log.Level)
+}
@@ -0,0 +1,99 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/naoina/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// decodeFile decodes the config file into cfg in the format of its extension, .yaml, .yml or .json, TOML otherwise.
+func decodeFile(file string, r io.Reader, cfg *Config) error {
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".yaml", ".yml":
+		var doc map[string]interface{}
+		if err := yaml.NewDecoder(r).Decode(&doc); err != nil && err != io.EOF {
+			return fmt.Errorf("%s, %w", file, err)
+		}
+		return decodeDocument(doc, cfg)
+	case ".json":
+		var doc map[string]interface{}
+		decoder := json.NewDecoder(r)
+		decoder.UseNumber()
+		if err := decoder.Decode(&doc); err != nil {
+			return fmt.Errorf("%s, %w", file, err)
+		}
+		return decodeDocument(doc, cfg)
+	}
+
+	err := tomlSettings.NewDecoder(bufio.NewReader(r)).Decode(cfg)
+	// Add file name to errors that have a line number.
+	if lineErr, ok := err.(*toml.LineError); ok {
+		return fmt.Errorf("%s, %w", file, lineErr)
+	}
+
+	return err
+}
+
+// decodeDocument decodes a YAML or JSON document into cfg with the same field names as TOML, the Go field names, and
+// reports the unknown fields the same way.
+func decodeDocument(doc map[string]interface{}, cfg *Config) error {
+	pruneUnknownFields(reflect.TypeOf(cfg).Elem(), doc)
+
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+
+	return json.NewDecoder(bytes.NewReader(b)).Decode(cfg)
+}
+
+// pruneUnknownFields removes the keys of the document not matching exactly any field of the type, which JSON would
+// otherwise match case-insensitively, after reporting them per tomlSettings.
+func pruneUnknownFields(rt reflect.Type, doc interface{}) {
+	for rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+	if reflect.PtrTo(rt).Implements(textUnmarshalerType) || rt.Implements(textUnmarshalerType) ||
+		reflect.PtrTo(rt).Implements(jsonUnmarshalerType) {
+		return
+	}
+
+	switch rt.Kind() {
+	case reflect.Struct:
+		m, ok := doc.(map[string]interface{})
+		if !ok {
+			return
+		}
+		for key, value := range m {
+			field, ok := rt.FieldByName(key)
+			if !ok || !field.IsExported() {
+				_ = tomlSettings.MissingField(rt, key)
+				delete(m, key)
+				continue
+			}
+			pruneUnknownFields(field.Type, value)
+		}
+	case reflect.Slice, reflect.Array:
+		if l, ok := doc.([]interface{}); ok {
+			for _, value := range l {
+				pruneUnknownFields(rt.Elem(), value)
+			}
+		}
+	case reflect.Map:
+		if m, ok := doc.(map[string]interface{}); ok {
+			for _, value := range m {
+				pruneUnknownFields(rt.Elem(), value)
+			}
+		}
+	}
+}
+
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeFormats(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"config.toml": `
+[Service]
+HTTPListenAddr = ":8555"
+RPCQueueTimeout = "2s"
+
+[[Validators]]
+PublicHostName = "bsc-fuji"
+PrivateURL = "http://10.0.0.1:8545"
+
+[[Builders]]
+Address = "0x980A75eCd1309eA12fa2ED87A8744fBfc9b863D5"
+URL = "http://10.0.0.2:8555"
+
+[Pushgateway.Labels]
+region = "ap"
+`,
+		"config.yaml": `
+Service:
+  HTTPListenAddr: ":8555"
+  RPCQueueTimeout: 2s
+  Unknown: 1
+Validators:
+  - PublicHostName: bsc-fuji
+    PrivateURL: http://10.0.0.1:8545
+    privateurl: http://10.0.0.3:8545
+Builders:
+  - Address: "0x980A75eCd1309eA12fa2ED87A8744fBfc9b863D5"
+    URL: http://10.0.0.2:8555
+Pushgateway:
+  Labels:
+    region: ap
+`,
+		"config.json": `{
+  "Service": {"HTTPListenAddr": ":8555", "RPCQueueTimeout": "2s"},
+  "Validators": [{"PublicHostName": "bsc-fuji", "PrivateURL": "http://10.0.0.1:8545"}],
+  "Builders": [{"Address": "0x980A75eCd1309eA12fa2ED87A8744fBfc9b863D5", "URL": "http://10.0.0.2:8555"}],
+  "Pushgateway": {"Labels": {"region": "ap"}}
+}`,
+	}
+
+	configs := make(map[string]*Config, len(files))
+	for name, content := range files {
+		file := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(file, []byte(content), 0o600))
+
+		cfg, err := Read(file)
+		require.NoError(t, err, name)
+		configs[name] = cfg
+	}
+
+	assert.Equal(t, configs["config.toml"], configs["config.yaml"])
+	assert.Equal(t, configs["config.toml"], configs["config.json"])
+	// defaults are kept
+	assert.Equal(t, ":6060", configs["config.yaml"].Debug.ListenAddr)
+
+	file := filepath.Join(dir, "invalid.yaml")
+	require.NoError(t, os.WriteFile(file, []byte("Service: [\n"), 0o600))
+	_, err := Read(file)
+	assert.ErrorContains(t, err, "invalid.yaml")
+}
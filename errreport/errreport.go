@@ -0,0 +1,198 @@
+// Package errreport reports the panics and the errors logged by the sentry to Sentry, or any error tracker speaking
+// its protocol, e.g. GlitchTip.
+package errreport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"reflect"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+
+	"github.com/bnb-chain/bsc-mev-sentry/log"
+	"github.com/bnb-chain/bsc-mev-sentry/version"
+)
+
+// maxValueLength caps the length of the reported field values, e.g. of raw txs.
+const maxValueLength = 256
+
+// reportedHeaders are the request headers reported along with the panics, any others may carry credentials.
+var reportedHeaders = []string{"Content-Type", "Content-Length", "User-Agent"}
+
+type Config struct {
+	// DSN of the project the errors are reported to, reporting is disabled if empty
+	DSN string
+	// Environment the reported errors are tagged with, e.g. mainnet or testnet
+	Environment string
+	// SampleRate of the reported errors in (0, 1], defaults to 1
+	SampleRate float64
+	// Level of the reported log entries, error or panic, defaults to error
+	Level string
+}
+
+// Validate checks the DSN, the sample rate and the level.
+func (c Config) Validate() error {
+	if c.DSN == "" {
+		return nil
+	}
+
+	if _, err := sentry.NewDsn(c.DSN); err != nil {
+		return fmt.Errorf("error report: invalid DSN, %w", err)
+	}
+	if c.SampleRate < 0 || c.SampleRate > 1 {
+		return errors.New("error report: SampleRate must be in (0, 1]")
+	}
+	if lvl, err := log.ParseLevel(c.Level); c.Level != "" && (err != nil || lvl < log.ErrorLevel) {
+		return fmt.Errorf("error report: invalid Level %s", c.Level)
+	}
+
+	return nil
+}
+
+// Reporter reports the log entries of its level and up as events, with the request they were logged for if any.
+type Reporter struct {
+	client *sentry.Client
+	level  log.Level
+}
+
+func NewReporter(cfg Config) (*Reporter, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	level := log.ErrorLevel
+	if cfg.Level != "" {
+		level, _ = log.ParseLevel(cfg.Level)
+	}
+
+	hostname, _ := os.Hostname()
+	client, err := sentry.NewClient(sentry.ClientOptions{
+		Dsn:         cfg.DSN,
+		Environment: cfg.Environment,
+		Release:     version.Get().Version,
+		SampleRate:  cfg.SampleRate,
+		ServerName:  hostname,
+		// the stack of the logging goroutine, also of the recovered panics as they are logged in the deferred call
+		AttachStacktrace: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Reporter{client: client, level: level}, nil
+}
+
+// Report is the log.Hook reporting the entry.
+func (r *Reporter) Report(ctx context.Context, lvl log.Level, msg string, kvs []interface{}) {
+	if lvl < r.level {
+		return
+	}
+
+	event := r.client.EventFromMessage(msg, sentry.LevelError)
+	if lvl >= log.PanicLevel {
+		event.Level = sentry.LevelFatal
+	}
+	event.Extra = fields(kvs)
+	if ctx != nil {
+		if req, ok := ctx.Value(requestKey{}).(*http.Request); ok {
+			event.Request = request(req)
+		}
+	}
+
+	r.client.CaptureEvent(event, nil, nil)
+
+	// the process may not survive the panic
+	if lvl >= log.PanicLevel {
+		r.client.Flush(2 * time.Second)
+	}
+}
+
+// Flush waits for the reported events to be sent.
+func (r *Reporter) Flush(ctx context.Context) error {
+	timeout := 5 * time.Second
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline)
+	}
+
+	if !r.client.Flush(timeout) {
+		return errors.New("reported errors not all sent")
+	}
+
+	return nil
+}
+
+type requestKey struct{}
+
+// WithRequest returns a context reporting the entries logged with it along with the request, its method, url and
+// a few headers but neither the body nor the credentials.
+func WithRequest(ctx context.Context, req *http.Request) context.Context {
+	return context.WithValue(ctx, requestKey{}, req)
+}
+
+func request(req *http.Request) *sentry.Request {
+	headers := make(map[string]string, len(reportedHeaders))
+	for _, name := range reportedHeaders {
+		if value := req.Header.Get(name); value != "" {
+			headers[name] = value
+		}
+	}
+
+	var env map[string]string
+	if host, _, err := net.SplitHostPort(req.RemoteAddr); err == nil {
+		env = map[string]string{"REMOTE_ADDR": host}
+	}
+
+	return &sentry.Request{
+		URL:     req.URL.Path,
+		Method:  req.Method,
+		Headers: headers,
+		Env:     env,
+	}
+}
+
+// fields returns the key-value pairs as the extra data of the event, values are reported as text and capped, the
+// composite ones, e.g. bids or txs, are omitted.
+func fields(kvs []interface{}) map[string]interface{} {
+	extra := make(map[string]interface{}, len(kvs)/2)
+	for i := 0; i+1 < len(kvs); i += 2 {
+		key, ok := kvs[i].(string)
+		if !ok {
+			continue
+		}
+
+		if !reportable(kvs[i+1]) {
+			extra[key] = "omitted"
+			continue
+		}
+
+		value := fmt.Sprint(kvs[i+1])
+		if len(value) > maxValueLength {
+			value = value[:maxValueLength] + "..."
+		}
+		extra[key] = value
+	}
+
+	return extra
+}
+
+func reportable(value interface{}) bool {
+	switch value.(type) {
+	case nil, error, fmt.Stringer:
+		return true
+	}
+
+	switch reflect.TypeOf(value).Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
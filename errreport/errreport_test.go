@@ -0,0 +1,85 @@
+package errreport
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+
+	"github.com/bnb-chain/bsc-mev-sentry/log"
+)
+
+type recordingTransport struct {
+	mu     sync.Mutex
+	events []*sentry.Event
+}
+
+func (t *recordingTransport) Flush(time.Duration) bool       { return true }
+func (t *recordingTransport) Configure(sentry.ClientOptions) {}
+func (t *recordingTransport) SendEvent(event *sentry.Event) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.events = append(t.events, event)
+}
+
+func TestReport(t *testing.T) {
+	transport := &recordingTransport{}
+	client, err := sentry.NewClient(sentry.ClientOptions{Transport: transport})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &Reporter{client: client, level: log.ErrorLevel}
+
+	type bid struct{ Txs [][]byte }
+
+	req := httptest.NewRequest("POST", "/", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Set("User-Agent", "builder")
+	ctx := WithRequest(context.Background(), req)
+
+	r.Report(ctx, log.WarnLevel, "warn", nil)
+	r.Report(ctx, log.ErrorLevel, "panic recovered", []interface{}{"err", errors.New("boom"), "bid", &bid{}, "count", 2})
+
+	if len(transport.events) != 1 {
+		t.Fatalf("got %d events, want 1", len(transport.events))
+	}
+
+	event := transport.events[0]
+	if event.Message != "panic recovered" || event.Level != sentry.LevelError {
+		t.Fatalf("unexpected event %s %s", event.Level, event.Message)
+	}
+	if event.Extra["err"] != "boom" || event.Extra["bid"] != "omitted" || event.Extra["count"] != "2" {
+		t.Fatalf("unexpected extra %v", event.Extra)
+	}
+	if event.Request == nil || event.Request.Method != "POST" {
+		t.Fatal("request not reported")
+	}
+	if _, ok := event.Request.Headers["Authorization"]; ok || event.Request.Headers["User-Agent"] != "builder" {
+		t.Fatalf("unexpected headers %v", event.Request.Headers)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := (Config{}).Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	valid := Config{DSN: "https://key@o0.ingest.sentry.io/1", SampleRate: 0.5, Level: "panic"}
+	if err := valid.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, cfg := range []Config{
+		{DSN: "invalid"},
+		{DSN: valid.DSN, SampleRate: 2},
+		{DSN: valid.DSN, Level: "warn"},
+	} {
+		if err := cfg.Validate(); err == nil {
+			t.Fatalf("%+v accepted", cfg)
+		}
+	}
+}
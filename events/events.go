@@ -0,0 +1,182 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bnb-chain/bsc-mev-sentry/log"
+	"github.com/bnb-chain/bsc-mev-sentry/metrics"
+)
+
+const publishTimeout = 5 * time.Second
+
+// types of the published events
+const (
+	BidAccepted = "bid_accepted"
+	BidRejected = "bid_rejected"
+)
+
+type Config struct {
+	// Backend publisher of the events, nats or redis, disabled if empty
+	Backend string
+	// URL of the nats server or the redis server, e.g. nats://127.0.0.1:4222 or redis://127.0.0.1:6379/0
+	URL string
+	// Subject nats subject or redis stream the events are published to, defaults to bsc-mev-sentry.bids
+	Subject string
+	// MaxLen caps the redis stream length approximately, unlimited if 0
+	MaxLen int64
+	// BufferSize events waiting to be published, more are dropped instead of slowing down bids
+	BufferSize int
+}
+
+// Event is the telemetry of a bid sent to the sentry.
+type Event struct {
+	Type        string         `json:"type"`
+	Time        time.Time      `json:"time"`
+	BidHash     common.Hash    `json:"bidHash"`
+	Builder     common.Address `json:"builder"`
+	Validator   string         `json:"validator"`
+	BlockNumber uint64         `json:"blockNumber"`
+	GasUsed     uint64         `json:"gasUsed"`
+	GasFee      *big.Int       `json:"gasFee,omitempty"`
+	BuilderFee  *big.Int       `json:"builderFee,omitempty"`
+	TxCount     int            `json:"txCount"`
+	Reason      string         `json:"reason,omitempty"`
+	Error       string         `json:"error,omitempty"`
+	LatencyMs   int64          `json:"latencyMs"`
+}
+
+// Publisher delivers encoded events to a message broker.
+type Publisher interface {
+	Name() string
+	Publish(ctx context.Context, data []byte) error
+	Close() error
+}
+
+// Bus publishes events in the background so that a slow or unreachable broker never delays bids.
+type Bus struct {
+	publisher Publisher
+	events    chan *Event
+	done      chan struct{}
+
+	mu     sync.RWMutex // guards closed against emits racing with close
+	closed bool
+}
+
+// NewBus connects the publisher of the config, a nil bus is returned if no backend is configured.
+func NewBus(cfg Config) (*Bus, error) {
+	if cfg.Backend == "" {
+		return nil, nil
+	}
+
+	if cfg.Subject == "" {
+		cfg.Subject = "bsc-mev-sentry.bids"
+	}
+
+	var (
+		publisher Publisher
+		err       error
+	)
+	switch cfg.Backend {
+	case "nats":
+		publisher, err = newNATS(cfg)
+	case "redis":
+		publisher, err = newRedis(cfg)
+	default:
+		return nil, fmt.Errorf("unsupported event backend %s", cfg.Backend)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return NewBusWithPublisher(publisher, cfg.BufferSize), nil
+}
+
+// NewBusWithPublisher creates a bus publishing to the given publisher.
+func NewBusWithPublisher(publisher Publisher, bufferSize int) *Bus {
+	if bufferSize <= 0 {
+		bufferSize = 4096
+	}
+
+	b := &Bus{
+		publisher: publisher,
+		events:    make(chan *Event, bufferSize),
+		done:      make(chan struct{}),
+	}
+
+	go b.loop()
+
+	return b
+}
+
+// Emit queues the event, it's dropped if the publisher falls behind.
+func (b *Bus) Emit(e *Event) {
+	if b == nil {
+		return
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.closed {
+		return
+	}
+
+	select {
+	case b.events <- e:
+	default:
+		metrics.EventCounter.WithLabelValues(b.publisher.Name(), "dropped").Inc()
+	}
+}
+
+func (b *Bus) loop() {
+	defer close(b.done)
+
+	for e := range b.events {
+		data, err := json.Marshal(e)
+		if err != nil {
+			log.Errorw("failed to encode event", "err", err)
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+		err = b.publisher.Publish(ctx, data)
+		cancel()
+
+		if err != nil {
+			metrics.EventCounter.WithLabelValues(b.publisher.Name(), "failed").Inc()
+			log.Errorw("failed to publish event", "publisher", b.publisher.Name(), "err", err)
+			continue
+		}
+
+		metrics.EventCounter.WithLabelValues(b.publisher.Name(), "published").Inc()
+	}
+}
+
+// Close publishes the queued events and disconnects the publisher.
+func (b *Bus) Close() {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return
+	}
+	b.closed = true
+	close(b.events)
+	b.mu.Unlock()
+
+	<-b.done
+
+	if err := b.publisher.Close(); err != nil {
+		log.Errorw("failed to close event publisher", "publisher", b.publisher.Name(), "err", err)
+	}
+}
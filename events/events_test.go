@@ -0,0 +1,67 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type memPublisher struct {
+	mu     sync.Mutex
+	events [][]byte
+	closed bool
+}
+
+func (p *memPublisher) Name() string {
+	return "mem"
+}
+
+func (p *memPublisher) Publish(_ context.Context, data []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.events = append(p.events, data)
+	return nil
+}
+
+func (p *memPublisher) Close() error {
+	p.closed = true
+	return nil
+}
+
+func TestBus(t *testing.T) {
+	publisher := &memPublisher{}
+	bus := NewBusWithPublisher(publisher, 16)
+
+	bus.Emit(&Event{Type: BidAccepted, BlockNumber: 100})
+	bus.Emit(&Event{Type: BidRejected, BlockNumber: 101, Reason: "gas_price_too_low"})
+	bus.Close()
+
+	// emits after close are ignored instead of panicking
+	bus.Emit(&Event{Type: BidAccepted})
+
+	require.True(t, publisher.closed)
+	require.Len(t, publisher.events, 2)
+
+	var e Event
+	require.NoError(t, json.Unmarshal(publisher.events[1], &e))
+	require.Equal(t, BidRejected, e.Type)
+	require.Equal(t, uint64(101), e.BlockNumber)
+	require.Equal(t, "gas_price_too_low", e.Reason)
+
+	var nilBus *Bus
+	nilBus.Emit(&Event{})
+	nilBus.Close()
+}
+
+func TestNewBus(t *testing.T) {
+	bus, err := NewBus(Config{})
+	require.NoError(t, err)
+	require.Nil(t, bus)
+
+	_, err = NewBus(Config{Backend: "kafka"})
+	require.Error(t, err)
+}
@@ -0,0 +1,34 @@
+package events
+
+import (
+	"context"
+
+	"github.com/nats-io/nats.go"
+)
+
+type natsPublisher struct {
+	conn    *nats.Conn
+	subject string
+}
+
+func newNATS(cfg Config) (*natsPublisher, error) {
+	// reconnect forever, events published while disconnected are buffered by the client
+	conn, err := nats.Connect(cfg.URL, nats.Name("bsc-mev-sentry"), nats.MaxReconnects(-1))
+	if err != nil {
+		return nil, err
+	}
+
+	return &natsPublisher{conn: conn, subject: cfg.Subject}, nil
+}
+
+func (p *natsPublisher) Name() string {
+	return "nats"
+}
+
+func (p *natsPublisher) Publish(_ context.Context, data []byte) error {
+	return p.conn.Publish(p.subject, data)
+}
+
+func (p *natsPublisher) Close() error {
+	return p.conn.Drain()
+}
@@ -0,0 +1,39 @@
+package events
+
+import (
+	"context"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type redisPublisher struct {
+	client *redis.Client
+	stream string
+	maxLen int64
+}
+
+func newRedis(cfg Config) (*redisPublisher, error) {
+	opts, err := redis.ParseURL(cfg.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &redisPublisher{client: redis.NewClient(opts), stream: cfg.Subject, maxLen: cfg.MaxLen}, nil
+}
+
+func (p *redisPublisher) Name() string {
+	return "redis"
+}
+
+func (p *redisPublisher) Publish(ctx context.Context, data []byte) error {
+	return p.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: p.stream,
+		MaxLen: p.maxLen,
+		Approx: p.maxLen > 0,
+		Values: []interface{}{"event", data},
+	}).Err()
+}
+
+func (p *redisPublisher) Close() error {
+	return p.client.Close()
+}
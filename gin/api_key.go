@@ -0,0 +1,18 @@
+package middlewares
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/bnb-chain/bsc-mev-sentry/auth"
+)
+
+// APIKey attaches the api key header to the request context, it's checked against the builder signing the bid
+func APIKey() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if key := c.GetHeader(auth.APIKeyHeader); key != "" {
+			c.Request = c.Request.WithContext(auth.WithAPIKey(c.Request.Context(), key))
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,22 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BodyLimit rejects requests whose body is larger than max bytes before they are read
+func BodyLimit(max int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.ContentLength > max {
+			c.AbortWithStatus(http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		// the content length may be unknown, reading beyond max fails then
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, max)
+
+		c.Next()
+	}
+}
@@ -0,0 +1,29 @@
+package middlewares
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/bnb-chain/bsc-mev-sentry/auth"
+)
+
+// ClientCertIdentity attaches the identity of the verified client certificate to the request context
+func ClientCertIdentity() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.TLS != nil && len(c.Request.TLS.VerifiedChains) > 0 {
+			cert := c.Request.TLS.VerifiedChains[0][0]
+
+			names := make([]string, 0, len(cert.DNSNames)+1)
+			if cert.Subject.CommonName != "" {
+				names = append(names, cert.Subject.CommonName)
+			}
+			names = append(names, cert.DNSNames...)
+
+			c.Request = c.Request.WithContext(auth.WithIdentity(c.Request.Context(), &auth.Identity{
+				Method: "mtls",
+				Names:  names,
+			}))
+		}
+
+		c.Next()
+	}
+}
@@ -1,19 +1,73 @@
 package middlewares
 
-import "github.com/gin-gonic/gin"
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
 
-// ConcurrencyLimiter limits simultaneous requests
-func ConcurrencyLimiter(max int64) gin.HandlerFunc {
+	"github.com/gin-gonic/gin"
+
+	"github.com/bnb-chain/bsc-mev-sentry/metrics"
+)
+
+// ConcurrencyLimiter limits simultaneous requests, requests beyond the limit wait in a queue of
+// queueSize for at most queueTimeout, and are rejected with 429 once the queue is full or they time out
+func ConcurrencyLimiter(max int64, queueSize int64, queueTimeout time.Duration) gin.HandlerFunc {
 	if max <= 0 {
 		return func(c *gin.Context) {
 			c.Next()
 		}
 	}
 
+	retryAfter := strconv.Itoa(int(math.Ceil(queueTimeout.Seconds())))
+
+	reject := func(c *gin.Context, reason string) {
+		metrics.ConcurrencyRejectedCounter.WithLabelValues(reason).Inc()
+		c.Header("Retry-After", retryAfter)
+		c.AbortWithStatus(http.StatusTooManyRequests)
+	}
+
+	var waiting atomic.Int64
 	lc := make(chan struct{}, max)
+	metrics.ConcurrencyLimit.Set(float64(max))
 	return func(c *gin.Context) {
-		lc <- struct{}{}
-		defer func() { <-lc }()
+		select {
+		case lc <- struct{}{}:
+		default:
+			if waiting.Add(1) > queueSize {
+				waiting.Add(-1)
+				reject(c, "queue_full")
+				return
+			}
+			metrics.ConcurrencyQueueDepth.Inc()
+
+			timer := time.NewTimer(queueTimeout)
+			select {
+			case lc <- struct{}{}:
+				timer.Stop()
+			case <-timer.C:
+				waiting.Add(-1)
+				metrics.ConcurrencyQueueDepth.Dec()
+				reject(c, "timeout")
+				return
+			case <-c.Request.Context().Done():
+				timer.Stop()
+				waiting.Add(-1)
+				metrics.ConcurrencyQueueDepth.Dec()
+				c.Abort()
+				return
+			}
+
+			waiting.Add(-1)
+			metrics.ConcurrencyQueueDepth.Dec()
+		}
+		metrics.ConcurrencyInUse.Inc()
+		defer func() {
+			metrics.ConcurrencyInUse.Dec()
+			<-lc
+		}()
 
 		c.Next()
 	}
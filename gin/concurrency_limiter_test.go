@@ -0,0 +1,52 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConcurrencyLimiter(t *testing.T) {
+	release := make(chan struct{})
+	app := gin.New()
+	app.Use(ConcurrencyLimiter(1, 1, 50*time.Millisecond))
+	app.GET("/", func(c *gin.Context) {
+		<-release
+		c.Status(http.StatusOK)
+	})
+
+	serve := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		return w
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		assert.Equal(t, http.StatusOK, serve().Code)
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	// the second request waits in the queue and times out, the third one finds the queue full
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		w := serve()
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, "1", w.Header().Get("Retry-After"))
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	assert.Equal(t, http.StatusTooManyRequests, serve().Code)
+
+	time.Sleep(60 * time.Millisecond)
+	close(release)
+	wg.Wait()
+}
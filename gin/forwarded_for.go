@@ -0,0 +1,71 @@
+package middlewares
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ForwardedFor sets the remote address of the requests from the trusted proxies to the one of their sender, i.e. the
+// last address of the X-Forwarded-For header which isn't of a trusted proxy, so that the source ip checks see the
+// sender rather than the proxy. The requests from elsewhere keep their remote address, whatever their header says.
+func ForwardedFor(trusted []*net.IPNet) gin.HandlerFunc {
+	if len(trusted) == 0 {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
+	return func(c *gin.Context) {
+		if ip := forwardedFor(c.Request, trusted); ip != nil {
+			c.Request.RemoteAddr = net.JoinHostPort(ip.String(), "0")
+		}
+
+		c.Next()
+	}
+}
+
+// forwardedFor returns the sender of the request from a trusted proxy, nil if it's not from one or the header is
+// missing or malformed.
+func forwardedFor(r *http.Request, trusted []*net.IPNet) net.IP {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if ip := net.ParseIP(host); ip == nil || !contains(trusted, ip) {
+		return nil
+	}
+
+	var hops []string
+	for _, value := range r.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(value, ",")...)
+	}
+
+	// each proxy appends the address it's connected from, the ones before the last trusted proxy may be forged
+	var sender net.IP
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip := net.ParseIP(strings.TrimSpace(hops[i]))
+		if ip == nil {
+			return nil
+		}
+
+		sender = ip
+		if !contains(trusted, ip) {
+			break
+		}
+	}
+
+	return sender
+}
+
+func contains(nets []*net.IPNet, ip net.IP) bool {
+	for _, n := range nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,38 @@
+package middlewares
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/bsc-mev-sentry/node"
+)
+
+func TestForwardedFor(t *testing.T) {
+	trusted, err := node.ParseCIDRs([]string{"10.0.0.0/8"})
+	require.NoError(t, err)
+
+	for _, c := range []struct {
+		name, remote, header, want string
+	}{
+		{"sender behind the proxy", "10.0.0.1:4000", "203.0.113.7", "203.0.113.7"},
+		{"forged hops before it", "10.0.0.1:4000", "198.51.100.1, 203.0.113.7, 10.0.0.2", "203.0.113.7"},
+		{"untrusted remote", "203.0.113.9:4000", "198.51.100.1", "203.0.113.9:4000"},
+		{"no header", "10.0.0.1:4000", "", "10.0.0.1:4000"},
+		{"malformed header", "10.0.0.1:4000", "unknown", "10.0.0.1:4000"},
+	} {
+		req := httptest.NewRequest("POST", "/", nil)
+		req.RemoteAddr = c.remote
+		if c.header != "" {
+			req.Header.Set("X-Forwarded-For", c.header)
+		}
+
+		got := c.remote
+		if ip := forwardedFor(req, trusted); ip != nil {
+			got = ip.String()
+		}
+		assert.Equal(t, c.want, got, c.name)
+	}
+}
@@ -0,0 +1,34 @@
+package middlewares
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bnb-chain/bsc-mev-sentry/auth"
+	"github.com/bnb-chain/bsc-mev-sentry/log"
+)
+
+// JWTAuth rejects requests without a valid bearer token, and attaches the builder identity of the token
+// to the request context
+func JWTAuth(verifier *auth.JWTVerifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+
+		id, err := verifier.Verify(token)
+		if err != nil {
+			log.Debugw("invalid jwt", "err", err)
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+
+		c.Request = c.Request.WithContext(auth.WithIdentity(c.Request.Context(), id))
+
+		c.Next()
+	}
+}
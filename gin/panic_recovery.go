@@ -12,6 +12,8 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/bnb-chain/bsc-mev-sentry/auth"
+	"github.com/bnb-chain/bsc-mev-sentry/errreport"
 	"github.com/bnb-chain/bsc-mev-sentry/log"
 )
 
@@ -22,6 +24,16 @@ var (
 	slash     = []byte("/")
 )
 
+// credentialHeaders are redacted from the headers logged along with panics, as the logs may be reported.
+var credentialHeaders = map[string]struct{}{
+	"Authorization":       {},
+	"Proxy-Authorization": {},
+	"Cookie":              {},
+	http.CanonicalHeaderKey(auth.APIKeyHeader):             {},
+	http.CanonicalHeaderKey(auth.SignatureHeader):          {},
+	http.CanonicalHeaderKey(auth.FlashbotsSignatureHeader): {},
+}
+
 // PanicRecovery returns a middleware that recovers from any panics and writes a 500 if there was one.
 // copy from gin.CustomRecoveryWithWriter, replaced the logger
 func PanicRecovery() gin.HandlerFunc {
@@ -41,18 +53,13 @@ func PanicRecovery() gin.HandlerFunc {
 
 				stack := stack(3)
 				httpRequest, _ := httputil.DumpRequest(c.Request, false)
-				headers := strings.Split(string(httpRequest), "\r\n")
-				for idx, header := range headers {
-					current := strings.Split(header, ":")
-					if current[0] == "Authorization" {
-						headers[idx] = current[0] + ": *"
-					}
-				}
-				headersToStr := strings.Join(headers, "\r\n")
+				headersToStr := redactHeaders(httpRequest)
 				if brokenPipe {
 					log.Errorw("broken pipe", "err", err, "headers", headersToStr)
 				} else {
-					log.Errorw("panic recovered", "err", err, "headers", headersToStr, "stack", stack)
+					// reported along with the request if the errors are reported
+					ctx := errreport.WithRequest(c.Request.Context(), c.Request)
+					log.CtxErrorw(ctx, "panic recovered", "err", err, "headers", headersToStr, "stack", stack)
 				}
 
 				if brokenPipe {
@@ -68,6 +75,19 @@ func PanicRecovery() gin.HandlerFunc {
 	}
 }
 
+// redactHeaders returns the dumped request with the values of the credential headers redacted.
+func redactHeaders(dump []byte) string {
+	headers := strings.Split(string(dump), "\r\n")
+	for idx, header := range headers {
+		current := strings.Split(header, ":")
+		if _, ok := credentialHeaders[http.CanonicalHeaderKey(current[0])]; ok {
+			headers[idx] = current[0] + ": *"
+		}
+	}
+
+	return strings.Join(headers, "\r\n")
+}
+
 // stack returns a nicely formatted stack frame, skipping skip frames.
 func stack(skip int) []byte {
 	buf := new(bytes.Buffer) // the returned data
@@ -0,0 +1,29 @@
+package middlewares
+
+import (
+	"net/http/httptest"
+	"net/http/httputil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/bsc-mev-sentry/auth"
+)
+
+func TestRedactHeaders(t *testing.T) {
+	req := httptest.NewRequest("POST", "/", nil)
+	req.Header.Set("Authorization", "Bearer token")
+	req.Header.Set(auth.APIKeyHeader, "api-key")
+	req.Header.Set(auth.SignatureHeader, "0xsignature")
+	req.Header.Set("User-Agent", "builder")
+
+	dump, err := httputil.DumpRequest(req, false)
+	require.NoError(t, err)
+
+	headers := redactHeaders(dump)
+	for _, secret := range []string{"token", "api-key", "0xsignature"} {
+		assert.NotContains(t, headers, secret)
+	}
+	assert.Contains(t, headers, "User-Agent: builder")
+}
@@ -0,0 +1,36 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestTimeoutHeader names how long the builder waits for the response, e.g. "800ms"
+const RequestTimeoutHeader = "X-Request-Timeout"
+
+// RequestTimeout bounds the request context by the timeout the builder waits for the response, so the sentry
+// stops working on the request once the builder has given up on it
+func RequestTimeout() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value := c.GetHeader(RequestTimeoutHeader)
+		if value == "" {
+			c.Next()
+			return
+		}
+
+		timeout, err := time.ParseDuration(value)
+		if err != nil || timeout <= 0 {
+			c.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
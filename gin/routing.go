@@ -0,0 +1,30 @@
+package middlewares
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/bnb-chain/bsc-mev-sentry/routing"
+)
+
+// RoutingHeader attaches the routing header to the request context, it names the validator the request is routed to
+func RoutingHeader(header string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if value := c.GetHeader(header); value != "" {
+			c.Request = c.Request.WithContext(routing.WithHeader(c.Request.Context(), value))
+		}
+
+		c.Next()
+	}
+}
+
+// RoutingSNI attaches the server name of the tls handshake to the request context, it names the validator the
+// request is routed to
+func RoutingSNI() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.TLS != nil && c.Request.TLS.ServerName != "" {
+			c.Request = c.Request.WithContext(routing.WithSNI(c.Request.Context(), c.Request.TLS.ServerName))
+		}
+
+		c.Next()
+	}
+}
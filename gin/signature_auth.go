@@ -0,0 +1,47 @@
+package middlewares
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/bnb-chain/bsc-mev-sentry/auth"
+	"github.com/bnb-chain/bsc-mev-sentry/log"
+)
+
+// SignatureAuth verifies the signature header of the request body, or the one of Flashbots tooling, and attaches
+// the builder identity of the signer to the request context. Requests with an invalid signature are rejected.
+func SignatureAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		signature, flashbots := c.GetHeader(auth.SignatureHeader), c.GetHeader(auth.FlashbotsSignatureHeader)
+		if signature == "" && flashbots == "" {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		var id *auth.Identity
+		if signature != "" {
+			id, err = auth.VerifyRequestSignature(body, signature)
+		} else {
+			id, err = auth.VerifyFlashbotsSignature(body, flashbots)
+		}
+		if err != nil {
+			log.Debugw("invalid request signature", "err", err)
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+
+		c.Request = c.Request.WithContext(auth.WithIdentity(c.Request.Context(), id))
+
+		c.Next()
+	}
+}
@@ -0,0 +1,282 @@
+package hints
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/bnb-chain/bsc-mev-sentry/log"
+	"github.com/bnb-chain/bsc-mev-sentry/metrics"
+)
+
+const sendTimeout = 5 * time.Second
+
+// DefaultMaxSubscribers caps the searchers subscribed to the stream unless configured
+const DefaultMaxSubscribers = 16
+
+// fields a hint may disclose of the txs of a bid, calldata never is
+const (
+	TxHash           = "tx_hash"
+	ContractAddress  = "contract_address"
+	FunctionSelector = "function_selector"
+)
+
+type Config struct {
+	// Backend where hints go, http posts each hint to URL and ws streams them to searchers, disabled if empty
+	Backend string
+	// URL the hints are posted to by the http backend
+	URL string
+	// Path of the websocket stream on the service listener, defaults to /hints
+	Path string
+	// Token the searchers subscribe to the stream with as a bearer token, required unless JWT authenticates them
+	Token string
+	// MaxSubscribers searchers subscribed to the stream at most, each of them holds one of the RPC concurrency
+	MaxSubscribers int
+	// Fields disclosed of the txs of a bid, of tx_hash, contract_address and function_selector, defaults to
+	// contract_address and function_selector
+	Fields []string
+	// Addresses only the txs calling these contracts are hinted, all txs if empty
+	Addresses []common.Address
+	// Builders only the bids of these builders are hinted, the builders opt in, none if empty
+	Builders []common.Address
+	// BufferSize hints waiting to be sent, more are dropped instead of slowing down bids
+	BufferSize int
+}
+
+// Hint discloses what a pending bid touches without its calldata, so that searchers can build on top of it.
+type Hint struct {
+	BidHash     common.Hash `json:"bidHash"`
+	BlockNumber uint64      `json:"blockNumber"`
+	Txs         []TxHint    `json:"txs"`
+}
+
+// TxHint is the disclosed part of a tx of a bid, only the configured fields are set.
+type TxHint struct {
+	Hash             *common.Hash    `json:"hash,omitempty"`
+	To               *common.Address `json:"to,omitempty"`
+	FunctionSelector hexutil.Bytes   `json:"functionSelector,omitempty"`
+}
+
+// sink delivers encoded hints.
+type sink interface {
+	Name() string
+	Send(ctx context.Context, data []byte) error
+	Close() error
+}
+
+// Publisher turns pending bids into hints and sends them in the background, so that a slow endpoint never delays
+// bids.
+type Publisher struct {
+	cfg       Config
+	fields    map[string]bool
+	addresses map[common.Address]bool
+	builders  map[common.Address]bool
+
+	sink sink
+	bids chan *pendingBid
+	done chan struct{}
+
+	mu     sync.RWMutex // guards closed against publishes racing with close
+	closed bool
+}
+
+// pendingBid is a bid accepted, its txs are decoded into a hint in the background.
+type pendingBid struct {
+	bid  *types.RawBid
+	hash common.Hash
+}
+
+// New creates the publisher of the config, a nil publisher is returned if no backend is configured.
+func New(cfg Config) (*Publisher, error) {
+	if cfg.Backend == "" {
+		return nil, nil
+	}
+
+	if cfg.Path == "" {
+		cfg.Path = "/hints"
+	}
+	if len(cfg.Fields) == 0 {
+		cfg.Fields = []string{ContractAddress, FunctionSelector}
+	}
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = 4096
+	}
+	if cfg.MaxSubscribers <= 0 {
+		cfg.MaxSubscribers = DefaultMaxSubscribers
+	}
+
+	fields := make(map[string]bool, len(cfg.Fields))
+	for _, field := range cfg.Fields {
+		switch field {
+		case TxHash, ContractAddress, FunctionSelector:
+			fields[field] = true
+		default:
+			return nil, fmt.Errorf("unsupported hint field %s", field)
+		}
+	}
+
+	var s sink
+	switch cfg.Backend {
+	case "http":
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("hint URL is required by the http backend")
+		}
+		s = newHTTPSink(cfg.URL)
+	case "ws":
+		s = newStream(cfg.MaxSubscribers)
+	default:
+		return nil, fmt.Errorf("unsupported hint backend %s", cfg.Backend)
+	}
+
+	p := &Publisher{
+		cfg:       cfg,
+		fields:    fields,
+		addresses: toSet(cfg.Addresses),
+		builders:  toSet(cfg.Builders),
+		sink:      s,
+		bids:      make(chan *pendingBid, cfg.BufferSize),
+		done:      make(chan struct{}),
+	}
+
+	if len(p.builders) == 0 {
+		log.Warnw("no builder opted in to hints, none is published", "backend", cfg.Backend)
+	}
+
+	go p.loop()
+
+	return p, nil
+}
+
+func toSet(addresses []common.Address) map[common.Address]bool {
+	set := make(map[common.Address]bool, len(addresses))
+	for _, address := range addresses {
+		set[address] = true
+	}
+
+	return set
+}
+
+// Stream returns the path and handler of the websocket stream of hints, false unless the backend is ws.
+func (p *Publisher) Stream() (string, http.Handler, bool) {
+	if p == nil {
+		return "", nil, false
+	}
+
+	stream, ok := p.sink.(*stream)
+	return p.cfg.Path, stream, ok
+}
+
+// Publish queues the bid of a builder opted in to be hinted, if any of its txs is hinted. It's dropped if the sink
+// falls behind.
+func (p *Publisher) Publish(builder common.Address, bid *types.RawBid, bidHash common.Hash) {
+	if p == nil || bid == nil || !p.builders[builder] {
+		return
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.closed {
+		return
+	}
+
+	select {
+	case p.bids <- &pendingBid{bid: bid, hash: bidHash}:
+	default:
+		metrics.HintCounter.WithLabelValues(p.sink.Name(), "dropped").Inc()
+	}
+}
+
+// hint discloses the configured fields of the txs of the bid, nil if none of them is hinted.
+func (p *Publisher) hint(bid *types.RawBid, bidHash common.Hash) *Hint {
+	hint := &Hint{BidHash: bidHash, BlockNumber: bid.BlockNumber}
+
+	for _, raw := range bid.Txs {
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(raw); err != nil {
+			continue
+		}
+
+		if len(p.addresses) > 0 && (tx.To() == nil || !p.addresses[*tx.To()]) {
+			continue
+		}
+
+		var txHint TxHint
+		if p.fields[TxHash] {
+			hash := tx.Hash()
+			txHint.Hash = &hash
+		}
+		if p.fields[ContractAddress] {
+			txHint.To = tx.To()
+		}
+		if p.fields[FunctionSelector] && len(tx.Data()) >= 4 {
+			txHint.FunctionSelector = tx.Data()[:4]
+		}
+
+		hint.Txs = append(hint.Txs, txHint)
+	}
+
+	if len(hint.Txs) == 0 {
+		return nil
+	}
+
+	return hint
+}
+
+func (p *Publisher) loop() {
+	defer close(p.done)
+
+	for pending := range p.bids {
+		hint := p.hint(pending.bid, pending.hash)
+		if hint == nil {
+			continue
+		}
+
+		data, err := json.Marshal(hint)
+		if err != nil {
+			log.Errorw("failed to encode hint", "err", err)
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+		err = p.sink.Send(ctx, data)
+		cancel()
+
+		if err != nil {
+			metrics.HintCounter.WithLabelValues(p.sink.Name(), "failed").Inc()
+			log.Errorw("failed to send hint", "backend", p.sink.Name(), "err", err)
+			continue
+		}
+
+		metrics.HintCounter.WithLabelValues(p.sink.Name(), "sent").Inc()
+	}
+}
+
+// Close sends the queued hints and closes the sink.
+func (p *Publisher) Close() {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return
+	}
+	p.closed = true
+	close(p.bids)
+	p.mu.Unlock()
+
+	<-p.done
+
+	if err := p.sink.Close(); err != nil {
+		log.Errorw("failed to close hint sink", "backend", p.sink.Name(), "err", err)
+	}
+}
@@ -0,0 +1,117 @@
+package hints
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newBid(t *testing.T, to ...common.Address) *types.RawBid {
+	key, _ := crypto.GenerateKey()
+	signer := types.LatestSignerForChainID(big.NewInt(56))
+
+	bid := &types.RawBid{BlockNumber: 100}
+	for i := range to {
+		tx, err := types.SignNewTx(key, signer, &types.LegacyTx{
+			Nonce: uint64(i), To: &to[i], Gas: 100000, GasPrice: big.NewInt(1), Data: []byte{1, 2, 3, 4, 5, 6},
+		})
+		require.NoError(t, err)
+		raw, err := tx.MarshalBinary()
+		require.NoError(t, err)
+		bid.Txs = append(bid.Txs, raw)
+	}
+
+	return bid
+}
+
+func TestHint(t *testing.T) {
+	pool, token := common.HexToAddress("0x01"), common.HexToAddress("0x02")
+	bid := newBid(t, pool, token)
+
+	p, err := New(Config{Backend: "ws", Addresses: []common.Address{pool}})
+	require.NoError(t, err)
+	defer p.Close()
+
+	hint := p.hint(bid, common.HexToHash("0xaa"))
+	require.Len(t, hint.Txs, 1)
+	assert.Equal(t, pool, *hint.Txs[0].To)
+	assert.Equal(t, hexutil.Bytes{1, 2, 3, 4}, hint.Txs[0].FunctionSelector)
+	assert.Nil(t, hint.Txs[0].Hash)
+
+	// bids touching none of the addresses aren't hinted
+	assert.Nil(t, p.hint(newBid(t, token), common.Hash{}))
+
+	_, err = New(Config{Backend: "ws", Fields: []string{"calldata"}})
+	assert.Error(t, err)
+
+	_, err = New(Config{Backend: "http"})
+	assert.Error(t, err)
+
+	p, err = New(Config{})
+	assert.NoError(t, err)
+	assert.Nil(t, p)
+}
+
+func TestStream(t *testing.T) {
+	builder := common.HexToAddress("0x03")
+	p, err := New(Config{
+		Backend:        "ws",
+		Fields:         []string{TxHash},
+		Builders:       []common.Address{builder},
+		MaxSubscribers: 1,
+	})
+	require.NoError(t, err)
+
+	path, handler, ok := p.Stream()
+	require.True(t, ok)
+	assert.Equal(t, "/hints", path)
+
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// the subscription is registered once the upgrade is served
+	require.Eventually(t, func() bool {
+		p.sink.(*stream).mu.Lock()
+		defer p.sink.(*stream).mu.Unlock()
+		return len(p.sink.(*stream).subscribers) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	// searchers beyond the cap are turned away
+	_, resp, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	require.Error(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+
+	// the bids of builders not opted in aren't hinted
+	p.Publish(common.HexToAddress("0x04"), newBid(t, common.HexToAddress("0x01")), common.HexToHash("0xbb"))
+	p.Publish(builder, newBid(t, common.HexToAddress("0x01")), common.HexToHash("0xaa"))
+
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	_, data, err := conn.ReadMessage()
+	require.NoError(t, err)
+
+	var hint Hint
+	require.NoError(t, json.Unmarshal(data, &hint))
+	assert.Equal(t, common.HexToHash("0xaa"), hint.BidHash)
+	require.Len(t, hint.Txs, 1)
+	assert.NotNil(t, hint.Txs[0].Hash)
+	assert.Nil(t, hint.Txs[0].To)
+
+	p.Close()
+	p.Publish(builder, newBid(t, common.HexToAddress("0x01")), common.Hash{})
+}
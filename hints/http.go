@@ -0,0 +1,49 @@
+package hints
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// httpSink posts each hint to the endpoint as JSON.
+type httpSink struct {
+	url    string
+	client *http.Client
+}
+
+func newHTTPSink(url string) *httpSink {
+	return &httpSink{url: url, client: &http.Client{Timeout: sendTimeout}}
+}
+
+func (s *httpSink) Name() string {
+	return "http"
+}
+
+func (s *httpSink) Send(ctx context.Context, data []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("hint endpoint answered %s", resp.Status)
+	}
+
+	return nil
+}
+
+func (s *httpSink) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,140 @@
+package hints
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/bnb-chain/bsc-mev-sentry/log"
+	"github.com/bnb-chain/bsc-mev-sentry/metrics"
+)
+
+const (
+	subscriberBuffer = 256
+	writeTimeout     = 5 * time.Second
+)
+
+// stream broadcasts hints to the searchers subscribed over websocket, a searcher falling behind misses hints
+// instead of holding back the others.
+type stream struct {
+	upgrader       websocket.Upgrader
+	maxSubscribers int
+
+	mu          sync.Mutex
+	subscribers map[*subscriber]struct{}
+	closed      bool
+}
+
+type subscriber struct {
+	conn  *websocket.Conn
+	hints chan []byte
+}
+
+func newStream(maxSubscribers int) *stream {
+	return &stream{
+		// searchers connect from anywhere, they're authenticated by the middlewares of the service listener
+		upgrader:       websocket.Upgrader{CheckOrigin: func(*http.Request) bool { return true }},
+		maxSubscribers: maxSubscribers,
+		subscribers:    make(map[*subscriber]struct{}),
+	}
+}
+
+func (s *stream) Name() string {
+	return "ws"
+}
+
+// ServeHTTP subscribes the searcher to the hints until either side closes the connection, searchers beyond the
+// subscriber cap are rejected.
+func (s *stream) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.full() {
+		log.Debugw("hint subscriber rejected beyond the cap", "remote", r.RemoteAddr, "max", s.maxSubscribers)
+		http.Error(w, "too many hint subscribers", http.StatusServiceUnavailable)
+		return
+	}
+
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Debugw("failed to upgrade hint subscriber", "remote", r.RemoteAddr, "err", err)
+		return
+	}
+
+	sub := &subscriber{conn: conn, hints: make(chan []byte, subscriberBuffer)}
+
+	s.mu.Lock()
+	// checked again, the searchers upgraded meanwhile may have filled the cap
+	if s.closed || len(s.subscribers) >= s.maxSubscribers {
+		s.mu.Unlock()
+		_ = conn.Close()
+		return
+	}
+	s.subscribers[sub] = struct{}{}
+	s.mu.Unlock()
+
+	// searchers aren't expected to send anything, reading notices them leaving
+	go func() {
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				s.unsubscribe(sub)
+				return
+			}
+		}
+	}()
+
+	for data := range sub.hints {
+		_ = conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+		if err := conn.WriteMessage(websocket.TextMessage, data); err != nil {
+			s.unsubscribe(sub)
+			break
+		}
+	}
+
+	_ = conn.Close()
+}
+
+func (s *stream) full() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.subscribers) >= s.maxSubscribers
+}
+
+func (s *stream) unsubscribe(sub *subscriber) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.subscribers[sub]; ok {
+		delete(s.subscribers, sub)
+		close(sub.hints)
+	}
+}
+
+func (s *stream) Send(_ context.Context, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for sub := range s.subscribers {
+		select {
+		case sub.hints <- data:
+		default:
+			metrics.HintCounter.WithLabelValues(s.Name(), "dropped").Inc()
+		}
+	}
+
+	return nil
+}
+
+func (s *stream) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	for sub := range s.subscribers {
+		delete(s.subscribers, sub)
+		close(sub.hints)
+	}
+
+	return nil
+}
@@ -0,0 +1,127 @@
+// Package lifecycle starts and stops the components of the sentry in dependency order.
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bnb-chain/bsc-mev-sentry/log"
+)
+
+const defaultStopTimeout = 5 * time.Second
+
+// Component is a part of the sentry with a lifecycle, e.g. a listener or a storage.
+type Component struct {
+	Name string
+	// Start starts the component without blocking, background failures are reported via Manager.Fail
+	Start func() error
+	// Stop stops the component, it should give up once ctx is done
+	Stop func(ctx context.Context) error
+	// StopTimeout bounds Stop, defaults to 5s
+	StopTimeout time.Duration
+}
+
+// StopResult reports how a component stopped.
+type StopResult struct {
+	Name     string
+	Duration time.Duration
+	Err      error
+}
+
+// Manager starts components in the order they are added, and stops them in the reverse order,
+// so a component is started after and stopped before the ones it depends on.
+type Manager struct {
+	components []Component
+	started    int
+	failed     chan error
+}
+
+func NewManager() *Manager {
+	return &Manager{failed: make(chan error, 1)}
+}
+
+func (m *Manager) Add(c Component) {
+	m.components = append(m.components, c)
+}
+
+// Start starts all components, the started ones are stopped if any of them fails to start.
+func (m *Manager) Start() error {
+	for _, c := range m.components[m.started:] {
+		if c.Start != nil {
+			if err := c.Start(); err != nil {
+				m.Stop()
+				return fmt.Errorf("failed to start %s: %w", c.Name, err)
+			}
+		}
+
+		m.started++
+		log.Infow("component started", "component", c.Name)
+	}
+
+	return nil
+}
+
+// Fail reports a background failure of a component, only the first one is kept.
+func (m *Manager) Fail(name string, err error) {
+	select {
+	case m.failed <- fmt.Errorf("%s failed: %w", name, err):
+	default:
+	}
+}
+
+// Failed returns the channel of the first background failure.
+func (m *Manager) Failed() <-chan error {
+	return m.failed
+}
+
+// Stop stops the started components in the reverse order, each within its stop timeout, and
+// returns how each of them stopped.
+func (m *Manager) Stop() []StopResult {
+	results := make([]StopResult, 0, m.started)
+
+	for i := m.started - 1; i >= 0; i-- {
+		c := m.components[i]
+		if c.Stop == nil {
+			continue
+		}
+
+		results = append(results, stop(c))
+	}
+	m.started = 0
+
+	for _, r := range results {
+		if r.Err != nil {
+			log.Errorw("component stopped", "component", r.Name, "duration", r.Duration, "err", r.Err)
+		} else {
+			log.Infow("component stopped", "component", r.Name, "duration", r.Duration)
+		}
+	}
+
+	return results
+}
+
+func stop(c Component) StopResult {
+	timeout := c.StopTimeout
+	if timeout <= 0 {
+		timeout = defaultStopTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- c.Stop(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = fmt.Errorf("not stopped within %v", timeout)
+	}
+
+	return StopResult{Name: c.Name, Duration: time.Since(start), Err: err}
+}
@@ -0,0 +1,68 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager(t *testing.T) {
+	var events []string
+	component := func(name string) Component {
+		return Component{
+			Name: name,
+			Start: func() error {
+				events = append(events, "start "+name)
+				return nil
+			},
+			Stop: func(context.Context) error {
+				events = append(events, "stop "+name)
+				return nil
+			},
+		}
+	}
+
+	m := NewManager()
+	m.Add(component("storage"))
+	m.Add(component("listener"))
+	m.Add(Component{
+		Name: "stuck",
+		Stop: func(ctx context.Context) error {
+			<-ctx.Done()
+			time.Sleep(10 * time.Millisecond)
+			return nil
+		},
+		StopTimeout: 10 * time.Millisecond,
+	})
+	require.NoError(t, m.Start())
+
+	results := m.Stop()
+	assert.Equal(t, []string{"start storage", "start listener", "stop listener", "stop storage"}, events)
+	require.Len(t, results, 3)
+	assert.Equal(t, "stuck", results[0].Name)
+	assert.Error(t, results[0].Err)
+	assert.NoError(t, results[1].Err)
+
+	// stopped components aren't stopped again
+	assert.Empty(t, m.Stop())
+}
+
+func TestManagerStartFailure(t *testing.T) {
+	var stopped []string
+
+	m := NewManager()
+	m.Add(Component{Name: "storage", Stop: func(context.Context) error {
+		stopped = append(stopped, "storage")
+		return nil
+	}})
+	m.Add(Component{Name: "listener", Start: func() error {
+		return errors.New("address in use")
+	}})
+
+	assert.Error(t, m.Start())
+	assert.Equal(t, []string{"storage"}, stopped)
+}
@@ -1,10 +1,15 @@
 package log
 
 import (
+	"compress/gzip"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -48,18 +53,44 @@ func (ht *HourTicker) Ticker() <-chan time.Time {
 	return ch
 }
 
+// RotateConfig controls the rotation and retention of log files, beyond the new file every hour.
+type RotateConfig struct {
+	// MaxSize bytes a log file grows to before the next one is started within the hour, unlimited if 0
+	MaxSize int64
+	// MaxAge rotated log files older are removed, kept if 0
+	MaxAge time.Duration
+	// MaxBackups number of the rotated log files kept, the oldest are removed, all kept if 0
+	MaxBackups int
+	// Compress gzips the rotated log files
+	Compress bool
+}
+
 type AsyncFileWriter struct {
 	filePath string
 	fd       *os.File
+	size     int64
+	rotate   RotateConfig
+	// current name of the file written to, read by the clean up in the background
+	current atomic.Value
 
 	wg         sync.WaitGroup
 	started    int32
 	buf        chan []byte
 	stop       chan struct{}
 	hourTicker *HourTicker
+
+	// compressMu serializes the compression of the rotated files in the background
+	compressMu sync.Mutex
+	compressWg sync.WaitGroup
 }
 
 func NewAsyncFileWriter(filePath string, bufSize int64) *AsyncFileWriter {
+	return NewRotatingFileWriter(filePath, bufSize, RotateConfig{})
+}
+
+// NewRotatingFileWriter creates a file writer rotating and cleaning up the log files as configured, besides
+// starting a new file every hour.
+func NewRotatingFileWriter(filePath string, bufSize int64, rotate RotateConfig) *AsyncFileWriter {
 	absFilePath, err := filepath.Abs(filePath)
 	if err != nil {
 		logger.With("filePath", filePath, "err", err).Panic("get file path of logger error")
@@ -67,6 +98,7 @@ func NewAsyncFileWriter(filePath string, bufSize int64) *AsyncFileWriter {
 
 	w := &AsyncFileWriter{
 		filePath:   absFilePath,
+		rotate:     rotate,
 		buf:        make(chan []byte, bufSize),
 		stop:       make(chan struct{}),
 		hourTicker: NewHourTicker(),
@@ -85,13 +117,18 @@ func (w *AsyncFileWriter) initLogFile() error {
 		err error
 	)
 
-	realFilePath := w.timeFilePath(w.filePath)
+	realFilePath := w.nextFilePath()
 	fd, err = os.OpenFile(realFilePath, os.O_CREATE|os.O_APPEND|os.O_RDWR, 0644)
 	if err != nil {
 		return err
 	}
 
 	w.fd = fd
+	w.current.Store(filepath.Base(fd.Name()))
+	w.size = 0
+	if info, err := fd.Stat(); err == nil {
+		w.size = info.Size()
+	}
 	_, err = os.Lstat(w.filePath)
 	if err == nil || os.IsExist(err) {
 		err = os.Remove(w.filePath)
@@ -117,6 +154,7 @@ func (w *AsyncFileWriter) Start() error {
 	if err != nil {
 		return err
 	}
+	w.cleanUp()
 
 	w.wg.Add(1)
 	go func() {
@@ -157,28 +195,175 @@ func (w *AsyncFileWriter) flushBuffer() {
 }
 
 func (w *AsyncFileWriter) SyncWrite(msg []byte) {
-	w.rotateFile()
+	w.rotateFile(len(msg))
 	if w.fd != nil {
-		w.fd.Write(msg)
+		n, _ := w.fd.Write(msg)
+		w.size += int64(n)
 	}
 }
 
-func (w *AsyncFileWriter) rotateFile() {
+func (w *AsyncFileWriter) rotateFile(next int) {
 	select {
 	case <-w.hourTicker.C:
-		if err := w.flushAndClose(); err != nil {
-			fmt.Fprintf(os.Stderr, "flush and close file error. err=%s", err)
+	default:
+		if w.rotate.MaxSize <= 0 || w.size == 0 || w.size+int64(next) <= w.rotate.MaxSize {
+			return
+		}
+	}
+
+	var rotated string
+	if w.fd != nil {
+		rotated = w.fd.Name()
+	}
+
+	if err := w.flushAndClose(); err != nil {
+		fmt.Fprintf(os.Stderr, "flush and close file error. err=%s", err)
+	}
+	if err := w.initLogFile(); err != nil {
+		fmt.Fprintf(os.Stderr, "init log file error. err=%s", err)
+	}
+
+	if w.rotate.Compress && rotated != "" {
+		w.compress(rotated)
+		return
+	}
+	w.cleanUp()
+}
+
+// compress gzips the rotated file in the background so that the writes aren't held up, and cleans up after it.
+func (w *AsyncFileWriter) compress(rotated string) {
+	w.compressWg.Add(1)
+	go func() {
+		defer w.compressWg.Done()
+
+		w.compressMu.Lock()
+		defer w.compressMu.Unlock()
+
+		// the file is gone if it's cleaned up while waiting
+		if err := compressFile(rotated); err != nil && !errors.Is(err, os.ErrNotExist) {
+			fmt.Fprintf(os.Stderr, "compress log file error. err=%s", err)
+		}
+		w.cleanUp()
+	}()
+}
+
+// nextFilePath returns the file of the current hour to write to. If the size of files is limited, it's the last
+// one of the hour unless it's full or rotated, e.g. <file>.2006-01-02_15.1 after <file>.2006-01-02_15.
+func (w *AsyncFileWriter) nextFilePath() string {
+	filePath := w.timeFilePath(w.filePath)
+	if w.rotate.MaxSize <= 0 {
+		return filePath
+	}
+
+	names, _ := filepath.Glob(filePath + "*")
+	last := -1
+	for _, name := range names {
+		suffix := strings.TrimSuffix(strings.TrimPrefix(name, filePath), ".gz")
+		if suffix == "" {
+			last = max(last, 0)
+		} else if i, err := strconv.Atoi(strings.TrimPrefix(suffix, ".")); err == nil && strings.HasPrefix(suffix, ".") {
+			last = max(last, i)
 		}
-		if err := w.initLogFile(); err != nil {
-			fmt.Fprintf(os.Stderr, "init log file error. err=%s", err)
+	}
+
+	name := func(i int) string {
+		if i == 0 {
+			return filePath
 		}
-	default:
+		return filePath + "." + strconv.Itoa(i)
+	}
+
+	if last < 0 {
+		return filePath
+	}
+	if w.fd == nil {
+		// keep appending to the last file on restart unless it's full
+		if info, err := os.Stat(name(last)); err == nil && info.Size() < w.rotate.MaxSize {
+			return name(last)
+		}
+	}
+
+	return name(last + 1)
+}
+
+// cleanUp removes the rotated log files beyond MaxAge or MaxBackups.
+func (w *AsyncFileWriter) cleanUp() {
+	if w.rotate.MaxAge <= 0 && w.rotate.MaxBackups <= 0 {
+		return
+	}
+
+	dir, prefix := filepath.Dir(w.filePath), filepath.Base(w.filePath)+"."
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "read log dir error. err=%s", err)
+		return
+	}
+
+	current, _ := w.current.Load().(string)
+
+	type backup struct {
+		name    string
+		modTime time.Time
+	}
+	var backups []backup
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasPrefix(entry.Name(), prefix) || entry.Name() == current {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		backups = append(backups, backup{name: entry.Name(), modTime: info.ModTime()})
+	}
+
+	// newest first
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].modTime.After(backups[j].modTime)
+	})
+
+	for i, b := range backups {
+		expired := w.rotate.MaxAge > 0 && time.Since(b.modTime) > w.rotate.MaxAge
+		if expired || (w.rotate.MaxBackups > 0 && i >= w.rotate.MaxBackups) {
+			if err := os.Remove(filepath.Join(dir, b.name)); err != nil {
+				fmt.Fprintf(os.Stderr, "remove log file error. err=%s", err)
+			}
+		}
+	}
+}
+
+// compressFile gzips the file into <file>.gz and removes the file.
+func compressFile(name string) error {
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(name+".gz", os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(dst)
+	if _, err = io.Copy(gz, src); err == nil {
+		err = gz.Close()
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
 	}
+	if err != nil {
+		_ = os.Remove(name + ".gz")
+		return err
+	}
+
+	return os.Remove(name)
 }
 
 func (w *AsyncFileWriter) Stop() error {
 	w.stop <- struct{}{}
 	w.wg.Wait()
+	w.compressWg.Wait()
 
 	w.hourTicker.Stop()
 	return nil
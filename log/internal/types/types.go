@@ -3,6 +3,7 @@ package types
 import (
 	"context"
 	"io"
+	"time"
 )
 
 type Level int
@@ -33,6 +34,12 @@ type Logger interface {
 	// SetWriter resets log writer
 	SetWriter(w AsyncWriter)
 
+	// SetSampling resets the sampling of repeated warn and error entries
+	SetSampling(cfg SamplingConfig)
+
+	// SetHook resets the hook receiving the entries logged at error level and up, none if nil
+	SetHook(hook Hook)
+
 	// AddCallerSkip increases the number of callers skipped by caller annotation
 	// (as enabled by the AddCaller option). When building wrappers around the
 	// Logger and SugaredLogger, supplying this Option prevents zap from always
@@ -169,3 +176,18 @@ type AsyncWriter interface {
 	// Stop flush all log entries
 	Stop() error
 }
+
+// SamplingConfig samples the repeated warn and error entries, i.e. of the same level, message and caller, within each
+// interval, and summarizes the ones dropped at its end along with the key-value pairs of the last one.
+type SamplingConfig struct {
+	// Interval the repeated entries are counted in, sampling is disabled if 0
+	Interval time.Duration
+	// First number of the repeated entries logged as is within an interval
+	First int
+	// Thereafter logs every Thereafter-th of the repeated entries beyond First, none if 0
+	Thereafter int
+}
+
+// Hook receives the entries logged at error level and up, e.g. to report them to an error tracking service. It runs
+// on the logging goroutine, before the panic of the panic level entries.
+type Hook func(ctx context.Context, lvl Level, msg string, kvs []interface{})
@@ -26,8 +26,10 @@ const (
 var _ types.Logger = (*logger)(nil)
 
 type logger struct {
-	level  *levelEnabler
-	writer *asyncWriterProxy
+	level   *levelEnabler
+	writer  *asyncWriterProxy
+	sampler *samplerProxy
+	hook    *atomic.Pointer[types.Hook]
 
 	base *zap.Logger
 }
@@ -48,9 +50,11 @@ func NewLogger(lvl types.Level, w types.AsyncWriter) *logger {
 		EncodeCaller:   zapcore.ShortCallerEncoder,
 	}
 	l := &logger{
-		level:  newLevelEnabler(toZapLevel(lvl)),
-		writer: newAsyncWriter(w),
-		base:   nil,
+		level:   newLevelEnabler(toZapLevel(lvl)),
+		writer:  newAsyncWriter(w),
+		sampler: &samplerProxy{},
+		hook:    &atomic.Pointer[types.Hook]{},
+		base:    nil,
 	}
 	core := zapcore.NewCore(newZapJSONEncoder(encoderConfig, false), l.writer, l.level)
 	l.base = zap.New(core,
@@ -79,6 +83,21 @@ func toZapLevel(lvl types.Level) zapcore.Level {
 	}
 }
 
+func fromZapLevel(lvl zapcore.Level) types.Level {
+	switch lvl {
+	case zap.DebugLevel:
+		return types.DebugLevel
+	case zap.InfoLevel:
+		return types.InfoLevel
+	case zap.WarnLevel:
+		return types.WarnLevel
+	case zap.ErrorLevel:
+		return types.ErrorLevel
+	default:
+		return types.PanicLevel
+	}
+}
+
 func (zl *logger) SetLevel(lvl types.Level) {
 	zl.level.SetLevel(toZapLevel(lvl))
 }
@@ -87,19 +106,45 @@ func (zl *logger) SetWriter(w types.AsyncWriter) {
 	zl.writer.SetWriter(w)
 }
 
+func (zl *logger) SetSampling(cfg types.SamplingConfig) {
+	if cfg.Interval <= 0 {
+		zl.sampler.set(nil)
+		return
+	}
+
+	zl.sampler.set(newSampler(cfg, func(lvl zapcore.Level, msg string, kvs []interface{}) {
+		if ce := zl.base.Check(lvl, msg); ce != nil {
+			ce.Write(zl.sweetenFields(kvs, 1)...)
+		}
+	}))
+}
+
+func (zl *logger) SetHook(hook types.Hook) {
+	if hook == nil {
+		zl.hook.Store(nil)
+		return
+	}
+
+	zl.hook.Store(&hook)
+}
+
 func (zl *logger) AddCallerSkip(skip int) types.Logger {
 	return &logger{
-		level:  zl.level,
-		writer: zl.writer,
-		base:   zl.base.WithOptions(zap.AddCallerSkip(skip)),
+		level:   zl.level,
+		writer:  zl.writer,
+		sampler: zl.sampler,
+		hook:    zl.hook,
+		base:    zl.base.WithOptions(zap.AddCallerSkip(skip)),
 	}
 }
 
 func (zl *logger) With(args ...interface{}) types.Logger {
 	return &logger{
-		level:  zl.level,
-		writer: zl.writer,
-		base:   zl.base.With(zl.sweetenFields(args, 0)...),
+		level:   zl.level,
+		writer:  zl.writer,
+		sampler: zl.sampler,
+		hook:    zl.hook,
+		base:    zl.base.With(zl.sweetenFields(args, 0)...),
 	}
 }
 
@@ -224,6 +269,8 @@ func (zl *logger) CtxPanicw(ctx context.Context, msg string, kvs ...interface{})
 }
 
 func (zl *logger) Stop() {
+	// the dropped entries are summarized before the writer is stopped
+	zl.sampler.set(nil)
 	zl.writer.Stop()
 }
 
@@ -233,7 +280,22 @@ func (zl *logger) log(lvl zapcore.Level, ctx context.Context, template string, f
 	}
 
 	msg := zl.getMessage(template, fmtArgs)
-	if ce := zl.base.Check(lvl, msg); ce != nil {
+	ce := zl.base.Check(lvl, msg)
+
+	// repeated entries are told apart by their caller, not by their fields
+	var caller string
+	if ce != nil {
+		caller = ce.Caller.TrimmedPath()
+	}
+	if !zl.sampler.allow(lvl, msg, caller, kvs) {
+		return
+	}
+
+	if hook := zl.hook.Load(); hook != nil && lvl >= zap.ErrorLevel {
+		(*hook)(ctx, fromZapLevel(lvl), msg, kvs)
+	}
+
+	if ce != nil {
 		fields := zl.getMetaInfo(ctx)
 		fields = append(fields, zl.sweetenFields(kvs, 1)...)
 		ce.Write(fields...)
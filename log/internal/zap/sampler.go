@@ -0,0 +1,135 @@
+package zap
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+
+	"github.com/bnb-chain/bsc-mev-sentry/log/internal/types"
+)
+
+// sampler drops the repeated warn and error entries beyond the configured ones within each interval, and logs a
+// summary of the dropped ones at its end. Entries are repeated if they share the level, message and caller, whatever
+// their fields, e.g. the same dial failure of validators with different errors.
+type sampler struct {
+	cfg  types.SamplingConfig
+	emit func(lvl zapcore.Level, msg string, kvs []interface{})
+
+	mu      sync.Mutex
+	entries map[sampleKey]*sampledEntry
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+type sampleKey struct {
+	lvl    zapcore.Level
+	msg    string
+	caller string
+}
+
+type sampledEntry struct {
+	kvs     []interface{} // of the last dropped entry
+	count   int
+	dropped int
+}
+
+func newSampler(cfg types.SamplingConfig, emit func(lvl zapcore.Level, msg string, kvs []interface{})) *sampler {
+	s := &sampler{
+		cfg:     cfg,
+		emit:    emit,
+		entries: make(map[sampleKey]*sampledEntry),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+
+	go s.loop()
+
+	return s
+}
+
+// allow tells whether the entry is logged, and counts it. The fields of the last dropped one are kept for the summary.
+func (s *sampler) allow(lvl zapcore.Level, msg, caller string, kvs []interface{}) bool {
+	key := sampleKey{lvl: lvl, msg: msg, caller: caller}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok {
+		e = &sampledEntry{}
+		s.entries[key] = e
+	}
+
+	e.count++
+	if e.count <= s.cfg.First || (s.cfg.Thereafter > 0 && (e.count-s.cfg.First)%s.cfg.Thereafter == 0) {
+		return true
+	}
+
+	e.kvs = kvs
+	e.dropped++
+	return false
+}
+
+func (s *sampler) loop() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.summarize()
+		case <-s.stop:
+			s.summarize()
+			return
+		}
+	}
+}
+
+// summarize logs how many of each entry were dropped in the interval and starts the next one.
+func (s *sampler) summarize() {
+	s.mu.Lock()
+	entries := s.entries
+	s.entries = make(map[sampleKey]*sampledEntry, len(entries))
+	s.mu.Unlock()
+
+	for key, e := range entries {
+		if e.dropped == 0 {
+			continue
+		}
+
+		kvs := append([]interface{}{"message", key.msg, "source", key.caller, "dropped", e.dropped,
+			"interval", s.cfg.Interval.String()}, e.kvs...)
+		s.emit(key.lvl, "repeated log entries dropped", kvs)
+	}
+}
+
+func (s *sampler) close() {
+	close(s.stop)
+	<-s.done
+}
+
+// samplerProxy is the sampler shared by the logger and the loggers derived from it.
+type samplerProxy struct {
+	raw atomic.Pointer[sampler]
+}
+
+// allow tells whether the entry is logged, entries below warn or from panic up are never dropped.
+func (p *samplerProxy) allow(lvl zapcore.Level, msg, caller string, kvs []interface{}) bool {
+	if lvl < zapcore.WarnLevel || lvl >= zapcore.DPanicLevel {
+		return true
+	}
+
+	s := p.raw.Load()
+	return s == nil || s.allow(lvl, msg, caller, kvs)
+}
+
+func (p *samplerProxy) set(s *sampler) {
+	if old := p.raw.Swap(s); old != nil {
+		old.close()
+	}
+}
@@ -0,0 +1,77 @@
+package zap
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+
+	"github.com/bnb-chain/bsc-mev-sentry/log/internal/types"
+)
+
+func TestSampler(t *testing.T) {
+	type summary struct {
+		lvl zapcore.Level
+		msg string
+		kvs []interface{}
+	}
+	var summaries []summary
+
+	s := newSampler(types.SamplingConfig{Interval: time.Hour, First: 2, Thereafter: 3},
+		func(lvl zapcore.Level, msg string, kvs []interface{}) {
+			summaries = append(summaries, summary{lvl, msg, kvs})
+		})
+
+	// the fields don't tell repeated entries apart, e.g. a validator down failing with another error each time
+	var logged int
+	for i := 0; i < 10; i++ {
+		kvs := []interface{}{"validator", fmt.Sprint("v", i)}
+		if s.allow(zapcore.ErrorLevel, "dial failed", "node/validator.go:10", kvs) {
+			logged++
+		}
+	}
+	// other callers are counted apart
+	if !s.allow(zapcore.ErrorLevel, "dial failed", "node/builder.go:10", []interface{}{"builder", "b1"}) {
+		t.Fatal("entry of another caller dropped")
+	}
+
+	// the first 2, then the 5th and 8th
+	if logged != 4 {
+		t.Fatalf("logged %d entries, want 4", logged)
+	}
+
+	s.close()
+
+	if len(summaries) != 1 {
+		t.Fatalf("got %d summaries, want 1", len(summaries))
+	}
+	got := summaries[0]
+	if got.lvl != zapcore.ErrorLevel || got.msg != "repeated log entries dropped" {
+		t.Fatalf("unexpected summary %v %s", got.lvl, got.msg)
+	}
+	if got.kvs[1] != "dial failed" || got.kvs[3] != "node/validator.go:10" || got.kvs[5] != 6 ||
+		got.kvs[len(got.kvs)-1] != "v9" {
+		t.Fatalf("unexpected summary fields %v", got.kvs)
+	}
+}
+
+func TestSamplerProxy(t *testing.T) {
+	var p samplerProxy
+
+	p.set(newSampler(types.SamplingConfig{Interval: time.Hour, First: 1}, func(zapcore.Level, string, []interface{}) {}))
+	defer p.set(nil)
+
+	for i := 0; i < 3; i++ {
+		if !p.allow(zapcore.InfoLevel, "info", "", nil) {
+			t.Fatal("info entry dropped")
+		}
+	}
+
+	if !p.allow(zapcore.WarnLevel, "warn", "", nil) {
+		t.Fatal("first warn entry dropped")
+	}
+	if p.allow(zapcore.WarnLevel, "warn", "", nil) {
+		t.Fatal("repeated warn entry logged")
+	}
+}
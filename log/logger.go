@@ -19,6 +19,12 @@ type Logger = types.Logger
 
 type Level = types.Level
 
+// SamplingConfig samples the repeated warn and error entries.
+type SamplingConfig = types.SamplingConfig
+
+// Hook receives the entries logged at error level and up.
+type Hook = types.Hook
+
 const (
 	// DebugLevel level. Usually only enabled when debugging. Very verbose logging.
 	DebugLevel = types.DebugLevel
@@ -84,6 +90,11 @@ func (w writerWrapper) Stop() error {
 
 // Init auto setting level and creating log directory
 func Init(lvl Level, path string) {
+	InitRotated(lvl, path, RotateConfig{})
+}
+
+// InitRotated is Init rotating and cleaning up the log files as configured.
+func InitRotated(lvl Level, path string, rotate RotateConfig) {
 	logger.SetLevel(lvl)
 
 	if path != "" {
@@ -96,7 +107,7 @@ func Init(lvl Level, path string) {
 			logger.With("err", err).Panicf("invalid dir stat")
 		}
 
-		logger.SetWriter(NewMultiWriteSyncer(NewAsyncFileWriter(path, 10*1024*1024),
+		logger.SetWriter(NewMultiWriteSyncer(NewRotatingFileWriter(path, 10*1024*1024, rotate),
 			&zapcore.BufferedWriteSyncer{WS: os.Stdout, FlushInterval: time.Second}))
 	}
 }
@@ -106,6 +117,17 @@ func SetLevel(lvl Level) {
 	logger.SetLevel(lvl)
 }
 
+// SetSampling resets the sampling of repeated warn and error entries, disabled if the interval is 0.
+func SetSampling(cfg SamplingConfig) {
+	logger.SetSampling(cfg)
+}
+
+// SetHook resets the hook receiving the entries logged at error level and up, e.g. to report them to an error
+// tracking service, none if nil.
+func SetHook(hook Hook) {
+	logger.SetHook(hook)
+}
+
 // SetWriter resets log writer
 func SetWriter(w types.AsyncWriter) {
 	logger.SetWriter(w)
@@ -0,0 +1,50 @@
+package log
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotatingFileWriter(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "sentry.log")
+
+	// a backup left by an earlier run, past MaxAge
+	stale := path + ".2000-01-01_00"
+	require.NoError(t, os.WriteFile(stale, []byte("stale\n"), 0o644))
+	require.NoError(t, os.Chtimes(stale, time.Now().Add(-48*time.Hour), time.Now().Add(-48*time.Hour)))
+
+	w := NewRotatingFileWriter(path, 100, RotateConfig{MaxSize: 10, MaxAge: 24 * time.Hour, MaxBackups: 2, Compress: true})
+	assert.NoFileExists(t, stale)
+
+	for _, line := range []string{"line-one\n", "line-two\n", "line-three\n", "line-four\n"} {
+		w.SyncWrite([]byte(line))
+	}
+	require.NoError(t, w.Stop())
+
+	current, err := os.Readlink(path)
+	require.NoError(t, err)
+	content, err := os.ReadFile(filepath.Join(dir, current))
+	require.NoError(t, err)
+	assert.Equal(t, "line-four\n", string(content))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	var backups []string
+	for _, entry := range entries {
+		if name := entry.Name(); name != "sentry.log" && name != current {
+			backups = append(backups, name)
+		}
+	}
+	// the first of the three rotated files is removed beyond MaxBackups
+	require.Len(t, backups, 2)
+	for _, backup := range backups {
+		assert.True(t, strings.HasSuffix(backup, ".gz"), backup)
+	}
+}
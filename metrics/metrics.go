@@ -2,6 +2,7 @@ package metrics
 
 import (
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 )
 
@@ -13,7 +14,21 @@ var (
 		Subsystem: "api",
 		Name:      "latency",
 		Buckets:   prometheus.ExponentialBuckets(0.01, 3, 15),
-	}, []string{"method"})
+	}, []string{"method", "served_from"})
+
+	// UpstreamLatencyHist is in milliseconds, labeled by the validator hostname and the rpc method called on it
+	UpstreamLatencyHist = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "upstream",
+		Name:      "latency",
+		Buckets:   prometheus.ExponentialBuckets(0.01, 3, 15),
+	}, []string{"validator", "method"})
+
+	ApiRequestCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "api",
+		Name:      "request",
+	}, []string{"method", "served_from"})
 
 	ApiErrorCounter = promauto.NewCounterVec(prometheus.CounterOpts{
 		Namespace: namespace,
@@ -21,15 +36,361 @@ var (
 		Name:      "error",
 	}, []string{"method", "code"})
 
+	ConcurrencyInUse = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "concurrency",
+		Name:      "in_use",
+	})
+
+	ConcurrencyLimit = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "concurrency",
+		Name:      "limit",
+	})
+
+	BuilderStakeGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "builder",
+		Name:      "stake",
+	}, []string{"builder"})
+
+	ValidatorActiveEndpoint = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "validator",
+		Name:      "active_endpoint",
+	}, []string{"validator"})
+
+	ValidatorCircuitBreaker = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "validator",
+		Name:      "circuit_breaker",
+	}, []string{"validator"})
+
+	ValidatorActive = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "validator",
+		Name:      "active",
+	}, []string{"validator"})
+
+	// ClusterLeader is 1 while the sentry is the elected leader of its replicas, 0 while a standby
+	ClusterLeader = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "cluster",
+		Name:      "leader",
+	})
+
+	RegisteredBuilders = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "builder_registry",
+		Name:      "size",
+	})
+
+	ConcurrencyQueueDepth = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "concurrency",
+		Name:      "queue_depth",
+	})
+
+	ConcurrencyRejectedCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "concurrency",
+		Name:      "rejected",
+	}, []string{"reason"})
+
+	BidArrivalOffsetHist = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "bid",
+		Name:      "arrival_offset",
+		Buckets:   prometheus.LinearBuckets(-3000, 250, 25),
+	}, []string{"validator"})
+
+	BidTxCountHist = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "bid",
+		Name:      "tx_count",
+		Buckets:   prometheus.ExponentialBuckets(1, 2, 12),
+	}, []string{"validator"})
+
+	BidGasUsedHist = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "bid",
+		Name:      "gas_used",
+		Buckets:   prometheus.ExponentialBuckets(21000, 2, 15),
+	}, []string{"validator"})
+
+	// BidFeeHist is in gwei, labeled by fee kind, i.e. gas or builder
+	BidFeeHist = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "bid",
+		Name:      "fee",
+		Buckets:   prometheus.ExponentialBuckets(1000, 4, 15),
+	}, []string{"validator", "kind"})
+
+	BidSizeHist = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "bid",
+		Name:      "size",
+		Buckets:   prometheus.ExponentialBuckets(1024, 2, 14),
+	}, []string{"validator"})
+
+	// BidLifecycleCounter counts bids by the stage they reached: received, forwarded to the validator,
+	// accepted by the validator and won, i.e. their pay bid tx is included on chain
+	BidLifecycleCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "bid",
+		Name:      "lifecycle",
+	}, []string{"stage"})
+
+	BidRejectedCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "bid",
+		Name:      "rejected",
+	}, []string{"reason"})
+
+	// BidCounter counts the bids of registered builders, labeled by the validator hostname, the builder address
+	// and the result, i.e. accepted or the rejection reason. Bids to unknown validators have an empty validator.
+	BidCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "bid",
+		Name:      "total",
+	}, []string{"validator", "builder", "result"})
+
+	BidStoreDroppedCounter = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "bid_store",
+		Name:      "dropped",
+	})
+
+	// BidStoreRows rows of the bid store, labeled by the table, i.e. bids or issues
+	BidStoreRows = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "bid_store",
+		Name:      "rows",
+	}, []string{"table"})
+
+	// BidStoreSizeBytes bytes taken by the bid store, i.e. the database file of sqlite or the tables of postgres
+	BidStoreSizeBytes = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "bid_store",
+		Name:      "size_bytes",
+	})
+
+	// BidStorePrunedCounter counts the rows pruned past the retention, labeled by the table
+	BidStorePrunedCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "bid_store",
+		Name:      "pruned",
+	}, []string{"table"})
+
+	// BidStoreResettledCounter counts the settled bids whose outcome changed as their block is settled again after a
+	// reorg, labeled by the outcome before and after
+	BidStoreResettledCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "bid_store",
+		Name:      "resettled",
+	}, []string{"from", "to"})
+
+	// ChainReorgCounter counts the reorgs seen on the chain of a validator, labeled by the validator hostname
+	ChainReorgCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "chain",
+		Name:      "reorg",
+	}, []string{"validator"})
+
+	// ChainReorgDepthHist observes the blocks replaced by each reorg, labeled by the validator hostname
+	ChainReorgDepthHist = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "chain",
+		Name:      "reorg_depth",
+		Buckets:   []float64{1, 2, 3, 4, 6, 8, 12, 16, 32, 64},
+	}, []string{"validator"})
+
+	// BlockWinRateGauge share of the recent blocks proposed by a validator won by a builder, labeled by the validator
+	// hostname and the builder address, or local for the blocks built by the validator itself
+	BlockWinRateGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "block",
+		Name:      "win_rate",
+	}, []string{"validator", "builder"})
+
+	// ArchiveExportCounter counts the batches of bids exported to object storage, labeled by the result, i.e. ok or
+	// failed
+	ArchiveExportCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "archive",
+		Name:      "export_total",
+	}, []string{"result"})
+
+	ArchiveExportedBidsCounter = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "archive",
+		Name:      "exported_bids",
+	})
+
+	// ArchiveExportedBytesCounter counts the compressed bytes exported
+	ArchiveExportedBytesCounter = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "archive",
+		Name:      "exported_bytes",
+	})
+
+	// ArchiveExportedReceivedAt unix seconds of when the last exported bid is received, how far the export lags behind
+	ArchiveExportedReceivedAt = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "archive",
+		Name:      "exported_received_at",
+	})
+
+	// ArchivePrunedCounter counts the exported objects deleted past the retention
+	ArchivePrunedCounter = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "archive",
+		Name:      "pruned",
+	})
+
+	BuilderIPMismatchCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "builder",
+		Name:      "ip_mismatch",
+	}, []string{"builder"})
+
 	AccountError = promauto.NewCounterVec(prometheus.CounterOpts{
 		Namespace: namespace,
 		Subsystem: "account",
 		Name:      "error",
 	}, []string{"account", "message"})
 
-	ChainError = promauto.NewCounter(prometheus.CounterOpts{
+	// PayBidTxPreflightCounter is labeled by the validator hostname and the result of simulating a pay bid tx: ok,
+	// error if the chain rpc failed, or the reason the simulation failed
+	PayBidTxPreflightCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "account",
+		Name:      "pay_bid_tx_preflight",
+	}, []string{"validator", "result"})
+
+	// AccountReservedGauge cost in BNB of the pay bid txs in flight of a pay account, reserved from its balance
+	AccountReservedGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "account",
+		Name:      "reserved",
+	}, []string{"account"})
+
+	BuildInfo = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "build_info",
+	}, []string{"version", "commit", "build_date", "go_version"})
+
+	ConfigReloadCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "config",
+		Name:      "reload",
+	}, []string{"result"})
+
+	// NotificationCounter counts the notifications by result, sent, deduplicated or rate_limited
+	NotificationCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "notification",
+		Name:      "total",
+	}, []string{"result"})
+
+	PushCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "push",
+		Name:      "total",
+	}, []string{"result"})
+
+	BuilderBanCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "builder",
+		Name:      "ban",
+	}, []string{"reason"})
+
+	BestBidGasFeeCacheCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "best_bid_gas_fee_cache",
+		Name:      "total",
+	}, []string{"result"})
+
+	EventCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "event",
+		Name:      "total",
+	}, []string{"publisher", "result"})
+
+	HintCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "hint",
+		Name:      "total",
+	}, []string{"backend", "result"})
+
+	SecretRotationCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "secret",
+		Name:      "rotation",
+	}, []string{"kind", "result"})
+
+	// ValidatorRetryCounter is labeled by the validator hostname and the rpc method retried on it
+	ValidatorRetryCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "validator",
+		Name:      "retry",
+	}, []string{"validator", "method"})
+
+	// ValidatorShadowCounter is labeled by the validator hostname and how the shadow's result of a mirrored bid
+	// compares to the validator's: match, mismatch, rejected or dropped
+	ValidatorShadowCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "validator",
+		Name:      "shadow",
+	}, []string{"validator", "result"})
+
+	// ValidatorShadowLatency is labeled by the validator hostname, the seconds its shadow takes more than it to
+	// accept a mirrored bid, negative if less
+	ValidatorShadowLatency = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "validator",
+		Name:      "shadow_latency_diff",
+		Buckets:   []float64{-0.5, -0.2, -0.1, -0.05, -0.02, -0.01, 0, 0.01, 0.02, 0.05, 0.1, 0.2, 0.5},
+	}, []string{"validator"})
+
+	// ChainError is labeled by the validator hostname and the rpc method failed on it
+	ChainError = promauto.NewCounterVec(prometheus.CounterOpts{
 		Namespace: namespace,
 		Subsystem: "chainRPC",
 		Name:      "error",
-	})
+	}, []string{"validator", "method"})
 )
+
+// the go runtime and process collectors are exported under the namespace too, e.g. bsc_mev_sentry_go_goroutines
+func init() {
+	goCollector := collectors.NewGoCollector()
+	processCollector := collectors.NewProcessCollector(collectors.ProcessCollectorOpts{})
+	prometheus.Unregister(goCollector)
+	prometheus.Unregister(processCollector)
+
+	prefixed := prometheus.WrapRegistererWithPrefix(namespace+"_", prometheus.DefaultRegisterer)
+	prefixed.MustRegister(goCollector, processCollector)
+}
+
+// NewCustomCounter registers an operator defined counter labeled by validator.
+func NewCustomCounter(name string) (*prometheus.CounterVec, error) {
+	counter := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "custom",
+		Name:      name,
+	}, []string{"validator"})
+
+	return counter, prometheus.Register(counter)
+}
+
+// NewCustomHistogram registers an operator defined histogram labeled by validator.
+func NewCustomHistogram(name string, buckets []float64) (*prometheus.HistogramVec, error) {
+	histogram := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "custom",
+		Name:      name,
+		Buckets:   buckets,
+	}, []string{"validator"})
+
+	return histogram, prometheus.Register(histogram)
+}
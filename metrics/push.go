@@ -0,0 +1,108 @@
+package metrics
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+
+	"github.com/bnb-chain/bsc-mev-sentry/log"
+	"github.com/bnb-chain/bsc-mev-sentry/utils"
+)
+
+type PushConfig struct {
+	// Enabled turns on pushing metrics to the Pushgateway
+	Enabled bool
+	// URL of the Pushgateway
+	URL string
+	// Interval between pushes, defaults to 15s
+	Interval utils.Duration
+	// Job label of the pushed metrics, defaults to bsc_mev_sentry
+	Job string
+	// Instance label of the pushed metrics, defaults to the hostname
+	Instance string
+	// Labels extra grouping labels of the pushed metrics
+	Labels map[string]string
+	// Username and Password of the basic auth of the Pushgateway, no auth if empty
+	Username string
+	Password string
+}
+
+// Pusher pushes all registered metrics to a Pushgateway periodically, for sentries whose
+// debug listener can't be scraped, e.g. behind NAT.
+type Pusher struct {
+	pusher   *push.Pusher
+	interval time.Duration
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+func NewPusher(cfg PushConfig) *Pusher {
+	if cfg.Job == "" {
+		cfg.Job = namespace
+	}
+	if cfg.Instance == "" {
+		cfg.Instance, _ = os.Hostname()
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = utils.Duration(15 * time.Second)
+	}
+
+	p := push.New(cfg.URL, cfg.Job).Gatherer(prometheus.DefaultGatherer).Grouping("instance", cfg.Instance)
+	for name, value := range cfg.Labels {
+		p = p.Grouping(name, value)
+	}
+	if cfg.Username != "" {
+		p = p.BasicAuth(cfg.Username, cfg.Password)
+	}
+
+	return &Pusher{
+		pusher:   p,
+		interval: time.Duration(cfg.Interval),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+func (p *Pusher) Start() error {
+	go p.loop()
+	return nil
+}
+
+func (p *Pusher) loop() {
+	defer close(p.done)
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), p.interval)
+			p.push(ctx)
+			cancel()
+		case <-p.stop:
+			return
+		}
+	}
+}
+
+// Stop stops pushing after a final push, so the last values before shutdown aren't lost.
+func (p *Pusher) Stop(ctx context.Context) error {
+	close(p.stop)
+	<-p.done
+
+	return p.pusher.PushContext(ctx)
+}
+
+func (p *Pusher) push(ctx context.Context) {
+	if err := p.pusher.PushContext(ctx); err != nil {
+		PushCounter.WithLabelValues("failure").Inc()
+		log.Errorw("failed to push metrics", "err", err)
+		return
+	}
+
+	PushCounter.WithLabelValues("success").Inc()
+}
@@ -0,0 +1,46 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/bsc-mev-sentry/utils"
+)
+
+func TestPusher(t *testing.T) {
+	paths := make(chan string, 16)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths <- r.Method + " " + r.URL.Path
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	p := NewPusher(PushConfig{
+		URL:      server.URL,
+		Interval: utils.Duration(10 * time.Millisecond),
+		Instance: "sentry-1",
+		Labels:   map[string]string{"region": "ap"},
+	})
+	require.NoError(t, p.Start())
+
+	// the order of grouping labels in the path is up to the push client
+	path := <-paths
+	assert.True(t, strings.HasPrefix(path, "PUT /metrics/job/bsc_mev_sentry/"), path)
+	assert.Contains(t, path, "/instance/sentry-1")
+	assert.Contains(t, path, "/region/ap")
+
+	for len(paths) > 0 {
+		<-paths
+	}
+
+	// the last values are pushed on stop
+	require.NoError(t, p.Stop(context.Background()))
+	assert.NotEmpty(t, paths)
+}
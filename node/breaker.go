@@ -0,0 +1,143 @@
+package node
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/bnb-chain/bsc-mev-sentry/log"
+	"github.com/bnb-chain/bsc-mev-sentry/metrics"
+	"github.com/bnb-chain/bsc-mev-sentry/utils"
+)
+
+// ErrCircuitOpen is returned instead of calling a validator whose circuit breaker is open.
+var ErrCircuitOpen = errors.New("validator is unavailable, circuit breaker is open")
+
+// states of a circuit breaker, exported as the value of its gauge
+const (
+	circuitClosed = iota
+	circuitHalfOpen
+	circuitOpen
+)
+
+var circuitStates = [...]string{"closed", "half_open", "open"}
+
+type CircuitBreakerConfig struct {
+	// Enabled turns on the circuit breaker
+	Enabled bool
+	// FailureThreshold consecutive failed bids or health probes opening the breaker, defaults to 3
+	FailureThreshold int
+	// OpenTimeout how long the breaker fast-fails bids before letting a trial one through, defaults to 5s
+	OpenTimeout utils.Duration
+}
+
+// circuitBreaker fast-fails the bids to a validator while it's unreachable, instead of letting each of them wait
+// for the rpc timeout. Failures are the errors of reaching the validator, errors answered by it don't count.
+type circuitBreaker struct {
+	cfg       CircuitBreakerConfig
+	validator string
+
+	mu       sync.Mutex
+	state    int
+	failures int
+	openedAt time.Time
+	trial    bool // a half open breaker lets one bid through at a time
+}
+
+func newCircuitBreaker(validator string, cfg CircuitBreakerConfig) *circuitBreaker {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = 3
+	}
+
+	if cfg.OpenTimeout <= 0 {
+		cfg.OpenTimeout = utils.Duration(5 * time.Second)
+	}
+
+	metrics.ValidatorCircuitBreaker.WithLabelValues(validator).Set(circuitClosed)
+
+	return &circuitBreaker{cfg: cfg, validator: validator}
+}
+
+// allow tells whether a bid may be sent, the caller must report its result if so.
+func (b *circuitBreaker) allow() bool {
+	if b == nil {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case circuitOpen:
+		if time.Since(b.openedAt) < time.Duration(b.cfg.OpenTimeout) {
+			return false
+		}
+		b.setState(circuitHalfOpen)
+		fallthrough
+	case circuitHalfOpen:
+		if b.trial {
+			return false
+		}
+		b.trial = true
+	}
+
+	return true
+}
+
+// done records the result of a bid or a health probe.
+func (b *circuitBreaker) done(err error) {
+	if b == nil {
+		return
+	}
+
+	var rpcErr rpc.Error
+	if err != nil && errors.As(err, &rpcErr) {
+		// the validator answered
+		err = nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.trial = false
+
+	if err == nil {
+		b.failures = 0
+		if b.state != circuitClosed {
+			b.setState(circuitClosed)
+		}
+		return
+	}
+
+	b.failures++
+	if b.state == circuitHalfOpen || (b.state == circuitClosed && b.failures >= b.cfg.FailureThreshold) {
+		b.openedAt = time.Now()
+		b.setState(circuitOpen)
+	}
+}
+
+// State returns the name of the breaker state.
+func (b *circuitBreaker) State() string {
+	if b == nil {
+		return ""
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return circuitStates[b.state]
+}
+
+func (b *circuitBreaker) setState(state int) {
+	log.Infow("validator circuit breaker state changed", "validator", b.validator,
+		"from", circuitStates[b.state], "to", circuitStates[state])
+
+	b.state = state
+	metrics.ValidatorCircuitBreaker.WithLabelValues(b.validator).Set(float64(state))
+}
@@ -0,0 +1,47 @@
+package node
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bnb-chain/bsc-mev-sentry/utils"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	b := newCircuitBreaker("validator", CircuitBreakerConfig{
+		Enabled:          true,
+		FailureThreshold: 2,
+		OpenTimeout:      utils.Duration(20 * time.Millisecond),
+	})
+
+	down := errors.New("connection refused")
+
+	// errors answered by the validator don't count
+	assert.True(t, b.allow())
+	b.done(types.NewInvalidBidError("invalid bid"))
+	assert.True(t, b.allow())
+	b.done(down)
+	assert.Equal(t, "closed", b.State())
+
+	assert.True(t, b.allow())
+	b.done(down)
+	assert.Equal(t, "open", b.State())
+	assert.False(t, b.allow())
+
+	// a failed trial opens it again
+	time.Sleep(30 * time.Millisecond)
+	assert.True(t, b.allow())
+	assert.Equal(t, "half_open", b.State())
+	assert.False(t, b.allow(), "one trial at a time")
+	b.done(down)
+	assert.Equal(t, "open", b.State())
+
+	// a successful health probe closes it
+	b.done(nil)
+	assert.Equal(t, "closed", b.State())
+	assert.True(t, b.allow())
+}
@@ -2,42 +2,157 @@ package node
 
 import (
 	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/miner/builderclient"
 	"github.com/ethereum/go-ethereum/rpc"
 
 	"github.com/bnb-chain/bsc-mev-sentry/log"
+	"github.com/bnb-chain/bsc-mev-sentry/utils"
 )
 
 type Builder interface {
-	ReportIssue(context.Context, types.BidIssue) error
+	// ReportIssue delivers the issue, or queues it if issues are batched, in which case done is called once it's
+	// delivered or fails if not nil.
+	ReportIssue(ctx context.Context, issue types.BidIssue, done func(err error)) error
+	// Config returns the config the builder is created with.
+	Config() BuilderConfig
+	// AllowsIP tells whether bids of the builder are accepted from the ip.
+	AllowsIP(ip net.IP) bool
+	// Stop delivers the batched issues and closes the connection to the builder.
+	Stop()
 }
 
 type BuilderConfig struct {
 	Address common.Address
 	URL     string
+	// Chains names of the chains the builder bids on, any if empty
+	Chains []string
+	// CertIdentities common names or DNS names of the builder's client certificate
+	CertIdentities []string
+	// APIKeyHashes hex encoded sha256 hashes of the builder's api keys
+	APIKeyHashes []string
+	// AllowedCIDRs source ip ranges the builder's bids are accepted from, any if empty
+	AllowedCIDRs []string
+	// ReportIPMismatch reports bids from outside AllowedCIDRs to the builder
+	ReportIPMismatch bool
+	// IssueBatchWindow batches the issues reported within the window into one mev_reportIssues call, disabled if 0
+	IssueBatchWindow utils.Duration
+	// TLS settings of the connections to the builder
+	TLS UpstreamTLSConfig
+	// Transport overrides the shared transport settings of the connections to the builder
+	Transport TransportConfig
 }
 
-func NewBuilder(config BuilderConfig) Builder {
-	cli, err := builderclient.DialOptions(context.Background(), config.URL, rpc.WithHTTPClient(client))
+// ParseCIDRs parses the ip ranges, a single ip is taken as a range of itself.
+func ParseCIDRs(cidrs []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		if !strings.Contains(cidr, "/") {
+			ip := net.ParseIP(cidr)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid ip %s", cidr)
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(len(ip)*8, len(ip)*8)})
+			continue
+		}
+
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, err
+		}
+		nets = append(nets, ipNet)
+	}
+
+	return nets, nil
+}
+
+func NewBuilder(config BuilderConfig) (Builder, error) {
+	allowed, err := ParseCIDRs(config.AllowedCIDRs)
+	if err != nil {
+		log.Errorw("invalid builder allowed cidrs", "address", config.Address, "err", err)
+		return nil, err
+	}
+
+	own, _, err := upstreamTransport(config.TLS, config.Transport)
+	if err != nil {
+		log.Errorw("failed to load builder tls files", "address", config.Address, "err", err)
+		return nil, err
+	}
+
+	httpClient := client
+	if own != nil {
+		httpClient = &http.Client{Timeout: client.Timeout, Transport: own}
+	}
+
+	cli, err := rpc.DialOptions(context.Background(), config.URL, rpc.WithHTTPClient(httpClient))
 	if err != nil {
 		log.Errorw("failed to dial builder", "url", config.URL, "err", err)
-		return nil
+		return nil, err
+	}
+
+	b := &builder{
+		cfg:     config,
+		client:  cli,
+		allowed: allowed,
 	}
 
-	return &builder{
-		cfg:    config,
-		client: cli,
+	if config.IssueBatchWindow > 0 {
+		b.batcher = newIssueBatcher(config.Address.String(), time.Duration(config.IssueBatchWindow), cli,
+			b.reportIssue)
 	}
+
+	return b, nil
 }
 
 type builder struct {
-	cfg    BuilderConfig
-	client *builderclient.Client
+	cfg     BuilderConfig
+	client  *rpc.Client
+	allowed []*net.IPNet
+	batcher *issueBatcher
 }
 
-func (b *builder) ReportIssue(ctx context.Context, issue types.BidIssue) error {
-	return b.client.ReportIssue(ctx, &issue)
+func (b *builder) Config() BuilderConfig {
+	return b.cfg
+}
+
+func (b *builder) AllowsIP(ip net.IP) bool {
+	if len(b.allowed) == 0 {
+		return true
+	}
+
+	for _, ipNet := range b.allowed {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ReportIssue delivers the issue to the builder, or queues it if issues are batched.
+func (b *builder) ReportIssue(ctx context.Context, issue types.BidIssue, done func(err error)) error {
+	if b.batcher != nil {
+		b.batcher.add(queuedIssue{issue: issue, done: done})
+		return nil
+	}
+
+	return b.reportIssue(ctx, issue)
+}
+
+func (b *builder) reportIssue(ctx context.Context, issue types.BidIssue) error {
+	return b.client.CallContext(ctx, nil, "mev_reportIssue", &issue)
+}
+
+func (b *builder) Stop() {
+	if b.batcher != nil {
+		b.batcher.stop()
+	}
+
+	b.client.Close()
 }
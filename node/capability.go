@@ -0,0 +1,75 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/bnb-chain/bsc-mev-sentry/log"
+)
+
+const (
+	methodNotFoundErrorCode = -32601
+	probeTimeout            = 3 * time.Second
+)
+
+// Capabilities tells which optional mev features a validator supports.
+type Capabilities struct {
+	BestBidGasFee  bool `json:"bestBidGasFee"`
+	HasBuilder     bool `json:"hasBuilder"`
+	BuilderFeeCeil bool `json:"builderFeeCeil"`
+	GasPrice       bool `json:"gasPrice"`
+	CancelBid      bool `json:"cancelBid"`
+}
+
+// probeCapabilities calls the optional mev methods of the validator. A feature is only
+// considered unsupported if the validator answers explicitly, e.g. method not found, so
+// that an unreachable validator isn't crippled forever.
+func probeCapabilities(cli *ethclient.Client) Capabilities {
+	caps := Capabilities{
+		BestBidGasFee:  true,
+		HasBuilder:     true,
+		BuilderFeeCeil: true,
+		GasPrice:       true,
+		CancelBid:      true,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	defer cancel()
+
+	if _, err := cli.BestBidGasFee(ctx, common.Hash{}); isMethodNotFound(err) {
+		caps.BestBidGasFee = false
+	}
+
+	if _, err := cli.HasBuilder(ctx, common.Address{}); isMethodNotFound(err) {
+		caps.HasBuilder = false
+	}
+
+	if err := cli.Client().CallContext(ctx, nil, "mev_cancelBid", common.Hash{}); isMethodNotFound(err) {
+		caps.CancelBid = false
+	}
+
+	params, err := cli.MevParams(ctx)
+	if isMethodNotFound(err) || (err == nil && params == nil) {
+		caps.BuilderFeeCeil = false
+		caps.GasPrice = false
+	} else if err == nil {
+		caps.BuilderFeeCeil = params.BuilderFeeCeil != nil
+		caps.GasPrice = params.GasPrice != nil
+	}
+
+	if err != nil && !isMethodNotFound(err) {
+		log.Errorw("failed to probe validator capabilities, assume supported", "err", err)
+	}
+
+	return caps
+}
+
+func isMethodNotFound(err error) bool {
+	var rpcErr rpc.Error
+	return errors.As(err, &rpcErr) && rpcErr.ErrorCode() == methodNotFoundErrorCode
+}
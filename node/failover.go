@@ -0,0 +1,173 @@
+package node
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/bnb-chain/bsc-mev-sentry/log"
+	"github.com/bnb-chain/bsc-mev-sentry/metrics"
+	"github.com/bnb-chain/bsc-mev-sentry/notification"
+)
+
+const (
+	// failBackProbes is the number of consecutive successful probes of the primary url before failing back to it,
+	// so that a flapping primary doesn't bounce the bids between urls.
+	failBackProbes = 3
+	// failBackInterval is how often the primary url is probed while failed over
+	failBackInterval = time.Second
+)
+
+// endpoints are the private urls of a validator. Requests go to the active one, which fails over to the next
+// reachable url when it's down, and fails back to the primary once it recovers.
+type endpoints struct {
+	urls     []string
+	clients  []*ethclient.Client
+	active   atomic.Int32
+	upstream *upstream
+
+	mu            sync.Mutex // serializes fail over and fail back
+	primaryProbes int
+	probing       bool // the primary is probed in the background while failed over
+
+	quit      chan struct{}
+	closeOnce sync.Once
+}
+
+func dialEndpoints(urls []string, tlsConfig UpstreamTLSConfig, transportConfig TransportConfig) (*endpoints, error) {
+	up, err := newUpstream(tlsConfig, transportConfig)
+	if err != nil {
+		log.Errorw("failed to load validator tls files", "urls", urls, "err", err)
+		return nil, err
+	}
+
+	e := &endpoints{urls: urls, upstream: up, quit: make(chan struct{})}
+	for _, url := range urls {
+		cli, err := ethclient.DialOptions(context.Background(), url, up.options...)
+		if err != nil {
+			log.Errorw("failed to dial validator", "url", url, "err", err)
+			e.close()
+			return nil, err
+		}
+
+		e.clients = append(e.clients, cli)
+	}
+
+	return e, nil
+}
+
+// client returns the client of the active url.
+func (e *endpoints) client() *ethclient.Client {
+	return e.clients[e.active.Load()]
+}
+
+// url returns the active url.
+func (e *endpoints) url() string {
+	return e.urls[e.active.Load()]
+}
+
+func (e *endpoints) close() {
+	e.closeOnce.Do(func() {
+		close(e.quit)
+	})
+
+	for _, cli := range e.clients {
+		cli.Close()
+	}
+	e.upstream.close()
+}
+
+// failOver switches to the first reachable url after the active one, it tells whether one is found.
+func (e *endpoints) failOver(validator string) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	active := int(e.active.Load())
+	for i := 1; i < len(e.clients); i++ {
+		next := (active + i) % len(e.clients)
+		if !e.probe(next) {
+			continue
+		}
+
+		e.switchTo(validator, next)
+		notification.Notify(notification.Warning, "validator failed over", "", "validator", validator,
+			"from", e.urls[active], "to", e.urls[next])
+
+		if next != 0 && !e.probing {
+			e.probing = true
+			go e.probePrimary(validator)
+		}
+
+		return true
+	}
+
+	return false
+}
+
+// probePrimary fails back to the primary url once it's reachable again, apart from the refreshes so that a primary
+// timing out doesn't hold them up.
+func (e *endpoints) probePrimary(validator string) {
+	ticker := time.NewTicker(failBackInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-e.quit:
+			return
+		case <-ticker.C:
+			e.failBack(validator)
+
+			e.mu.Lock()
+			if e.active.Load() == 0 {
+				e.probing = false
+				e.mu.Unlock()
+				return
+			}
+			e.mu.Unlock()
+		}
+	}
+}
+
+// failBack switches back to the primary url once it's reachable again.
+func (e *endpoints) failBack(validator string) {
+	if e.active.Load() == 0 {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if !e.probe(0) {
+		e.primaryProbes = 0
+		return
+	}
+
+	if e.primaryProbes++; e.primaryProbes < failBackProbes {
+		return
+	}
+
+	from := e.urls[e.active.Load()]
+	e.switchTo(validator, 0)
+	notification.Notify(notification.Info, "validator failed back", "", "validator", validator,
+		"from", from, "to", e.urls[0])
+}
+
+func (e *endpoints) switchTo(validator string, i int) {
+	e.active.Store(int32(i))
+	e.primaryProbes = 0
+
+	metrics.ValidatorActiveEndpoint.WithLabelValues(validator).Set(float64(i))
+	log.Infow("validator endpoint switched", "validator", validator, "url", e.urls[i])
+}
+
+// probe tells whether the url of the index answers.
+func (e *endpoints) probe(i int) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	defer cancel()
+
+	_, err := e.clients[i].BlockNumber(ctx)
+	return err == nil
+}
@@ -0,0 +1,66 @@
+package node
+
+import (
+	"errors"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type chainStub struct {
+	down atomic.Bool
+}
+
+func (c *chainStub) BlockNumber() (hexutil.Uint64, error) {
+	if c.down.Load() {
+		return 0, errors.New("down")
+	}
+
+	return 1, nil
+}
+
+func newChainStub(t *testing.T) (*chainStub, string) {
+	stub := &chainStub{}
+	server := rpc.NewServer()
+	require.NoError(t, server.RegisterName("eth", stub))
+	srv := httptest.NewServer(server)
+	t.Cleanup(srv.Close)
+
+	return stub, srv.URL
+}
+
+func TestEndpointsFailOverAndBack(t *testing.T) {
+	primary, primaryURL := newChainStub(t)
+	backup, backupURL := newChainStub(t)
+
+	e, err := dialEndpoints([]string{primaryURL, backupURL}, UpstreamTLSConfig{}, TransportConfig{})
+	require.NoError(t, err)
+	defer e.close()
+
+	assert.Equal(t, primaryURL, e.url())
+
+	primary.down.Store(true)
+	backup.down.Store(true)
+	assert.False(t, e.failOver("validator"), "no url is reachable")
+	assert.Equal(t, primaryURL, e.url())
+
+	backup.down.Store(false)
+	assert.True(t, e.failOver("validator"))
+	assert.Equal(t, backupURL, e.url())
+
+	// the primary is probed in the background, and must stay reachable for a few probes before failing back
+	primary.down.Store(false)
+	assert.Equal(t, backupURL, e.url())
+	require.Eventually(t, func() bool {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+
+		return e.url() == primaryURL && !e.probing
+	}, (failBackProbes+2)*failBackInterval, 100*time.Millisecond)
+}
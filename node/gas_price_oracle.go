@@ -0,0 +1,96 @@
+package node
+
+import (
+	"context"
+	"math/big"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/bnb-chain/bsc-mev-sentry/log"
+	"github.com/bnb-chain/bsc-mev-sentry/metrics"
+)
+
+// maxGasPriceOracleFailures is the number of consecutive failed updates after which
+// the oracle serves the fallback gas price instead of the cached one.
+const maxGasPriceOracleFailures = 3
+
+type GasPriceOracleConfig struct {
+	// Enabled turns on the gas price oracle
+	Enabled bool
+	// FeeHistoryBlocks number of recent blocks whose base fee is considered
+	FeeHistoryBlocks uint64
+	// FallbackGasPrice gas price in wei served when the chain rpc is unreachable
+	FallbackGasPrice uint64
+	// PayBidTx prices the pay bid tx with the oracle gas price instead of zero
+	PayBidTx bool
+	// MinBidGasPrice rejects bids whose average gas price is below the oracle gas price
+	MinBidGasPrice bool
+}
+
+// gasPriceOracle caches the gas price suggested by the chain rpc, i.e. the
+// suggested tip plus the highest base fee of recent blocks.
+type gasPriceOracle struct {
+	cfg      GasPriceOracleConfig
+	gasPrice atomic.Pointer[big.Int]
+	failures atomic.Uint32
+}
+
+func newGasPriceOracle(cfg GasPriceOracleConfig) *gasPriceOracle {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	if cfg.FeeHistoryBlocks == 0 {
+		cfg.FeeHistoryBlocks = 20
+	}
+
+	return &gasPriceOracle{cfg: cfg}
+}
+
+func (o *gasPriceOracle) update(ctx context.Context, validator string, cli *ethclient.Client) {
+	start := time.Now()
+	tip, err := cli.SuggestGasTipCap(ctx)
+	observeUpstream(validator, "eth_maxPriorityFeePerGas", start)
+	if err != nil {
+		o.fail(validator, "eth_maxPriorityFeePerGas", "failed to fetch suggested gas tip", err)
+		return
+	}
+
+	start = time.Now()
+	history, err := cli.FeeHistory(ctx, o.cfg.FeeHistoryBlocks, nil, nil)
+	observeUpstream(validator, "eth_feeHistory", start)
+	if err != nil {
+		o.fail(validator, "eth_feeHistory", "failed to fetch fee history", err)
+		return
+	}
+
+	price := new(big.Int).Set(tip)
+	baseFee := new(big.Int)
+	for _, fee := range history.BaseFee {
+		if fee != nil && fee.Cmp(baseFee) > 0 {
+			baseFee = fee
+		}
+	}
+	price.Add(price, baseFee)
+
+	o.gasPrice.Store(price)
+	o.failures.Store(0)
+}
+
+func (o *gasPriceOracle) fail(validator, method, msg string, err error) {
+	metrics.ChainError.WithLabelValues(validator, method).Inc()
+	log.Errorw(msg, "err", err)
+	o.failures.Add(1)
+}
+
+// GasPrice returns the cached gas price, or the fallback one if the oracle is unreachable.
+func (o *gasPriceOracle) GasPrice() *big.Int {
+	price := o.gasPrice.Load()
+	if price == nil || o.failures.Load() >= maxGasPriceOracleFailures {
+		return new(big.Int).SetUint64(o.cfg.FallbackGasPrice)
+	}
+
+	return price
+}
@@ -0,0 +1,32 @@
+package node
+
+// Health tells whether the sentry can forward bids to a validator, besides the validator's own mev running flag.
+type Health struct {
+	// Reachable the latest refresh reached the validator
+	Reachable bool `json:"reachable"`
+	// PayAccountFunded the pay account has balance to pay builders
+	PayAccountFunded bool `json:"payAccountFunded"`
+	// NonceHealthy the nonce of the pay account is fetched by the latest refresh
+	NonceHealthy bool `json:"nonceHealthy"`
+	// ActiveURL the private url requests go to, a backup one after a fail over
+	ActiveURL string `json:"activeURL"`
+	// CircuitBreaker state of the circuit breaker, closed, half_open or open, empty if disabled
+	CircuitBreaker string `json:"circuitBreaker,omitempty"`
+}
+
+// OK tells whether bids can be forwarded.
+func (h Health) OK() bool {
+	return h.Reachable && h.PayAccountFunded && h.NonceHealthy
+}
+
+func (n *validator) Health() Health {
+	balance := n.payAccountBalance.Load()
+
+	return Health{
+		Reachable:        !n.unreachable.Load() && n.head.Load() != nil,
+		PayAccountFunded: balance != nil && balance.Sign() > 0 && !n.lowBalance.Load(),
+		NonceHealthy:     n.nonceHealthy.Load(),
+		ActiveURL:        n.endpoints.url(),
+		CircuitBreaker:   n.breaker.State(),
+	}
+}
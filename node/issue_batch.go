@@ -0,0 +1,161 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/bnb-chain/bsc-mev-sentry/log"
+)
+
+const (
+	// maxIssueBatchSize flushes a batch early once it holds this many issues
+	maxIssueBatchSize  = 100
+	issueReportTimeout = 10 * time.Second
+)
+
+// issueBatcher collects the issues reported to a builder within a window and delivers them in one
+// mev_reportIssues call, falling back to one mev_reportIssue call per issue if the builder doesn't
+// support batches.
+type issueBatcher struct {
+	address string
+	window  time.Duration
+	client  *rpc.Client
+	single  func(ctx context.Context, issue types.BidIssue) error
+
+	mu          sync.Mutex
+	pending     []queuedIssue
+	timer       *time.Timer
+	unsupported bool
+	stopped     bool
+}
+
+// queuedIssue is an issue waiting for its batch, done is called once it's delivered or fails if not nil.
+type queuedIssue struct {
+	issue types.BidIssue
+	done  func(err error)
+}
+
+func newIssueBatcher(address string, window time.Duration, client *rpc.Client,
+	single func(ctx context.Context, issue types.BidIssue) error,
+) *issueBatcher {
+	return &issueBatcher{address: address, window: window, client: client, single: single}
+}
+
+// add queues the issue, the batch is delivered once the window since its first issue elapses.
+func (b *issueBatcher) add(issue queuedIssue) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// the builder is replaced or the sentry stops, the issues reported meanwhile aren't batched anymore
+	if b.stopped {
+		go b.deliver([]queuedIssue{issue})
+		return
+	}
+
+	b.pending = append(b.pending, issue)
+
+	if len(b.pending) >= maxIssueBatchSize {
+		if b.timer != nil {
+			b.timer.Stop()
+			b.timer = nil
+		}
+		go b.deliver(b.take())
+		return
+	}
+
+	if b.timer == nil {
+		b.timer = time.AfterFunc(b.window, b.flush)
+	}
+}
+
+// take returns the pending issues and starts a new batch, b.mu must be held.
+func (b *issueBatcher) take() []queuedIssue {
+	issues := b.pending
+	b.pending = nil
+	return issues
+}
+
+func (b *issueBatcher) flush() {
+	b.mu.Lock()
+	b.timer = nil
+	issues := b.take()
+	b.mu.Unlock()
+
+	b.deliver(issues)
+}
+
+// stop delivers the pending issues without waiting for the window to elapse.
+func (b *issueBatcher) stop() {
+	b.mu.Lock()
+	b.stopped = true
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	issues := b.take()
+	b.mu.Unlock()
+
+	b.deliver(issues)
+}
+
+func (b *issueBatcher) deliver(queued []queuedIssue) {
+	if len(queued) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), issueReportTimeout)
+	defer cancel()
+
+	b.mu.Lock()
+	unsupported := b.unsupported
+	b.mu.Unlock()
+
+	if !unsupported {
+		issues := make([]types.BidIssue, 0, len(queued))
+		for _, q := range queued {
+			issues = append(issues, q.issue)
+		}
+
+		err := b.client.CallContext(ctx, nil, "mev_reportIssues", issues)
+		if err == nil || !isMethodNotFound(err) {
+			if err != nil {
+				log.Errorw("failed to report issues", "builder", b.address, "count", len(issues), "err", err)
+			}
+			for _, q := range queued {
+				q.report(err)
+			}
+			return
+		}
+
+		log.Infow("builder doesn't support batched issues, reporting one by one", "builder", b.address)
+
+		b.mu.Lock()
+		b.unsupported = true
+		b.mu.Unlock()
+	}
+
+	var errs []error
+	for _, q := range queued {
+		err := b.single(ctx, q.issue)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		q.report(err)
+	}
+
+	if len(errs) > 0 {
+		log.Errorw("failed to report issues", "builder", b.address, "failed", len(errs), "count", len(queued),
+			"err", errors.Join(errs...))
+	}
+}
+
+func (q queuedIssue) report(err error) {
+	if q.done != nil {
+		q.done(err)
+	}
+}
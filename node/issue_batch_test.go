@@ -0,0 +1,118 @@
+package node
+
+import (
+	"context"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/bsc-mev-sentry/utils"
+)
+
+type issueReceiver struct {
+	mu      sync.Mutex
+	batches [][]types.BidIssue
+	single  []types.BidIssue
+}
+
+func (r *issueReceiver) ReportIssues(issues []types.BidIssue) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.batches = append(r.batches, issues)
+}
+
+func (r *issueReceiver) ReportIssue(issue types.BidIssue) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.single = append(r.single, issue)
+}
+
+// singleIssueReceiver is a builder serving mev_reportIssue only.
+type singleIssueReceiver struct {
+	receiver *issueReceiver
+}
+
+func (r *singleIssueReceiver) ReportIssue(issue types.BidIssue) {
+	r.receiver.ReportIssue(issue)
+}
+
+func newIssueBuilder(t *testing.T, service interface{}) Builder {
+	return newIssueBuilderWithWindow(t, service, 50*time.Millisecond)
+}
+
+func newIssueBuilderWithWindow(t *testing.T, service interface{}, window time.Duration) Builder {
+	server := rpc.NewServer()
+	require.NoError(t, server.RegisterName("mev", service))
+	srv := httptest.NewServer(server)
+	t.Cleanup(srv.Close)
+
+	b, err := NewBuilder(BuilderConfig{
+		Address:          common.HexToAddress("0x01"),
+		URL:              srv.URL,
+		IssueBatchWindow: utils.Duration(window),
+	})
+	require.NoError(t, err)
+
+	return b
+}
+
+func TestIssueBatch(t *testing.T) {
+	receiver := &issueReceiver{}
+	b := newIssueBuilder(t, receiver)
+
+	var delivered atomic.Int32
+	for i := 0; i < 3; i++ {
+		require.NoError(t, b.ReportIssue(context.Background(), types.BidIssue{Message: "issue"}, func(err error) {
+			if err == nil {
+				delivered.Add(1)
+			}
+		}))
+	}
+
+	require.Eventually(t, func() bool {
+		receiver.mu.Lock()
+		defer receiver.mu.Unlock()
+		return len(receiver.batches) == 1 && len(receiver.batches[0]) == 3
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Empty(t, receiver.single)
+	require.Eventually(t, func() bool { return delivered.Load() == 3 }, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestIssueBatchFallback(t *testing.T) {
+	receiver := &issueReceiver{}
+	b := newIssueBuilder(t, &singleIssueReceiver{receiver: receiver})
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, b.ReportIssue(context.Background(), types.BidIssue{Message: "issue"}, nil))
+	}
+
+	require.Eventually(t, func() bool {
+		receiver.mu.Lock()
+		defer receiver.mu.Unlock()
+		return len(receiver.single) == 3
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestIssueBatchStop(t *testing.T) {
+	receiver := &issueReceiver{}
+	b := newIssueBuilderWithWindow(t, receiver, time.Hour)
+
+	for i := 0; i < 2; i++ {
+		require.NoError(t, b.ReportIssue(context.Background(), types.BidIssue{Message: "issue"}, nil))
+	}
+
+	// the pending batch is delivered right away rather than once the window elapses
+	b.Stop()
+
+	receiver.mu.Lock()
+	defer receiver.mu.Unlock()
+	require.Len(t, receiver.batches, 1)
+	require.Len(t, receiver.batches[0], 2)
+}
@@ -0,0 +1,140 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/bnb-chain/bsc-mev-sentry/log"
+	"github.com/bnb-chain/bsc-mev-sentry/metrics"
+	"github.com/bnb-chain/bsc-mev-sentry/utils"
+)
+
+const defaultPreflightTimeout = 200 * time.Millisecond
+
+// reasons a pay bid tx fails its simulation
+const (
+	PreflightInsufficientBalance = "insufficient_balance"
+	PreflightRecipientReverted   = "recipient_reverted"
+	PreflightRecipientOutOfGas   = "recipient_out_of_gas"
+)
+
+type PreflightConfig struct {
+	// Enabled simulates the pay bid tx at the pending state before signing it, the bid is rejected if it fails
+	Enabled bool
+	// ChainRPC url of the chain rpc the pay bid tx is simulated on, the validator itself if empty
+	ChainRPC string
+	// Timeout bounds each simulation, the bid goes on if it's exceeded, defaults to 200ms
+	Timeout utils.Duration
+}
+
+// PreflightError is a pay bid tx failing its simulation, including it would invalidate the block of the bid.
+type PreflightError struct {
+	// Reason one of the Preflight reasons
+	Reason string
+	Err    error
+}
+
+func (e *PreflightError) Error() string {
+	return fmt.Sprintf("pay bid tx simulation failed, %s: %v", e.Reason, e.Err)
+}
+
+func (e *PreflightError) Unwrap() error {
+	return e.Err
+}
+
+// preflight simulates pay bid txs via eth_call, so that a pay account short of balance at the pending state, or a
+// builder address whose code reverts on the transfer, is caught before the bid is forwarded.
+type preflight struct {
+	hostname string
+	timeout  time.Duration
+	client   func() *ethclient.Client
+	own      *ethclient.Client // of the ChainRPC, nil if simulated on the validator
+}
+
+// newPreflight creates the preflight of the validator, simulating on the chain rpc of the config or on validator.
+func newPreflight(hostname string, cfg PreflightConfig, validator func() *ethclient.Client) (*preflight, error) {
+	p := &preflight{hostname: hostname, timeout: time.Duration(cfg.Timeout), client: validator}
+	if p.timeout <= 0 {
+		p.timeout = defaultPreflightTimeout
+	}
+
+	if cfg.ChainRPC != "" {
+		cli, err := ethclient.DialOptions(context.Background(), cfg.ChainRPC, rpc.WithHTTPClient(client))
+		if err != nil {
+			return nil, err
+		}
+		p.own, p.client = cli, func() *ethclient.Client { return cli }
+	}
+
+	return p, nil
+}
+
+func (p *preflight) close() {
+	if p.own != nil {
+		p.own.Close()
+	}
+}
+
+// simulate calls the transfer of the amount from the pay account to the builder at the pending state, and returns a
+// PreflightError if it fails. The bid isn't held up by a chain rpc failing or too slow, the error is logged instead.
+func (p *preflight) simulate(ctx context.Context, from, builder common.Address, amount, gasPrice *big.Int) error {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	start := time.Now()
+	_, err := p.client().PendingCallContract(ctx, ethereum.CallMsg{
+		From:     from,
+		To:       &builder,
+		Gas:      PayBidTxGasUsed,
+		GasPrice: gasPrice,
+		Value:    amount,
+	})
+	observeUpstream(p.hostname, "eth_call", start)
+
+	reason := preflightReason(err)
+	switch {
+	case err == nil:
+		metrics.PayBidTxPreflightCounter.WithLabelValues(p.hostname, "ok").Inc()
+		return nil
+	case reason == "":
+		metrics.PayBidTxPreflightCounter.WithLabelValues(p.hostname, "error").Inc()
+		log.Warnw("failed to simulate pay bid tx", "validator", p.hostname, "builder", builder, "err", err)
+		return nil
+	default:
+		metrics.PayBidTxPreflightCounter.WithLabelValues(p.hostname, reason).Inc()
+		return &PreflightError{Reason: reason, Err: err}
+	}
+}
+
+// preflightReason tells why the simulation failed, empty if it's the chain rpc failing rather than the tx.
+func preflightReason(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	var rpcErr rpc.Error
+	if !errors.As(err, &rpcErr) {
+		return ""
+	}
+
+	message := strings.ToLower(rpcErr.Error())
+	switch {
+	case strings.Contains(message, "insufficient funds"), strings.Contains(message, "insufficient balance"):
+		return PreflightInsufficientBalance
+	case strings.Contains(message, "execution reverted"):
+		return PreflightRecipientReverted
+	case strings.Contains(message, "out of gas"), strings.Contains(message, "gas required exceeds"):
+		return PreflightRecipientOutOfGas
+	default:
+		return ""
+	}
+}
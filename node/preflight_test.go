@@ -0,0 +1,85 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/bsc-mev-sentry/utils"
+)
+
+// callStub fails the calls to the builders mapped to an error, and the others hang if slow.
+type callStub struct {
+	errs map[common.Address]error
+	slow time.Duration
+}
+
+func (c *callStub) Call(args map[string]interface{}, block string) (hexutil.Bytes, error) {
+	if block != "pending" {
+		return nil, errors.New("not pending")
+	}
+
+	to := common.HexToAddress(args["to"].(string))
+	if err, ok := c.errs[to]; ok {
+		return nil, err
+	}
+	time.Sleep(c.slow)
+
+	return hexutil.Bytes{}, nil
+}
+
+func TestPreflight(t *testing.T) {
+	var (
+		reverting = common.HexToAddress("0x01")
+		hungry    = common.HexToAddress("0x02")
+		poor      = common.HexToAddress("0x03")
+		ok        = common.HexToAddress("0x04")
+	)
+	stub := &callStub{errs: map[common.Address]error{
+		reverting: errors.New("execution reverted"),
+		hungry:    errors.New("out of gas"),
+		poor:      errors.New("insufficient funds for gas * price + value"),
+	}}
+	server := rpc.NewServer()
+	require.NoError(t, server.RegisterName("eth", stub))
+	srv := httptest.NewServer(server)
+	defer srv.Close()
+
+	cli, err := ethclient.Dial(srv.URL)
+	require.NoError(t, err)
+	defer cli.Close()
+
+	cfg := PreflightConfig{Enabled: true, Timeout: utils.Duration(100 * time.Millisecond)}
+	p, err := newPreflight("preflight-test", cfg, func() *ethclient.Client { return cli })
+	require.NoError(t, err)
+
+	simulate := func(builder common.Address) error {
+		return p.simulate(context.Background(), common.HexToAddress("0xff"), builder, big.NewInt(1), big.NewInt(0))
+	}
+
+	for builder, reason := range map[common.Address]string{
+		reverting: PreflightRecipientReverted,
+		hungry:    PreflightRecipientOutOfGas,
+		poor:      PreflightInsufficientBalance,
+	} {
+		var preflightErr *PreflightError
+		require.ErrorAs(t, simulate(builder), &preflightErr)
+		assert.Equal(t, reason, preflightErr.Reason)
+	}
+
+	assert.NoError(t, simulate(ok))
+
+	// the bid goes on if the chain rpc is too slow
+	stub.slow = 300 * time.Millisecond
+	assert.NoError(t, simulate(ok))
+}
@@ -0,0 +1,158 @@
+package node
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/bnb-chain/bsc-mev-sentry/log"
+	"github.com/bnb-chain/bsc-mev-sentry/metrics"
+	"github.com/bnb-chain/bsc-mev-sentry/utils"
+)
+
+const defaultProposerRefreshInterval = time.Second
+
+type ProposerScheduleConfig struct {
+	// ChainRPC url of the chain rpc serving parlia_getSnapshot, disabled if empty
+	ChainRPC string
+	// RefreshInterval how often the validator set snapshot is read, defaults to 1s
+	RefreshInterval utils.Duration
+}
+
+// Proposer is the in turn proposer of a block.
+type Proposer struct {
+	Address common.Address `json:"address"`
+	Block   uint64         `json:"block"`
+}
+
+// parliaSnapshot is the part of the parlia snapshot the in turn proposer is derived from.
+type parliaSnapshot struct {
+	Number     uint64                             `json:"number"`
+	TurnLength uint8                              `json:"turn_length"`
+	Validators map[common.Address]json.RawMessage `json:"validators"`
+}
+
+// inTurn returns the proposer in turn for the block after the snapshot, the validators take turns of
+// TurnLength blocks in ascending order of their addresses.
+func (s *parliaSnapshot) inTurn() (Proposer, bool) {
+	if len(s.Validators) == 0 {
+		return Proposer{}, false
+	}
+
+	validators := make([]common.Address, 0, len(s.Validators))
+	for address := range s.Validators {
+		validators = append(validators, address)
+	}
+
+	sort.Slice(validators, func(i, j int) bool {
+		return bytes.Compare(validators[i][:], validators[j][:]) < 0
+	})
+
+	turnLength := uint64(s.TurnLength)
+	if turnLength == 0 {
+		turnLength = 1
+	}
+
+	block := s.Number + 1
+	return Proposer{Address: validators[block/turnLength%uint64(len(validators))], Block: block}, true
+}
+
+// ProposerSchedule periodically derives the proposer in turn for the next block from the validator set
+// snapshot of the chain.
+type ProposerSchedule struct {
+	cfg    ProposerScheduleConfig
+	client *rpc.Client
+	next   atomic.Pointer[Proposer]
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+func NewProposerSchedule(cfg ProposerScheduleConfig) (*ProposerSchedule, error) {
+	if cfg.ChainRPC == "" {
+		return nil, nil
+	}
+
+	if cfg.RefreshInterval <= 0 {
+		cfg.RefreshInterval = utils.Duration(defaultProposerRefreshInterval)
+	}
+
+	cli, err := rpc.DialOptions(context.Background(), cfg.ChainRPC, rpc.WithHTTPClient(client))
+	if err != nil {
+		return nil, err
+	}
+
+	p := &ProposerSchedule{
+		cfg:    cfg,
+		client: cli,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+
+	p.refresh()
+
+	go p.loop()
+
+	return p, nil
+}
+
+func (p *ProposerSchedule) loop() {
+	defer close(p.done)
+
+	ticker := time.NewTicker(time.Duration(p.cfg.RefreshInterval))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			p.refresh()
+		case <-p.stop:
+			return
+		}
+	}
+}
+
+func (p *ProposerSchedule) refresh() {
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	defer cancel()
+
+	var snapshot parliaSnapshot
+	if err := p.client.CallContext(ctx, &snapshot, "parlia_getSnapshot", "latest"); err != nil {
+		metrics.ChainError.WithLabelValues("proposer_schedule", "parlia_getSnapshot").Inc()
+		log.Errorw("failed to read validator set snapshot", "rpc", p.cfg.ChainRPC, "err", err)
+		return
+	}
+
+	next, ok := snapshot.inTurn()
+	if !ok {
+		log.Errorw("empty validator set snapshot", "rpc", p.cfg.ChainRPC, "number", snapshot.Number)
+		return
+	}
+
+	p.next.Store(&next)
+}
+
+// Next returns the proposer in turn for the next block, ok is false until the snapshot is read.
+func (p *ProposerSchedule) Next() (next Proposer, ok bool) {
+	if n := p.next.Load(); n != nil {
+		return *n, true
+	}
+
+	return Proposer{}, false
+}
+
+// Close stops the refresh and releases the connection, it's safe to call on a nil schedule.
+func (p *ProposerSchedule) Close() {
+	if p == nil {
+		return
+	}
+
+	close(p.stop)
+	<-p.done
+	p.client.Close()
+}
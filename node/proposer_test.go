@@ -0,0 +1,54 @@
+package node
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type parliaStub struct {
+	snapshot parliaSnapshot
+}
+
+func (p *parliaStub) GetSnapshot(_ string) parliaSnapshot {
+	return p.snapshot
+}
+
+func TestProposerSchedule(t *testing.T) {
+	v1, v2, v3 := common.HexToAddress("0x01"), common.HexToAddress("0x02"), common.HexToAddress("0x03")
+	validators := map[common.Address]json.RawMessage{
+		v3: json.RawMessage(`{}`),
+		v1: json.RawMessage(`{}`),
+		v2: json.RawMessage(`{}`),
+	}
+
+	stub := &parliaStub{snapshot: parliaSnapshot{Number: 99, TurnLength: 4, Validators: validators}}
+	server := rpc.NewServer()
+	require.NoError(t, server.RegisterName("parlia", stub))
+	srv := httptest.NewServer(server)
+	defer srv.Close()
+
+	schedule, err := NewProposerSchedule(ProposerScheduleConfig{ChainRPC: srv.URL})
+	require.NoError(t, err)
+	defer schedule.Close()
+
+	// block 100 is in the 25th turn of 4 blocks, 25 % 3 = 1
+	next, ok := schedule.Next()
+	require.True(t, ok)
+	assert.Equal(t, Proposer{Address: v2, Block: 100}, next)
+
+	stub.snapshot.Number, stub.snapshot.TurnLength = 100, 0
+	schedule.refresh()
+	next, _ = schedule.Next()
+	assert.Equal(t, Proposer{Address: v3, Block: 101}, next, "one block per turn before turn length")
+
+	schedule, err = NewProposerSchedule(ProposerScheduleConfig{})
+	require.NoError(t, err)
+	require.Nil(t, schedule)
+	schedule.Close()
+}
@@ -0,0 +1,206 @@
+package node
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/bnb-chain/bsc-mev-sentry/log"
+	"github.com/bnb-chain/bsc-mev-sentry/metrics"
+	"github.com/bnb-chain/bsc-mev-sentry/utils"
+)
+
+const (
+	defaultRegistryMethod          = "getBuilders"
+	defaultRegistryRefreshInterval = time.Minute
+	registryCallTimeout            = 10 * time.Second
+)
+
+type BuilderRegistryConfig struct {
+	// ChainRPC url of the chain rpc the registry contract is read from, disabled if empty
+	ChainRPC string
+	// Contract address of the builder registration contract
+	Contract common.Address
+	// Method view method of the contract returning the registered builders as address[], defaults to getBuilders
+	Method string
+	// RefreshInterval how often the registered builders are read, defaults to 1m
+	RefreshInterval utils.Duration
+	// Enforce rejects bids of builders not registered on chain, otherwise they're only logged
+	Enforce bool
+}
+
+// RegisteredBuilders is the set of builders registered on chain as of the last refresh.
+type RegisteredBuilders struct {
+	Builders  []common.Address `json:"builders"`
+	UpdatedAt time.Time        `json:"updatedAt"`
+}
+
+// BuilderRegistry periodically reads the builders registered in a contract on chain, independent of the
+// builders each validator registers.
+type BuilderRegistry struct {
+	cfg      BuilderRegistryConfig
+	client   *ethclient.Client
+	method   abi.Method
+	builders atomic.Pointer[builderSet]
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+type builderSet struct {
+	addresses map[common.Address]struct{}
+	updatedAt time.Time
+}
+
+func NewBuilderRegistry(cfg BuilderRegistryConfig) (*BuilderRegistry, error) {
+	if cfg.ChainRPC == "" {
+		return nil, nil
+	}
+
+	if cfg.Method == "" {
+		cfg.Method = defaultRegistryMethod
+	}
+
+	if cfg.RefreshInterval <= 0 {
+		cfg.RefreshInterval = utils.Duration(defaultRegistryRefreshInterval)
+	}
+
+	method, err := newAddressesMethod(cfg.Method)
+	if err != nil {
+		return nil, err
+	}
+
+	cli, err := ethclient.DialOptions(context.Background(), cfg.ChainRPC, rpc.WithHTTPClient(client))
+	if err != nil {
+		return nil, err
+	}
+
+	r := &BuilderRegistry{
+		cfg:    cfg,
+		client: cli,
+		method: method,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+
+	r.refresh()
+
+	go r.loop()
+
+	return r, nil
+}
+
+// newAddressesMethod returns the abi of a view method without inputs returning address[].
+func newAddressesMethod(name string) (abi.Method, error) {
+	parsed, err := abi.JSON(strings.NewReader(fmt.Sprintf(
+		`[{"name":%q,"type":"function","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"address[]"}]}]`,
+		name)))
+	if err != nil {
+		return abi.Method{}, err
+	}
+
+	return parsed.Methods[name], nil
+}
+
+func (r *BuilderRegistry) loop() {
+	defer close(r.done)
+
+	ticker := time.NewTicker(time.Duration(r.cfg.RefreshInterval))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			r.refresh()
+		case <-r.stop:
+			return
+		}
+	}
+}
+
+func (r *BuilderRegistry) refresh() {
+	ctx, cancel := context.WithTimeout(context.Background(), registryCallTimeout)
+	defer cancel()
+
+	contract := r.cfg.Contract
+	output, err := r.client.CallContract(ctx, ethereum.CallMsg{To: &contract, Data: r.method.ID}, nil)
+	if err != nil {
+		log.Errorw("failed to read registered builders", "contract", contract, "err", err)
+		return
+	}
+
+	values, err := r.method.Outputs.Unpack(output)
+	if err != nil || len(values) != 1 {
+		log.Errorw("failed to decode registered builders", "contract", contract, "err", err)
+		return
+	}
+
+	addresses, ok := values[0].([]common.Address)
+	if !ok {
+		log.Errorw("unexpected registered builders", "contract", contract, "value", values[0])
+		return
+	}
+
+	set := &builderSet{addresses: make(map[common.Address]struct{}, len(addresses)), updatedAt: time.Now()}
+	for _, address := range addresses {
+		set.addresses[address] = struct{}{}
+	}
+
+	r.builders.Store(set)
+	metrics.RegisteredBuilders.Set(float64(len(set.addresses)))
+}
+
+// Enforced tells whether bids of builders not registered on chain are rejected.
+func (r *BuilderRegistry) Enforced() bool {
+	return r.cfg.Enforce
+}
+
+// Has tells whether the builder is registered on chain, known is false until the builders are read.
+func (r *BuilderRegistry) Has(builder common.Address) (has, known bool) {
+	set := r.builders.Load()
+	if set == nil {
+		return false, false
+	}
+
+	_, has = set.addresses[builder]
+	return has, true
+}
+
+// Builders returns the registered builders as of the last refresh.
+func (r *BuilderRegistry) Builders() RegisteredBuilders {
+	set := r.builders.Load()
+	if set == nil {
+		return RegisteredBuilders{Builders: []common.Address{}}
+	}
+
+	addresses := make([]common.Address, 0, len(set.addresses))
+	for address := range set.addresses {
+		addresses = append(addresses, address)
+	}
+
+	sort.Slice(addresses, func(i, j int) bool {
+		return bytes.Compare(addresses[i][:], addresses[j][:]) < 0
+	})
+
+	return RegisteredBuilders{Builders: addresses, UpdatedAt: set.updatedAt}
+}
+
+// Close stops the refresh and releases the connection, it's safe to call on a nil registry.
+func (r *BuilderRegistry) Close() {
+	if r == nil {
+		return
+	}
+
+	close(r.stop)
+	<-r.done
+	r.client.Close()
+}
@@ -0,0 +1,48 @@
+package node
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/require"
+)
+
+type registryContract struct {
+	builders []common.Address
+}
+
+func (c *registryContract) Call(_ map[string]interface{}, _ string) (hexutil.Bytes, error) {
+	method, _ := newAddressesMethod(defaultRegistryMethod)
+	return method.Outputs.Pack(c.builders)
+}
+
+func TestBuilderRegistry(t *testing.T) {
+	builder := common.HexToAddress("0x01")
+
+	server := rpc.NewServer()
+	require.NoError(t, server.RegisterName("eth", &registryContract{builders: []common.Address{builder}}))
+	srv := httptest.NewServer(server)
+	defer srv.Close()
+
+	registry, err := NewBuilderRegistry(BuilderRegistryConfig{ChainRPC: srv.URL, Contract: common.HexToAddress("0x02")})
+	require.NoError(t, err)
+	defer registry.Close()
+
+	has, known := registry.Has(builder)
+	require.True(t, known)
+	require.True(t, has)
+
+	has, known = registry.Has(common.HexToAddress("0x03"))
+	require.True(t, known)
+	require.False(t, has)
+
+	require.Equal(t, []common.Address{builder}, registry.Builders().Builders)
+
+	registry, err = NewBuilderRegistry(BuilderRegistryConfig{})
+	require.NoError(t, err)
+	require.Nil(t, registry)
+	registry.Close()
+}
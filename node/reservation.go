@@ -0,0 +1,136 @@
+package node
+
+import (
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
+
+	"github.com/bnb-chain/bsc-mev-sentry/metrics"
+)
+
+// reservedBalance is the cost of the pay bid txs in flight of a pay account, i.e. signed for bids whose block isn't
+// reflected in the balance fetched yet. It's shared by the validators of a chain paying from the same account, so that
+// simultaneous bids can't spend the same balance. The pay bid txs sharing a nonce compete for it, at most one of them
+// is included, so only the max cost of each nonce is reserved.
+type reservedBalance struct {
+	account common.Address
+
+	mu    sync.Mutex
+	txs   map[common.Hash]reservation // pay bid tx hash -> reservation
+	total *big.Int
+}
+
+type reservation struct {
+	cost  *big.Int
+	nonce uint64
+	block uint64
+}
+
+type reservationKey struct {
+	chain   string
+	account common.Address
+}
+
+var (
+	reservedBalancesMu sync.Mutex
+	reservedBalances   = make(map[reservationKey]*reservedBalance)
+)
+
+// reservedBalanceOf returns the reserved balance of the pay account on the chain.
+func reservedBalanceOf(chain string, account common.Address) *reservedBalance {
+	reservedBalancesMu.Lock()
+	defer reservedBalancesMu.Unlock()
+
+	key := reservationKey{chain: chain, account: account}
+	if r, ok := reservedBalances[key]; ok {
+		return r
+	}
+
+	r := &reservedBalance{account: account, txs: make(map[common.Hash]reservation), total: new(big.Int)}
+	reservedBalances[key] = r
+
+	return r
+}
+
+// reserve reserves the cost of the pay bid tx of the nonce for the block if the balance covers it on top of the
+// reserved costs, false otherwise.
+func (r *reservedBalance) reserve(balance *big.Int, tx common.Hash, cost *big.Int, nonce, block uint64) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	prev, replaced := r.txs[tx]
+	r.txs[tx] = reservation{cost: cost, nonce: nonce, block: block}
+
+	total := r.sum()
+	if total.Cmp(balance) > 0 {
+		if replaced {
+			r.txs[tx] = prev
+		} else {
+			delete(r.txs, tx)
+		}
+		return false
+	}
+
+	r.total = total
+	r.export()
+
+	return true
+}
+
+// release releases the cost of the pay bid tx, e.g. its bid isn't forwarded.
+func (r *reservedBalance) release(tx common.Hash) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.txs[tx]; ok {
+		delete(r.txs, tx)
+		r.total = r.sum()
+		r.export()
+	}
+}
+
+// settle releases the costs of the pay bid txs for the blocks up to the number, once the balance fetched reflects them.
+func (r *reservedBalance) settle(number uint64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for tx, res := range r.txs {
+		if res.block <= number {
+			delete(r.txs, tx)
+		}
+	}
+	r.total = r.sum()
+	r.export()
+}
+
+// sum returns the max cost of the pay bid txs of each nonce, summed over the nonces.
+func (r *reservedBalance) sum() *big.Int {
+	costs := make(map[uint64]*big.Int)
+	for _, res := range r.txs {
+		if cost, ok := costs[res.nonce]; !ok || res.cost.Cmp(cost) > 0 {
+			costs[res.nonce] = res.cost
+		}
+	}
+
+	total := new(big.Int)
+	for _, cost := range costs {
+		total.Add(total, cost)
+	}
+
+	return total
+}
+
+// reserved returns the total cost reserved.
+func (r *reservedBalance) reserved() *big.Int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return new(big.Int).Set(r.total)
+}
+
+func (r *reservedBalance) export() {
+	bnb, _ := new(big.Float).Quo(new(big.Float).SetInt(r.total), big.NewFloat(params.Ether)).Float64()
+	metrics.AccountReservedGauge.WithLabelValues(r.account.String()).Set(bnb)
+}
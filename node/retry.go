@@ -0,0 +1,83 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/bnb-chain/bsc-mev-sentry/log"
+	"github.com/bnb-chain/bsc-mev-sentry/metrics"
+	"github.com/bnb-chain/bsc-mev-sentry/utils"
+)
+
+type RetryConfig struct {
+	// Attempts of a read-only call including the first one, defaults to 1, i.e. no retry. Bids are never retried.
+	Attempts int
+	// Backoff before the first retry, doubled on each retry and jittered, defaults to 50ms
+	Backoff utils.Duration
+	// MaxBackoff caps the backoff, defaults to 500ms
+	MaxBackoff utils.Duration
+}
+
+// backoffs returns the backoffs with the defaults applied.
+func (c RetryConfig) backoffs() (backoff, maxBackoff time.Duration) {
+	backoff, maxBackoff = time.Duration(c.Backoff), time.Duration(c.MaxBackoff)
+	if backoff <= 0 {
+		backoff = 50 * time.Millisecond
+	}
+	if maxBackoff <= 0 {
+		maxBackoff = 500 * time.Millisecond
+	}
+
+	return min(backoff, maxBackoff), maxBackoff
+}
+
+// retry calls the idempotent call until it succeeds, fails for good or runs out of attempts or context.
+func retry[T any](ctx context.Context, cfg RetryConfig, validator, method string, call func() (T, error)) (T, error) {
+	backoff, maxBackoff := cfg.backoffs()
+
+	for attempt := 1; ; attempt++ {
+		result, err := call()
+		if err == nil || attempt >= cfg.Attempts || !isTransient(err) {
+			return result, err
+		}
+
+		// the backoff is jittered down to half, so retries of concurrent calls don't line up
+		sleep := backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+		log.Debugw("retrying validator call", "validator", validator, "method", method, "attempt", attempt,
+			"backoff", sleep, "err", err)
+
+		select {
+		case <-ctx.Done():
+			return result, err
+		case <-time.After(sleep):
+		}
+
+		metrics.ValidatorRetryCounter.WithLabelValues(validator, method).Inc()
+		backoff = min(2*backoff, maxBackoff)
+	}
+}
+
+// isTransient tells whether the call may succeed if retried, i.e. the validator wasn't reached or answered with
+// a server error. Errors answered by the validator in JSON-RPC are final.
+func isTransient(err error) bool {
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) {
+		return false
+	}
+
+	var httpErr rpc.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode >= http.StatusInternalServerError || httpErr.StatusCode == http.StatusTooManyRequests
+	}
+
+	var netErr net.Error
+	return errors.Is(err, context.DeadlineExceeded) || errors.As(err, &netErr) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
@@ -0,0 +1,68 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bnb-chain/bsc-mev-sentry/utils"
+)
+
+type rpcErrorStub struct{ error }
+
+func (rpcErrorStub) ErrorCode() int { return -32000 }
+
+func TestRetry(t *testing.T) {
+	cfg := RetryConfig{Attempts: 3, Backoff: utils.Duration(time.Millisecond)}
+	ctx := context.Background()
+
+	calls := 0
+	result, err := retry(ctx, cfg, "v", "mev_params", func() (int, error) {
+		if calls++; calls < 3 {
+			return 0, rpc.HTTPError{StatusCode: 502}
+		}
+		return 1, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result)
+	assert.Equal(t, 3, calls)
+
+	// the validator answered, retrying won't help
+	calls = 0
+	_, err = retry(ctx, cfg, "v", "mev_params", func() (int, error) {
+		calls++
+		return 0, rpcErrorStub{errors.New("invalid")}
+	})
+	assert.Error(t, err)
+	assert.Equal(t, 1, calls)
+
+	calls = 0
+	_, err = retry(ctx, cfg, "v", "mev_params", func() (int, error) {
+		calls++
+		return 0, io.EOF
+	})
+	assert.ErrorIs(t, err, io.EOF)
+	assert.Equal(t, 3, calls)
+
+	// no retry by default
+	calls = 0
+	_, _ = retry(ctx, RetryConfig{}, "v", "mev_params", func() (int, error) {
+		calls++
+		return 0, io.EOF
+	})
+	assert.Equal(t, 1, calls)
+}
+
+func TestIsTransient(t *testing.T) {
+	assert.True(t, isTransient(context.DeadlineExceeded))
+	assert.True(t, isTransient(rpc.HTTPError{StatusCode: 503}))
+	assert.True(t, isTransient(rpc.HTTPError{StatusCode: 429}))
+	assert.False(t, isTransient(rpc.HTTPError{StatusCode: 400}))
+	assert.False(t, isTransient(rpcErrorStub{errors.New("invalid")}))
+	assert.False(t, isTransient(errors.New("invalid")))
+}
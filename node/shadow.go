@@ -0,0 +1,190 @@
+package node
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/bnb-chain/bsc-mev-sentry/account"
+	"github.com/bnb-chain/bsc-mev-sentry/log"
+	"github.com/bnb-chain/bsc-mev-sentry/metrics"
+	"github.com/bnb-chain/bsc-mev-sentry/utils"
+)
+
+const (
+	defaultShadowTimeout = time.Second
+	// shadowInFlight bounds the bids mirrored at once, the others are dropped
+	shadowInFlight = 64
+)
+
+// ShadowConfig is a shadow validator the bids accepted by the validator are mirrored to, e.g. a node running a new
+// version checked before the traffic is cut over to it. Its results are compared to the validator's and logged, never
+// returned to the builder.
+type ShadowConfig struct {
+	// PrivateURL url of the shadow validator, disabled if empty
+	PrivateURL string
+
+	// PayAccountMode of a test account signing the pay bid txs of the mirrored bids, which are sent without one if empty
+	PayAccountMode    account.Mode
+	PrivateKey        string
+	KeystorePath      string
+	PasswordFilePath  string
+	PayAccountAddress string
+
+	// Timeout bounds each mirrored bid, 1s by default
+	Timeout utils.Duration
+}
+
+// shadow mirrors the bids to the shadow validator in the background.
+type shadow struct {
+	hostname string
+	timeout  time.Duration
+	// validator of the shadow paying from the test account, nil if there's none
+	validator Validator
+	// endpoints of the shadow sent the bids without a pay bid tx, nil if there's a test account
+	endpoints *endpoints
+
+	mu      sync.Mutex
+	stopped bool
+	wg      sync.WaitGroup
+	slots   chan struct{}
+}
+
+func newShadow(config ValidatorConfig) (*shadow, error) {
+	cfg := config.Shadow
+
+	s := &shadow{
+		hostname: config.PublicHostName,
+		timeout:  time.Duration(cfg.Timeout),
+		slots:    make(chan struct{}, shadowInFlight),
+	}
+	if s.timeout <= 0 {
+		s.timeout = defaultShadowTimeout
+	}
+
+	if cfg.PayAccountMode == "" {
+		eps, err := dialEndpoints([]string{cfg.PrivateURL}, config.TLS, config.Transport)
+		if err != nil {
+			return nil, err
+		}
+		s.endpoints = eps
+
+		return s, nil
+	}
+
+	validator, err := NewValidator(ValidatorConfig{
+		PrivateURL:        cfg.PrivateURL,
+		PublicHostName:    config.PublicHostName + "-shadow",
+		Chain:             config.Chain,
+		TLS:               config.TLS,
+		Transport:         config.Transport,
+		PayAccountMode:    cfg.PayAccountMode,
+		PrivateKey:        cfg.PrivateKey,
+		KeystorePath:      cfg.KeystorePath,
+		PasswordFilePath:  cfg.PasswordFilePath,
+		PayAccountAddress: cfg.PayAccountAddress,
+		Refresh:           config.Refresh,
+		GasPriceOracle:    config.GasPriceOracle,
+		Retry:             config.Retry,
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.validator = validator
+
+	return s, nil
+}
+
+// mirror sends the bid accepted by the validator as the hash after the latency to the shadow, unless too many bids are
+// mirrored already.
+func (s *shadow) mirror(args types.BidArgs, hash common.Hash, latency time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopped {
+		return
+	}
+
+	select {
+	case s.slots <- struct{}{}:
+	default:
+		metrics.ValidatorShadowCounter.WithLabelValues(s.hostname, "dropped").Inc()
+		return
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer func() {
+			<-s.slots
+			s.wg.Done()
+		}()
+
+		s.compare(args, hash, latency)
+	}()
+}
+
+func (s *shadow) compare(args types.BidArgs, hash common.Hash, latency time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	start := time.Now()
+	shadowHash, err := s.send(ctx, args)
+	shadowLatency := time.Since(start)
+
+	switch {
+	case err != nil:
+		metrics.ValidatorShadowCounter.WithLabelValues(s.hostname, "rejected").Inc()
+		log.Warnw("shadow rejected bid accepted by validator", "validator", s.hostname, "bidHash", hash,
+			"err", err)
+	case shadowHash != hash:
+		metrics.ValidatorShadowCounter.WithLabelValues(s.hostname, "mismatch").Inc()
+		log.Warnw("shadow accepted bid as another hash", "validator", s.hostname, "bidHash", hash,
+			"shadowBidHash", shadowHash)
+	default:
+		metrics.ValidatorShadowCounter.WithLabelValues(s.hostname, "match").Inc()
+		metrics.ValidatorShadowLatency.WithLabelValues(s.hostname).Observe((shadowLatency - latency).Seconds())
+		log.Debugw("shadow accepted bid", "validator", s.hostname, "bidHash", hash, "latency", latency,
+			"shadowLatency", shadowLatency)
+	}
+}
+
+// send sends the bid with a pay bid tx of the test account, or without one. The pay bid txs of the test account are
+// left out of the audit log on purpose, which records what the real pay accounts sign: they pay the shadow only, and
+// recording them would mix test payments into the log operators reconcile against the chain.
+func (s *shadow) send(ctx context.Context, args types.BidArgs) (common.Hash, error) {
+	if s.validator == nil {
+		args.PayBidTx, args.PayBidTxGasUsed = nil, 0
+		return s.endpoints.client().SendBid(ctx, args)
+	}
+
+	builder, err := args.EcrecoverSender()
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	payBidTx, err := s.validator.GeneratePayBidTx(ctx, builder, args.RawBid.BuilderFee, args.RawBid.BlockNumber)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	args.PayBidTx = payBidTx
+
+	return s.validator.SendBid(ctx, args)
+}
+
+// stop waits for the bids being mirrored, and releases the shadow.
+func (s *shadow) stop() {
+	s.mu.Lock()
+	s.stopped = true
+	s.mu.Unlock()
+
+	s.wg.Wait()
+
+	if s.validator != nil {
+		s.validator.Stop()
+	} else {
+		s.endpoints.close()
+	}
+}
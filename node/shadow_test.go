@@ -0,0 +1,70 @@
+package node
+
+import (
+	"errors"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/bsc-mev-sentry/metrics"
+)
+
+type mevStub struct {
+	mu   sync.Mutex
+	bids []types.BidArgs
+	hash common.Hash
+	err  error
+}
+
+func (m *mevStub) SendBid(args types.BidArgs) (common.Hash, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.bids = append(m.bids, args)
+	return m.hash, m.err
+}
+
+func TestShadowMirror(t *testing.T) {
+	stub := &mevStub{hash: common.HexToHash("0x01")}
+	server := rpc.NewServer()
+	require.NoError(t, server.RegisterName("mev", stub))
+	srv := httptest.NewServer(server)
+	defer srv.Close()
+
+	s, err := newShadow(ValidatorConfig{PublicHostName: "shadow-test", Shadow: ShadowConfig{PrivateURL: srv.URL}})
+	require.NoError(t, err)
+
+	args := types.BidArgs{RawBid: &types.RawBid{BlockNumber: 1}, PayBidTx: []byte{0x01}, PayBidTxGasUsed: 25000}
+	counter := func(result string) float64 {
+		return testutil.ToFloat64(metrics.ValidatorShadowCounter.WithLabelValues("shadow-test", result))
+	}
+
+	s.mirror(args, common.HexToHash("0x01"), 0)
+	s.wg.Wait()
+	s.mirror(args, common.HexToHash("0x02"), 0)
+	s.wg.Wait()
+	stub.mu.Lock()
+	stub.err = errors.New("invalid bid")
+	stub.mu.Unlock()
+	s.mirror(args, common.HexToHash("0x01"), 0)
+	s.stop()
+
+	// mirrored after stop
+	s.mirror(args, common.HexToHash("0x01"), 0)
+
+	assert.Equal(t, float64(1), counter("match"))
+	assert.Equal(t, float64(1), counter("mismatch"))
+	assert.Equal(t, float64(1), counter("rejected"))
+
+	require.Len(t, stub.bids, 3)
+	// the shadow has no test account, the pay bid tx of the validator isn't mirrored
+	assert.Empty(t, stub.bids[0].PayBidTx)
+	assert.Zero(t, stub.bids[0].PayBidTxGasUsed)
+}
@@ -0,0 +1,244 @@
+package node
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/bnb-chain/bsc-mev-sentry/log"
+	"github.com/bnb-chain/bsc-mev-sentry/metrics"
+	"github.com/bnb-chain/bsc-mev-sentry/utils"
+)
+
+const (
+	defaultStakeMethod          = "stakeOf"
+	defaultStakeRefreshInterval = 5 * time.Minute
+	stakeCallTimeout            = 10 * time.Second
+)
+
+type BuilderStakeConfig struct {
+	// ChainRPC url of the chain rpc stakes are read from, disabled if empty
+	ChainRPC string
+	// Contract address of the stake contract, the balance of the builder is its stake if empty
+	Contract common.Address
+	// Method view method of the contract taking the builder address and returning its stake as uint256,
+	// defaults to stakeOf
+	Method string
+	// MinStake minimum stake in BNB of a builder whose bids are accepted
+	MinStake float64
+	// RefreshInterval how often the stakes are read, defaults to 5m
+	RefreshInterval utils.Duration
+}
+
+// StakeChecker periodically reads the on chain stake, or balance, of the builders and tells whether it
+// reaches the minimum.
+type StakeChecker struct {
+	cfg      BuilderStakeConfig
+	client   *ethclient.Client
+	method   abi.Method
+	minStake *big.Int
+	builders func() []common.Address
+
+	mu     sync.RWMutex
+	stakes map[common.Address]*big.Int
+
+	unknown chan common.Address // builders to read before the next refresh
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// NewStakeChecker creates a checker of the stakes of the builders listed by builders.
+func NewStakeChecker(cfg BuilderStakeConfig, builders func() []common.Address) (*StakeChecker, error) {
+	if cfg.ChainRPC == "" {
+		return nil, nil
+	}
+
+	if cfg.Method == "" {
+		cfg.Method = defaultStakeMethod
+	}
+
+	if cfg.RefreshInterval <= 0 {
+		cfg.RefreshInterval = utils.Duration(defaultStakeRefreshInterval)
+	}
+
+	parsed, err := abi.JSON(strings.NewReader(fmt.Sprintf(`[{"name":%q,"type":"function","stateMutability":"view",`+
+		`"inputs":[{"name":"builder","type":"address"}],"outputs":[{"name":"","type":"uint256"}]}]`, cfg.Method)))
+	if err != nil {
+		return nil, err
+	}
+
+	cli, err := ethclient.DialOptions(context.Background(), cfg.ChainRPC, rpc.WithHTTPClient(client))
+	if err != nil {
+		return nil, err
+	}
+
+	minStake, _ := new(big.Float).Mul(big.NewFloat(cfg.MinStake), big.NewFloat(params.Ether)).Int(nil)
+
+	c := &StakeChecker{
+		cfg:      cfg,
+		client:   cli,
+		method:   parsed.Methods[cfg.Method],
+		minStake: minStake,
+		builders: builders,
+		stakes:   make(map[common.Address]*big.Int),
+		unknown:  make(chan common.Address, 64),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+
+	go c.loop()
+
+	return c, nil
+}
+
+func (c *StakeChecker) loop() {
+	defer close(c.done)
+
+	ticker := time.NewTicker(time.Duration(c.cfg.RefreshInterval))
+	defer ticker.Stop()
+
+	c.refresh()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.refresh()
+		case builder := <-c.unknown:
+			c.update(builder)
+		case <-c.stop:
+			return
+		}
+	}
+}
+
+func (c *StakeChecker) refresh() {
+	builders := c.builders()
+
+	stakes := make(map[common.Address]*big.Int, len(builders))
+	for _, builder := range builders {
+		if stake, err := c.stake(builder); err == nil {
+			stakes[builder] = stake
+		} else if old, ok := c.get(builder); ok {
+			// keep the last known stake while the chain rpc is unreachable
+			stakes[builder] = old
+		}
+	}
+
+	c.mu.Lock()
+	c.stakes = stakes
+	c.mu.Unlock()
+}
+
+func (c *StakeChecker) update(builder common.Address) {
+	if _, ok := c.get(builder); ok {
+		return
+	}
+
+	stake, err := c.stake(builder)
+	if err != nil {
+		return
+	}
+
+	c.mu.Lock()
+	c.stakes[builder] = stake
+	c.mu.Unlock()
+}
+
+func (c *StakeChecker) stake(builder common.Address) (*big.Int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), stakeCallTimeout)
+	defer cancel()
+
+	var (
+		stake *big.Int
+		err   error
+	)
+	if c.cfg.Contract == (common.Address{}) {
+		stake, err = c.client.BalanceAt(ctx, builder, nil)
+	} else {
+		stake, err = c.contractStake(ctx, builder)
+	}
+
+	if err != nil {
+		log.Errorw("failed to read builder stake", "address", builder, "err", err)
+		return nil, err
+	}
+
+	bnb, _ := new(big.Float).Quo(new(big.Float).SetInt(stake), big.NewFloat(params.Ether)).Float64()
+	metrics.BuilderStakeGauge.WithLabelValues(builder.String()).Set(bnb)
+
+	return stake, nil
+}
+
+func (c *StakeChecker) contractStake(ctx context.Context, builder common.Address) (*big.Int, error) {
+	data, err := c.method.Inputs.Pack(builder)
+	if err != nil {
+		return nil, err
+	}
+
+	contract := c.cfg.Contract
+	output, err := c.client.CallContract(ctx, ethereum.CallMsg{To: &contract, Data: append(c.method.ID, data...)}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := c.method.Outputs.Unpack(output)
+	if err != nil {
+		return nil, err
+	}
+
+	stake, ok := values[0].(*big.Int)
+	if !ok {
+		return nil, fmt.Errorf("unexpected stake %v", values[0])
+	}
+
+	return stake, nil
+}
+
+func (c *StakeChecker) get(builder common.Address) (*big.Int, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	stake, ok := c.stakes[builder]
+	return stake, ok
+}
+
+// Sufficient tells whether the stake of the builder reaches the minimum, known is false until its stake
+// is read, which is then scheduled.
+func (c *StakeChecker) Sufficient(builder common.Address) (sufficient, known bool) {
+	stake, ok := c.get(builder)
+	if !ok {
+		select {
+		case c.unknown <- builder:
+		default:
+		}
+		return false, false
+	}
+
+	return stake.Cmp(c.minStake) >= 0, true
+}
+
+// MinStake returns the minimum stake in wei.
+func (c *StakeChecker) MinStake() *big.Int {
+	return c.minStake
+}
+
+// Close stops the refresh and releases the connection, it's safe to call on a nil checker.
+func (c *StakeChecker) Close() {
+	if c == nil {
+		return
+	}
+
+	close(c.stop)
+	<-c.done
+	c.client.Close()
+}
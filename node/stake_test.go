@@ -0,0 +1,49 @@
+package node
+
+import (
+	"math/big"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/require"
+)
+
+type balances map[common.Address]*big.Int
+
+func (b balances) GetBalance(address common.Address, _ string) (*hexutil.Big, error) {
+	balance, ok := b[address]
+	if !ok {
+		balance = new(big.Int)
+	}
+	return (*hexutil.Big)(balance), nil
+}
+
+func TestStakeChecker(t *testing.T) {
+	rich, poor := common.HexToAddress("0x01"), common.HexToAddress("0x02")
+
+	server := rpc.NewServer()
+	require.NoError(t, server.RegisterName("eth", balances{rich: new(big.Int).Mul(big.NewInt(20), big.NewInt(params.Ether))}))
+	srv := httptest.NewServer(server)
+	defer srv.Close()
+
+	checker, err := NewStakeChecker(BuilderStakeConfig{ChainRPC: srv.URL, MinStake: 10},
+		func() []common.Address { return []common.Address{rich} })
+	require.NoError(t, err)
+	defer checker.Close()
+
+	require.Eventually(t, func() bool {
+		sufficient, known := checker.Sufficient(rich)
+		return known && sufficient
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// builders missing from the refresh are read on demand
+	require.Eventually(t, func() bool {
+		sufficient, known := checker.Sufficient(poor)
+		return known && !sufficient
+	}, 5*time.Second, 10*time.Millisecond)
+}
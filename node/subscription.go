@@ -0,0 +1,127 @@
+package node
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/bnb-chain/bsc-mev-sentry/log"
+	"github.com/bnb-chain/bsc-mev-sentry/metrics"
+	"github.com/bnb-chain/bsc-mev-sentry/notification"
+)
+
+// maxHeadSilence is how long a subscription may go without a new head before the validator is polled again
+const maxHeadSilence = 5 * time.Second
+
+func isWebSocket(url string) bool {
+	return strings.HasPrefix(url, "ws://") || strings.HasPrefix(url, "wss://")
+}
+
+// watch refreshes the state of a websocket validator on its new heads instead of polling it. The validator is
+// polled as usual while it can't be subscribed to, e.g. after failing over to an http backup url, or while the
+// subscription goes silent.
+func (n *validator) watch() {
+	interval, expensiveInterval := n.cfg.Refresh.intervals()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var (
+		heads         = make(chan *types.Header, 16)
+		sub           ethereum.Subscription
+		subCli        *ethclient.Client
+		lastHead      time.Time
+		lastExpensive time.Time
+	)
+
+	// the expensive calls are made on new heads too, but not more often than their interval
+	refreshExpensive := func() {
+		if time.Since(lastExpensive) >= expensiveInterval {
+			lastExpensive = time.Now()
+			n.refreshExpensive()
+		}
+	}
+
+	unsubscribe := func() {
+		if sub != nil {
+			sub.Unsubscribe()
+			sub = nil
+		}
+	}
+	defer unsubscribe()
+
+	for {
+		// resubscribe once the active url changes
+		if cli := n.endpoints.client(); sub != nil && subCli != cli {
+			unsubscribe()
+		}
+
+		if sub == nil && isWebSocket(n.endpoints.url()) {
+			cli := n.endpoints.client()
+			ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+			s, err := cli.SubscribeNewHead(ctx, heads)
+			cancel()
+			if err != nil {
+				metrics.ChainError.WithLabelValues(n.cfg.PublicHostName, "eth_subscribe").Inc()
+				log.Errorw("failed to subscribe new heads", "url", n.endpoints.url(), "err", err)
+			} else {
+				sub, subCli, lastHead = s, cli, time.Now()
+				n.refresh()
+				refreshExpensive()
+			}
+		}
+
+		var subErr <-chan error
+		if sub != nil {
+			subErr = sub.Err()
+		}
+
+		select {
+		case <-n.quit:
+			return
+		case header := <-heads:
+			lastHead = time.Now()
+			n.onHead(header)
+			refreshExpensive()
+		case err := <-subErr:
+			log.Errorw("new heads subscription dropped", "url", n.endpoints.url(), "err", err)
+			sub = nil
+			n.refresh()
+			refreshExpensive()
+		case <-ticker.C:
+			if sub == nil || time.Since(lastHead) > maxHeadSilence {
+				n.refresh()
+				refreshExpensive()
+			} else {
+				n.endpoints.failBack(n.cfg.PublicHostName)
+			}
+		}
+	}
+}
+
+// onHead refreshes the cheap state of the validator on its new head.
+func (n *validator) onHead(header *types.Header) {
+	n.head.Store(&ChainHead{Hash: header.Hash(), Number: header.Number.Uint64(), Time: header.Time})
+	n.breaker.done(nil)
+
+	if n.unreachable.Swap(false) {
+		notification.Notify(notification.Info, "validator recovered", "", "validator", n.cfg.PublicHostName)
+	}
+
+	if n.chainID.Load() == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), upstreamTimeout)
+		chainID, err := n.endpoints.client().ChainID(ctx)
+		cancel()
+		if err != nil {
+			metrics.ChainError.WithLabelValues(n.cfg.PublicHostName, "eth_chainId").Inc()
+			log.Errorw("failed to fetch chainID", "url", n.endpoints.url(), "err", err)
+		} else {
+			n.chainID.Store(chainID)
+		}
+	}
+
+	n.refreshState()
+}
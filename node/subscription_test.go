@@ -0,0 +1,72 @@
+package node
+
+import (
+	"context"
+	"math/big"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/require"
+)
+
+// headStub is a websocket validator serving new heads, the mev methods are missing.
+type headStub struct {
+	heads chan *types.Header
+}
+
+func (h *headStub) ChainId() hexutil.Big {
+	return hexutil.Big(*big.NewInt(56))
+}
+
+func (h *headStub) NewHeads(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, _ := rpc.NotifierFromContext(ctx)
+	sub := notifier.CreateSubscription()
+
+	go func() {
+		for {
+			select {
+			case header := <-h.heads:
+				_ = notifier.Notify(sub.ID, header)
+			case <-sub.Err():
+				return
+			}
+		}
+	}()
+
+	return sub, nil
+}
+
+func TestValidatorWatchesNewHeads(t *testing.T) {
+	stub := &headStub{heads: make(chan *types.Header, 1)}
+	server := rpc.NewServer()
+	require.NoError(t, server.RegisterName("eth", stub))
+	srv := httptest.NewServer(server.WebsocketHandler([]string{"*"}))
+	t.Cleanup(srv.Close)
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	v, err := NewValidator(ValidatorConfig{
+		PrivateURL:     "ws" + strings.TrimPrefix(srv.URL, "http"),
+		PublicHostName: "validator",
+		PayAccountMode: "privateKey",
+		PrivateKey:     hexutil.Encode(crypto.FromECDSA(key))[2:],
+	})
+	require.NoError(t, err)
+	defer v.Stop()
+
+	stub.heads <- &types.Header{Number: big.NewInt(100), Time: 1700000000, Difficulty: big.NewInt(2)}
+
+	// the stub doesn't serve eth_getBlockByNumber, so the head can only come from the subscription
+	require.Eventually(t, func() bool {
+		head := v.Head()
+		return head != nil && head.Number == 100
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, big.NewInt(56), v.ChainID())
+}
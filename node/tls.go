@@ -0,0 +1,144 @@
+package node
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/gorilla/websocket"
+
+	"github.com/bnb-chain/bsc-mev-sentry/tlsutil"
+)
+
+type UpstreamTLSConfig struct {
+	// CertFile client certificate the sentry authenticates itself with to the upstream over mTLS, none if empty
+	CertFile string
+	// KeyFile private key of the client certificate
+	KeyFile string
+	// CAFile CA bundle verifying the certificate of the upstream, the system roots if empty
+	CAFile string
+	// InsecureSkipVerify doesn't verify the certificate of the upstream, e.g. a self-signed one in a private network
+	InsecureSkipVerify bool
+}
+
+// Validate checks the files are given in pairs and the verification settings agree.
+func (c UpstreamTLSConfig) Validate() error {
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		return errors.New("TLS CertFile and KeyFile are required together")
+	}
+	if c.CAFile != "" && c.InsecureSkipVerify {
+		return errors.New("TLS CAFile and InsecureSkipVerify are exclusive")
+	}
+
+	return nil
+}
+
+// clientConfig returns the tls config of the connections to a host of the upstream, verifying the certificate of
+// the upstream is issued for the host.
+func (c UpstreamTLSConfig) clientConfig() (func(host string) *tls.Config, error) {
+	if c.CertFile == "" && c.CAFile == "" {
+		return func(host string) *tls.Config {
+			return &tls.Config{ServerName: host, InsecureSkipVerify: c.InsecureSkipVerify}
+		}, nil
+	}
+
+	// the files are reloaded once changed, as the ones of the service listener
+	reloader, err := tlsutil.NewCertReloader(c.CertFile, c.KeyFile, c.CAFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(host string) *tls.Config {
+		return reloader.ClientTLSConfig(host, c.InsecureSkipVerify)
+	}, nil
+}
+
+type dialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// dialTLS returns a dialer of tls connections whose config is the one of the host dialed, rather than a config
+// shared by the urls of the validator, so that an ip host is verified against the certificate as well.
+func dialTLS(dial dialFunc, configFor func(host string) *tls.Config, protos ...string) dialFunc {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+
+		cfg := configFor(host)
+		cfg.NextProtos = protos
+		tlsConn := tls.Client(conn, cfg)
+		if err = tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, err
+		}
+
+		return tlsConn, nil
+	}
+}
+
+// upstreamTransport returns the transport of an upstream with its own tls settings or transport overrides, nil if
+// it shares the transport, along with the dialer of its tls connections over http/1.1, e.g. of websockets.
+func upstreamTransport(tlsCfg UpstreamTLSConfig, transportCfg TransportConfig) (*http.Transport, dialFunc, error) {
+	if tlsCfg == (UpstreamTLSConfig{}) && transportCfg == (TransportConfig{}) {
+		return nil, nil, nil
+	}
+
+	configFor, err := tlsCfg.clientConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	own := newTransport(transportCfg.merge(transportConfig))
+	protos := []string{"http/1.1"}
+	if own.ForceAttemptHTTP2 {
+		protos = []string{"h2", "http/1.1"}
+	}
+	own.DialTLSContext = dialTLS(own.DialContext, configFor, protos...)
+
+	return own, dialTLS(own.DialContext, configFor, "http/1.1"), nil
+}
+
+// upstream is how the private urls of a validator are dialed, the shared http client unless the validator has
+// its own tls settings or transport overrides.
+type upstream struct {
+	options   []rpc.ClientOption
+	transport *http.Transport // owned by the validator, nil if shared
+}
+
+func newUpstream(tlsCfg UpstreamTLSConfig, transportCfg TransportConfig) (*upstream, error) {
+	own, dialHTTP1, err := upstreamTransport(tlsCfg, transportCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if own == nil {
+		return &upstream{options: []rpc.ClientOption{rpc.WithHTTPClient(validatorClient)}}, nil
+	}
+
+	return &upstream{
+		options: []rpc.ClientOption{
+			rpc.WithHTTPClient(&http.Client{Transport: own}),
+			rpc.WithWebsocketDialer(websocket.Dialer{
+				NetDialContext:    own.DialContext,
+				NetDialTLSContext: dialHTTP1,
+				Proxy:             http.ProxyFromEnvironment,
+				HandshakeTimeout:  upstreamTimeout,
+			}),
+		},
+		transport: own,
+	}, nil
+}
+
+func (u *upstream) close() {
+	if u.transport != nil {
+		u.transport.CloseIdleConnections()
+	}
+}
@@ -0,0 +1,163 @@
+package node
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+// newTestCert issues a certificate for 127.0.0.1 signed by the parent, self-signed if nil.
+func newTestCert(t *testing.T, name string, parent *testCert) *testCert {
+	return newTestCertFor(t, name, parent, net.ParseIP("127.0.0.1"))
+}
+
+// newTestCertFor issues a certificate for the ips signed by the parent, self-signed if nil.
+func newTestCertFor(t *testing.T, name string, parent *testCert, ips ...net.IP) *testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  ips,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA, template.BasicConstraintsValid = true, true
+		template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &testCert{cert: cert, key: key}
+}
+
+// write writes the certificate and key files, it returns their paths.
+func (c *testCert) write(t *testing.T, dir, name string) (string, string) {
+	certFile, keyFile := filepath.Join(dir, name+".crt"), filepath.Join(dir, name+".key")
+
+	der, err := x509.MarshalECPrivateKey(c.key)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.cert.Raw}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), 0o600))
+
+	return certFile, keyFile
+}
+
+func TestEndpointsMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCert(t, "ca", nil)
+	caFile, _ := ca.write(t, dir, "ca")
+	serverCert := newTestCert(t, "validator", ca)
+	certFile, keyFile := newTestCert(t, "sentry", ca).write(t, dir, "sentry")
+
+	server := rpc.NewServer()
+	require.NoError(t, server.RegisterName("eth", &chainStub{}))
+	srv := httptest.NewUnstartedServer(server)
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+	srv.TLS = &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{serverCert.cert.Raw}, PrivateKey: serverCert.key}},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    pool,
+	}
+	srv.StartTLS()
+	defer srv.Close()
+
+	tlsConfig := UpstreamTLSConfig{CertFile: certFile, KeyFile: keyFile, CAFile: caFile}
+	e, err := dialEndpoints([]string{srv.URL}, tlsConfig, TransportConfig{})
+	require.NoError(t, err)
+	defer e.close()
+	assert.True(t, e.probe(0))
+
+	// the validator requires a client certificate
+	e, err = dialEndpoints([]string{srv.URL}, UpstreamTLSConfig{CAFile: caFile}, TransportConfig{})
+	require.NoError(t, err)
+	defer e.close()
+	assert.False(t, e.probe(0))
+
+	// the certificate of the validator isn't issued by the CA
+	tlsConfig.CAFile, _ = newTestCert(t, "other", nil).write(t, dir, "other")
+	e, err = dialEndpoints([]string{srv.URL}, tlsConfig, TransportConfig{})
+	require.NoError(t, err)
+	defer e.close()
+	assert.False(t, e.probe(0))
+}
+
+func TestEndpointsTLSHostVerification(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCert(t, "ca", nil)
+	caFile, _ := ca.write(t, dir, "ca")
+
+	// a certificate of the CA issued for another validator
+	serverCert := newTestCertFor(t, "other-validator", ca, net.ParseIP("10.200.0.1"))
+
+	server := rpc.NewServer()
+	require.NoError(t, server.RegisterName("eth", &chainStub{}))
+	srv := httptest.NewUnstartedServer(server)
+	srv.TLS = &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{serverCert.cert.Raw}, PrivateKey: serverCert.key}},
+	}
+	srv.StartTLS()
+	defer srv.Close()
+
+	e, err := dialEndpoints([]string{srv.URL}, UpstreamTLSConfig{CAFile: caFile}, TransportConfig{})
+	require.NoError(t, err)
+	defer e.close()
+	assert.False(t, e.probe(0))
+}
+
+func TestEndpointsTLSVerification(t *testing.T) {
+	server := rpc.NewServer()
+	require.NoError(t, server.RegisterName("eth", &chainStub{}))
+	srv := httptest.NewTLSServer(server)
+	defer srv.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.crt")
+	require.NoError(t, os.WriteFile(caFile,
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}), 0o600))
+
+	for _, tc := range []struct {
+		name     string
+		tls      UpstreamTLSConfig
+		verified bool
+	}{
+		{"system roots", UpstreamTLSConfig{}, false},
+		{"ca file", UpstreamTLSConfig{CAFile: caFile}, true},
+		{"insecure", UpstreamTLSConfig{InsecureSkipVerify: true}, true},
+	} {
+		e, err := dialEndpoints([]string{srv.URL}, tc.tls, TransportConfig{})
+		require.NoError(t, err)
+		assert.Equal(t, tc.verified, e.probe(0), tc.name)
+		e.close()
+	}
+}
@@ -0,0 +1,99 @@
+package node
+
+import (
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/bnb-chain/bsc-mev-sentry/utils"
+)
+
+type TransportConfig struct {
+	// DialTimeout of connecting to an upstream, defaults to 5s
+	DialTimeout utils.Duration
+	// KeepAlive interval of the tcp keep-alive probes, defaults to 60s
+	KeepAlive utils.Duration
+	// MaxConnsPerHost connections per upstream host, defaults to 50, unlimited if negative
+	MaxConnsPerHost int
+	// MaxIdleConnsPerHost idle connections kept per upstream host, defaults to 50
+	MaxIdleConnsPerHost int
+	// IdleConnTimeout how long an idle connection is kept, defaults to 90s
+	IdleConnTimeout utils.Duration
+	// HTTP2 negotiates HTTP/2 with the upstreams served over TLS, off by default, an override unset keeps the shared
+	// setting and false turns it off
+	HTTP2 *bool
+}
+
+var defaultTransportConfig = TransportConfig{
+	DialTimeout:         utils.Duration(5 * time.Second),
+	KeepAlive:           utils.Duration(60 * time.Second),
+	MaxConnsPerHost:     50,
+	MaxIdleConnsPerHost: 50,
+	IdleConnTimeout:     utils.Duration(90 * time.Second),
+}
+
+var (
+	// transportConfig is the config shared by the upstreams, the one of an upstream overrides it
+	transportConfig = defaultTransportConfig
+	transport       = newTransport(transportConfig)
+
+	client = &http.Client{
+		Timeout:   5 * time.Second,
+		Transport: transport,
+	}
+
+	// validatorClient has no timeout of its own, calls to validators are bounded by the deadline of their context
+	// so that they don't outlive the requests they're made for, see withUpstreamTimeout.
+	validatorClient = &http.Client{
+		Transport: transport,
+	}
+)
+
+// ConfigureTransport sets the transport shared by the upstreams, it must be called before any is dialed.
+func ConfigureTransport(cfg TransportConfig) {
+	transportConfig = cfg.merge(defaultTransportConfig)
+	transport = newTransport(transportConfig)
+	client = &http.Client{Timeout: client.Timeout, Transport: transport}
+	validatorClient = &http.Client{Transport: transport}
+}
+
+// merge returns the config whose zero fields are taken from base.
+func (c TransportConfig) merge(base TransportConfig) TransportConfig {
+	if c.DialTimeout == 0 {
+		c.DialTimeout = base.DialTimeout
+	}
+	if c.KeepAlive == 0 {
+		c.KeepAlive = base.KeepAlive
+	}
+	if c.MaxConnsPerHost == 0 {
+		c.MaxConnsPerHost = base.MaxConnsPerHost
+	}
+	if c.MaxIdleConnsPerHost == 0 {
+		c.MaxIdleConnsPerHost = base.MaxIdleConnsPerHost
+	}
+	if c.IdleConnTimeout == 0 {
+		c.IdleConnTimeout = base.IdleConnTimeout
+	}
+	if c.HTTP2 == nil {
+		c.HTTP2 = base.HTTP2
+	}
+
+	return c
+}
+
+// newTransport creates a transport of the merged config.
+func newTransport(cfg TransportConfig) *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   time.Duration(cfg.DialTimeout),
+		KeepAlive: time.Duration(cfg.KeepAlive),
+	}
+
+	return &http.Transport{
+		DialContext:         dialer.DialContext,
+		MaxIdleConnsPerHost: cfg.MaxIdleConnsPerHost,
+		// 0 is unlimited for http.Transport
+		MaxConnsPerHost:   max(cfg.MaxConnsPerHost, 0),
+		IdleConnTimeout:   time.Duration(cfg.IdleConnTimeout),
+		ForceAttemptHTTP2: cfg.HTTP2 != nil && *cfg.HTTP2,
+	}
+}
@@ -0,0 +1,32 @@
+package node
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bnb-chain/bsc-mev-sentry/utils"
+)
+
+func TestTransportConfigMerge(t *testing.T) {
+	enabled, disabled := true, false
+	shared := TransportConfig{MaxConnsPerHost: 100, HTTP2: &enabled}.merge(defaultTransportConfig)
+	assert.Equal(t, 100, shared.MaxConnsPerHost)
+	assert.Equal(t, 50, shared.MaxIdleConnsPerHost)
+	assert.Equal(t, utils.Duration(5*time.Second), shared.DialTimeout)
+
+	override := TransportConfig{MaxConnsPerHost: -1, IdleConnTimeout: utils.Duration(time.Second)}.merge(shared)
+	assert.Equal(t, -1, override.MaxConnsPerHost)
+	assert.Equal(t, utils.Duration(time.Second), override.IdleConnTimeout)
+	assert.True(t, *override.HTTP2)
+
+	transport := newTransport(override)
+	assert.Equal(t, 0, transport.MaxConnsPerHost, "unlimited")
+	assert.True(t, transport.ForceAttemptHTTP2)
+
+	// an override turns HTTP/2 off
+	override = TransportConfig{HTTP2: &disabled}.merge(shared)
+	assert.False(t, newTransport(override).ForceAttemptHTTP2)
+	assert.False(t, newTransport(defaultTransportConfig).ForceAttemptHTTP2)
+}
@@ -2,11 +2,8 @@ package node
 
 import (
 	"context"
-	"crypto/tls"
 	"errors"
 	"math/big"
-	"net"
-	"net/http"
 	"strings"
 	"sync/atomic"
 	"time"
@@ -14,50 +11,79 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/ethclient"
-	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/go-co-op/gocron"
 
 	"github.com/bnb-chain/bsc-mev-sentry/account"
 	"github.com/bnb-chain/bsc-mev-sentry/log"
 	"github.com/bnb-chain/bsc-mev-sentry/metrics"
+	"github.com/bnb-chain/bsc-mev-sentry/notification"
+	"github.com/bnb-chain/bsc-mev-sentry/utils"
 )
 
 var (
 	PayBidTxGasUsed = uint64(25000)
 
-	dialer = &net.Dialer{
-		Timeout:   5 * time.Second,
-		KeepAlive: 60 * time.Second,
-	}
-
-	transport = &http.Transport{
-		DialContext:         dialer.DialContext,
-		MaxIdleConnsPerHost: 50,
-		MaxConnsPerHost:     50,
-		IdleConnTimeout:     90 * time.Second,
-		TLSClientConfig:     &tls.Config{InsecureSkipVerify: true},
-	}
-
-	client = &http.Client{
-		Timeout:   5 * time.Second,
-		Transport: transport,
-	}
+	// ErrStaleMevParams is returned instead of mev params older than their ttl.
+	ErrStaleMevParams = errors.New("stale params, the mev params of the validator are outdated")
 )
 
+// upstreamTimeout bounds the calls to validators whose context has no deadline.
+const upstreamTimeout = 5 * time.Second
+
 type Validator interface {
 	SendBid(context.Context, types.BidArgs) (common.Hash, error)
+	// CancelBid withdraws a bid sent to the validator, only if Capabilities().CancelBid.
+	CancelBid(ctx context.Context, bidHash common.Hash) error
 	MevRunning() bool
 	HasBuilder(ctx context.Context, builder common.Address) (bool, error)
 	BestBidGasFee(ctx context.Context, parentHash common.Hash) (*big.Int, error)
 	MevParams(ctx context.Context) (*types.MevParams, error)
 	BuilderFeeCeil() *big.Int
-	GeneratePayBidTx(ctx context.Context, builder common.Address, builderFee *big.Int) (hexutil.Bytes, error)
+	// Head returns the latest block header known by the validator, nil if not fetched yet.
+	Head() *ChainHead
+	// BlockTxs returns the hash, the parent hash, the coinbase and the hashes of the txs of the block of the number,
+	// along with the builder paid by the pay bid tx of the block if the validator proposed it.
+	BlockTxs(ctx context.Context, number uint64) (*BlockTxs, error)
+	// ChainID returns the chain id of the validator, nil if not fetched yet.
+	ChainID() *big.Int
+	// PayAccount returns the address of the pay account signing the pay bid txs.
+	PayAccount() common.Address
+	// PayAccountNonce returns the nonce of the next pay bid tx.
+	PayAccountNonce() uint64
+	// LeaseNonce makes the pay bid txs use nonces from floor on, until the chain catches up with it,
+	// e.g. when taking over from a peer sentry sharing the pay account.
+	LeaseNonce(floor uint64)
+	// MinBidGasPrice returns the minimum average gas price of bids, nil if not enforced.
+	MinBidGasPrice() *big.Int
+	// GeneratePayBidTx signs the pay bid tx of a bid for the block, paying the builder fee to the builder.
+	GeneratePayBidTx(ctx context.Context, builder common.Address, builderFee *big.Int, block uint64) (hexutil.Bytes,
+		error)
+	// ReleasePayBidTx releases the balance reserved for the pay bid tx of a bid that isn't forwarded, only if
+	// ReserveBalance.
+	ReleasePayBidTx(payBidTx hexutil.Bytes)
+	// Health returns the sentry side conditions of forwarding bids to the validator.
+	Health() Health
+	// Capabilities returns the optional features probed when the validator is created.
+	Capabilities() Capabilities
+	// Config returns the config the validator is created with.
+	Config() ValidatorConfig
+	// Stop stops the background refresh and releases the upstream connection.
+	Stop()
 }
 
 type ValidatorConfig struct {
-	PrivateURL     string
-	PublicHostName string
+	PrivateURL string
+	// BackupPrivateURLs urls of the same validator failed over to in order when the active one is unreachable
+	BackupPrivateURLs []string
+	PublicHostName    string
+	// Chain name of the chain the validator belongs to, one of the Chains of the service, if any
+	Chain string
+	// ConsensusAddress consensus address of the validator, the proposer routing and the win rates need it
+	ConsensusAddress common.Address
+	// TLS settings of the connections to the private urls, e.g. a client certificate for mTLS
+	TLS UpstreamTLSConfig
+	// Transport overrides the shared transport settings of the connections to the private urls
+	Transport TransportConfig
 
 	PayAccountMode account.Mode
 	// PrivateKey private key of sentry wallet
@@ -68,13 +94,114 @@ type ValidatorConfig struct {
 	PasswordFilePath string
 	// PayAccountAddress public address of sentry wallet
 	PayAccountAddress string
+
+	// Refresh cadence of fetching the state of the validator
+	Refresh RefreshConfig
+	// GasPriceOracle gas price oracle fed from the validator's chain rpc
+	GasPriceOracle GasPriceOracleConfig
+	// BidWindow acceptable bid arrival offsets relative to the expected timestamp of the target block
+	BidWindow BidWindowConfig
+	// ReplayWindow rejects bids on a parent block too far behind the validator's latest block
+	ReplayWindow ReplayWindowConfig
+	// HasBuilder rejects bids of builders the validator hasn't registered, as told by mev_hasBuilder
+	HasBuilder HasBuilderConfig
+	// CircuitBreaker fast-fails bids while the validator is unreachable
+	CircuitBreaker CircuitBreakerConfig
+	// Retry retries the read-only calls failing transiently, bids are sent once
+	Retry RetryConfig
+	// StrictChainID rejects bids containing txs signed for another chain
+	StrictChainID bool
+	// MaxBidSize rejects bids whose txs exceed the size in bytes, unlimited if 0
+	MaxBidSize int
+	// MaxBidTxs rejects bids with more txs, unlimited if 0
+	MaxBidTxs int
+	// BuilderFeeCeil caps the builder fee ceiling in wei of the mev params of the validator, not capped if nil
+	BuilderFeeCeil *big.Int
+	// OverrideBuilderFeeCeil enforces BuilderFeeCeil instead of the ceiling of the mev params even if it's higher
+	OverrideBuilderFeeCeil bool
+	// MevParamsTTL how long the mev params fetched last are served, mev_params and bids fail once they're older,
+	// disabled if 0
+	MevParamsTTL utils.Duration
+	// ReserveBalance subtracts the cost of the pay bid txs in flight of the pay account from its balance checked for
+	// the next one, until the balance fetched reflects their blocks
+	ReserveBalance bool
+	// DryRun runs every check of the bids to the validator and signs their pay bid txs, but reports the outcome to
+	// the builder instead of forwarding them
+	DryRun bool
+	// Shadow mirrors the bids accepted by the validator to a shadow validator, disabled if its PrivateURL is empty
+	Shadow ShadowConfig
+	// Preflight simulates the pay bid txs before signing them
+	Preflight PreflightConfig
+}
+
+type RefreshConfig struct {
+	// Interval of polling the head, mev running flag and pay account nonce, defaults to 500ms
+	Interval utils.Duration
+	// ExpensiveInterval of fetching the pay account balance, mev params and gas price, defaults to Interval
+	ExpensiveInterval utils.Duration
+}
+
+// intervals returns the intervals with the defaults applied.
+func (c RefreshConfig) intervals() (interval, expensiveInterval time.Duration) {
+	interval, expensiveInterval = time.Duration(c.Interval), time.Duration(c.ExpensiveInterval)
+	if interval <= 0 {
+		interval = 500 * time.Millisecond
+	}
+	if expensiveInterval <= 0 {
+		expensiveInterval = interval
+	}
+
+	return interval, expensiveInterval
+}
+
+type BidWindowConfig struct {
+	// Enabled turns on the bid window check
+	Enabled bool
+	// BlockInterval expected interval between blocks
+	BlockInterval utils.Duration
+	// Earliest offset a bid may arrive at, e.g. "-3s" means 3s before the target block time
+	Earliest utils.Duration
+	// Latest offset a bid may arrive at
+	Latest utils.Duration
+}
+
+type ReplayWindowConfig struct {
+	// Enabled turns on the replay window check
+	Enabled bool
+	// MaxParentAge number of blocks the parent of a bid may be behind the latest block, 0 means it must be the latest
+	MaxParentAge uint64
+}
+
+type HasBuilderConfig struct {
+	// Enabled turns on the registered builder check
+	Enabled bool
+	// CacheTTL how long the answer of the validator is cached per builder
+	CacheTTL utils.Duration
+}
+
+// ChainHead is the latest block known by a validator.
+type ChainHead struct {
+	Hash   common.Hash
+	Number uint64
+	Time   uint64
+}
+
+// BlockTxs is a block as the bids for it are settled by.
+type BlockTxs struct {
+	Hash       common.Hash
+	ParentHash common.Hash
+	Coinbase   common.Address
+	Txs        []common.Hash
+	// Builder recipient of the pay bid tx of the block, i.e. the builder whose bid won it, zero if the block has
+	// none. Only looked for in the blocks of the ConsensusAddress, if set.
+	Builder common.Address
 }
 
-func NewValidator(config ValidatorConfig) Validator {
-	cli, err := ethclient.DialOptions(context.Background(), config.PrivateURL, rpc.WithHTTPClient(client))
+func NewValidator(config ValidatorConfig) (Validator, error) {
+	urls := append([]string{config.PrivateURL}, config.BackupPrivateURLs...)
+	eps, err := dialEndpoints(urls, config.TLS, config.Transport)
 	if err != nil {
-		log.Errorw("failed to dial validator", "url", config.PrivateURL, "err", err)
-		return nil
+		return nil, err
 	}
 
 	acc, err := account.New(&account.Config{
@@ -84,62 +211,186 @@ func NewValidator(config ValidatorConfig) Validator {
 		PasswordFilePath: config.PasswordFilePath,
 		Address:          config.PayAccountAddress})
 	if err != nil {
-		log.Panicw("failed to create payAccount", "err", err)
+		log.Errorw("failed to create payAccount", "err", err)
+		eps.close()
+		return nil, err
+	}
+
+	var sh *shadow
+	if config.Shadow.PrivateURL != "" {
+		if sh, err = newShadow(config); err != nil {
+			log.Errorw("failed to create shadow validator", "hostname", config.PublicHostName, "err", err)
+			eps.close()
+			return nil, err
+		}
 	}
 
+	caps := probeCapabilities(eps.client())
+	log.Infow("validator capabilities probed", "hostname", config.PublicHostName, "capabilities", caps)
+
 	v := &validator{
 		cfg:        config,
-		client:     cli,
+		caps:       caps,
+		endpoints:  eps,
 		scheduler:  gocron.NewScheduler(time.UTC),
 		payAccount: acc,
+		oracle:     newGasPriceOracle(config.GasPriceOracle),
+		breaker:    newCircuitBreaker(config.PublicHostName, config.CircuitBreaker),
+		shadow:     sh,
+	}
+	if config.ReserveBalance {
+		v.reserved = reservedBalanceOf(config.Chain, acc.Address())
+	}
+	if config.Preflight.Enabled {
+		if v.preflight, err = newPreflight(config.PublicHostName, config.Preflight, eps.client); err != nil {
+			log.Errorw("failed to create pay bid tx preflight", "hostname", config.PublicHostName, "err", err)
+			eps.close()
+			if sh != nil {
+				sh.stop()
+			}
+			return nil, err
+		}
+	}
+
+	if isWebSocket(config.PrivateURL) {
+		v.quit = make(chan struct{})
+		go v.watch()
+	} else {
+		interval, expensiveInterval := config.Refresh.intervals()
+		if _, err := v.scheduler.Every(interval).Do(v.refresh); err != nil {
+			log.Debugw("error while setting up scheduler", "err", err)
+		}
+		if _, err := v.scheduler.Every(expensiveInterval).Do(v.refreshExpensive); err != nil {
+			log.Debugw("error while setting up scheduler", "err", err)
+		}
 	}
 
-	if _, err := v.scheduler.Every(500).Milliseconds().Do(func() {
-		v.refresh()
-	}); err != nil {
-		log.Debugw("error while setting up scheduler", "err", err)
+	if rotatable, ok := acc.(account.Rotatable); ok {
+		if _, err := v.scheduler.Every(5).Seconds().Do(rotatable.CheckRotation); err != nil {
+			log.Debugw("error while setting up scheduler", "err", err)
+		}
 	}
 
 	v.scheduler.StartAsync()
 
-	return v
+	return v, nil
 }
 
 type validator struct {
 	cfg        ValidatorConfig
-	client     *ethclient.Client
+	caps       Capabilities
+	endpoints  *endpoints
 	payAccount account.Account
+	reserved   *reservedBalance // nil unless ReserveBalance
+	oracle     *gasPriceOracle
+	breaker    *circuitBreaker
+	shadow     *shadow    // nil unless Shadow
+	preflight  *preflight // nil unless Preflight.Enabled
 
 	scheduler         *gocron.Scheduler
+	quit              chan struct{} // stops watching the new heads of a websocket validator
 	chainID           atomic.Pointer[big.Int]
+	head              atomic.Pointer[ChainHead]
 	mevRunning        uint32
 	mevParams         atomic.Pointer[types.MevParams]
+	mevParamsAt       atomic.Int64 // unix nanoseconds when the mev params are fetched
 	payAccountBalance atomic.Pointer[big.Int]
 	payAccountNonce   uint64
+	nonceFloor        atomic.Uint64
+
+	// unreachable, lowBalance, mevStopped and nonceStuck only notify operators when the state changes
+	unreachable atomic.Bool
+	lowBalance  atomic.Bool
+	mevStopped  atomic.Bool
+	nonceStuck  atomic.Bool
+
+	nonceHealthy atomic.Bool
+	chainNonce   atomic.Uint64 // the highest nonce of the pay account on chain, each increase is a won bid
 }
 
 func (n *validator) SendBid(ctx context.Context, args types.BidArgs) (common.Hash, error) {
-	hash, err := n.client.SendBid(ctx, args)
+	// the builder is gone, the validator isn't to blame
+	if err := ctx.Err(); err != nil {
+		return common.Hash{}, err
+	}
+
+	if !n.breaker.allow() {
+		return common.Hash{}, ErrCircuitOpen
+	}
+
+	ctx, cancel := withUpstreamTimeout(ctx)
+	defer cancel()
+
+	start := time.Now()
+	hash, err := n.endpoints.client().SendBid(ctx, args)
+	observeUpstream(n.cfg.PublicHostName, "mev_sendBid", start)
+	n.breaker.done(err)
 	if err != nil {
-		metrics.ChainError.Inc()
+		metrics.ChainError.WithLabelValues(n.cfg.PublicHostName, "mev_sendBid").Inc()
 		log.Errorw("failed to send bid", "err", err)
 
 		if strings.Contains(err.Error(), "timeout") {
 			err = errors.New("timeout when send bid to validator")
 		}
+	} else if n.shadow != nil {
+		n.shadow.mirror(args, hash, time.Since(start))
 	}
 
 	return hash, err
 }
 
+func (n *validator) CancelBid(ctx context.Context, bidHash common.Hash) error {
+	ctx, cancel := withUpstreamTimeout(ctx)
+	defer cancel()
+
+	start := time.Now()
+	err := n.endpoints.client().Client().CallContext(ctx, nil, "mev_cancelBid", bidHash)
+	observeUpstream(n.cfg.PublicHostName, "mev_cancelBid", start)
+	if err != nil {
+		metrics.ChainError.WithLabelValues(n.cfg.PublicHostName, "mev_cancelBid").Inc()
+		log.Errorw("failed to cancel bid", "bidHash", bidHash, "err", err)
+	}
+
+	return err
+}
+
+func (n *validator) Capabilities() Capabilities {
+	return n.caps
+}
+
+func (n *validator) Config() ValidatorConfig {
+	return n.cfg
+}
+
+func (n *validator) Stop() {
+	n.scheduler.Stop()
+	if n.quit != nil {
+		close(n.quit)
+	}
+	n.endpoints.close()
+	if n.shadow != nil {
+		n.shadow.stop()
+	}
+	if n.preflight != nil {
+		n.preflight.close()
+	}
+}
+
 func (n *validator) MevRunning() bool {
 	return atomic.LoadUint32(&n.mevRunning) == 1
 }
 
 func (n *validator) HasBuilder(ctx context.Context, builder common.Address) (bool, error) {
-	has, err := n.client.HasBuilder(ctx, builder)
+	ctx, cancel := withUpstreamTimeout(ctx)
+	defer cancel()
+
+	start := time.Now()
+	has, err := retry(ctx, n.cfg.Retry, n.cfg.PublicHostName, "mev_hasBuilder", func() (bool, error) {
+		return n.endpoints.client().HasBuilder(ctx, builder)
+	})
+	observeUpstream(n.cfg.PublicHostName, "mev_hasBuilder", start)
 	if err != nil {
-		metrics.ChainError.Inc()
+		metrics.ChainError.WithLabelValues(n.cfg.PublicHostName, "mev_hasBuilder").Inc()
 		log.Errorw("failed to check if has builder", "err", err)
 
 		if strings.Contains(err.Error(), "timeout") {
@@ -151,20 +402,63 @@ func (n *validator) HasBuilder(ctx context.Context, builder common.Address) (boo
 }
 
 func (n *validator) refresh() {
-	chainID, err := n.client.ChainID(context.Background())
+	ctx, cancel := context.WithTimeout(context.Background(), upstreamTimeout)
+	defer cancel()
+
+	start := time.Now()
+	chainID, err := retry(ctx, n.cfg.Retry, n.cfg.PublicHostName, "eth_chainId", func() (*big.Int, error) {
+		return n.endpoints.client().ChainID(ctx)
+	})
+	observeUpstream(n.cfg.PublicHostName, "eth_chainId", start)
+	// the chain id is the health probe closing the breaker once the validator is back
+	n.breaker.done(err)
 	if err != nil {
-		metrics.ChainError.Inc()
-		log.Errorw("failed to fetch chainID", "url", n.cfg.PrivateURL, "err", err)
+		metrics.ChainError.WithLabelValues(n.cfg.PublicHostName, "eth_chainId").Inc()
+		log.Errorw("failed to fetch chainID", "url", n.endpoints.url(), "err", err)
 	}
 
 	if chainID != nil {
 		n.chainID.Store(chainID)
 	}
 
-	mevRunning, err := n.client.MevRunning(context.Background())
+	start = time.Now()
+	header, err := retry(ctx, n.cfg.Retry, n.cfg.PublicHostName, "eth_getBlockByNumber", func() (*types.Header, error) {
+		return n.endpoints.client().HeaderByNumber(ctx, nil)
+	})
+	observeUpstream(n.cfg.PublicHostName, "eth_getBlockByNumber", start)
+	if err != nil {
+		metrics.ChainError.WithLabelValues(n.cfg.PublicHostName, "eth_getBlockByNumber").Inc()
+		log.Errorw("failed to fetch latest header", "url", n.endpoints.url(), "err", err)
+
+		// the next refresh goes to the backup url if any is reachable
+		if !n.endpoints.failOver(n.cfg.PublicHostName) && !n.unreachable.Swap(true) {
+			notification.Notify(notification.Critical, "validator down", err.Error(),
+				"validator", n.cfg.PublicHostName)
+		}
+	} else if n.unreachable.Swap(false) {
+		notification.Notify(notification.Info, "validator recovered", "", "validator", n.cfg.PublicHostName)
+	}
+
+	if header != nil {
+		n.head.Store(&ChainHead{Hash: header.Hash(), Number: header.Number.Uint64(), Time: header.Time})
+	}
+
+	n.refreshState()
+}
+
+// refreshState fetches the state of the validator which is cheap to fetch.
+func (n *validator) refreshState() {
+	ctx, cancel := context.WithTimeout(context.Background(), upstreamTimeout)
+	defer cancel()
+
+	start := time.Now()
+	mevRunning, err := retry(ctx, n.cfg.Retry, n.cfg.PublicHostName, "mev_running", func() (bool, error) {
+		return n.endpoints.client().MevRunning(ctx)
+	})
+	observeUpstream(n.cfg.PublicHostName, "mev_running", start)
 	if err != nil {
-		metrics.ChainError.Inc()
-		log.Errorw("failed to fetch mev running status", "url", n.cfg.PrivateURL, "err", err)
+		metrics.ChainError.WithLabelValues(n.cfg.PublicHostName, "mev_running").Inc()
+		log.Errorw("failed to fetch mev running status", "url", n.endpoints.url(), "err", err)
 	}
 
 	if mevRunning {
@@ -173,49 +467,141 @@ func (n *validator) refresh() {
 		atomic.StoreUint32(&n.mevRunning, 0)
 	}
 
-	balance, err := n.client.BalanceAt(context.Background(), n.payAccount.Address(), nil)
-	if err != nil {
-		metrics.ChainError.Inc()
-		log.Errorw("failed to fetch validator payAccount balance", "err", err)
+	if err == nil && !mevRunning && !n.mevStopped.Swap(true) {
+		notification.Notify(notification.Warning, "validator mev stopped", "mev_running is off, no bids are taken",
+			"validator", n.cfg.PublicHostName)
+	} else if mevRunning && n.mevStopped.Swap(false) {
+		notification.Notify(notification.Info, "validator mev running", "", "validator", n.cfg.PublicHostName)
 	}
 
-	if balance != nil {
-		n.payAccountBalance.Store(balance)
+	start = time.Now()
+	nonce, err := retry(ctx, n.cfg.Retry, n.cfg.PublicHostName, "eth_getTransactionCount", func() (uint64, error) {
+		return n.endpoints.client().NonceAt(ctx, n.payAccount.Address(), nil)
+	})
+	observeUpstream(n.cfg.PublicHostName, "eth_getTransactionCount", start)
+	if err != nil {
+		metrics.ChainError.WithLabelValues(n.cfg.PublicHostName, "eth_getTransactionCount").Inc()
+		log.Errorw("failed to fetch validator payAccount nonce", "err", err)
+		n.nonceHealthy.Store(false)
+
+		// a validator down is notified already
+		if !n.unreachable.Load() && !n.nonceStuck.Swap(true) {
+			notification.Notify(notification.Warning, "pay account nonce stuck", err.Error(),
+				"validator", n.cfg.PublicHostName, "address", n.payAccount.Address().String())
+		}
+	} else {
+		log.Infow("refresh payAccount nonce", "address", n.payAccount.Address(), "nonce", nonce)
+
+		// the pay account only sends pay bid txs, so each one included is a won bid. The highest nonce is kept, so
+		// that the pay bid txs dropped by a reorg and included again, or replaced, aren't counted twice.
+		if last := n.chainNonce.Load(); nonce > last {
+			n.chainNonce.Store(nonce)
+			if last > 0 {
+				metrics.BidLifecycleCounter.WithLabelValues("won").Add(float64(nonce - last))
+			}
+		} else if nonce < last {
+			log.Warnw("pay account nonce went back, pay bid txs are dropped by a reorg", "validator",
+				n.cfg.PublicHostName, "nonce", nonce, "highest", last)
+		}
+
+		if floor := n.nonceFloor.Load(); floor > nonce {
+			nonce = floor
+		} else if floor > 0 {
+			n.nonceFloor.Store(0)
+		}
+
+		atomic.StoreUint64(&n.payAccountNonce, nonce)
+		n.nonceHealthy.Store(true)
+
+		if n.nonceStuck.Swap(false) {
+			notification.Notify(notification.Info, "pay account nonce recovered", "",
+				"validator", n.cfg.PublicHostName, "address", n.payAccount.Address().String())
+		}
 	}
+}
+
+// refreshExpensive fetches the state of the validator which is expensive to fetch or changes rarely.
+func (n *validator) refreshExpensive() {
+	ctx, cancel := context.WithTimeout(context.Background(), upstreamTimeout)
+	defer cancel()
 
-	nonce, err := n.client.NonceAt(context.Background(), n.payAccount.Address(), nil)
+	// the balance fetched reflects the blocks up to the head at least
+	head := n.head.Load()
+	start := time.Now()
+	balance, err := retry(ctx, n.cfg.Retry, n.cfg.PublicHostName, "eth_getBalance", func() (*big.Int, error) {
+		return n.endpoints.client().BalanceAt(ctx, n.payAccount.Address(), nil)
+	})
+	observeUpstream(n.cfg.PublicHostName, "eth_getBalance", start)
 	if err != nil {
-		metrics.ChainError.Inc()
-		log.Errorw("failed to fetch validator payAccount nonce", "err", err)
+		metrics.ChainError.WithLabelValues(n.cfg.PublicHostName, "eth_getBalance").Inc()
+		log.Errorw("failed to fetch validator payAccount balance", "err", err)
 	}
 
-	log.Infow("refresh payAccount nonce", "address", n.payAccount.Address(), "nonce", nonce)
+	if balance != nil {
+		n.payAccountBalance.Store(balance)
 
-	atomic.StoreUint64(&n.payAccountNonce, nonce)
+		if n.reserved != nil && head != nil {
+			n.reserved.settle(head.Number)
+		}
+	}
 
-	params, err := n.client.MevParams(context.Background())
+	start = time.Now()
+	params, err := retry(ctx, n.cfg.Retry, n.cfg.PublicHostName, "mev_params", func() (*types.MevParams, error) {
+		return n.endpoints.client().MevParams(ctx)
+	})
+	observeUpstream(n.cfg.PublicHostName, "mev_params", start)
 	if err != nil {
-		metrics.ChainError.Inc()
+		metrics.ChainError.WithLabelValues(n.cfg.PublicHostName, "mev_params").Inc()
 		log.Errorw("failed to fetch validator mev params", "err", err)
 	}
 
 	if params != nil {
 		n.mevParams.Store(params)
+		n.mevParamsAt.Store(time.Now().UnixNano())
+	}
+
+	if n.oracle != nil {
+		n.oracle.update(ctx, n.cfg.PublicHostName, n.endpoints.client())
 	}
 }
 
 func (n *validator) BestBidGasFee(ctx context.Context, parentHash common.Hash) (*big.Int, error) {
-	return n.client.BestBidGasFee(ctx, parentHash)
+	ctx, cancel := withUpstreamTimeout(ctx)
+	defer cancel()
+
+	start := time.Now()
+	fee, err := retry(ctx, n.cfg.Retry, n.cfg.PublicHostName, "mev_bestBidGasFee", func() (*big.Int, error) {
+		return n.endpoints.client().BestBidGasFee(ctx, parentHash)
+	})
+	observeUpstream(n.cfg.PublicHostName, "mev_bestBidGasFee", start)
+	if err != nil {
+		metrics.ChainError.WithLabelValues(n.cfg.PublicHostName, "mev_bestBidGasFee").Inc()
+	}
+
+	return fee, err
 }
 
 func (n *validator) MevParams(_ context.Context) (*types.MevParams, error) {
-	return n.mevParams.Load(), nil
+	if n.mevParamsStale() {
+		return nil, ErrStaleMevParams
+	}
+
+	params := n.mevParams.Load()
+	if params == nil || n.cfg.BuilderFeeCeil == nil {
+		return params, nil
+	}
+
+	// builders see the ceiling the sentry enforces
+	overridden := *params
+	overridden.BuilderFeeCeil = n.builderFeeCeil(params)
+
+	return &overridden, nil
 }
 
 func (n *validator) BuilderFeeCeil() *big.Int {
 	params := n.mevParams.Load()
-	if params != nil {
-		return params.BuilderFeeCeil
+	if params != nil || (n.cfg.BuilderFeeCeil != nil && n.cfg.OverrideBuilderFeeCeil) {
+		return n.builderFeeCeil(params)
 	}
 
 	log.Errorw("mev params is nil, return 0 for BuilderFeeCeil", "validator", n.cfg.PublicHostName)
@@ -223,7 +609,117 @@ func (n *validator) BuilderFeeCeil() *big.Int {
 	return big.NewInt(0)
 }
 
-func (n *validator) GeneratePayBidTx(_ context.Context, builder common.Address, builderFee *big.Int) (hexutil.Bytes, error) {
+// mevParamsStale tells whether the mev params are older than their ttl, or not fetched yet.
+func (n *validator) mevParamsStale() bool {
+	ttl := time.Duration(n.cfg.MevParamsTTL)
+	if ttl <= 0 {
+		return false
+	}
+
+	fetchedAt := n.mevParamsAt.Load()
+	return fetchedAt == 0 || time.Since(time.Unix(0, fetchedAt)) > ttl
+}
+
+// builderFeeCeil returns the ceiling of the params, capped or overridden by the configured one.
+func (n *validator) builderFeeCeil(params *types.MevParams) *big.Int {
+	ceil := n.cfg.BuilderFeeCeil
+	if ceil == nil {
+		return params.BuilderFeeCeil
+	}
+
+	if n.cfg.OverrideBuilderFeeCeil || params.BuilderFeeCeil == nil || ceil.Cmp(params.BuilderFeeCeil) < 0 {
+		return ceil
+	}
+
+	return params.BuilderFeeCeil
+}
+
+func (n *validator) Head() *ChainHead {
+	return n.head.Load()
+}
+
+func (n *validator) BlockTxs(ctx context.Context, number uint64) (*BlockTxs, error) {
+	ctx, cancel := withUpstreamTimeout(ctx)
+	defer cancel()
+
+	start := time.Now()
+	block, err := n.endpoints.client().BlockByNumber(ctx, new(big.Int).SetUint64(number))
+	observeUpstream(n.cfg.PublicHostName, "eth_getBlockByNumber", start)
+	if err != nil {
+		metrics.ChainError.WithLabelValues(n.cfg.PublicHostName, "eth_getBlockByNumber").Inc()
+		return nil, err
+	}
+
+	txs := &BlockTxs{
+		Hash:       block.Hash(),
+		ParentHash: block.ParentHash(),
+		Coinbase:   block.Coinbase(),
+		Txs:        make([]common.Hash, 0, len(block.Transactions())),
+	}
+	for _, tx := range block.Transactions() {
+		txs.Txs = append(txs.Txs, tx.Hash())
+	}
+
+	if consensus := n.cfg.ConsensusAddress; consensus == (common.Address{}) || consensus == txs.Coinbase {
+		txs.Builder = payBidTxRecipient(block.Transactions(), block.Coinbase(), n.payAccount.Address())
+	}
+
+	return txs, nil
+}
+
+// payBidTxRecipient returns the recipient of the pay bid tx, zero if there's none. The pay bid tx is the last tx of
+// the block before the system txs, which the coinbase sends, so a tx of the pay account anywhere else, e.g. in a
+// block no bid won, is no pay bid tx.
+func payBidTxRecipient(txs types.Transactions, coinbase, payAccount common.Address) common.Address {
+	for i := len(txs) - 1; i >= 0; i-- {
+		tx := txs[i]
+		sender, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+		if err != nil {
+			return common.Address{}
+		}
+		if sender == coinbase {
+			continue
+		}
+
+		if sender != payAccount || tx.To() == nil {
+			return common.Address{}
+		}
+		return *tx.To()
+	}
+
+	return common.Address{}
+}
+
+func (n *validator) ChainID() *big.Int {
+	return n.chainID.Load()
+}
+
+func (n *validator) PayAccount() common.Address {
+	return n.payAccount.Address()
+}
+
+func (n *validator) PayAccountNonce() uint64 {
+	return atomic.LoadUint64(&n.payAccountNonce)
+}
+
+func (n *validator) LeaseNonce(floor uint64) {
+	n.nonceFloor.Store(floor)
+
+	if atomic.LoadUint64(&n.payAccountNonce) < floor {
+		atomic.StoreUint64(&n.payAccountNonce, floor)
+	}
+}
+
+func (n *validator) MinBidGasPrice() *big.Int {
+	if n.oracle == nil || !n.cfg.GasPriceOracle.MinBidGasPrice {
+		return nil
+	}
+
+	return n.oracle.GasPrice()
+}
+
+func (n *validator) GeneratePayBidTx(ctx context.Context, builder common.Address, builderFee *big.Int,
+	block uint64) (hexutil.Bytes, error) {
 	// take pay bid tx as block tag
 	var amount = big.NewInt(0)
 
@@ -231,16 +727,41 @@ func (n *validator) GeneratePayBidTx(_ context.Context, builder common.Address,
 		amount = builderFee
 	}
 
-	if n.payAccountBalance.Load().Cmp(amount) < 0 {
+	var gasPrice = big.NewInt(0)
+	if n.oracle != nil && n.cfg.GasPriceOracle.PayBidTx {
+		gasPrice = n.oracle.GasPrice()
+	}
+
+	cost := new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(PayBidTxGasUsed))
+	cost.Add(cost, amount)
+
+	if n.payAccountBalance.Load().Cmp(cost) < 0 {
 		metrics.AccountError.WithLabelValues(n.payAccount.Address().String(), "insufficient_balance").Inc()
 		log.Errorw("insufficient balance", "balance", n.payAccountBalance.Load().String(),
 			"builderFee", builderFee.String())
+
+		if !n.lowBalance.Swap(true) {
+			notification.Notify(notification.Warning, "pay account balance too low", "",
+				"validator", n.cfg.PublicHostName, "address", n.payAccount.Address().String(),
+				"balance", n.payAccountBalance.Load().String())
+		}
+
 		return nil, errors.New("insufficient balance")
 	}
 
+	n.lowBalance.Store(false)
+
+	if n.preflight != nil {
+		if err := n.preflight.simulate(ctx, n.payAccount.Address(), builder, amount, gasPrice); err != nil {
+			log.Errorw("pay bid tx simulation failed", "validator", n.cfg.PublicHostName, "builder", builder,
+				"err", err)
+			return nil, err
+		}
+	}
+
 	tx := types.NewTx(&types.LegacyTx{
 		Nonce:    atomic.LoadUint64(&n.payAccountNonce),
-		GasPrice: big.NewInt(0),
+		GasPrice: gasPrice,
 		Gas:      PayBidTxGasUsed,
 		To:       &builder,
 		Value:    amount,
@@ -252,6 +773,15 @@ func (n *validator) GeneratePayBidTx(_ context.Context, builder common.Address,
 		return nil, err
 	}
 
+	if n.reserved != nil &&
+		!n.reserved.reserve(n.payAccountBalance.Load(), signedTx.Hash(), cost, signedTx.Nonce(), block) {
+		metrics.AccountError.WithLabelValues(n.payAccount.Address().String(), "balance_reserved").Inc()
+		log.Errorw("insufficient balance for the bids in flight", "balance", n.payAccountBalance.Load().String(),
+			"reserved", n.reserved.reserved().String(), "builderFee", builderFee.String())
+
+		return nil, errors.New("insufficient balance for the bids in flight")
+	}
+
 	payBidTx, err := signedTx.MarshalBinary()
 	if err != nil {
 		log.Errorw("failed to marshal pay bid tx", "err", err)
@@ -260,3 +790,31 @@ func (n *validator) GeneratePayBidTx(_ context.Context, builder common.Address,
 
 	return payBidTx, nil
 }
+
+func (n *validator) ReleasePayBidTx(payBidTx hexutil.Bytes) {
+	if n.reserved == nil || len(payBidTx) == 0 {
+		return
+	}
+
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(payBidTx); err != nil {
+		log.Errorw("failed to decode pay bid tx", "err", err)
+		return
+	}
+
+	n.reserved.release(tx.Hash())
+}
+
+// withUpstreamTimeout bounds the context by upstreamTimeout unless it has a deadline already.
+func withUpstreamTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok {
+		return ctx, func() {}
+	}
+
+	return context.WithTimeout(ctx, upstreamTimeout)
+}
+
+// observeUpstream records the latency of an rpc call to the validator since start.
+func observeUpstream(validator, method string, start time.Time) {
+	metrics.UpstreamLatencyHist.WithLabelValues(validator, method).Observe(float64(time.Since(start).Milliseconds()))
+}
@@ -0,0 +1,73 @@
+package node
+
+import (
+	"context"
+	"math/big"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/bnb-chain/bsc-mev-sentry/account"
+)
+
+func newBenchValidator(b *testing.B) *validator {
+	key, _ := crypto.GenerateKey()
+	payAccount, err := account.New(&account.Config{
+		Mode:       "privateKey",
+		PrivateKey: common.Bytes2Hex(crypto.FromECDSA(key)),
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	v := &validator{payAccount: payAccount}
+	v.chainID.Store(big.NewInt(56))
+	v.payAccountBalance.Store(new(big.Int).Exp(big.NewInt(10), big.NewInt(24), nil))
+
+	return v
+}
+
+func BenchmarkGeneratePayBidTx(b *testing.B) {
+	v := newBenchValidator(b)
+	builder := common.HexToAddress("0x01")
+	fee := big.NewInt(1e15)
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := v.GeneratePayBidTx(ctx, builder, fee, 1); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkPayAccountNonce measures the nonce read on every bid while it's advanced concurrently.
+func BenchmarkPayAccountNonce(b *testing.B) {
+	v := newBenchValidator(b)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if v.PayAccountNonce()%64 == 0 {
+				atomic.AddUint64(&v.payAccountNonce, 1)
+			}
+		}
+	})
+}
+
+func BenchmarkLeaseNonce(b *testing.B) {
+	v := newBenchValidator(b)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		v.LeaseNonce(uint64(i))
+	}
+}
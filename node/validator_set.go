@@ -0,0 +1,186 @@
+package node
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/bnb-chain/bsc-mev-sentry/log"
+	"github.com/bnb-chain/bsc-mev-sentry/metrics"
+	"github.com/bnb-chain/bsc-mev-sentry/utils"
+)
+
+const (
+	defaultValidatorSetMethod          = "getValidators"
+	defaultValidatorSetRefreshInterval = time.Minute
+)
+
+// ValidatorSetContract is the validator set system contract of BSC.
+var ValidatorSetContract = common.HexToAddress("0x0000000000000000000000000000000000001000")
+
+type ValidatorSetConfig struct {
+	// ChainRPC url of the chain rpc the validator set is read from, disabled if empty
+	ChainRPC string
+	// Contract address of the validator set contract, defaults to the BSC system contract
+	Contract common.Address
+	// Method view method of the contract returning the consensus addresses of the active validators as address[],
+	// defaults to getValidators
+	Method string
+	// RefreshInterval how often the validator set is read, defaults to 1m
+	RefreshInterval utils.Duration
+	// Enforce rejects bids to validators not in the active set, otherwise they're only reported
+	Enforce bool
+}
+
+// ValidatorStatus tells whether a configured validator is in the active validator set.
+type ValidatorStatus struct {
+	PublicHostName   string         `json:"publicHostName"`
+	ConsensusAddress common.Address `json:"consensusAddress"`
+	// Active is nil if the validator has no consensus address or the validator set isn't read yet
+	Active *bool `json:"active"`
+}
+
+// ValidatorSet periodically reads the active validators from the validator set contract on chain.
+type ValidatorSet struct {
+	cfg        ValidatorSetConfig
+	client     *ethclient.Client
+	method     abi.Method
+	validators func() map[common.Address]string // consensus address -> public hostname of the configured validators
+	active     atomic.Pointer[map[common.Address]struct{}]
+	stop       chan struct{}
+	done       chan struct{}
+}
+
+// NewValidatorSet creates a reader of the validator set, whether the validators listed by validators are active
+// is exported as metrics.
+func NewValidatorSet(cfg ValidatorSetConfig, validators func() map[common.Address]string) (*ValidatorSet, error) {
+	if cfg.ChainRPC == "" {
+		return nil, nil
+	}
+
+	if cfg.Contract == (common.Address{}) {
+		cfg.Contract = ValidatorSetContract
+	}
+
+	if cfg.Method == "" {
+		cfg.Method = defaultValidatorSetMethod
+	}
+
+	if cfg.RefreshInterval <= 0 {
+		cfg.RefreshInterval = utils.Duration(defaultValidatorSetRefreshInterval)
+	}
+
+	method, err := newAddressesMethod(cfg.Method)
+	if err != nil {
+		return nil, err
+	}
+
+	cli, err := ethclient.DialOptions(context.Background(), cfg.ChainRPC, rpc.WithHTTPClient(client))
+	if err != nil {
+		return nil, err
+	}
+
+	s := &ValidatorSet{
+		cfg:        cfg,
+		client:     cli,
+		method:     method,
+		validators: validators,
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+
+	s.refresh()
+
+	go s.loop()
+
+	return s, nil
+}
+
+func (s *ValidatorSet) loop() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(time.Duration(s.cfg.RefreshInterval))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.refresh()
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+func (s *ValidatorSet) refresh() {
+	ctx, cancel := context.WithTimeout(context.Background(), registryCallTimeout)
+	defer cancel()
+
+	contract := s.cfg.Contract
+	output, err := s.client.CallContract(ctx, ethereum.CallMsg{To: &contract, Data: s.method.ID}, nil)
+	if err != nil {
+		log.Errorw("failed to read validator set", "contract", contract, "err", err)
+		return
+	}
+
+	values, err := s.method.Outputs.Unpack(output)
+	if err != nil || len(values) != 1 {
+		log.Errorw("failed to decode validator set", "contract", contract, "err", err)
+		return
+	}
+
+	addresses, ok := values[0].([]common.Address)
+	if !ok {
+		log.Errorw("unexpected validator set", "contract", contract, "value", values[0])
+		return
+	}
+
+	active := make(map[common.Address]struct{}, len(addresses))
+	for _, address := range addresses {
+		active[address] = struct{}{}
+	}
+
+	s.active.Store(&active)
+
+	for address, hostname := range s.validators() {
+		_, ok := active[address]
+		if ok {
+			metrics.ValidatorActive.WithLabelValues(hostname).Set(1)
+		} else {
+			metrics.ValidatorActive.WithLabelValues(hostname).Set(0)
+		}
+	}
+}
+
+// Enforced tells whether bids to validators not in the active set are rejected.
+func (s *ValidatorSet) Enforced() bool {
+	return s.cfg.Enforce
+}
+
+// Active tells whether the consensus address is in the active validator set, known is false until the set is read.
+func (s *ValidatorSet) Active(address common.Address) (active, known bool) {
+	set := s.active.Load()
+	if set == nil {
+		return false, false
+	}
+
+	_, active = (*set)[address]
+	return active, true
+}
+
+// Close stops the refresh and releases the connection, it's safe to call on a nil validator set.
+func (s *ValidatorSet) Close() {
+	if s == nil {
+		return
+	}
+
+	close(s.stop)
+	<-s.done
+	s.client.Close()
+}
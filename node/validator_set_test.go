@@ -0,0 +1,44 @@
+package node
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/bsc-mev-sentry/metrics"
+)
+
+func TestValidatorSet(t *testing.T) {
+	active, inactive := common.HexToAddress("0x01"), common.HexToAddress("0x02")
+
+	server := rpc.NewServer()
+	require.NoError(t, server.RegisterName("eth", &registryContract{builders: []common.Address{active}}))
+	srv := httptest.NewServer(server)
+	defer srv.Close()
+
+	set, err := NewValidatorSet(ValidatorSetConfig{ChainRPC: srv.URL}, func() map[common.Address]string {
+		return map[common.Address]string{active: "active", inactive: "inactive"}
+	})
+	require.NoError(t, err)
+	defer set.Close()
+
+	isActive, known := set.Active(active)
+	require.True(t, known)
+	require.True(t, isActive)
+
+	isActive, known = set.Active(inactive)
+	require.True(t, known)
+	require.False(t, isActive)
+
+	require.Equal(t, float64(1), testutil.ToFloat64(metrics.ValidatorActive.WithLabelValues("active")))
+	require.Equal(t, float64(0), testutil.ToFloat64(metrics.ValidatorActive.WithLabelValues("inactive")))
+
+	set, err = NewValidatorSet(ValidatorSetConfig{}, nil)
+	require.NoError(t, err)
+	require.Nil(t, set)
+	set.Close()
+}
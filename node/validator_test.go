@@ -0,0 +1,137 @@
+package node
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/bsc-mev-sentry/account"
+	"github.com/bnb-chain/bsc-mev-sentry/utils"
+)
+
+func TestValidatorBuilderFeeCeil(t *testing.T) {
+	low, high := big.NewInt(100), big.NewInt(200)
+
+	v := &validator{}
+	assert.Equal(t, big.NewInt(0), v.BuilderFeeCeil(), "unknown until mev params are fetched")
+
+	v.mevParams.Store(&types.MevParams{BuilderFeeCeil: high})
+	assert.Equal(t, high, v.BuilderFeeCeil())
+
+	// the configured ceiling caps the one of the mev params
+	v.cfg.BuilderFeeCeil = low
+	assert.Equal(t, low, v.BuilderFeeCeil())
+
+	params, err := v.MevParams(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, low, params.BuilderFeeCeil, "builders see the enforced ceiling")
+	assert.Equal(t, high, v.mevParams.Load().BuilderFeeCeil)
+
+	v.cfg.BuilderFeeCeil = big.NewInt(300)
+	assert.Equal(t, high, v.BuilderFeeCeil())
+
+	v.cfg.OverrideBuilderFeeCeil = true
+	assert.Equal(t, big.NewInt(300), v.BuilderFeeCeil())
+
+	v.mevParams.Store(nil)
+	assert.Equal(t, big.NewInt(300), v.BuilderFeeCeil(), "overridden without mev params")
+}
+
+func TestValidatorStaleMevParams(t *testing.T) {
+	v := &validator{}
+	v.mevParams.Store(&types.MevParams{})
+
+	_, err := v.MevParams(context.Background())
+	assert.NoError(t, err, "never stale without a ttl")
+
+	v.cfg.MevParamsTTL = utils.Duration(time.Minute)
+	_, err = v.MevParams(context.Background())
+	assert.ErrorIs(t, err, ErrStaleMevParams, "stale until fetched")
+
+	v.mevParamsAt.Store(time.Now().UnixNano())
+	_, err = v.MevParams(context.Background())
+	assert.NoError(t, err)
+
+	v.mevParamsAt.Store(time.Now().Add(-2 * time.Minute).UnixNano())
+	_, err = v.MevParams(context.Background())
+	assert.ErrorIs(t, err, ErrStaleMevParams)
+}
+
+func TestGeneratePayBidTxReserveBalance(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	payAccount, err := account.New(&account.Config{
+		Mode:       "privateKey",
+		PrivateKey: common.Bytes2Hex(crypto.FromECDSA(key)),
+	})
+	require.NoError(t, err)
+
+	// two validators paying from the same account share the reserved balance
+	newValidator := func() *validator {
+		v := &validator{payAccount: payAccount, reserved: reservedBalanceOf("test", payAccount.Address())}
+		v.chainID.Store(big.NewInt(56))
+		v.payAccountBalance.Store(big.NewInt(1e18))
+		return v
+	}
+	v1, v2 := newValidator(), newValidator()
+	builder, fee := common.HexToAddress("0x01"), big.NewInt(6e17)
+	ctx := context.Background()
+
+	payBidTx, err := v1.GeneratePayBidTx(ctx, builder, fee, 100)
+	require.NoError(t, err)
+	// the pay bid txs of the same nonce compete for it, only the max cost is reserved
+	competingTx, err := v2.GeneratePayBidTx(ctx, builder, fee, 100)
+	require.NoError(t, err)
+	assert.Equal(t, fee, v1.reserved.reserved())
+	v2.payAccountNonce = 1
+	_, err = v2.GeneratePayBidTx(ctx, builder, fee, 100)
+	assert.Error(t, err, "the balance is reserved for the bid in flight")
+
+	// the bids aren't forwarded
+	v1.ReleasePayBidTx(payBidTx)
+	v2.ReleasePayBidTx(competingTx)
+	_, err = v2.GeneratePayBidTx(ctx, builder, fee, 101)
+	require.NoError(t, err)
+
+	// the balance fetched reflects the block of the bid
+	v1.reserved.settle(100)
+	assert.Equal(t, fee, v1.reserved.reserved())
+	v1.reserved.settle(101)
+	assert.Equal(t, big.NewInt(0), v1.reserved.reserved())
+	_, err = v1.GeneratePayBidTx(ctx, builder, fee, 102)
+	assert.NoError(t, err)
+}
+
+func TestPayBidTxRecipient(t *testing.T) {
+	payKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	otherKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	coinbaseKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	signer := types.LatestSignerForChainID(big.NewInt(56))
+	transfer := func(key *ecdsa.PrivateKey, to common.Address) *types.Transaction {
+		tx, err := types.SignNewTx(key, signer, &types.LegacyTx{GasPrice: big.NewInt(0), Gas: 21000, To: &to})
+		require.NoError(t, err)
+		return tx
+	}
+
+	payAccount, coinbase := crypto.PubkeyToAddress(payKey.PublicKey), crypto.PubkeyToAddress(coinbaseKey.PublicKey)
+	builder, system := common.HexToAddress("0xb1"), common.HexToAddress("0x1000")
+
+	txs := types.Transactions{transfer(otherKey, builder), transfer(payKey, builder), transfer(coinbaseKey, system)}
+	assert.Equal(t, builder, payBidTxRecipient(txs, coinbase, payAccount))
+	assert.Equal(t, common.Address{}, payBidTxRecipient(txs[:1], coinbase, payAccount))
+
+	// a tx of the pay account followed by another one than a system tx isn't a pay bid tx
+	txs = types.Transactions{transfer(payKey, builder), transfer(otherKey, builder), transfer(coinbaseKey, system)}
+	assert.Equal(t, common.Address{}, payBidTxRecipient(txs, coinbase, payAccount))
+}
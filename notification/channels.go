@@ -0,0 +1,178 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strings"
+)
+
+var httpClient = &http.Client{Timeout: sendTimeout}
+
+func postJSON(ctx context.Context, url string, body interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	return nil
+}
+
+type SlackConfig struct {
+	// WebhookURL incoming webhook url of the slack channel
+	WebhookURL string
+}
+
+type slack struct {
+	cfg SlackConfig
+}
+
+func newSlack(cfg SlackConfig) *slack {
+	return &slack{cfg: cfg}
+}
+
+func (s *slack) Name() string {
+	return "slack"
+}
+
+func (s *slack) Send(ctx context.Context, n *Notification) error {
+	return postJSON(ctx, s.cfg.WebhookURL, map[string]string{"text": n.Text()})
+}
+
+type TelegramConfig struct {
+	// BotToken token of the telegram bot
+	BotToken string
+	// ChatID id of the chat to send to
+	ChatID string
+}
+
+type telegram struct {
+	cfg TelegramConfig
+}
+
+func newTelegram(cfg TelegramConfig) *telegram {
+	return &telegram{cfg: cfg}
+}
+
+func (t *telegram) Name() string {
+	return "telegram"
+}
+
+func (t *telegram) Send(ctx context.Context, n *Notification) error {
+	url := fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage", t.cfg.BotToken)
+	return postJSON(ctx, url, map[string]string{"chat_id": t.cfg.ChatID, "text": n.Text()})
+}
+
+type PagerDutyConfig struct {
+	// RoutingKey integration key of the pagerduty service
+	RoutingKey string
+}
+
+type pagerDuty struct {
+	cfg PagerDutyConfig
+}
+
+func newPagerDuty(cfg PagerDutyConfig) *pagerDuty {
+	return &pagerDuty{cfg: cfg}
+}
+
+func (p *pagerDuty) Name() string {
+	return "pagerduty"
+}
+
+func (p *pagerDuty) Send(ctx context.Context, n *Notification) error {
+	severity := string(n.Severity)
+	if n.Severity == "" {
+		severity = string(Info)
+	}
+
+	return postJSON(ctx, "https://events.pagerduty.com/v2/enqueue", map[string]interface{}{
+		"routing_key":  p.cfg.RoutingKey,
+		"event_action": "trigger",
+		"payload": map[string]interface{}{
+			"summary":        n.Title,
+			"source":         "bsc-mev-sentry",
+			"severity":       severity,
+			"timestamp":      n.Time,
+			"custom_details": n.Fields,
+		},
+	})
+}
+
+type WebhookConfig struct {
+	// URL receives notifications as json via POST
+	URL string
+}
+
+type webhook struct {
+	cfg WebhookConfig
+}
+
+func newWebhook(cfg WebhookConfig) *webhook {
+	return &webhook{cfg: cfg}
+}
+
+func (w *webhook) Name() string {
+	return "webhook"
+}
+
+func (w *webhook) Send(ctx context.Context, n *Notification) error {
+	return postJSON(ctx, w.cfg.URL, n)
+}
+
+type EmailConfig struct {
+	// SMTPAddr host:port of the smtp server
+	SMTPAddr string
+	Username string
+	Password string
+	From     string
+	To       []string
+}
+
+type email struct {
+	cfg EmailConfig
+}
+
+func newEmail(cfg EmailConfig) *email {
+	return &email{cfg: cfg}
+}
+
+func (e *email) Name() string {
+	return "email"
+}
+
+func (e *email) Send(_ context.Context, n *Notification) error {
+	var auth smtp.Auth
+	if e.cfg.Username != "" {
+		host, _, err := net.SplitHostPort(e.cfg.SMTPAddr)
+		if err != nil {
+			return err
+		}
+		auth = smtp.PlainAuth("", e.cfg.Username, e.cfg.Password, host)
+	}
+
+	msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: [bsc-mev-sentry] %s\r\n\r\n%s\r\n",
+		e.cfg.From, strings.Join(e.cfg.To, ", "), n.Title, n.Text())
+
+	return smtp.SendMail(e.cfg.SMTPAddr, auth, e.cfg.From, e.cfg.To, []byte(msg))
+}
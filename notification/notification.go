@@ -0,0 +1,280 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/bnb-chain/bsc-mev-sentry/log"
+	"github.com/bnb-chain/bsc-mev-sentry/metrics"
+	"github.com/bnb-chain/bsc-mev-sentry/utils"
+)
+
+const sendTimeout = 10 * time.Second
+
+type Severity string
+
+const (
+	Info     Severity = "info"
+	Warning  Severity = "warning"
+	Critical Severity = "critical"
+)
+
+// Notification is an operational event pushed to operators.
+type Notification struct {
+	Title    string            `json:"title"`
+	Message  string            `json:"message"`
+	Severity Severity          `json:"severity"`
+	Fields   map[string]string `json:"fields,omitempty"`
+	Time     time.Time         `json:"time"`
+}
+
+// Text renders the notification as plain text.
+func (n *Notification) Text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[%s] %s", strings.ToUpper(string(n.Severity)), n.Title)
+	if n.Message != "" {
+		fmt.Fprintf(&b, "\n%s", n.Message)
+	}
+
+	keys := make([]string, 0, len(n.Fields))
+	for k := range n.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		fmt.Fprintf(&b, "\n%s: %s", k, n.Fields[k])
+	}
+
+	return b.String()
+}
+
+// key identifies the notification apart from its time, equal ones are deduplicated.
+func (n *Notification) key() string {
+	return fmt.Sprint(n.Severity, n.Title, n.Message, n.Fields)
+}
+
+// Channel delivers notifications to somewhere operators watch.
+type Channel interface {
+	Name() string
+	Send(ctx context.Context, n *Notification) error
+}
+
+type Config struct {
+	Slack     SlackConfig
+	Telegram  TelegramConfig
+	PagerDuty PagerDutyConfig
+	Webhook   WebhookConfig
+	Email     EmailConfig
+
+	// DedupInterval drops the notifications equal to one sent within the interval, e.g. of a validator flapping
+	DedupInterval utils.Duration
+	// MaxPerMinute caps the notifications sent each minute, the dropped ones are counted in a notification at the
+	// end of the minute, unlimited if 0
+	MaxPerMinute int
+}
+
+// Notifier delivers notifications to all configured channels.
+type Notifier struct {
+	channels []Channel
+
+	dedupInterval time.Duration
+	maxPerMinute  int
+
+	mu          sync.Mutex
+	sent        map[string]time.Time // the last time each notification was sent, by its key
+	window      time.Time            // start of the minute the notifications are counted in
+	windowCount int
+	dropped     int
+	flush       *time.Timer // reports the dropped ones at the end of the minute if no notification comes by then
+}
+
+func NewNotifier(channels ...Channel) *Notifier {
+	return &Notifier{channels: channels, sent: make(map[string]time.Time)}
+}
+
+// NewNotifierFromConfig creates a notifier with the channels enabled in config.
+func NewNotifierFromConfig(cfg *Config) *Notifier {
+	var channels []Channel
+	if cfg.Slack.WebhookURL != "" {
+		channels = append(channels, newSlack(cfg.Slack))
+	}
+	if cfg.Telegram.BotToken != "" {
+		channels = append(channels, newTelegram(cfg.Telegram))
+	}
+	if cfg.PagerDuty.RoutingKey != "" {
+		channels = append(channels, newPagerDuty(cfg.PagerDuty))
+	}
+	if cfg.Webhook.URL != "" {
+		channels = append(channels, newWebhook(cfg.Webhook))
+	}
+	if cfg.Email.SMTPAddr != "" {
+		channels = append(channels, newEmail(cfg.Email))
+	}
+
+	n := NewNotifier(channels...)
+	n.dedupInterval = time.Duration(cfg.DedupInterval)
+	n.maxPerMinute = cfg.MaxPerMinute
+
+	return n
+}
+
+// Notify sends the notification to all channels asynchronously, failures are only logged.
+func (n *Notifier) Notify(notification *Notification) {
+	if notification.Time.IsZero() {
+		notification.Time = time.Now()
+	}
+
+	allowed, dropped := n.allow(notification)
+	if dropped > 0 {
+		n.sendDropped(dropped, notification.Time)
+	}
+	if allowed {
+		n.send(notification)
+	}
+}
+
+// sendDropped sends the count of the notifications dropped by the rate limit.
+func (n *Notifier) sendDropped(dropped int, at time.Time) {
+	n.send(&Notification{
+		Title:    "notifications rate limited",
+		Message:  fmt.Sprintf("%d notifications dropped in the last minute", dropped),
+		Severity: Warning,
+		Time:     at,
+	})
+}
+
+// flushDropped sends the count of the notifications dropped in a minute no notification followed.
+func (n *Notifier) flushDropped() {
+	n.mu.Lock()
+	dropped := n.dropped
+	n.dropped, n.flush = 0, nil
+	n.mu.Unlock()
+
+	if dropped > 0 {
+		n.sendDropped(dropped, time.Now())
+	}
+}
+
+// allow tells whether the notification is sent, and how many were dropped by the rate limit of the minute before if
+// it's the first one of a minute.
+func (n *Notifier) allow(notification *Notification) (bool, int) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	var dropped int
+	if notification.Time.Sub(n.window) >= time.Minute {
+		n.window, n.windowCount, dropped, n.dropped = notification.Time, 0, n.dropped, 0
+		if n.flush != nil {
+			n.flush.Stop()
+			n.flush = nil
+		}
+
+		for key, sent := range n.sent {
+			if notification.Time.Sub(sent) >= n.dedupInterval {
+				delete(n.sent, key)
+			}
+		}
+	}
+
+	if n.dedupInterval > 0 {
+		key := notification.key()
+		if sent, ok := n.sent[key]; ok && notification.Time.Sub(sent) < n.dedupInterval {
+			metrics.NotificationCounter.WithLabelValues("deduplicated").Inc()
+			return false, dropped
+		}
+		n.sent[key] = notification.Time
+	}
+
+	if n.maxPerMinute > 0 {
+		if n.windowCount >= n.maxPerMinute {
+			metrics.NotificationCounter.WithLabelValues("rate_limited").Inc()
+			n.dropped++
+			if n.flush == nil {
+				n.flush = time.AfterFunc(time.Until(n.window.Add(time.Minute)), n.flushDropped)
+			}
+			return false, dropped
+		}
+		n.windowCount++
+	}
+
+	metrics.NotificationCounter.WithLabelValues("sent").Inc()
+	return true, dropped
+}
+
+func (n *Notifier) send(notification *Notification) {
+	for _, ch := range n.channels {
+		go func(ch Channel) {
+			ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+			defer cancel()
+
+			if err := ch.Send(ctx, notification); err != nil {
+				log.Errorw("failed to send notification", "channel", ch.Name(), "title", notification.Title, "err", err)
+			}
+		}(ch)
+	}
+}
+
+var notifier atomic.Pointer[Notifier]
+
+func init() {
+	notifier.Store(NewNotifier())
+}
+
+// Init sets the notifier used by Notify, notifications are dropped until initialized. The notifier replaced passes
+// on what it has sent and dropped lately, so that a config reload doesn't send the notifications deduplicated again
+// nor lose the count of the dropped ones.
+func Init(n *Notifier) {
+	if old := notifier.Swap(n); old != nil && old != n {
+		n.inherit(old)
+	}
+}
+
+// inherit takes over the dedup and rate limit state of the old notifier.
+func (n *Notifier) inherit(old *Notifier) {
+	old.mu.Lock()
+	sent, window, windowCount, dropped := old.sent, old.window, old.windowCount, old.dropped
+	old.sent, old.dropped = make(map[string]time.Time), 0
+	if old.flush != nil {
+		old.flush.Stop()
+		old.flush = nil
+	}
+	old.mu.Unlock()
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	for key, at := range sent {
+		if at.After(n.sent[key]) {
+			n.sent[key] = at
+		}
+	}
+
+	if window.After(n.window) {
+		n.window, n.windowCount = window, windowCount+n.windowCount
+	}
+	n.dropped += dropped
+	if n.dropped > 0 && n.flush == nil {
+		n.flush = time.AfterFunc(time.Until(n.window.Add(time.Minute)), n.flushDropped)
+	}
+}
+
+// Notify sends the notification with the notifier set by Init.
+func Notify(severity Severity, title, message string, kvs ...string) {
+	fields := make(map[string]string, len(kvs)/2)
+	for i := 0; i+1 < len(kvs); i += 2 {
+		fields[kvs[i]] = kvs[i+1]
+	}
+
+	notifier.Load().Notify(&Notification{
+		Title:    title,
+		Message:  message,
+		Severity: severity,
+		Fields:   fields,
+	})
+}
@@ -0,0 +1,148 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotificationText(t *testing.T) {
+	n := &Notification{
+		Title:    "validator down",
+		Message:  "connection refused",
+		Severity: Critical,
+		Fields:   map[string]string{"validator": "bsc-fuji", "address": "0x01"},
+	}
+
+	assert.Equal(t, "[CRITICAL] validator down\nconnection refused\naddress: 0x01\nvalidator: bsc-fuji", n.Text())
+}
+
+func TestNotifierWebhook(t *testing.T) {
+	received := make(chan Notification, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var n Notification
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&n))
+		received <- n
+	}))
+	defer srv.Close()
+
+	notifier := NewNotifierFromConfig(&Config{Webhook: WebhookConfig{URL: srv.URL}})
+	notifier.Notify(&Notification{Title: "low balance", Severity: Warning})
+
+	select {
+	case n := <-received:
+		require.Equal(t, "low balance", n.Title)
+		require.Equal(t, Warning, n.Severity)
+		require.False(t, n.Time.IsZero())
+	case <-time.After(5 * time.Second):
+		t.Fatal("notification not received")
+	}
+}
+
+type recordingChannel struct {
+	sent chan *Notification
+}
+
+func (c *recordingChannel) Name() string {
+	return "recording"
+}
+
+func (c *recordingChannel) Send(_ context.Context, n *Notification) error {
+	c.sent <- n
+	return nil
+}
+
+func TestNotifierDedupAndRateLimit(t *testing.T) {
+	ch := &recordingChannel{sent: make(chan *Notification, 10)}
+	notifier := NewNotifier(ch)
+	notifier.dedupInterval = time.Minute
+	notifier.maxPerMinute = 2
+
+	now := time.Now()
+	notify := func(title string, at time.Duration) {
+		notifier.Notify(&Notification{Title: title, Severity: Warning, Time: now.Add(at)})
+	}
+
+	notify("validator down", 0)
+	notify("validator down", time.Second) // deduplicated
+	notify("builder banned", 2*time.Second)
+	notify("low balance", 3*time.Second) // rate limited
+	notify("validator down", 2*time.Minute)
+
+	var titles []string
+	for i := 0; i < 4; i++ {
+		select {
+		case n := <-ch.sent:
+			titles = append(titles, n.Title)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("got %v, want 4 notifications", titles)
+		}
+	}
+
+	// the notifications are sent concurrently
+	assert.ElementsMatch(t, []string{"validator down", "builder banned", "notifications rate limited",
+		"validator down"}, titles)
+
+	select {
+	case n := <-ch.sent:
+		t.Fatalf("unexpected notification %s", n.Title)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestNotifierFlushesDropped(t *testing.T) {
+	ch := &recordingChannel{sent: make(chan *Notification, 10)}
+	notifier := NewNotifier(ch)
+	notifier.maxPerMinute = 1
+
+	// the minute ends shortly
+	start := time.Now().Add(-time.Minute + 100*time.Millisecond)
+	notifier.Notify(&Notification{Title: "validator down", Severity: Warning, Time: start})
+	notifier.Notify(&Notification{Title: "low balance", Severity: Warning, Time: start.Add(time.Second)})
+
+	// the dropped one is counted at the end of the minute, with no notification following
+	var titles []string
+	for i := 0; i < 2; i++ {
+		select {
+		case n := <-ch.sent:
+			titles = append(titles, n.Title)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("got %v, want 2 notifications", titles)
+		}
+	}
+	assert.Equal(t, []string{"validator down", "notifications rate limited"}, titles)
+}
+
+func TestInitKeepsDedup(t *testing.T) {
+	ch := &recordingChannel{sent: make(chan *Notification, 10)}
+	newNotifier := func() *Notifier {
+		n := NewNotifier(ch)
+		n.dedupInterval = time.Minute
+		return n
+	}
+	// Init would pass the state on
+	defer notifier.Store(NewNotifier())
+
+	Init(newNotifier())
+	Notify(Warning, "validator down", "")
+	select {
+	case <-ch.sent:
+	case <-time.After(5 * time.Second):
+		t.Fatal("notification not sent")
+	}
+
+	// a reload replaces the notifier, the notification sent before is still deduplicated
+	Init(newNotifier())
+	Notify(Warning, "validator down", "")
+	select {
+	case n := <-ch.sent:
+		t.Fatalf("unexpected notification %s", n.Title)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -0,0 +1,92 @@
+package routing
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bnb-chain/bsc-mev-sentry/node"
+)
+
+// sources of the validator a request is routed to
+const (
+	// Header the value of the configured routing header
+	Header = "header"
+	// Host the host the request is sent to
+	Host = "host"
+	// SNI the server name of the tls handshake, only of the native tls listener
+	SNI = "sni"
+)
+
+type Config struct {
+	// Header names the header carrying the public hostname of the target validator, e.g. X-Target-Validator,
+	// header routing is disabled if empty
+	Header string
+	// Order sources tried in order until one names a validator, defaults to header then host
+	Order []string
+	// ProposerHostName generic hostname whose requests go to the validator proposing the next block, e.g.
+	// bsc-mev, disabled if empty
+	ProposerHostName string
+	// ProposerSchedule reads the proposer in turn from the validator set snapshot of the chain
+	ProposerSchedule node.ProposerScheduleConfig
+}
+
+// Sources returns the sources in the order they're tried.
+func (c Config) Sources() []string {
+	if len(c.Order) > 0 {
+		return c.Order
+	}
+
+	if c.Header != "" {
+		return []string{Header, Host}
+	}
+
+	return []string{Host}
+}
+
+// Validate checks the sources are known and configured.
+func (c Config) Validate() error {
+	for _, source := range c.Sources() {
+		switch source {
+		case Header:
+			if c.Header == "" {
+				return fmt.Errorf("routing: Header is required to route by %s", source)
+			}
+		case Host, SNI:
+		default:
+			return fmt.Errorf("routing: unknown source %s", source)
+		}
+	}
+
+	if c.ProposerHostName != "" && c.ProposerSchedule.ChainRPC == "" {
+		return fmt.Errorf("routing: ProposerSchedule.ChainRPC is required to route by proposer")
+	}
+
+	return nil
+}
+
+type (
+	headerContextKey struct{}
+	sniContextKey    struct{}
+)
+
+// WithHeader adds the value of the routing header to the request context.
+func WithHeader(ctx context.Context, value string) context.Context {
+	return context.WithValue(ctx, headerContextKey{}, value)
+}
+
+// HeaderFromContext returns the value of the routing header of the request, empty if not given.
+func HeaderFromContext(ctx context.Context) string {
+	value, _ := ctx.Value(headerContextKey{}).(string)
+	return value
+}
+
+// WithSNI adds the server name of the tls handshake to the request context.
+func WithSNI(ctx context.Context, serverName string) context.Context {
+	return context.WithValue(ctx, sniContextKey{}, serverName)
+}
+
+// SNIFromContext returns the server name of the tls handshake of the request, empty if not given.
+func SNIFromContext(ctx context.Context) string {
+	serverName, _ := ctx.Value(sniContextKey{}).(string)
+	return serverName
+}
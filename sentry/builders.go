@@ -0,0 +1,125 @@
+package sentry
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bnb-chain/bsc-mev-sentry/config"
+	"github.com/bnb-chain/bsc-mev-sentry/lifecycle"
+	"github.com/bnb-chain/bsc-mev-sentry/log"
+	"github.com/bnb-chain/bsc-mev-sentry/metrics"
+)
+
+// BuilderAdmin reloads the builders from the config file on demand, served as admin_reloadBuilders next to the
+// methods of service.MevAdmin.
+type BuilderAdmin struct {
+	sentry *Sentry
+}
+
+// ReloadBuilders swaps in the builders of the config file without touching the validators, and returns their
+// addresses. An invalid config is rejected and the running builders are kept.
+func (a *BuilderAdmin) ReloadBuilders(_ context.Context) ([]common.Address, error) {
+	if a.sentry.configPath == "" {
+		return nil, errors.New("sentry is not run with a config file")
+	}
+
+	cfg, err := a.sentry.reloadBuilders()
+	if err != nil {
+		return nil, err
+	}
+
+	addresses := make([]common.Address, 0, len(cfg.Builders))
+	for _, b := range cfg.Builders {
+		addresses = append(addresses, b.Address)
+	}
+	sort.Slice(addresses, func(i, j int) bool {
+		return bytes.Compare(addresses[i][:], addresses[j][:]) < 0
+	})
+
+	return addresses, nil
+}
+
+// reloadBuilders reads the config file and swaps in its builders.
+func (s *Sentry) reloadBuilders() (*config.Config, error) {
+	cfg, err := config.Read(s.configPath)
+	if err == nil {
+		err = s.override(cfg)
+	}
+	if err == nil {
+		err = s.service.UpdateBuilders(cfg.Builders)
+	}
+
+	if err != nil {
+		metrics.ConfigReloadCounter.WithLabelValues("failure").Inc()
+		log.Errorw("failed to reload builders, keep the running ones", "configPath", s.configPath, "err", err)
+		return nil, err
+	}
+
+	metrics.ConfigReloadCounter.WithLabelValues("success").Inc()
+	log.Infow("builders reloaded", "configPath", s.configPath, "builder_count", len(cfg.Builders))
+
+	return cfg, nil
+}
+
+// addBuilderWatcher adds a component polling the config file for changes and reloading the builders once it's
+// changed. Polling keeps working when the file is replaced rather than written to, e.g. a mounted Kubernetes
+// ConfigMap.
+func (s *Sentry) addBuilderWatcher(interval time.Duration) {
+	stop := make(chan struct{})
+	done := make(chan struct{})
+
+	s.manager.Add(lifecycle.Component{
+		Name: "builder-watcher",
+		Start: func() error {
+			last, err := os.Stat(s.configPath)
+			if err != nil {
+				return err
+			}
+
+			go func() {
+				defer close(done)
+
+				ticker := time.NewTicker(interval)
+				defer ticker.Stop()
+
+				for {
+					select {
+					case <-stop:
+						return
+					case <-ticker.C:
+					}
+
+					info, err := os.Stat(s.configPath)
+					if err != nil {
+						log.Errorw("failed to stat config file", "configPath", s.configPath, "err", err)
+						continue
+					}
+					if info.ModTime().Equal(last.ModTime()) && info.Size() == last.Size() {
+						continue
+					}
+
+					last = info
+					_, _ = s.reloadBuilders()
+				}
+			}()
+
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			close(stop)
+
+			select {
+			case <-done:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+	})
+}
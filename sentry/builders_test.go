@@ -0,0 +1,63 @@
+package sentry
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/bsc-mev-sentry/lifecycle"
+	"github.com/bnb-chain/bsc-mev-sentry/service"
+)
+
+func TestReloadBuilders(t *testing.T) {
+	builder1 := common.HexToAddress("0x980A75eCd1309eA12fa2ED87A8744fBfc9b863D5")
+	builder2 := common.HexToAddress("0x45EbEBe8E4b2cF6a1F1B1b9f30A1E9C664D59c12")
+
+	file := filepath.Join(t.TempDir(), "config.toml")
+	writeBuilders := func(addresses ...common.Address) {
+		content := ""
+		for _, address := range addresses {
+			content += "[[Builders]]\nAddress = \"" + address.Hex() + "\"\nURL = \"http://127.0.0.1:1\"\n"
+		}
+		require.NoError(t, os.WriteFile(file, []byte(content), 0o600))
+	}
+	writeBuilders(builder1)
+
+	s, err := New(WithConfigFile(file), WithoutLogger())
+	require.NoError(t, err)
+	defer s.Close()
+
+	ctx := context.Background()
+	admin := service.NewMevAdmin(s.service)
+	assert.Equal(t, []common.Address{builder1}, admin.Builders(ctx))
+
+	writeBuilders(builder1, builder2)
+	addresses, err := (&BuilderAdmin{sentry: s}).ReloadBuilders(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []common.Address{builder2, builder1}, addresses)
+	assert.ElementsMatch(t, addresses, admin.Builders(ctx))
+
+	// an invalid config keeps the running builders
+	require.NoError(t, os.WriteFile(file, []byte("[[Builders]]\nURL = \"http://127.0.0.1:1\"\n"), 0o600))
+	_, err = (&BuilderAdmin{sentry: s}).ReloadBuilders(ctx)
+	assert.Error(t, err)
+	assert.Len(t, admin.Builders(ctx), 2)
+
+	s.manager = lifecycle.NewManager()
+	defer func() { s.manager = nil }()
+	s.addBuilderWatcher(10 * time.Millisecond)
+	require.NoError(t, s.manager.Start())
+	defer s.manager.Stop()
+
+	writeBuilders(builder2)
+	assert.Eventually(t, func() bool {
+		builders := admin.Builders(ctx)
+		return len(builders) == 1 && builders[0] == builder2
+	}, time.Second, 10*time.Millisecond)
+}
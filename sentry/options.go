@@ -0,0 +1,84 @@
+package sentry
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+
+	"github.com/bnb-chain/bsc-mev-sentry/config"
+	"github.com/bnb-chain/bsc-mev-sentry/node"
+	"github.com/bnb-chain/bsc-mev-sentry/notification"
+)
+
+// Option customizes a sentry created by New.
+type Option func(*Sentry)
+
+// WithConfig uses the given config instead of loading it from a file.
+func WithConfig(cfg *config.Config) Option {
+	return func(s *Sentry) {
+		s.cfg = cfg
+	}
+}
+
+// WithConfigFile loads the config from the file, which is reloaded on SIGHUP.
+func WithConfigFile(path string) Option {
+	return func(s *Sentry) {
+		s.configPath = path
+	}
+}
+
+// WithConfigOverride overrides the config after it's loaded and on every reload, e.g. with command line flags.
+func WithConfigOverride(override func(cfg *config.Config)) Option {
+	return func(s *Sentry) {
+		s.overrides = append(s.overrides, override)
+	}
+}
+
+// WithMiddleware appends gin middlewares to the service listener, they run after the built-in ones.
+func WithMiddleware(handlers ...gin.HandlerFunc) Option {
+	return func(s *Sentry) {
+		s.middlewares = append(s.middlewares, handlers...)
+	}
+}
+
+// WithAdminMiddleware appends gin middlewares to the admin listener, they run after the built-in ones.
+func WithAdminMiddleware(handlers ...gin.HandlerFunc) Option {
+	return func(s *Sentry) {
+		s.adminMiddlewares = append(s.adminMiddlewares, handlers...)
+	}
+}
+
+// WithRPCService registers an extra JSON-RPC namespace on the service listener.
+func WithRPCService(namespace string, receiver interface{}) Option {
+	return func(s *Sentry) {
+		s.rpcServices[namespace] = receiver
+	}
+}
+
+// WithValidators uses the given validators instead of creating them from the config.
+func WithValidators(validators map[string]node.Validator) Option {
+	return func(s *Sentry) {
+		s.validators = validators
+	}
+}
+
+// WithBuilders uses the given builders instead of creating them from the config.
+func WithBuilders(builders map[common.Address]node.Builder) Option {
+	return func(s *Sentry) {
+		s.builders = builders
+	}
+}
+
+// WithNotifier uses the given notifier instead of creating it from the config.
+func WithNotifier(notifier *notification.Notifier) Option {
+	return func(s *Sentry) {
+		s.notifier = notifier
+	}
+}
+
+// WithoutLogger keeps the logger as is instead of initializing it from the config, for
+// binaries initializing the logger themselves.
+func WithoutLogger() Option {
+	return func(s *Sentry) {
+		s.skipLogger = true
+	}
+}
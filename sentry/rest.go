@@ -0,0 +1,116 @@
+package sentry
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/gin-gonic/gin"
+
+	"github.com/bnb-chain/bsc-mev-sentry/service"
+)
+
+// validatorStatus is a validator as listed by the REST API.
+type validatorStatus struct {
+	Hostname string `json:"hostname"`
+	Running  bool   `json:"running"`
+}
+
+// registerREST serves the read-only REST API under /v1, backed by the same service methods as JSON-RPC. Writes
+// stay JSON-RPC only.
+func (s *Sentry) registerREST(app *gin.Engine) {
+	v1 := app.Group("/v1")
+	v1.GET("/bids", s.restBids)
+	v1.GET("/validators", s.restValidators)
+	v1.GET("/params/:host", s.restParams)
+}
+
+// restBids serves the signed bid history query of a builder as mev_bidHistory, the filter in the query string.
+func (s *Sentry) restBids(c *gin.Context) {
+	var (
+		args service.BidHistoryArgs
+		err  error
+	)
+
+	query := func(key string, parse func(string) error) {
+		if v := c.Query(key); v != "" && err == nil {
+			if err = parse(v); err != nil {
+				err = &restQueryError{key: key}
+			}
+		}
+	}
+	query("fromBlock", func(v string) (err error) { args.FromBlock, err = strconv.ParseUint(v, 10, 64); return })
+	query("toBlock", func(v string) (err error) { args.ToBlock, err = strconv.ParseUint(v, 10, 64); return })
+	query("fromTime", func(v string) (err error) { args.FromTime, err = strconv.ParseInt(v, 10, 64); return })
+	query("toTime", func(v string) (err error) { args.ToTime, err = strconv.ParseInt(v, 10, 64); return })
+	query("cursor", func(v string) (err error) { args.Cursor, err = strconv.ParseInt(v, 10, 64); return })
+	query("limit", func(v string) (err error) { args.Limit, err = strconv.Atoi(v); return })
+	query("timestamp", func(v string) (err error) { args.Timestamp, err = strconv.ParseInt(v, 10, 64); return })
+	query("signature", func(v string) (err error) { args.Signature, err = hexutil.Decode(v); return })
+	args.Outcome = c.Query("outcome")
+
+	if err != nil {
+		restError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	page, err := s.service.BidHistory(c.Request.Context(), args)
+	if err != nil {
+		restError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// restValidators lists the validators and whether they run mev, as mev_running tells for each of them.
+func (s *Sentry) restValidators(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	hostnames := service.NewMevAdmin(s.service).Validators(ctx)
+	validators := make([]validatorStatus, 0, len(hostnames))
+	for _, hostname := range hostnames {
+		running, _ := s.service.Running(service.WithTarget(ctx, hostname))
+		validators = append(validators, validatorStatus{Hostname: hostname, Running: running})
+	}
+
+	c.JSON(http.StatusOK, validators)
+}
+
+// restParams serves the mev params of the validator named in the path, as mev_params sent to its host.
+func (s *Sentry) restParams(c *gin.Context) {
+	ctx := c.Request.Context()
+	hostname := c.Param("host")
+
+	if _, err := service.NewMevAdmin(s.service).ValidatorCapabilities(ctx, hostname); err != nil {
+		restError(c, http.StatusNotFound, err)
+		return
+	}
+
+	params, err := s.service.Params(service.WithTarget(ctx, hostname))
+	if err != nil {
+		restError(c, http.StatusBadGateway, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, params)
+}
+
+type restQueryError struct {
+	key string
+}
+
+func (e *restQueryError) Error() string {
+	return "invalid query parameter " + e.key
+}
+
+// restError answers the error as JSON, with its JSON-RPC error code if it has one.
+func restError(c *gin.Context, status int, err error) {
+	body := gin.H{"error": err.Error()}
+	if rpcErr, ok := err.(rpc.Error); ok {
+		body["code"] = rpcErr.ErrorCode()
+	}
+
+	c.AbortWithStatusJSON(status, body)
+}
@@ -0,0 +1,90 @@
+package sentry
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/bsc-mev-sentry/auth"
+	"github.com/bnb-chain/bsc-mev-sentry/config"
+	"github.com/bnb-chain/bsc-mev-sentry/node"
+	"github.com/bnb-chain/bsc-mev-sentry/service"
+)
+
+type restValidator struct {
+	node.Validator
+}
+
+func (v *restValidator) Capabilities() node.Capabilities { return node.Capabilities{} }
+func (v *restValidator) MevRunning() bool                { return true }
+func (v *restValidator) Config() node.ValidatorConfig    { return node.ValidatorConfig{} }
+func (v *restValidator) Health() node.Health {
+	return node.Health{Reachable: true, PayAccountFunded: true, NonceHealthy: true}
+}
+func (v *restValidator) MevParams(context.Context) (*types.MevParams, error) {
+	return &types.MevParams{GasCeil: 100}, nil
+}
+
+func TestREST(t *testing.T) {
+	s := &Sentry{service: service.NewMevSentry(&service.Config{},
+		map[string]node.Validator{"bsc-fuji": &restValidator{}}, nil)}
+	defer s.service.Close()
+
+	app := gin.New()
+	s.registerREST(app)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	w := get("/v1/validators")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `[{"hostname": "bsc-fuji", "running": true}]`, w.Body.String())
+
+	w = get("/v1/params/bsc-fuji")
+	require.Equal(t, http.StatusOK, w.Code)
+	var params types.MevParams
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &params))
+	assert.Equal(t, uint64(100), params.GasCeil)
+
+	assert.Equal(t, http.StatusNotFound, get("/v1/params/bsc-chapel").Code)
+	assert.Equal(t, http.StatusBadRequest, get("/v1/bids?fromBlock=x").Code)
+
+	// the bid store is disabled
+	assert.Equal(t, http.StatusBadRequest, get("/v1/bids").Code)
+}
+
+func TestReadyWithoutCredentials(t *testing.T) {
+	secretFile := filepath.Join(t.TempDir(), "jwt.secret")
+	require.NoError(t, os.WriteFile(secretFile, []byte("secret"), 0o600))
+
+	cfg := &config.Config{}
+	cfg.Service.JWT = auth.JWTConfig{Enabled: true, Algorithm: "HS256", SecretFile: secretFile}
+	s := &Sentry{cfg: cfg, service: service.NewMevSentry(&service.Config{},
+		map[string]node.Validator{"bsc-fuji": &restValidator{}}, nil)}
+	defer s.service.Close()
+
+	handler, err := s.serviceHandler(rpc.NewServer())
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{}`)))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
@@ -0,0 +1,531 @@
+// Package sentry runs a mev sentry, it lets operators build custom binaries embedding the sentry
+// with extra middlewares and services instead of forking cmd/main.go.
+package sentry
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	_ "net/http/pprof"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/gin-gonic/contrib/gzip"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/bnb-chain/bsc-mev-sentry/auth"
+	"github.com/bnb-chain/bsc-mev-sentry/config"
+	"github.com/bnb-chain/bsc-mev-sentry/errreport"
+	ginutils "github.com/bnb-chain/bsc-mev-sentry/gin"
+	"github.com/bnb-chain/bsc-mev-sentry/lifecycle"
+	"github.com/bnb-chain/bsc-mev-sentry/log"
+	"github.com/bnb-chain/bsc-mev-sentry/metrics"
+	"github.com/bnb-chain/bsc-mev-sentry/node"
+	"github.com/bnb-chain/bsc-mev-sentry/notification"
+	"github.com/bnb-chain/bsc-mev-sentry/service"
+	"github.com/bnb-chain/bsc-mev-sentry/tlsutil"
+	"github.com/bnb-chain/bsc-mev-sentry/version"
+)
+
+const serviceName = "bsc-mev-sentry"
+
+type Sentry struct {
+	cfg        *config.Config
+	configPath string
+	overrides  []func(cfg *config.Config)
+	skipLogger bool
+
+	middlewares      []gin.HandlerFunc
+	adminMiddlewares []gin.HandlerFunc
+	rpcServices      map[string]interface{}
+
+	validators map[string]node.Validator
+	builders   map[common.Address]node.Builder
+	notifier   *notification.Notifier
+
+	service *service.MevSentry
+	manager *lifecycle.Manager
+}
+
+// New creates a sentry, either WithConfig or WithConfigFile is required.
+func New(opts ...Option) (*Sentry, error) {
+	s := &Sentry{rpcServices: make(map[string]interface{})}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if s.cfg == nil {
+		if s.configPath == "" {
+			return nil, errors.New("config is required")
+		}
+
+		cfg, err := config.Read(s.configPath)
+		if err != nil {
+			return nil, err
+		}
+		s.cfg = cfg
+	}
+
+	if err := s.override(s.cfg); err != nil {
+		return nil, err
+	}
+
+	if !s.skipLogger {
+		lvl, _ := log.ParseLevel(s.cfg.Log.Level)
+		log.InitRotated(lvl, log.StandardizePath(s.cfg.Log.RootDir, serviceName), s.cfg.Log.Rotate())
+		log.SetSampling(s.cfg.Log.SamplingConfig())
+	}
+
+	if s.notifier == nil {
+		s.notifier = notification.NewNotifierFromConfig(&s.cfg.Notification)
+	}
+	notification.Init(s.notifier)
+
+	node.ConfigureTransport(s.cfg.Transport)
+
+	// the validators given by an option belong to the caller, the ones created here are stopped on failure
+	ownValidators := s.validators == nil
+	if ownValidators {
+		s.validators = make(map[string]node.Validator)
+		for _, v := range s.cfg.Validators {
+			validator, err := node.NewValidator(v)
+			if err != nil {
+				for _, created := range s.validators {
+					created.Stop()
+				}
+				return nil, fmt.Errorf("failed to create validator %s: %w", v.PublicHostName, err)
+			}
+			s.validators[v.PublicHostName] = validator
+		}
+	}
+
+	if s.builders == nil {
+		s.builders = make(map[common.Address]node.Builder)
+		for _, b := range s.cfg.Builders {
+			builder, err := node.NewBuilder(b)
+			if err != nil {
+				for _, created := range s.builders {
+					created.Stop()
+				}
+				if ownValidators {
+					for _, created := range s.validators {
+						created.Stop()
+					}
+				}
+				return nil, fmt.Errorf("failed to create builder %s: %w", b.Address, err)
+			}
+			s.builders[b.Address] = builder
+		}
+	}
+
+	s.service = service.NewMevSentry(&s.cfg.Service, s.validators, s.builders)
+
+	return s, nil
+}
+
+// Service returns the mev service, e.g. to change the topology.
+func (s *Sentry) Service() *service.MevSentry {
+	return s.service
+}
+
+// Run starts all components and serves until ctx is done or any component fails, then stops the
+// components in the reverse order.
+func (s *Sentry) Run(ctx context.Context) error {
+	cfg := s.cfg
+
+	info := version.Get()
+	metrics.BuildInfo.WithLabelValues(info.Version, info.Commit, info.BuildDate, info.GoVersion).Set(1)
+
+	log.Infow("bsc mev-sentry start", "configPath", s.configPath, "version", info.Version, "commit", info.Commit,
+		"validator_count", len(s.validators), "builder_count", len(s.builders))
+
+	receivers := map[string]interface{}{"mev": s.service}
+	if s.cfg.Service.BundleAdapter {
+		receivers["eth"] = service.NewBundleAdapter(s.service)
+	}
+	for namespace, receiver := range s.rpcServices {
+		receivers[namespace] = receiver
+	}
+
+	rpcServer := rpc.NewServer()
+	for namespace, receiver := range receivers {
+		if err := rpcServer.RegisterName(namespace, receiver); err != nil {
+			return err
+		}
+	}
+
+	// rpc_discover sits next to the rpc_modules of the rpc server
+	if err := rpcServer.RegisterName("rpc", service.NewDiscovery(&s.cfg.Service, receivers)); err != nil {
+		return err
+	}
+
+	app, err := s.serviceHandler(rpcServer)
+	if err != nil {
+		return err
+	}
+
+	s.manager = lifecycle.NewManager()
+	m := s.manager
+
+	// first started and last stopped, to report the errors of the other components
+	if cfg.ErrorReport.DSN != "" {
+		reporter, err := errreport.NewReporter(cfg.ErrorReport)
+		if err != nil {
+			return err
+		}
+		m.Add(lifecycle.Component{
+			Name: "error-report",
+			Start: func() error {
+				log.SetHook(reporter.Report)
+				return nil
+			},
+			Stop: func(ctx context.Context) error {
+				log.SetHook(nil)
+				return reporter.Flush(ctx)
+			},
+		})
+	}
+
+	m.Add(lifecycle.Component{
+		Name: "storage",
+		Stop: func(context.Context) error {
+			s.service.Close()
+			return nil
+		},
+	})
+	m.Add(lifecycle.Component{
+		Name: "validators",
+		Stop: func(context.Context) error {
+			s.service.StopValidators()
+			return nil
+		},
+	})
+
+	if cfg.Debug.ListenAddr != "" {
+		s.addServer("debug", &http.Server{Addr: cfg.Debug.ListenAddr}, nil)
+	}
+
+	if cfg.Pushgateway.Enabled {
+		pusher := metrics.NewPusher(cfg.Pushgateway)
+		m.Add(lifecycle.Component{
+			Name:  "pushgateway",
+			Start: pusher.Start,
+			Stop:  pusher.Stop,
+		})
+	}
+
+	if s.configPath != "" {
+		s.addReloader()
+
+		if interval := cfg.Service.BuilderWatchInterval; interval > 0 {
+			s.addBuilderWatcher(time.Duration(interval))
+		}
+	}
+
+	if cfg.Service.AdminListenAddr != "" {
+		if err = s.addAdmin(); err != nil {
+			return err
+		}
+	}
+
+	if cfg.Service.Archive.Backend != "" {
+		m.Add(lifecycle.Component{
+			Name:  "archive",
+			Start: s.service.StartArchive,
+			Stop:  s.service.StopArchive,
+		})
+	}
+
+	if cfg.Service.Cluster.Backend != "" {
+		m.Add(lifecycle.Component{
+			Name:  "cluster",
+			Start: s.service.StartCluster,
+			Stop:  s.service.StopCluster,
+		})
+	}
+
+	if cfg.Service.Election.Backend != "" {
+		m.Add(lifecycle.Component{
+			Name:  "election",
+			Start: s.service.StartElection,
+			Stop:  s.service.StopElection,
+		})
+	}
+
+	if cfg.Service.Failover.Enabled {
+		m.Add(lifecycle.Component{
+			Name:  "failover",
+			Start: s.service.StartFailover,
+			Stop:  s.service.StopFailover,
+		})
+	}
+
+	server := &http.Server{
+		Addr:    cfg.Service.HTTPListenAddr,
+		Handler: app,
+	}
+
+	var tlsConfig *tls.Config
+	if cfg.Service.TLSCertFile != "" && cfg.Service.TLSKeyFile != "" {
+		reloader, err := tlsutil.NewCertReloader(cfg.Service.TLSCertFile, cfg.Service.TLSKeyFile, cfg.Service.TLSClientCAFile)
+		if err != nil {
+			return err
+		}
+
+		// validators don't have to present certificates, bids are checked against the builder's certificate
+		tlsConfig = reloader.TLSConfig()
+	}
+
+	s.addServer("service", server, tlsConfig)
+
+	if err = m.Start(); err != nil {
+		return err
+	}
+
+	select {
+	case <-ctx.Done():
+		log.Infow("bsc mev-sentry stopping")
+	case err = <-m.Failed():
+		log.Errorw("bsc mev-sentry stopping on failure", "err", err)
+	}
+
+	m.Stop()
+
+	return err
+}
+
+// Close releases the resources held by the sentry if it's never run, Run releases them once stopped.
+func (s *Sentry) Close() {
+	if s.manager != nil {
+		return
+	}
+
+	s.service.StopValidators()
+	s.service.Close()
+}
+
+func (s *Sentry) serviceHandler(rpcServer *rpc.Server) (http.Handler, error) {
+	cfg := s.cfg
+
+	maxBodySize := cfg.Service.MaxBodySize
+	if maxBodySize <= 0 {
+		maxBodySize = service.DefaultMaxBodySize
+	}
+
+	app := gin.New()
+
+	// the readiness probe of load balancers carries no credentials, so it's registered ahead of the auth middlewares
+	app.GET("/ready", func(c *gin.Context) {
+		if s.service.Draining() || s.service.Standby() {
+			c.Status(http.StatusServiceUnavailable)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+
+	// validated with the config
+	trustedProxies, _ := node.ParseCIDRs(cfg.Service.TrustedProxies)
+
+	app.Use(
+		// the remote address is the sender's rather than the proxy's for every check that follows
+		ginutils.ForwardedFor(trustedProxies),
+		// the time queued for concurrency counts against the timeout of the builder
+		ginutils.RequestTimeout(),
+		ginutils.ConcurrencyLimiter(cfg.Service.RPCConcurrency, cfg.Service.RPCQueueSize,
+			time.Duration(cfg.Service.RPCQueueTimeout)),
+		ginutils.PanicRecovery(),
+		ginutils.BodyLimit(maxBodySize),
+		ginutils.ClientCertIdentity(),
+		ginutils.SignatureAuth(),
+		ginutils.APIKey(),
+	)
+
+	if cfg.Service.JWT.Enabled {
+		verifier, err := auth.NewJWTVerifier(cfg.Service.JWT)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create jwt verifier: %w", err)
+		}
+		app.Use(ginutils.JWTAuth(verifier))
+	}
+
+	// the hint stream is hijacked by the websocket, so it's registered ahead of the compression of responses. The
+	// subscribers present the token of hints unless the jwt authenticates them already.
+	if path, stream, ok := s.service.HintStream(); ok {
+		token := cfg.Service.Hints.Token
+		if cfg.Service.JWT.Enabled {
+			token = ""
+		}
+		app.GET(path, ginutils.TokenAuth(token), gin.WrapH(stream))
+	}
+
+	app.Use(gzip.Gzip(gzip.DefaultCompression))
+
+	if cfg.Service.Routing.Header != "" {
+		app.Use(ginutils.RoutingHeader(cfg.Service.Routing.Header))
+	}
+
+	if cfg.Service.TLSCertFile != "" {
+		app.Use(ginutils.RoutingSNI())
+	}
+
+	app.Use(s.middlewares...)
+
+	app.POST("/", gin.WrapH(rpcServer))
+
+	if cfg.Service.RESTAPI {
+		s.registerREST(app)
+	}
+
+	return app, nil
+}
+
+// addServer adds a listener component serving https if tlsConfig is set, requests in flight are
+// finished on stop.
+func (s *Sentry) addServer(name string, server *http.Server, tlsConfig *tls.Config) {
+	s.manager.Add(lifecycle.Component{
+		Name: name,
+		Start: func() error {
+			ln, err := net.Listen("tcp", server.Addr)
+			if err != nil {
+				return err
+			}
+
+			if tlsConfig != nil {
+				ln = tls.NewListener(ln, tlsConfig)
+			}
+
+			log.Infow("server listen", "server", name, "addr", server.Addr, "tls", tlsConfig != nil)
+
+			go func() {
+				if err := server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					s.manager.Fail(name, errors.WithStack(err))
+				}
+			}()
+
+			return nil
+		},
+		Stop:        server.Shutdown,
+		StopTimeout: time.Duration(s.cfg.Service.MaxRPCTimeout()) + time.Second,
+	})
+}
+
+func (s *Sentry) addAdmin() error {
+	cfg := &s.cfg.Service
+
+	adminServer := rpc.NewServer()
+	if err := adminServer.RegisterName("admin", service.NewMevAdmin(s.service)); err != nil {
+		return err
+	}
+
+	// admin_reloadBuilders sits next to the methods of the service admin
+	if err := adminServer.RegisterName("admin", &BuilderAdmin{sentry: s}); err != nil {
+		return err
+	}
+
+	if err := adminServer.RegisterName("peer", service.NewMevPeer(s.service)); err != nil {
+		return err
+	}
+
+	if cfg.AdminToken == "" && len(s.adminMiddlewares) == 0 {
+		log.Warnw("admin service accepts unauthenticated requests, set AdminToken", "addr", cfg.AdminListenAddr)
+	}
+
+	app := gin.New()
+	app.Use(
+		ginutils.PanicRecovery(),
+		ginutils.TokenAuth(cfg.AdminToken),
+	)
+	app.Use(s.adminMiddlewares...)
+
+	app.POST("/", gin.WrapH(adminServer))
+
+	s.addServer("admin", &http.Server{Addr: cfg.AdminListenAddr, Handler: app}, nil)
+
+	return nil
+}
+
+// addReloader adds a component reloading validators, builders, notification channels and log level
+// from the config file on SIGHUP, an invalid config is rejected and the running one is kept.
+func (s *Sentry) addReloader() {
+	sigs := make(chan os.Signal, 1)
+	done := make(chan struct{})
+
+	s.manager.Add(lifecycle.Component{
+		Name: "reloader",
+		Start: func() error {
+			signal.Notify(sigs, syscall.SIGHUP)
+
+			go func() {
+				defer close(done)
+				for range sigs {
+					s.reload()
+				}
+			}()
+
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			signal.Stop(sigs)
+			close(sigs)
+
+			select {
+			case <-done:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+	})
+}
+
+func (s *Sentry) reload() {
+	cfg, err := config.Read(s.configPath)
+	if err == nil {
+		err = s.override(cfg)
+	}
+	if err == nil {
+		err = s.service.UpdateTopology(cfg.Validators, cfg.Builders)
+	}
+
+	if err != nil {
+		metrics.ConfigReloadCounter.WithLabelValues("failure").Inc()
+		log.Errorw("failed to reload config, keep the running one", "configPath", s.configPath, "err", err)
+		return
+	}
+
+	if lvl, err := log.ParseLevel(cfg.Log.Level); err == nil {
+		log.SetLevel(lvl)
+	}
+	if !s.skipLogger {
+		log.SetSampling(cfg.Log.SamplingConfig())
+	}
+	notification.Init(notification.NewNotifierFromConfig(&cfg.Notification))
+
+	metrics.ConfigReloadCounter.WithLabelValues("success").Inc()
+	log.Infow("config reloaded", "configPath", s.configPath)
+}
+
+// override applies the config overrides and validates the result.
+func (s *Sentry) override(cfg *config.Config) error {
+	if len(s.overrides) == 0 {
+		return nil
+	}
+
+	for _, override := range s.overrides {
+		override(cfg)
+	}
+
+	return cfg.Validate()
+}
+
+func init() {
+	http.Handle("/debug/metrics/prometheus", promhttp.Handler())
+}
@@ -0,0 +1,50 @@
+package sentry
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/bsc-mev-sentry/config"
+	"github.com/bnb-chain/bsc-mev-sentry/node"
+)
+
+func TestConfigOverride(t *testing.T) {
+	newSentry := func(override func(cfg *config.Config)) (*Sentry, error) {
+		cfg := &config.Config{}
+		cfg.Service.HTTPListenAddr = ":8555"
+		cfg.Log.Level = "info"
+
+		return New(WithConfig(cfg), WithoutLogger(), WithConfigOverride(override),
+			WithValidators(map[string]node.Validator{}), WithBuilders(map[common.Address]node.Builder{}))
+	}
+
+	s, err := newSentry(func(cfg *config.Config) {
+		cfg.Service.HTTPListenAddr = ":8556"
+	})
+	require.NoError(t, err)
+	defer s.service.Close()
+	assert.Equal(t, ":8556", s.cfg.Service.HTTPListenAddr)
+	assert.Equal(t, "info", s.cfg.Log.Level)
+
+	_, err = newSentry(func(cfg *config.Config) {
+		cfg.Log.Level = "loud"
+	})
+	assert.Error(t, err)
+}
+
+func TestNewFailsOnBuilder(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Log.Level = "info"
+	cfg.Builders = []node.BuilderConfig{{
+		Address: common.HexToAddress("0x980A75eCd1309eA12fa2ED87A8744fBfc9b863D5"),
+		URL:     "http://127.0.0.1:1",
+		TLS:     node.UpstreamTLSConfig{CAFile: "./missing.pem"},
+	}}
+
+	// a builder which can't be created fails the sentry rather than being left out
+	_, err := New(WithConfig(cfg), WithoutLogger(), WithValidators(map[string]node.Validator{}))
+	assert.ErrorContains(t, err, "failed to create builder 0x980A75eCd1309eA12fa2ED87A8744fBfc9b863D5")
+}
@@ -0,0 +1,258 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bnb-chain/bsc-mev-sentry/auth"
+	"github.com/bnb-chain/bsc-mev-sentry/log"
+	"github.com/bnb-chain/bsc-mev-sentry/node"
+	"github.com/bnb-chain/bsc-mev-sentry/store"
+)
+
+// MevAdmin serves the admin namespace, which lets operators change the
+// validator and builder topology of a running sentry without a restart.
+type MevAdmin struct {
+	sentry *MevSentry
+}
+
+func NewMevAdmin(sentry *MevSentry) *MevAdmin {
+	return &MevAdmin{sentry: sentry}
+}
+
+// AddValidator adds a validator, replacing the one with the same public hostname if any.
+func (a *MevAdmin) AddValidator(_ context.Context, cfg node.ValidatorConfig) error {
+	if cfg.PublicHostName == "" {
+		return errors.New("public hostname is required")
+	}
+
+	a.sentry.topologyMu.Lock()
+	defer a.sentry.topologyMu.Unlock()
+
+	validator, err := a.sentry.newValidator(cfg)
+	if err != nil {
+		return err
+	}
+
+	a.sentry.mu.Lock()
+	old := a.sentry.validators[cfg.PublicHostName]
+	a.sentry.validators[cfg.PublicHostName] = validator
+	a.sentry.mu.Unlock()
+
+	if old != nil {
+		old.Stop()
+	}
+
+	log.Infow("validator added", "hostname", cfg.PublicHostName, "replaced", old != nil)
+
+	return nil
+}
+
+func (a *MevAdmin) RemoveValidator(_ context.Context, hostname string) error {
+	a.sentry.topologyMu.Lock()
+	defer a.sentry.topologyMu.Unlock()
+
+	a.sentry.mu.Lock()
+	validator, ok := a.sentry.validators[hostname]
+	delete(a.sentry.validators, hostname)
+	a.sentry.mu.Unlock()
+
+	if !ok {
+		return errors.New("validator not found")
+	}
+
+	validator.Stop()
+
+	log.Infow("validator removed", "hostname", hostname)
+
+	return nil
+}
+
+// Validators returns the public hostnames of all validators.
+func (a *MevAdmin) Validators(_ context.Context) []string {
+	a.sentry.mu.RLock()
+	defer a.sentry.mu.RUnlock()
+
+	hostnames := make([]string, 0, len(a.sentry.validators))
+	for hostname := range a.sentry.validators {
+		hostnames = append(hostnames, hostname)
+	}
+
+	sort.Strings(hostnames)
+
+	return hostnames
+}
+
+// ValidatorCapabilities returns the optional features supported by the validator.
+func (a *MevAdmin) ValidatorCapabilities(_ context.Context, hostname string) (*node.Capabilities, error) {
+	validator, ok := a.sentry.validator(hostname)
+	if !ok {
+		return nil, errors.New("validator not found")
+	}
+
+	caps := validator.Capabilities()
+	return &caps, nil
+}
+
+// ValidatorHealth returns the sentry side conditions of forwarding bids to the validator.
+func (a *MevAdmin) ValidatorHealth(_ context.Context, hostname string) (*node.Health, error) {
+	validator, ok := a.sentry.validator(hostname)
+	if !ok {
+		return nil, errors.New("validator not found")
+	}
+
+	health := validator.Health()
+	return &health, nil
+}
+
+// AddBuilder adds a builder, replacing the one with the same address if any.
+func (a *MevAdmin) AddBuilder(_ context.Context, cfg node.BuilderConfig) error {
+	if cfg.Address == (common.Address{}) {
+		return errors.New("builder address is required")
+	}
+
+	a.sentry.topologyMu.Lock()
+	defer a.sentry.topologyMu.Unlock()
+
+	replaced, err := a.setBuilder(cfg)
+	if err != nil {
+		return err
+	}
+
+	log.Infow("builder added", "address", cfg.Address, "replaced", replaced)
+
+	return nil
+}
+
+// setBuilder creates the builder along with its stored api keys and replaces the one with the same address,
+// topologyMu must be held.
+func (a *MevAdmin) setBuilder(cfg node.BuilderConfig) (replaced bool, err error) {
+	builder, err := node.NewBuilder(a.sentry.withStoredAPIKeys(cfg))
+	if err != nil {
+		return false, err
+	}
+
+	a.sentry.mu.Lock()
+	old, replaced := a.sentry.builders[cfg.Address]
+	a.sentry.builders[cfg.Address] = builder
+	a.sentry.mu.Unlock()
+
+	if replaced {
+		old.Stop()
+	}
+
+	return replaced, nil
+}
+
+// AddBuilderAPIKey generates an api key for the builder, the key is only returned once and
+// only its hash is kept, persisted if APIKeyStorePath is configured.
+func (a *MevAdmin) AddBuilderAPIKey(_ context.Context, address common.Address) (string, error) {
+	a.sentry.topologyMu.Lock()
+	defer a.sentry.topologyMu.Unlock()
+
+	builder, ok := a.sentry.builder(address)
+	if !ok {
+		return "", errors.New("builder not found")
+	}
+
+	key, err := auth.NewAPIKey()
+	if err != nil {
+		return "", err
+	}
+
+	hash := auth.HashAPIKey(key)
+	if a.sentry.apiKeys != nil {
+		if err = a.sentry.apiKeys.Add(address, hash); err != nil {
+			return "", err
+		}
+	}
+
+	cfg := builder.Config()
+	cfg.APIKeyHashes = append(cfg.APIKeyHashes[:len(cfg.APIKeyHashes):len(cfg.APIKeyHashes)], hash)
+	if _, err = a.setBuilder(cfg); err != nil {
+		return "", err
+	}
+
+	log.Infow("builder api key added", "address", address, "hash", hash)
+
+	return key, nil
+}
+
+// RevokeBuilderAPIKey removes the api key of the given hash from the builder. A key configured in the config file comes
+// back once it's reloaded unless removed from it.
+func (a *MevAdmin) RevokeBuilderAPIKey(_ context.Context, address common.Address, hash string) error {
+	a.sentry.topologyMu.Lock()
+	defer a.sentry.topologyMu.Unlock()
+
+	builder, ok := a.sentry.builder(address)
+	if !ok {
+		return errors.New("builder not found")
+	}
+
+	cfg := builder.Config()
+	hashes := make([]string, 0, len(cfg.APIKeyHashes))
+	for _, h := range cfg.APIKeyHashes {
+		if h != hash {
+			hashes = append(hashes, h)
+		}
+	}
+
+	if len(hashes) == len(cfg.APIKeyHashes) {
+		return errors.New("api key not found")
+	}
+
+	if a.sentry.apiKeys != nil {
+		if err := a.sentry.apiKeys.Revoke(address, hash); err != nil && !errors.Is(err, store.ErrNotFound) {
+			return err
+		}
+	}
+
+	cfg.APIKeyHashes = hashes
+	if _, err := a.setBuilder(cfg); err != nil {
+		return err
+	}
+
+	log.Infow("builder api key revoked", "address", address, "hash", hash)
+
+	return nil
+}
+
+func (a *MevAdmin) RemoveBuilder(_ context.Context, address common.Address) error {
+	a.sentry.topologyMu.Lock()
+	defer a.sentry.topologyMu.Unlock()
+
+	a.sentry.mu.Lock()
+	old, ok := a.sentry.builders[address]
+	delete(a.sentry.builders, address)
+	a.sentry.mu.Unlock()
+
+	if !ok {
+		return errors.New("builder not found")
+	}
+	old.Stop()
+
+	log.Infow("builder removed", "address", address)
+
+	return nil
+}
+
+// Builders returns the addresses of all builders.
+func (a *MevAdmin) Builders(_ context.Context) []common.Address {
+	a.sentry.mu.RLock()
+	defer a.sentry.mu.RUnlock()
+
+	addresses := make([]common.Address, 0, len(a.sentry.builders))
+	for address := range a.sentry.builders {
+		addresses = append(addresses, address)
+	}
+
+	sort.Slice(addresses, func(i, j int) bool {
+		return bytes.Compare(addresses[i][:], addresses[j][:]) < 0
+	})
+
+	return addresses
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/bsc-mev-sentry/auth"
+	"github.com/bnb-chain/bsc-mev-sentry/node"
+	"github.com/bnb-chain/bsc-mev-sentry/store"
+)
+
+func TestBuilderAPIKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api_keys.json")
+	apiKeys, err := store.OpenAPIKeyStore(path)
+	require.NoError(t, err)
+
+	address := common.HexToAddress("0x01")
+	cfgs := []node.BuilderConfig{{Address: address, URL: "http://127.0.0.1:1", APIKeyHashes: []string{"configured"}}}
+
+	s := &MevSentry{validators: map[string]node.Validator{}, apiKeys: apiKeys}
+	require.NoError(t, s.UpdateBuilders(cfgs))
+	admin := NewMevAdmin(s)
+	ctx := context.Background()
+
+	key, err := admin.AddBuilderAPIKey(ctx, address)
+	require.NoError(t, err)
+	hashesOf := func() []string {
+		builder, ok := s.builder(address)
+		require.True(t, ok)
+		return builder.Config().APIKeyHashes
+	}
+	assert.Equal(t, []string{"configured", auth.HashAPIKey(key)}, hashesOf())
+
+	// the key survives a reload of the config, which doesn't have it
+	require.NoError(t, s.UpdateBuilders(cfgs))
+	assert.Equal(t, []string{"configured", auth.HashAPIKey(key)}, hashesOf())
+
+	// and a restart
+	apiKeys, err = store.OpenAPIKeyStore(path)
+	require.NoError(t, err)
+	s = &MevSentry{validators: map[string]node.Validator{}, apiKeys: apiKeys}
+	require.NoError(t, s.UpdateBuilders(cfgs))
+	admin = NewMevAdmin(s)
+	assert.Equal(t, []string{"configured", auth.HashAPIKey(key)}, hashesOf())
+
+	// a revoked key doesn't come back on reload
+	require.NoError(t, admin.RevokeBuilderAPIKey(ctx, address, auth.HashAPIKey(key)))
+	require.NoError(t, s.UpdateBuilders(cfgs))
+	assert.Equal(t, []string{"configured"}, hashesOf())
+}
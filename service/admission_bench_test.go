@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/bnb-chain/bsc-mev-sentry/node"
+	"github.com/bnb-chain/bsc-mev-sentry/utils"
+)
+
+// BenchmarkAdmission measures mev_sendBid on the sentry side, i.e. the checks a bid goes through, signing its pay
+// bid tx and tracking it, with a validator accepting every bid right away.
+func BenchmarkAdmission(b *testing.B) {
+	builderKey, _ := crypto.GenerateKey()
+	txKey, _ := crypto.GenerateKey()
+	chainID := big.NewInt(56)
+	signer := types.LatestSignerForChainID(chainID)
+
+	head := &node.ChainHead{Hash: common.HexToHash("0x01"), Number: 100, Time: uint64(time.Now().Unix())}
+
+	rawBid := &types.RawBid{
+		BlockNumber: head.Number + 1,
+		ParentHash:  head.Hash,
+		GasUsed:     21000 * 50,
+		GasFee:      big.NewInt(3e9 * 21000 * 50),
+		BuilderFee:  big.NewInt(1e15),
+	}
+	for i := 0; i < 50; i++ {
+		tx, _ := types.SignNewTx(txKey, signer, &types.LegacyTx{Nonce: uint64(i), GasPrice: big.NewInt(3e9), Gas: 21000,
+			To: &common.Address{}, Value: big.NewInt(1)})
+		raw, _ := tx.MarshalBinary()
+		rawBid.Txs = append(rawBid.Txs, raw)
+	}
+
+	sig, _ := crypto.Sign(rawBid.Hash().Bytes(), builderKey)
+	args := types.BidArgs{RawBid: rawBid, Signature: sig}
+
+	validator := &stubValidator{
+		cfg: node.ValidatorConfig{
+			PublicHostName: "v",
+			StrictChainID:  true,
+			ReplayWindow:   node.ReplayWindowConfig{Enabled: true},
+		},
+		head:    head,
+		chainID: chainID,
+		params:  &types.MevParams{GasCeil: 140_000_000, GasPrice: big.NewInt(1)},
+	}
+	address := crypto.PubkeyToAddress(builderKey.PublicKey)
+
+	s := NewMevSentry(&Config{RPCTimeout: utils.Duration(time.Second), RejectionStatsHours: 24,
+		ArrivalHeatmapBlocks: 1200}, map[string]node.Validator{"v": validator},
+		map[common.Address]node.Builder{address: &stubBuilder{cfg: node.BuilderConfig{Address: address}}})
+	defer s.Close()
+	ctx := WithTarget(context.Background(), "v")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := s.SendBid(ctx, args); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkRejectionTracker(b *testing.B) {
+	tracker := newRejectionTracker(24)
+	builder := common.HexToAddress("0x01")
+	now := time.Now()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		tracker.record(builder, rejectFeeCeiling, now)
+	}
+}
+
+func BenchmarkArrivalHeatmap(b *testing.B) {
+	heatmap := newArrivalHeatmap(1200)
+	builder := common.HexToAddress("0x01")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		heatmap.record("v", uint64(i/10), builder, time.Duration(i%30)*100*time.Millisecond)
+	}
+}
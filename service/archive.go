@@ -0,0 +1,21 @@
+package service
+
+import "context"
+
+// StartArchive starts exporting the stored bids to object storage, if the archive is enabled.
+func (s *MevSentry) StartArchive() error {
+	if s.archive == nil {
+		return nil
+	}
+
+	return s.archive.Start()
+}
+
+// StopArchive stops exporting, the bids not exported yet are exported after restart.
+func (s *MevSentry) StopArchive(ctx context.Context) error {
+	if s.archive == nil {
+		return nil
+	}
+
+	return s.archive.Stop(ctx)
+}
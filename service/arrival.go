@@ -0,0 +1,171 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// arrivalBucketWidth is the width of the offset buckets of the bid arrival heatmap
+const arrivalBucketWidth = 100 * time.Millisecond
+
+// ArrivalCell is a cell of the bid arrival heatmap, the number of bids of a builder for a block
+// arriving within [Offset, Offset+100ms) relative to the expected block time.
+type ArrivalCell struct {
+	Validator string         `json:"validator"`
+	Block     uint64         `json:"block"`
+	Builder   common.Address `json:"builder"`
+	Offset    int64          `json:"offset"` // milliseconds
+	Count     uint64         `json:"count"`
+}
+
+type arrivalCellKey struct {
+	builder common.Address
+	bucket  int64
+}
+
+type validatorArrivals struct {
+	latest uint64
+	blocks map[uint64]map[arrivalCellKey]uint64
+}
+
+// arrivalHeatmap counts bid arrival offsets per validator, block and builder over the recent blocks.
+type arrivalHeatmap struct {
+	mu         sync.Mutex
+	blocks     uint64
+	validators map[string]*validatorArrivals
+}
+
+func newArrivalHeatmap(blocks int) *arrivalHeatmap {
+	if blocks <= 0 {
+		blocks = 1200
+	}
+
+	return &arrivalHeatmap{
+		blocks:     uint64(blocks),
+		validators: make(map[string]*validatorArrivals),
+	}
+}
+
+func (h *arrivalHeatmap) record(validator string, block uint64, builder common.Address, offset time.Duration) {
+	bucket := offset.Milliseconds() / arrivalBucketWidth.Milliseconds()
+	if offset < 0 && offset%arrivalBucketWidth != 0 {
+		bucket-- // floor for negative offsets
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	va, ok := h.validators[validator]
+	if !ok {
+		va = &validatorArrivals{blocks: make(map[uint64]map[arrivalCellKey]uint64)}
+		h.validators[validator] = va
+	}
+
+	if block+h.blocks <= va.latest {
+		return
+	}
+
+	if block > va.latest {
+		va.latest = block
+		for b := range va.blocks {
+			if b+h.blocks <= block {
+				delete(va.blocks, b)
+			}
+		}
+	}
+
+	cells, ok := va.blocks[block]
+	if !ok {
+		cells = make(map[arrivalCellKey]uint64)
+		va.blocks[block] = cells
+	}
+
+	cells[arrivalCellKey{builder: builder, bucket: bucket}]++
+}
+
+// cells returns the heatmap cells of the last blocks of each validator, only of the builder if given.
+func (h *arrivalHeatmap) cells(blocks int, builder *common.Address) []ArrivalCell {
+	n := uint64(blocks)
+	if blocks <= 0 || n > h.blocks {
+		n = h.blocks
+	}
+
+	h.mu.Lock()
+	result := make([]ArrivalCell, 0)
+	for validator, va := range h.validators {
+		for block, cells := range va.blocks {
+			if block+n <= va.latest {
+				continue
+			}
+
+			for key, count := range cells {
+				if builder != nil && key.builder != *builder {
+					continue
+				}
+
+				result = append(result, ArrivalCell{
+					Validator: validator,
+					Block:     block,
+					Builder:   key.builder,
+					Offset:    key.bucket * arrivalBucketWidth.Milliseconds(),
+					Count:     count,
+				})
+			}
+		}
+	}
+	h.mu.Unlock()
+
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.Validator != b.Validator {
+			return a.Validator < b.Validator
+		}
+		if a.Block != b.Block {
+			return a.Block < b.Block
+		}
+		if c := bytes.Compare(a.Builder[:], b.Builder[:]); c != 0 {
+			return c < 0
+		}
+		return a.Offset < b.Offset
+	})
+
+	return result
+}
+
+// BidArrivalsArgs is the signed query of a builder for the arrival heatmap of its own bids.
+type BidArrivalsArgs struct {
+	// Blocks of history to return
+	Blocks int `json:"blocks"`
+	// Timestamp unix seconds when the query was signed
+	Timestamp int64 `json:"timestamp"`
+	// Signature of BidArrivalsHash by the builder key
+	Signature hexutil.Bytes `json:"signature"`
+}
+
+// BidArrivalsHash returns the hash a builder signs to query the arrival heatmap of its bids.
+func BidArrivalsHash(blocks int, timestamp int64) common.Hash {
+	return crypto.Keccak256Hash([]byte(fmt.Sprintf("mev_bidArrivals:%d:%d", blocks, timestamp)))
+}
+
+// BidArrivals returns the arrival heatmap of the bids of the signing builder over the last blocks.
+func (s *MevSentry) BidArrivals(_ context.Context, args BidArrivalsArgs) ([]ArrivalCell, error) {
+	builder, err := s.verifyBuilderQuery(BidArrivalsHash(args.Blocks, args.Timestamp), args.Timestamp, args.Signature)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.arrivals.cells(args.Blocks, &builder), nil
+}
+
+// BidArrivals returns the arrival heatmap of the bids of all builders over the last blocks.
+func (a *MevAdmin) BidArrivals(_ context.Context, blocks int) []ArrivalCell {
+	return a.sentry.arrivals.cells(blocks, nil)
+}
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArrivalHeatmap(t *testing.T) {
+	heatmap := newArrivalHeatmap(2)
+	b1, b2 := common.HexToAddress("0x01"), common.HexToAddress("0x02")
+
+	heatmap.record("v", 10, b1, -250*time.Millisecond)
+	heatmap.record("v", 11, b1, 50*time.Millisecond)
+	heatmap.record("v", 11, b1, 99*time.Millisecond)
+	heatmap.record("v", 11, b2, -100*time.Millisecond)
+
+	assert.Equal(t, []ArrivalCell{
+		{Validator: "v", Block: 10, Builder: b1, Offset: -300, Count: 1},
+		{Validator: "v", Block: 11, Builder: b1, Offset: 0, Count: 2},
+		{Validator: "v", Block: 11, Builder: b2, Offset: -100, Count: 1},
+	}, heatmap.cells(0, nil))
+
+	assert.Equal(t, []ArrivalCell{
+		{Validator: "v", Block: 11, Builder: b2, Offset: -100, Count: 1},
+	}, heatmap.cells(1, &b2))
+
+	// old blocks are evicted once newer ones arrive, bids for them are ignored
+	heatmap.record("v", 12, b1, 0)
+	heatmap.record("v", 10, b1, 0)
+	assert.Equal(t, []ArrivalCell{
+		{Validator: "v", Block: 11, Builder: b1, Offset: 0, Count: 2},
+		{Validator: "v", Block: 12, Builder: b1, Offset: 0, Count: 1},
+	}, heatmap.cells(0, &b1))
+}
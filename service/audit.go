@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/bnb-chain/bsc-mev-sentry/store"
+)
+
+var errAuditLogDisabled = errors.New("audit log is disabled")
+
+// auditPayBidTx appends the signed pay bid tx to the audit log, if it's enabled. The bid is rejected if the tx can't
+// be recorded, so that no tx leaves the sentry unaudited.
+func (s *MevSentry) auditPayBidTx(hostname string, builder common.Address, bidHash common.Hash,
+	payBidTx hexutil.Bytes) error {
+	if s.audit == nil {
+		return nil
+	}
+
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(payBidTx); err != nil {
+		return err
+	}
+
+	account, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		return err
+	}
+
+	return s.audit.Append(&store.AuditEntry{
+		Time:      time.Now().UnixMilli(),
+		Validator: hostname,
+		Account:   account,
+		Builder:   builder,
+		BidHash:   bidHash,
+		TxHash:    tx.Hash(),
+		Nonce:     tx.Nonce(),
+		Amount:    tx.Value(),
+		GasPrice:  tx.GasPrice(),
+	})
+}
+
+// AuditHead returns the last entry of the audit log, to record elsewhere for verifying the log later.
+func (a *MevAdmin) AuditHead(_ context.Context) (*store.AuditHead, error) {
+	if a.sentry.audit == nil {
+		return nil, errAuditLogDisabled
+	}
+
+	head := a.sentry.audit.Head()
+	return &head, nil
+}
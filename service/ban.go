@@ -0,0 +1,324 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bnb-chain/bsc-mev-sentry/cluster"
+	"github.com/bnb-chain/bsc-mev-sentry/log"
+	"github.com/bnb-chain/bsc-mev-sentry/metrics"
+	"github.com/bnb-chain/bsc-mev-sentry/notification"
+	"github.com/bnb-chain/bsc-mev-sentry/utils"
+)
+
+// offenses counted towards a temporary ban of a builder. Failed authentications aren't, anyone can resend a bid of
+// the builder without its credentials.
+const (
+	offenseFeeCeiling = "fee_ceiling"
+	offenseDuplicate  = "duplicate"
+)
+
+// maxBidRetries resends of a bid not counted as duplicates, e.g. the builder retries a bid whose response is lost
+const maxBidRetries = 1
+
+const (
+	defaultBanWindow   = time.Minute
+	defaultBanCooldown = 10 * time.Minute
+)
+
+type AutoBanConfig struct {
+	// Enabled turns on the automatic bans
+	Enabled bool
+	// Window period the offenses of a builder are counted over
+	Window utils.Duration
+	// Cooldown how long a builder stays banned
+	Cooldown utils.Duration
+	// MaxFeeCeilingViolations bids exceeding the builder fee ceiling allowed per window, unlimited if 0
+	MaxFeeCeilingViolations int
+	// MaxDuplicates bids resent to the same validator allowed per window beyond the retries, unlimited if 0
+	MaxDuplicates int
+}
+
+// BannedBuilder is a builder banned until the time, for the offense.
+type BannedBuilder struct {
+	Builder common.Address `json:"builder"`
+	Reason  string         `json:"reason"`
+	Until   time.Time      `json:"until"`
+}
+
+type offenseWindow struct {
+	start   time.Time
+	counts  map[string]int
+	resends map[common.Hash]int // bid hash -> duplicates
+}
+
+// banList bans builders temporarily once their offenses within a window exceed the thresholds.
+type banList struct {
+	window   time.Duration
+	cooldown time.Duration
+	limits   map[string]int
+
+	// shared counts the offenses and keeps the bans of all replicas if set, bans are synced into bans then
+	shared cluster.Store
+
+	mu       sync.Mutex
+	offenses map[common.Address]*offenseWindow
+	bans     map[common.Address]BannedBuilder
+}
+
+func newBanList(cfg AutoBanConfig, shared cluster.Store) *banList {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	l := &banList{
+		window:   time.Duration(cfg.Window),
+		cooldown: time.Duration(cfg.Cooldown),
+		limits: map[string]int{
+			offenseFeeCeiling: cfg.MaxFeeCeilingViolations,
+			offenseDuplicate:  cfg.MaxDuplicates,
+		},
+		shared:   shared,
+		offenses: make(map[common.Address]*offenseWindow),
+		bans:     make(map[common.Address]BannedBuilder),
+	}
+
+	if l.window <= 0 {
+		l.window = defaultBanWindow
+	}
+	if l.cooldown <= 0 {
+		l.cooldown = defaultBanCooldown
+	}
+
+	return l
+}
+
+// banned returns the ban of the builder if it's banned at now.
+func (l *banList) banned(builder common.Address, now time.Time) (BannedBuilder, bool) {
+	if l == nil {
+		return BannedBuilder{}, false
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	ban, ok := l.bans[builder]
+	if !ok {
+		return BannedBuilder{}, false
+	}
+
+	if now.After(ban.Until) {
+		delete(l.bans, builder)
+		return BannedBuilder{}, false
+	}
+
+	return ban, true
+}
+
+// record counts the offense of the builder, and bans it if the offenses within the window exceed the threshold.
+func (l *banList) record(builder common.Address, offense string, now time.Time) {
+	if l == nil {
+		return
+	}
+
+	limit := l.limits[offense]
+	if limit <= 0 {
+		return
+	}
+
+	count, err := l.count(builder, offense, now)
+	if err != nil {
+		log.Errorw("failed to count builder offense", "address", builder, "reason", offense, "err", err)
+		return
+	}
+	if count <= limit {
+		return
+	}
+
+	ban := BannedBuilder{Builder: builder, Reason: offense, Until: now.Add(l.cooldown)}
+	if err = l.ban(ban); err != nil {
+		log.Errorw("failed to ban builder", "address", builder, "reason", offense, "err", err)
+		return
+	}
+
+	metrics.BuilderBanCounter.WithLabelValues(offense).Inc()
+	log.Errorw("builder banned", "address", builder, "reason", offense, "until", ban.Until)
+	notification.Notify(notification.Warning, "builder banned",
+		fmt.Sprintf("more than %d %s offenses within %v", limit, offense, l.window),
+		"builder", builder.String(), "until", ban.Until.Format(time.RFC3339))
+}
+
+// count counts the offense of the builder within its window, and returns the count of the offense.
+func (l *banList) count(builder common.Address, offense string, now time.Time) (int, error) {
+	if l.shared != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), clusterTimeout)
+		defer cancel()
+
+		return l.shared.CountOffense(ctx, builder, offense, l.window)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	w := l.offenseWindow(builder, now)
+	w.counts[offense]++
+
+	return w.counts[offense], nil
+}
+
+// retry tells whether the duplicate bid is a retry rather than an offense, i.e. the builder resends it at most
+// maxBidRetries times within the window.
+func (l *banList) retry(builder common.Address, bid common.Hash, now time.Time) bool {
+	if l == nil {
+		return true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	w := l.offenseWindow(builder, now)
+	w.resends[bid]++
+
+	return w.resends[bid] <= maxBidRetries
+}
+
+// offenseWindow returns the window of the builder at now, restarting it once it elapses.
+func (l *banList) offenseWindow(builder common.Address, now time.Time) *offenseWindow {
+	w, ok := l.offenses[builder]
+	if !ok || now.Sub(w.start) >= l.window {
+		w = &offenseWindow{start: now, counts: make(map[string]int), resends: make(map[common.Hash]int)}
+		l.offenses[builder] = w
+	}
+
+	return w
+}
+
+// ban bans the builder and resets its offenses.
+func (l *banList) ban(ban BannedBuilder) error {
+	if l.shared != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), clusterTimeout)
+		defer cancel()
+
+		if err := l.shared.Ban(ctx, cluster.Ban(ban)); err != nil {
+			return err
+		}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.offenses, ban.Builder)
+	l.bans[ban.Builder] = ban
+
+	return nil
+}
+
+// sync takes the bans of all replicas in place of the local ones.
+func (l *banList) sync(ctx context.Context) error {
+	if l == nil || l.shared == nil {
+		return nil
+	}
+
+	shared, err := l.shared.Bans(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+
+	bans := make(map[common.Address]BannedBuilder, len(shared))
+	for _, ban := range shared {
+		bans[ban.Builder] = BannedBuilder(ban)
+	}
+
+	l.mu.Lock()
+	l.bans = bans
+	l.mu.Unlock()
+
+	return nil
+}
+
+// offense maps a rejection reason to the offense it counts as, empty if none.
+func offense(reason string) string {
+	switch reason {
+	case rejectFeeCeiling:
+		return offenseFeeCeiling
+	case rejectDuplicate:
+		return offenseDuplicate
+	default:
+		return ""
+	}
+}
+
+func (l *banList) unban(builder common.Address) (bool, error) {
+	var sharedBan bool
+	if l.shared != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), clusterTimeout)
+		defer cancel()
+
+		var err error
+		if sharedBan, err = l.shared.Unban(ctx, builder); err != nil {
+			return false, err
+		}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	_, ok := l.bans[builder]
+	delete(l.bans, builder)
+	delete(l.offenses, builder)
+
+	return ok || sharedBan, nil
+}
+
+func (l *banList) list(now time.Time) []BannedBuilder {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	bans := make([]BannedBuilder, 0, len(l.bans))
+	for _, ban := range l.bans {
+		if now.Before(ban.Until) {
+			bans = append(bans, ban)
+		}
+	}
+
+	sort.Slice(bans, func(i, j int) bool {
+		return bytes.Compare(bans[i].Builder[:], bans[j].Builder[:]) < 0
+	})
+
+	return bans
+}
+
+// BannedBuilders returns the builders banned automatically.
+func (a *MevAdmin) BannedBuilders(_ context.Context) ([]BannedBuilder, error) {
+	if a.sentry.bans == nil {
+		return nil, errors.New("auto ban is not enabled")
+	}
+
+	return a.sentry.bans.list(time.Now()), nil
+}
+
+// UnbanBuilder lifts the ban of the builder before its cooldown ends.
+func (a *MevAdmin) UnbanBuilder(_ context.Context, address common.Address) error {
+	if a.sentry.bans == nil {
+		return errors.New("auto ban is not enabled")
+	}
+
+	unbanned, err := a.sentry.bans.unban(address)
+	if err != nil {
+		return err
+	}
+	if !unbanned {
+		return errors.New("builder not banned")
+	}
+
+	log.Infow("builder unbanned", "address", address)
+
+	return nil
+}
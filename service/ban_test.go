@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/bsc-mev-sentry/cluster"
+	"github.com/bnb-chain/bsc-mev-sentry/utils"
+)
+
+func TestBanList(t *testing.T) {
+	bans := newBanList(AutoBanConfig{
+		Enabled:                 true,
+		Window:                  utils.Duration(time.Minute),
+		Cooldown:                utils.Duration(10 * time.Minute),
+		MaxFeeCeilingViolations: 2,
+	}, nil)
+	builder := common.HexToAddress("0x01")
+	now := time.Unix(1700000000, 0)
+
+	bans.record(builder, offense(rejectFeeCeiling), now)
+	bans.record(builder, offense(rejectFeeCeiling), now)
+	// offenses without a threshold are not counted
+	bans.record(builder, offense(rejectDuplicate), now)
+	_, banned := bans.banned(builder, now)
+	assert.False(t, banned)
+
+	// the window restarts after it elapses
+	bans.record(builder, offense(rejectFeeCeiling), now.Add(time.Minute))
+	_, banned = bans.banned(builder, now.Add(time.Minute))
+	assert.False(t, banned)
+
+	bans.record(builder, offense(rejectFeeCeiling), now.Add(time.Minute))
+	bans.record(builder, offense(rejectFeeCeiling), now.Add(time.Minute))
+	ban, banned := bans.banned(builder, now.Add(time.Minute))
+	assert.True(t, banned)
+	assert.Equal(t, offenseFeeCeiling, ban.Reason)
+	assert.Equal(t, now.Add(11*time.Minute), ban.Until)
+
+	_, banned = bans.banned(builder, now.Add(12*time.Minute))
+	assert.False(t, banned)
+
+	bans.record(builder, offense(rejectFeeCeiling), now)
+	bans.record(builder, offense(rejectFeeCeiling), now)
+	bans.record(builder, offense(rejectFeeCeiling), now)
+	assert.Len(t, bans.list(now), 1)
+	unbanned, err := bans.unban(builder)
+	assert.NoError(t, err)
+	assert.True(t, unbanned)
+	assert.Empty(t, bans.list(now))
+
+	// a bid retried once isn't a duplicate
+	bans = newBanList(AutoBanConfig{Enabled: true, MaxDuplicates: 1}, nil)
+	bid := common.HexToHash("0xaa")
+	assert.True(t, bans.retry(builder, bid, now))
+	assert.False(t, bans.retry(builder, bid, now))
+	assert.True(t, bans.retry(builder, common.HexToHash("0xbb"), now))
+	assert.True(t, bans.retry(builder, bid, now.Add(time.Minute)), "the window restarts")
+
+	var disabled *banList
+	disabled.record(builder, offenseFeeCeiling, now)
+	_, banned = disabled.banned(builder, now)
+	assert.False(t, banned)
+}
+
+func TestSharedBanList(t *testing.T) {
+	cfg := AutoBanConfig{
+		Enabled:                 true,
+		Window:                  utils.Duration(time.Minute),
+		Cooldown:                utils.Duration(10 * time.Minute),
+		MaxFeeCeilingViolations: 1,
+	}
+	shared := cluster.NewMemory()
+	replica1, replica2 := newBanList(cfg, shared), newBanList(cfg, shared)
+	builder := common.HexToAddress("0x01")
+	now := time.Now()
+
+	// the offenses on both replicas count towards the ban
+	replica1.record(builder, offenseFeeCeiling, now)
+	replica2.record(builder, offenseFeeCeiling, now)
+	_, banned := replica2.banned(builder, now)
+	assert.True(t, banned)
+
+	_, banned = replica1.banned(builder, now)
+	assert.False(t, banned)
+	require.NoError(t, replica1.sync(context.Background()))
+	_, banned = replica1.banned(builder, now)
+	assert.True(t, banned)
+
+	unbanned, err := replica1.unban(builder)
+	require.NoError(t, err)
+	assert.True(t, unbanned)
+	require.NoError(t, replica2.sync(context.Background()))
+	assert.Empty(t, replica2.list(now))
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bnb-chain/bsc-mev-sentry/metrics"
+	"github.com/bnb-chain/bsc-mev-sentry/node"
+)
+
+// defaultBestBidGasFeeTimeout bounds the shared call to the validator unless mev_bestBidGasFee has a timeout
+const defaultBestBidGasFeeTimeout = 5 * time.Second
+
+type bestBidGasFeeKey struct {
+	validator  string
+	parentHash common.Hash
+}
+
+type bestBidGasFeeEntry struct {
+	done    chan struct{} // closed once fee and err are set
+	fee     *big.Int
+	err     error
+	expires time.Time
+}
+
+// bestBidGasFeeCache caches the best bid gas fee of each validator and parent block for a short while, and
+// lets concurrent requests share one upstream call, so that bursts of builders polling mev_bestBidGasFee
+// don't turn into one call to the validator each.
+type bestBidGasFeeCache struct {
+	ttl     time.Duration
+	timeout time.Duration // of the shared call
+
+	mu      sync.Mutex
+	entries map[bestBidGasFeeKey]*bestBidGasFeeEntry
+}
+
+func newBestBidGasFeeCache(ttl, timeout time.Duration) *bestBidGasFeeCache {
+	if ttl <= 0 {
+		return nil
+	}
+	if timeout <= 0 {
+		timeout = defaultBestBidGasFeeTimeout
+	}
+
+	return &bestBidGasFeeCache{ttl: ttl, timeout: timeout, entries: make(map[bestBidGasFeeKey]*bestBidGasFeeEntry)}
+}
+
+// get returns the cached fee, or calls the validator on a miss, cached tells whether no call is made.
+func (c *bestBidGasFeeCache) get(ctx context.Context, hostname string, validator node.Validator,
+	parentHash common.Hash,
+) (fee *big.Int, cached bool, err error) {
+	if c == nil {
+		fee, err = validator.BestBidGasFee(ctx, parentHash)
+		return fee, false, err
+	}
+
+	key := bestBidGasFeeKey{validator: hostname, parentHash: parentHash}
+	now := time.Now()
+
+	c.mu.Lock()
+	if entry, ok := c.entries[key]; ok && (entry.expires.IsZero() || now.Before(entry.expires)) {
+		c.mu.Unlock()
+		metrics.BestBidGasFeeCacheCounter.WithLabelValues("hit").Inc()
+
+		select {
+		case <-entry.done:
+			return entry.fee, true, entry.err
+		case <-ctx.Done():
+			return nil, true, ctx.Err()
+		}
+	}
+
+	// drop the expired entries once in a while, parent blocks are only polled for seconds
+	if len(c.entries) >= 1024 {
+		for k, entry := range c.entries {
+			if !entry.expires.IsZero() && now.After(entry.expires) {
+				delete(c.entries, k)
+			}
+		}
+	}
+
+	entry := &bestBidGasFeeEntry{done: make(chan struct{})}
+	c.entries[key] = entry
+	c.mu.Unlock()
+	metrics.BestBidGasFeeCacheCounter.WithLabelValues("miss").Inc()
+
+	go c.fetch(key, entry, validator, parentHash)
+
+	select {
+	case <-entry.done:
+		return entry.fee, false, entry.err
+	case <-ctx.Done():
+		return nil, false, ctx.Err()
+	}
+}
+
+// fetch calls the validator on behalf of all the requests waiting for the entry, detached from the request missing
+// it, so that the request giving up doesn't fail the others.
+func (c *bestBidGasFeeCache) fetch(key bestBidGasFeeKey, entry *bestBidGasFeeEntry, validator node.Validator,
+	parentHash common.Hash,
+) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	fee, err := validator.BestBidGasFee(ctx, parentHash)
+
+	c.mu.Lock()
+	entry.fee, entry.err = fee, err
+	if err != nil {
+		// errors aren't cached, the waiters get it but the next request calls the validator again
+		delete(c.entries, key)
+	} else {
+		entry.expires = time.Now().Add(c.ttl)
+	}
+	c.mu.Unlock()
+	close(entry.done)
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/bsc-mev-sentry/node"
+)
+
+type bestBidValidator struct {
+	stubValidator
+	calls atomic.Int32
+	err   error
+}
+
+func (v *bestBidValidator) BestBidGasFee(_ context.Context, parentHash common.Hash) (*big.Int, error) {
+	v.calls.Add(1)
+	time.Sleep(10 * time.Millisecond)
+	return parentHash.Big(), v.err
+}
+
+func TestBestBidGasFeeCache(t *testing.T) {
+	c := newBestBidGasFeeCache(50*time.Millisecond, time.Second)
+	validator := &bestBidValidator{}
+	ctx := context.Background()
+	parent := common.HexToHash("0x01")
+
+	// a burst of requests makes one call
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fee, _, err := c.get(ctx, "v", validator, parent)
+			assert.NoError(t, err)
+			assert.Equal(t, big.NewInt(1), fee)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), validator.calls.Load())
+
+	_, cached, err := c.get(ctx, "v", validator, parent)
+	require.NoError(t, err)
+	assert.True(t, cached)
+
+	// per validator and parent block
+	_, cached, _ = c.get(ctx, "v", validator, common.HexToHash("0x02"))
+	assert.False(t, cached)
+	_, cached, _ = c.get(ctx, "w", validator, parent)
+	assert.False(t, cached)
+	assert.Equal(t, int32(3), validator.calls.Load())
+
+	time.Sleep(60 * time.Millisecond)
+	_, cached, _ = c.get(ctx, "v", validator, parent)
+	assert.False(t, cached, "expired")
+
+	// errors aren't cached
+	validator.err = errors.New("upstream")
+	parent = common.HexToHash("0x03")
+	_, _, err = c.get(ctx, "v", validator, parent)
+	assert.Error(t, err)
+	_, cached, _ = c.get(ctx, "v", validator, parent)
+	assert.False(t, cached)
+
+	// the request missing the entry gives up, the others sharing its call still get the fee
+	validator.err = nil
+	parent = common.HexToHash("0x04")
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, _, err = c.get(cancelled, "v", validator, parent)
+	assert.ErrorIs(t, err, context.Canceled)
+	fee, cached, err := c.get(ctx, "v", validator, parent)
+	require.NoError(t, err)
+	assert.True(t, cached)
+	assert.Equal(t, parent.Big(), fee)
+
+	// disabled without a ttl
+	assert.Nil(t, newBestBidGasFeeCache(0, 0))
+}
+
+type bestBidCapableValidator struct {
+	bestBidValidator
+}
+
+func (v *bestBidCapableValidator) Capabilities() node.Capabilities {
+	return node.Capabilities{BestBidGasFee: true}
+}
+
+func TestBestBidGasFeeAll(t *testing.T) {
+	failing := &bestBidCapableValidator{}
+	failing.err = errors.New("unreachable")
+
+	s := &MevSentry{
+		validators: map[string]node.Validator{
+			"a": &bestBidCapableValidator{},
+			"b": failing,
+			// doesn't serve mev_bestBidGasFee
+			"c": &hasBuilderValidator{},
+		},
+		bestBidGasFees: newBestBidGasFeeCache(0, 0),
+	}
+
+	results, err := s.BestBidGasFeeAll(context.Background(), common.HexToHash("0x01"))
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, BestBidGasFeeResult{Validator: "a", Fee: big.NewInt(1)}, results[0])
+	assert.Equal(t, "b", results[1].Validator)
+	assert.Nil(t, results[1].Fee)
+	assert.NotEmpty(t, results[1].Error)
+}
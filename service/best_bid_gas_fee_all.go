@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"math/big"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/bnb-chain/bsc-mev-sentry/metrics"
+)
+
+// BestBidGasFeeResult is the best bid gas fee of the parent block at one validator.
+type BestBidGasFeeResult struct {
+	Validator string   `json:"validator"`
+	Fee       *big.Int `json:"fee,omitempty"`
+	Error     string   `json:"error,omitempty"`
+	Code      int      `json:"code,omitempty"`
+}
+
+// BestBidGasFeeAll returns the best bid gas fee of the parent block at every validator serving mev_bestBidGasFee,
+// asked concurrently under the deadline of the request. Each fee is answered as mev_bestBidGasFee sent to the host of
+// the validator, cached fees included.
+func (s *MevSentry) BestBidGasFeeAll(ctx context.Context, parentHash common.Hash) (results []BestBidGasFeeResult,
+	err error) {
+	method := "mev_bestBidGasFeeAll"
+	start := time.Now()
+	defer recordLatency(method, servedFromUpstream, start)
+	// the fees asked per validator keep the deadline margin themselves
+	defer s.budget(&ctx, method)()
+	defer func() {
+		if err != nil {
+			if rpcErr, ok := err.(rpc.Error); ok {
+				metrics.ApiErrorCounter.WithLabelValues(method, strconv.Itoa(rpcErr.ErrorCode())).Inc()
+			}
+		}
+	}()
+
+	var hostnames []string
+	s.mu.RLock()
+	for hostname, validator := range s.validators {
+		if validator.Capabilities().BestBidGasFee {
+			hostnames = append(hostnames, hostname)
+		}
+	}
+	s.mu.RUnlock()
+	sort.Strings(hostnames)
+
+	results = make([]BestBidGasFeeResult, len(hostnames))
+
+	var wg sync.WaitGroup
+	for i, hostname := range hostnames {
+		wg.Add(1)
+		go func(i int, hostname string) {
+			defer wg.Done()
+
+			result := BestBidGasFeeResult{Validator: hostname}
+
+			fee, err := s.BestBidGasFee(WithTarget(ctx, hostname), parentHash)
+			if err != nil {
+				result.Error = err.Error()
+				if rpcErr, ok := err.(rpc.Error); ok {
+					result.Code = rpcErr.ErrorCode()
+				}
+			} else {
+				result.Fee = fee
+			}
+
+			results[i] = result
+		}(i, hostname)
+	}
+	wg.Wait()
+
+	return
+}
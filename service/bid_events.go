@@ -0,0 +1,40 @@
+package service
+
+import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/bnb-chain/bsc-mev-sentry/events"
+)
+
+// publishBid publishes what became of the bid, if an event backend is configured.
+func (s *MevSentry) publishBid(builder common.Address, hostname string, bid *types.RawBid, bidHash common.Hash,
+	receivedAt time.Time, reason string, err error) {
+	if s.events == nil || bid == nil {
+		return
+	}
+
+	eventType := events.BidAccepted
+	if reason != "" || err != nil {
+		eventType = events.BidRejected
+		bidHash = bid.Hash()
+	}
+
+	s.events.Emit(&events.Event{
+		Type:        eventType,
+		Time:        receivedAt,
+		BidHash:     bidHash,
+		Builder:     builder,
+		Validator:   hostname,
+		BlockNumber: bid.BlockNumber,
+		GasUsed:     bid.GasUsed,
+		GasFee:      bid.GasFee,
+		BuilderFee:  bid.BuilderFee,
+		TxCount:     len(bid.Txs),
+		Reason:      reason,
+		Error:       errString(err),
+		LatencyMs:   time.Since(receivedAt).Milliseconds(),
+	})
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/bnb-chain/bsc-mev-sentry/store"
+)
+
+var errBidStoreDisabled = errors.New("bid store is disabled")
+
+// BidHistoryArgs is the signed query of a builder for the history of its own bids.
+type BidHistoryArgs struct {
+	store.BidFilter
+	// Timestamp unix seconds when the query was signed
+	Timestamp int64 `json:"timestamp"`
+	// Signature of BidHistoryHash by the builder key
+	Signature hexutil.Bytes `json:"signature"`
+}
+
+// BidHistoryHash returns the hash a builder signs to query the history of its bids, the builder
+// and validator of the filter aren't signed since a builder only gets its own bids anyway.
+func BidHistoryHash(f store.BidFilter, timestamp int64) common.Hash {
+	return crypto.Keccak256Hash([]byte(fmt.Sprintf("mev_bidHistory:%d:%d:%s:%d:%d:%d:%d:%d",
+		f.FromBlock, f.ToBlock, f.Outcome, f.FromTime, f.ToTime, f.Cursor, f.Limit, timestamp)))
+}
+
+// BidHistory returns a page of the bids of the signing builder and what became of them.
+func (s *MevSentry) BidHistory(_ context.Context, args BidHistoryArgs) (*store.BidPage, error) {
+	if s.bidStore == nil {
+		return nil, newSentryError(errBidStoreDisabled.Error())
+	}
+
+	builder, err := s.verifyBuilderQuery(BidHistoryHash(args.BidFilter, args.Timestamp), args.Timestamp, args.Signature)
+	if err != nil {
+		return nil, err
+	}
+
+	// the args aren't covered by the signature, they're for the operators only
+	filter := args.BidFilter
+	filter.Builder, filter.WithArgs = &builder, false
+
+	page, err := s.bidStore.Query(filter)
+	if err != nil {
+		return nil, newSentryError(fmt.Sprintf("failed to query bid history: %v", err))
+	}
+
+	return page, nil
+}
+
+// BidHistory returns a page of the bids of any builder and validator.
+func (a *MevAdmin) BidHistory(_ context.Context, filter store.BidFilter) (*store.BidPage, error) {
+	if a.sentry.bidStore == nil {
+		return nil, errBidStoreDisabled
+	}
+
+	return a.sentry.bidStore.Query(filter)
+}
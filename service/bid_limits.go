@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/bnb-chain/bsc-mev-sentry/log"
+	"github.com/bnb-chain/bsc-mev-sentry/node"
+)
+
+// checkBidSize rejects the bid if its txs exceed the size or count limits of the validator, which
+// would reject it anyway after a round trip.
+func checkBidSize(hostname string, validator node.Validator, bid *types.RawBid) error {
+	cfg := validator.Config()
+
+	if cfg.MaxBidTxs > 0 && len(bid.Txs) > cfg.MaxBidTxs {
+		log.Errorw("bid has too many txs", "validator", hostname, "txs", len(bid.Txs), "max", cfg.MaxBidTxs)
+		return types.NewInvalidBidError(fmt.Sprintf("bid has %d txs, at most %d allowed", len(bid.Txs), cfg.MaxBidTxs))
+	}
+
+	if size := bidSize(bid); cfg.MaxBidSize > 0 && size > cfg.MaxBidSize {
+		log.Errorw("bid is too large", "validator", hostname, "size", size, "max", cfg.MaxBidSize)
+		return types.NewInvalidBidError(fmt.Sprintf("bid txs are %d bytes, at most %d allowed", size, cfg.MaxBidSize))
+	}
+
+	return nil
+}
+
+// checkGas rejects the bid if its gas used or gas fee is obviously invalid against the mev params
+// of the validator, returning the rejection reason along with the error. Bids are left to the
+// validator if its mev params aren't fetched yet.
+func checkGas(hostname string, validator node.Validator, bid *types.RawBid) (string, error) {
+	if bid.GasFee == nil || bid.GasFee.Sign() < 0 {
+		return rejectGasFee, types.NewInvalidBidError("gas fee should be non-negative")
+	}
+
+	if bid.GasUsed == 0 && len(bid.Txs) > 0 {
+		return rejectGasUsed, types.NewInvalidBidError("gas used should not be 0")
+	}
+
+	params, _ := validator.MevParams(context.Background())
+	if params == nil {
+		return "", nil
+	}
+
+	if params.GasCeil > 0 && bid.GasUsed > params.GasCeil {
+		log.Errorw("bid gas used exceeds the gas ceil", "validator", hostname, "gasUsed", bid.GasUsed,
+			"gasCeil", params.GasCeil)
+		return rejectGasUsed, types.NewInvalidBidError(fmt.Sprintf("gas used %d exceeds the gas ceil %d",
+			bid.GasUsed, params.GasCeil))
+	}
+
+	if params.GasPrice == nil || bid.GasUsed == 0 {
+		return "", nil
+	}
+
+	gasPrice := new(big.Int).Div(bid.GasFee, new(big.Int).SetUint64(bid.GasUsed))
+	if gasPrice.Cmp(params.GasPrice) < 0 {
+		log.Errorw("bid gas price is below the mev params", "validator", hostname, "gasPrice", gasPrice,
+			"minGasPrice", params.GasPrice)
+		return rejectGasPrice, types.NewInvalidBidError(fmt.Sprintf("bid gas price is lower than %v", params.GasPrice))
+	}
+
+	return "", nil
+}
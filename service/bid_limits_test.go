@@ -0,0 +1,50 @@
+package service
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bnb-chain/bsc-mev-sentry/node"
+)
+
+func TestCheckBidSize(t *testing.T) {
+	validator := &stubValidator{cfg: node.ValidatorConfig{MaxBidSize: 10, MaxBidTxs: 2}}
+
+	assert.NoError(t, checkBidSize("v", validator, &types.RawBid{Txs: []hexutil.Bytes{make([]byte, 5), make([]byte, 5)}}))
+	assert.Error(t, checkBidSize("v", validator, &types.RawBid{Txs: []hexutil.Bytes{make([]byte, 11)}}))
+	assert.Error(t, checkBidSize("v", validator, &types.RawBid{Txs: []hexutil.Bytes{{1}, {2}, {3}}}))
+
+	validator.cfg = node.ValidatorConfig{}
+	assert.NoError(t, checkBidSize("v", validator, &types.RawBid{Txs: []hexutil.Bytes{make([]byte, 11)}}))
+}
+
+func TestCheckGas(t *testing.T) {
+	validator := &stubValidator{params: &types.MevParams{GasCeil: 1000, GasPrice: big.NewInt(10)}}
+	txs := []hexutil.Bytes{{1}}
+
+	for _, c := range []struct {
+		bid    *types.RawBid
+		reason string
+	}{
+		{&types.RawBid{Txs: txs, GasUsed: 100, GasFee: big.NewInt(1000)}, ""},
+		{&types.RawBid{Txs: txs, GasUsed: 100}, rejectGasFee},
+		{&types.RawBid{Txs: txs, GasUsed: 100, GasFee: big.NewInt(-1)}, rejectGasFee},
+		{&types.RawBid{Txs: txs, GasFee: big.NewInt(1000)}, rejectGasUsed},
+		{&types.RawBid{Txs: txs, GasUsed: 1001, GasFee: big.NewInt(100000)}, rejectGasUsed},
+		{&types.RawBid{Txs: txs, GasUsed: 100, GasFee: big.NewInt(999)}, rejectGasPrice},
+	} {
+		reason, err := checkGas("v", validator, c.bid)
+		assert.Equal(t, c.reason, reason)
+		assert.Equal(t, c.reason != "", err != nil)
+	}
+
+	// bids are left to the validator until its mev params are fetched
+	validator.params = nil
+	reason, err := checkGas("v", validator, &types.RawBid{Txs: txs, GasUsed: 1001, GasFee: big.NewInt(1)})
+	assert.NoError(t, err)
+	assert.Empty(t, reason)
+}
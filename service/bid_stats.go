@@ -0,0 +1,33 @@
+package service
+
+import (
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/bnb-chain/bsc-mev-sentry/metrics"
+)
+
+// recordBidStats exports the aggregates of an admitted bid, the transactions themselves are never kept.
+func recordBidStats(hostname string, bid *types.RawBid) {
+	size := bidSize(bid)
+
+	metrics.BidTxCountHist.WithLabelValues(hostname).Observe(float64(len(bid.Txs)))
+	metrics.BidGasUsedHist.WithLabelValues(hostname).Observe(float64(bid.GasUsed))
+	metrics.BidSizeHist.WithLabelValues(hostname).Observe(float64(size))
+
+	if bid.GasFee != nil {
+		metrics.BidFeeHist.WithLabelValues(hostname, "gas").Observe(inUnit(bid.GasFee, "gwei"))
+	}
+	if bid.BuilderFee != nil {
+		metrics.BidFeeHist.WithLabelValues(hostname, "builder").Observe(inUnit(bid.BuilderFee, "gwei"))
+	}
+}
+
+// bidSize returns the total size of the txs of the bid in bytes.
+func bidSize(bid *types.RawBid) int {
+	size := 0
+	for _, tx := range bid.Txs {
+		size += len(tx)
+	}
+
+	return size
+}
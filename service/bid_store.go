@@ -0,0 +1,57 @@
+package service
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/bnb-chain/bsc-mev-sentry/store"
+)
+
+// storeBid persists the bid and what became of it, if the bid store is enabled.
+func (s *MevSentry) storeBid(builder common.Address, hostname string, args types.BidArgs, receivedAt time.Time,
+	reason string, err error) {
+	bid, payBidTx := args.RawBid, args.PayBidTx
+	if s.bidStore == nil || bid == nil {
+		return
+	}
+
+	outcome := store.BidAccepted
+	if reason != "" || err != nil {
+		outcome = store.BidRejected
+	}
+
+	var payTxHash common.Hash
+	if len(payBidTx) > 0 {
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(payBidTx); err == nil {
+			payTxHash = tx.Hash()
+		}
+	}
+
+	var signed json.RawMessage
+	if s.bidStore.KeepsArgs() {
+		// the pay bid tx is left out, it's signed again on replay
+		signed, _ = json.Marshal(types.BidArgs{RawBid: bid, Signature: args.Signature})
+	}
+
+	s.bidStore.Record(&store.Bid{
+		Hash:        bid.Hash(),
+		Builder:     builder,
+		Validator:   hostname,
+		BlockNumber: bid.BlockNumber,
+		ParentHash:  bid.ParentHash,
+		GasUsed:     bid.GasUsed,
+		GasFee:      bid.GasFee,
+		BuilderFee:  bid.BuilderFee,
+		TxCount:     len(bid.Txs),
+		PayTxHash:   payTxHash,
+		ReceivedAt:  receivedAt,
+		Outcome:     outcome,
+		Reason:      reason,
+		Error:       errString(err),
+		Args:        signed,
+	})
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/bnb-chain/bsc-mev-sentry/log"
+	"github.com/bnb-chain/bsc-mev-sentry/metrics"
+	"github.com/bnb-chain/bsc-mev-sentry/node"
+)
+
+const defaultBlockInterval = 3 * time.Second
+
+// checkBidWindow observes when the bid arrives relative to the expected timestamp of the target
+// block, and rejects it if it's outside the window configured for the validator. Bids on a parent
+// other than the validator's latest block are left to the validator.
+func (s *MevSentry) checkBidWindow(hostname string, builder common.Address, validator node.Validator,
+	bid *types.RawBid,
+) error {
+	head := validator.Head()
+	if head == nil || head.Hash != bid.ParentHash {
+		return nil
+	}
+
+	window := validator.Config().BidWindow
+
+	interval := time.Duration(window.BlockInterval)
+	if interval <= 0 {
+		interval = defaultBlockInterval
+	}
+
+	expected := time.Unix(int64(head.Time), 0).Add(interval)
+	offset := time.Since(expected)
+
+	metrics.BidArrivalOffsetHist.WithLabelValues(hostname).Observe(float64(offset.Milliseconds()))
+	s.arrivals.record(hostname, bid.BlockNumber, builder, offset)
+
+	if !window.Enabled {
+		return nil
+	}
+
+	earliest, latest := time.Duration(window.Earliest), time.Duration(window.Latest)
+	if offset < earliest || offset > latest {
+		log.Errorw("bid arrives outside the window", "validator", hostname, "offset", offset,
+			"earliest", earliest, "latest", latest)
+		return types.NewInvalidBidError(fmt.Sprintf("bid arrives at %v relative to block time, out of [%v, %v]",
+			offset, earliest, latest))
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/bnb-chain/bsc-mev-sentry/store"
+)
+
+// defaultBuilderStatsHours is the window of builder stats if not given
+const defaultBuilderStatsHours = 24
+
+// BuilderStatsArgs is the signed query of a builder for its own stats.
+type BuilderStatsArgs struct {
+	// Hours of the window, defaults to 24
+	Hours int `json:"hours"`
+	// Timestamp unix seconds when the query was signed
+	Timestamp int64 `json:"timestamp"`
+	// Signature of BuilderStatsHash by the builder key
+	Signature hexutil.Bytes `json:"signature"`
+}
+
+// BuilderStatsHash returns the hash a builder signs to query its stats.
+func BuilderStatsHash(hours int, timestamp int64) common.Hash {
+	return crypto.Keccak256Hash([]byte(fmt.Sprintf("mev_builderStats:%d:%d", hours, timestamp)))
+}
+
+// BuilderStats returns the stats of the signing builder over the last hours, nil if it has no bids.
+func (s *MevSentry) BuilderStats(_ context.Context, args BuilderStatsArgs) (*store.BuilderStats, error) {
+	if s.bidStore == nil {
+		return nil, newSentryError(errBidStoreDisabled.Error())
+	}
+
+	builder, err := s.verifyBuilderQuery(BuilderStatsHash(args.Hours, args.Timestamp), args.Timestamp, args.Signature)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := s.bidStore.BuilderStats(statsSince(args.Hours), &builder)
+	if err != nil {
+		return nil, newSentryError(fmt.Sprintf("failed to query builder stats: %v", err))
+	}
+
+	if len(stats) == 0 {
+		return nil, nil
+	}
+
+	return stats[0], nil
+}
+
+// BuilderStats returns the stats of all builders with bids over the last hours.
+func (a *MevAdmin) BuilderStats(_ context.Context, hours int) ([]*store.BuilderStats, error) {
+	if a.sentry.bidStore == nil {
+		return nil, errBidStoreDisabled
+	}
+
+	return a.sentry.bidStore.BuilderStats(statsSince(hours), nil)
+}
+
+func statsSince(hours int) time.Time {
+	if hours <= 0 {
+		hours = defaultBuilderStatsHours
+	}
+
+	return time.Now().Add(-time.Duration(hours) * time.Hour)
+}
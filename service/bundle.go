@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/bnb-chain/bsc-mev-sentry/log"
+	"github.com/bnb-chain/bsc-mev-sentry/metrics"
+)
+
+// BundleAdapter serves Flashbots style eth_sendBundle and eth_callBundle, translating bundles into bids.
+type BundleAdapter struct {
+	sentry *MevSentry
+}
+
+func NewBundleAdapter(sentry *MevSentry) *BundleAdapter {
+	return &BundleAdapter{sentry: sentry}
+}
+
+// SendBundleArgs is a Flashbots style bundle along with the fields of a bid Flashbots bundles don't have. Other
+// Flashbots fields, e.g. minTimestamp, are ignored.
+type SendBundleArgs struct {
+	Txs               []hexutil.Bytes `json:"txs"`
+	BlockNumber       hexutil.Uint64  `json:"blockNumber"`
+	RevertingTxHashes []common.Hash   `json:"revertingTxHashes"`
+
+	// ParentHash of the bid, by eth_callBundle the latest block of the validator if blockNumber is the next one.
+	// Required by eth_sendBundle, the one returned by eth_callBundle, as the latest block may have changed since.
+	ParentHash *common.Hash   `json:"parentHash"`
+	GasUsed    hexutil.Uint64 `json:"gasUsed"`
+	GasFee     *hexutil.Big   `json:"gasFee"`
+	BuilderFee *hexutil.Big   `json:"builderFee"`
+	// Signature of the bid hash returned by eth_callBundle by the builder key, required by eth_sendBundle
+	Signature hexutil.Bytes `json:"signature"`
+}
+
+// BundleResult is the hash of the bid a bundle is translated into, along with the bid by eth_callBundle.
+type BundleResult struct {
+	BundleHash common.Hash   `json:"bundleHash"`
+	RawBid     *types.RawBid `json:"rawBid,omitempty"`
+}
+
+// CallBundle translates the bundle into a bid without sending it, the builder signs the returned bundle hash and
+// sends the bundle along with the parentHash of the returned bid.
+func (a *BundleAdapter) CallBundle(ctx context.Context, args SendBundleArgs) (result *BundleResult, err error) {
+	method := "eth_callBundle"
+	start := time.Now()
+	defer recordLatency(method, servedLocally, start)
+	defer func() {
+		if err != nil {
+			if rpcErr, ok := err.(rpc.Error); ok {
+				metrics.ApiErrorCounter.WithLabelValues(method, strconv.Itoa(rpcErr.ErrorCode())).Inc()
+			}
+		}
+	}()
+
+	bid, err := a.bid(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BundleResult{BundleHash: bid.Hash(), RawBid: bid}, nil
+}
+
+// SendBundle translates the bundle into a bid and sends it as mev_sendBid does, the bundle hash is the bid hash.
+func (a *BundleAdapter) SendBundle(ctx context.Context, args SendBundleArgs) (result *BundleResult, err error) {
+	method := "eth_sendBundle"
+	start := time.Now()
+	defer recordLatency(method, servedFromUpstream, start)
+	defer func() {
+		if err != nil {
+			if rpcErr, ok := err.(rpc.Error); ok {
+				metrics.ApiErrorCounter.WithLabelValues(method, strconv.Itoa(rpcErr.ErrorCode())).Inc()
+			}
+		}
+	}()
+
+	if len(args.Signature) == 0 {
+		return nil, types.NewInvalidBidError("signature of the bundle hash returned by eth_callBundle is required")
+	}
+
+	// the parent is not resolved again, the bid would not be the one signed if the head has moved on
+	if args.ParentHash == nil {
+		return nil, types.NewInvalidBidError("parentHash of the bid returned by eth_callBundle is required")
+	}
+
+	bid, err := a.bid(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+
+	bidHash, err := a.sentry.SendBid(ctx, types.BidArgs{RawBid: bid, Signature: args.Signature})
+	if err != nil {
+		return nil, err
+	}
+
+	return &BundleResult{BundleHash: bidHash}, nil
+}
+
+// bid translates the bundle into a bid, the txs not allowed to revert are the unrevertible ones.
+func (a *BundleAdapter) bid(ctx context.Context, args SendBundleArgs) (*types.RawBid, error) {
+	if len(args.Txs) == 0 {
+		return nil, types.NewInvalidBidError("bundle has no txs")
+	}
+
+	if args.GasUsed == 0 || args.GasFee == nil {
+		return nil, types.NewInvalidBidError("gasUsed and gasFee are required")
+	}
+
+	reverting := make(map[common.Hash]struct{}, len(args.RevertingTxHashes))
+	for _, hash := range args.RevertingTxHashes {
+		reverting[hash] = struct{}{}
+	}
+
+	bid := &types.RawBid{
+		BlockNumber:  uint64(args.BlockNumber),
+		Txs:          args.Txs,
+		UnRevertible: make([]common.Hash, 0, len(args.Txs)),
+		GasUsed:      uint64(args.GasUsed),
+		GasFee:       args.GasFee.ToInt(),
+		BuilderFee:   big.NewInt(0),
+	}
+
+	if args.BuilderFee != nil {
+		bid.BuilderFee = args.BuilderFee.ToInt()
+	}
+
+	for i, raw := range args.Txs {
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(raw); err != nil {
+			return nil, types.NewInvalidBidError(fmt.Sprintf("invalid tx #%d: %v", i, err))
+		}
+
+		if _, ok := reverting[tx.Hash()]; !ok {
+			bid.UnRevertible = append(bid.UnRevertible, tx.Hash())
+		}
+	}
+
+	if args.ParentHash != nil {
+		bid.ParentHash = *args.ParentHash
+		return bid, nil
+	}
+
+	hostname := a.sentry.route(ctx)
+	validator, ok := a.sentry.validator(hostname)
+	if !ok {
+		log.Errorw("validator not found", "hostname", hostname)
+		return nil, types.NewInvalidBidError("validator hostname not found")
+	}
+
+	head := validator.Head()
+	if head == nil || head.Number+1 != bid.BlockNumber {
+		return nil, types.NewInvalidBidError("parentHash is required unless blockNumber is the next block")
+	}
+	bid.ParentHash = head.Hash
+
+	return bid, nil
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/bsc-mev-sentry/node"
+)
+
+// bundleValidator serves the head the parent of a bundle is resolved from.
+type bundleValidator struct {
+	node.Validator
+	head *node.ChainHead
+}
+
+func (v *bundleValidator) Head() *node.ChainHead {
+	return v.head
+}
+
+func TestBundleTranslation(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	signer := types.LatestSignerForChainID(big.NewInt(56))
+
+	var txs []hexutil.Bytes
+	var hashes []common.Hash
+	for nonce := uint64(0); nonce < 2; nonce++ {
+		tx, err := types.SignNewTx(key, signer, &types.LegacyTx{Nonce: nonce, Gas: 21000, GasPrice: big.NewInt(1)})
+		require.NoError(t, err)
+		raw, err := tx.MarshalBinary()
+		require.NoError(t, err)
+		txs, hashes = append(txs, raw), append(hashes, tx.Hash())
+	}
+
+	head := &node.ChainHead{Hash: common.HexToHash("0x01"), Number: 100}
+	adapter := NewBundleAdapter(&MevSentry{validators: map[string]node.Validator{"v": &bundleValidator{head: head}}})
+	ctx := WithTarget(context.Background(), "v")
+
+	args := SendBundleArgs{
+		Txs:               txs,
+		BlockNumber:       101,
+		RevertingTxHashes: hashes[1:],
+		GasUsed:           42000,
+		GasFee:            (*hexutil.Big)(big.NewInt(42000)),
+	}
+
+	result, err := adapter.CallBundle(ctx, args)
+	require.NoError(t, err)
+	assert.Equal(t, head.Hash, result.RawBid.ParentHash)
+	assert.Equal(t, hashes[:1], result.RawBid.UnRevertible)
+	assert.Equal(t, int64(0), result.RawBid.BuilderFee.Int64())
+	assert.Equal(t, result.RawBid.Hash(), result.BundleHash)
+
+	// the parent can't be told unless the bundle is for the next block
+	args.BlockNumber = 102
+	_, err = adapter.CallBundle(ctx, args)
+	assert.Error(t, err)
+
+	parentHash := common.HexToHash("0x02")
+	args.ParentHash = &parentHash
+	result, err = adapter.CallBundle(ctx, args)
+	require.NoError(t, err)
+	assert.Equal(t, parentHash, result.RawBid.ParentHash)
+
+	_, err = adapter.SendBundle(ctx, args)
+	assert.Error(t, err, "signature is required")
+
+	// the parent of the prepared bid is sent back rather than resolved again
+	args.BlockNumber, args.ParentHash, args.Signature = 101, nil, hexutil.Bytes{0x01}
+	_, err = adapter.SendBundle(ctx, args)
+	assert.Error(t, err, "parentHash is required")
+	args.ParentHash = &parentHash
+
+	args.Txs = append(args.Txs, hexutil.Bytes{0x01})
+	_, err = adapter.CallBundle(ctx, args)
+	assert.Error(t, err)
+}
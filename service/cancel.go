@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/bnb-chain/bsc-mev-sentry/log"
+)
+
+// recentBidsCapacity bounds the bids remembered for cancellation, far more than a few blocks of bids
+const recentBidsCapacity = 100000
+
+// statuses of a bid cancellation
+const (
+	// CancelStatusCancelled the validator withdrew the bid, and the sentry won't forward it again
+	CancelStatusCancelled = "cancelled"
+	// CancelStatusNotCancellable the validator doesn't support cancellation, the bid may still win but the sentry
+	// won't forward it again
+	CancelStatusNotCancellable = "not_cancellable"
+	// CancelStatusExpired the block of the bid is already produced
+	CancelStatusExpired = "expired"
+	// CancelStatusNotFound the bid wasn't forwarded by the sentry lately
+	CancelStatusNotFound = "not_found"
+)
+
+type recentBid struct {
+	builder   common.Address
+	validator string
+	block     uint64
+	cancelled bool
+}
+
+// recentBids remembers the bids forwarded lately, the oldest ones are forgotten beyond capacity.
+type recentBids struct {
+	mu     sync.Mutex
+	bids   map[common.Hash]*recentBid
+	ring   []common.Hash // insertion order, indexed by next % capacity
+	next   int
+	filled bool
+}
+
+func newRecentBids(capacity int) *recentBids {
+	return &recentBids{
+		bids: make(map[common.Hash]*recentBid, capacity),
+		ring: make([]common.Hash, capacity),
+	}
+}
+
+func (r *recentBids) add(hash common.Hash, bid *recentBid) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.bids[hash]; ok {
+		return
+	}
+
+	if r.filled {
+		delete(r.bids, r.ring[r.next])
+	}
+
+	r.bids[hash] = bid
+	r.ring[r.next] = hash
+	r.next = (r.next + 1) % len(r.ring)
+	r.filled = r.filled || r.next == 0
+}
+
+// get returns a copy of the bid, nil if it's not remembered.
+func (r *recentBids) get(hash common.Hash) *recentBid {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	bid, ok := r.bids[hash]
+	if !ok {
+		return nil
+	}
+
+	cp := *bid
+	return &cp
+}
+
+func (r *recentBids) cancel(hash common.Hash) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if bid, ok := r.bids[hash]; ok {
+		bid.cancelled = true
+	}
+}
+
+func (r *recentBids) cancelled(hash common.Hash) bool {
+	bid := r.get(hash)
+	return bid != nil && bid.cancelled
+}
+
+// CancelBidArgs is the signed request of a builder to withdraw one of its bids.
+type CancelBidArgs struct {
+	BidHash common.Hash `json:"bidHash"`
+	// Timestamp unix seconds when the request was signed
+	Timestamp int64 `json:"timestamp"`
+	// Signature of CancelBidHash by the builder key
+	Signature hexutil.Bytes `json:"signature"`
+}
+
+// CancelBidHash returns the hash a builder signs to cancel a bid.
+func CancelBidHash(bidHash common.Hash, timestamp int64) common.Hash {
+	return crypto.Keccak256Hash([]byte(fmt.Sprintf("mev_cancelBid:%s:%d", bidHash.Hex(), timestamp)))
+}
+
+// CancelBid withdraws a bid of the signing builder, the sentry doesn't forward it again and asks the validator
+// to drop it if supported. It returns one of the CancelStatus.
+func (s *MevSentry) CancelBid(ctx context.Context, args CancelBidArgs) (string, error) {
+	defer s.budget(&ctx, "mev_cancelBid")()
+
+	builder, err := s.verifyBuilderQuery(CancelBidHash(args.BidHash, args.Timestamp), args.Timestamp, args.Signature)
+	if err != nil {
+		return "", err
+	}
+
+	bid := s.recentBids.get(args.BidHash)
+	if bid == nil || bid.builder != builder {
+		return CancelStatusNotFound, nil
+	}
+
+	if bid.cancelled {
+		return CancelStatusCancelled, nil
+	}
+
+	validator, ok := s.validator(bid.validator)
+	if !ok {
+		return CancelStatusNotFound, nil
+	}
+
+	if head := validator.Head(); head != nil && head.Number >= bid.block {
+		return CancelStatusExpired, nil
+	}
+
+	if !validator.Capabilities().CancelBid {
+		s.recentBids.cancel(args.BidHash)
+		return CancelStatusNotCancellable, nil
+	}
+
+	if err = validator.CancelBid(ctx, args.BidHash); err != nil {
+		return "", newSentryError(fmt.Sprintf("failed to cancel bid: %v", err))
+	}
+
+	s.recentBids.cancel(args.BidHash)
+
+	log.Infow("bid cancelled", "builder", builder, "validator", bid.validator, "bidHash", args.BidHash)
+
+	return CancelStatusCancelled, nil
+}
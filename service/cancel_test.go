@@ -0,0 +1,31 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecentBids(t *testing.T) {
+	bids := newRecentBids(2)
+	h1, h2, h3 := common.HexToHash("0x01"), common.HexToHash("0x02"), common.HexToHash("0x03")
+	builder := common.HexToAddress("0x0a")
+
+	bids.add(h1, &recentBid{builder: builder, validator: "v", block: 10})
+	bids.add(h2, &recentBid{builder: builder, validator: "v", block: 10})
+	bids.cancel(h2)
+
+	assert.Equal(t, &recentBid{builder: builder, validator: "v", block: 10}, bids.get(h1))
+	assert.True(t, bids.cancelled(h2))
+
+	// the oldest bid is forgotten beyond capacity
+	bids.add(h3, &recentBid{builder: builder, validator: "v", block: 11})
+	assert.Nil(t, bids.get(h1))
+	assert.True(t, bids.cancelled(h2))
+	assert.False(t, bids.cancelled(h3))
+
+	// adding a remembered bid again keeps its state
+	bids.add(h2, &recentBid{builder: builder, validator: "v", block: 10})
+	assert.True(t, bids.cancelled(h2))
+}
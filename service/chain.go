@@ -0,0 +1,120 @@
+package service
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/bnb-chain/bsc-mev-sentry/log"
+	"github.com/bnb-chain/bsc-mev-sentry/node"
+)
+
+// ChainConfig groups the validators of one network, e.g. BSC mainnet, Chapel or opBNB, served by the sentry along
+// with the validators of other networks.
+type ChainConfig struct {
+	// Name of the chain, referred to by the Chain of validators and the Chains of builders
+	Name string
+	// ChainID the validators of the chain must report, txs of bids to them must be signed for it, not checked if 0
+	ChainID uint64
+	// ChainRPC url of the chain rpc of the chain, the validator set is read from it unless ValidatorSet has its own
+	ChainRPC string
+	// ValidatorSet reads the active validators of the chain, in place of the ValidatorSet of the service
+	ValidatorSet node.ValidatorSetConfig
+}
+
+// chain is a group of validators of one network.
+type chain struct {
+	cfg          ChainConfig
+	validatorSet *node.ValidatorSet
+}
+
+// startChains creates the chains and starts reading their validator sets.
+func (s *MevSentry) startChains(cfgs []ChainConfig) error {
+	// the chains are known before the validator sets are read first, to tell which validators each set reads
+	s.chains = make(map[string]*chain, len(cfgs))
+	for _, cfg := range cfgs {
+		if cfg.ValidatorSet.ChainRPC == "" {
+			cfg.ValidatorSet.ChainRPC = cfg.ChainRPC
+		}
+		s.chains[cfg.Name] = &chain{cfg: cfg}
+	}
+
+	for name, c := range s.chains {
+		name := name
+		validatorSet, err := node.NewValidatorSet(c.cfg.ValidatorSet, func() map[common.Address]string {
+			return s.consensusAddresses(name)
+		})
+		if err != nil {
+			s.closeChains()
+			return fmt.Errorf("chain %s: %w", name, err)
+		}
+		c.validatorSet = validatorSet
+	}
+
+	return nil
+}
+
+// validatorSetChain returns the name of the chain whose validator set the validator is checked against, empty for
+// the validator set of the service.
+func (s *MevSentry) validatorSetChain(validator node.Validator) string {
+	if len(s.chains) == 0 {
+		return ""
+	}
+
+	if c, ok := s.chains[validator.Config().Chain]; ok && c.cfg.ValidatorSet.ChainRPC != "" {
+		return c.cfg.Name
+	}
+
+	return ""
+}
+
+// validatorSetOf returns the reader of the validator set the validator is checked against, nil if not configured.
+func (s *MevSentry) validatorSetOf(validator node.Validator) *node.ValidatorSet {
+	if name := s.validatorSetChain(validator); name != "" {
+		return s.chains[name].validatorSet
+	}
+
+	return s.validatorSet
+}
+
+// chainIDOf returns the chain id the validator must report, nil if not configured.
+func (s *MevSentry) chainIDOf(validator node.Validator) *big.Int {
+	if len(s.chains) == 0 {
+		return nil
+	}
+
+	if c, ok := s.chains[validator.Config().Chain]; ok && c.cfg.ChainID != 0 {
+		return new(big.Int).SetUint64(c.cfg.ChainID)
+	}
+
+	return nil
+}
+
+// checkBuilderChain rejects the bid if the builder doesn't bid on the chain of the validator.
+func checkBuilderChain(hostname string, builder node.Builder, validator node.Validator) error {
+	chains := builder.Config().Chains
+	if len(chains) == 0 {
+		return nil
+	}
+
+	chain := validator.Config().Chain
+	for _, c := range chains {
+		if c == chain {
+			return nil
+		}
+	}
+
+	log.Errorw("builder doesn't bid on the chain of the validator", "builder", builder.Config().Address,
+		"validator", hostname, "chain", chain)
+
+	return types.NewInvalidBidError(fmt.Sprintf("builder is not allowed on chain %s", chain))
+}
+
+// closeChains stops reading the validator sets of the chains.
+func (s *MevSentry) closeChains() {
+	for _, c := range s.chains {
+		c.validatorSet.Close()
+	}
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/bnb-chain/bsc-mev-sentry/log"
+	"github.com/bnb-chain/bsc-mev-sentry/node"
+)
+
+// checkChainID rejects the bid if any of its txs is signed for a chain other than the validator's,
+// so bids can't be replayed between sentries of different networks. Unprotected legacy txs are
+// valid on any chain and left as is. Validators of a chain with a chain id are always checked
+// against it, and must report it themselves.
+func (s *MevSentry) checkChainID(hostname string, validator node.Validator, bid *types.RawBid) error {
+	chainID := s.chainIDOf(validator)
+	if chainID != nil {
+		if reported := validator.ChainID(); reported != nil && reported.Cmp(chainID) != 0 {
+			log.Errorw("validator reports another chain", "validator", hostname, "chain", validator.Config().Chain,
+				"chainID", reported, "expected", chainID)
+			return &sentryError{
+				error: fmt.Errorf("validator is on chain %v, expected %v", reported, chainID),
+				code:  chainIDErrorCode,
+			}
+		}
+	} else if !validator.Config().StrictChainID {
+		return nil
+	} else if chainID = validator.ChainID(); chainID == nil {
+		return newSentryError("chain id of validator is unknown")
+	}
+
+	for i, raw := range bid.Txs {
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(raw); err != nil {
+			return types.NewInvalidBidError(fmt.Sprintf("invalid tx #%d: %v", i, err))
+		}
+
+		if !tx.Protected() || tx.ChainId().Cmp(chainID) == 0 {
+			continue
+		}
+
+		log.Errorw("bid tx signed for another chain", "validator", hostname, "index", i,
+			"chainID", tx.ChainId(), "expected", chainID)
+
+		return &sentryError{
+			error: fmt.Errorf("tx #%d is signed for chain %v, expected %v", i, tx.ChainId(), chainID),
+			code:  chainIDErrorCode,
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/bsc-mev-sentry/node"
+)
+
+func TestChains(t *testing.T) {
+	s := &MevSentry{}
+	require.NoError(t, s.startChains([]ChainConfig{{Name: "bsc", ChainID: 56}, {Name: "chapel"}}))
+	defer s.closeChains()
+
+	txKey, _ := crypto.GenerateKey()
+	signedFor := func(chainID int64) *types.RawBid {
+		tx, _ := types.SignNewTx(txKey, types.LatestSignerForChainID(big.NewInt(chainID)), &types.LegacyTx{Gas: 21000})
+		raw, _ := tx.MarshalBinary()
+		return &types.RawBid{Txs: []hexutil.Bytes{raw}}
+	}
+
+	bsc := &stubValidator{cfg: node.ValidatorConfig{Chain: "bsc"}, chainID: big.NewInt(56)}
+	assert.NoError(t, s.checkChainID("bsc-fuji", bsc, signedFor(56)))
+	assert.Error(t, s.checkChainID("bsc-fuji", bsc, signedFor(97)))
+
+	// put in the wrong chain
+	misplaced := &stubValidator{cfg: node.ValidatorConfig{Chain: "bsc"}, chainID: big.NewInt(97)}
+	assert.Error(t, s.checkChainID("bsc-chapel", misplaced, signedFor(56)))
+
+	// chains without a chain id are checked as configured per validator
+	chapel := &stubValidator{cfg: node.ValidatorConfig{Chain: "chapel"}, chainID: big.NewInt(97)}
+	assert.NoError(t, s.checkChainID("bsc-chapel", chapel, signedFor(56)))
+
+	builder := &stubBuilder{cfg: node.BuilderConfig{Chains: []string{"bsc"}}}
+	assert.NoError(t, checkBuilderChain("bsc-fuji", builder, bsc))
+	assert.Error(t, checkBuilderChain("bsc-chapel", builder, chapel))
+	assert.NoError(t, checkBuilderChain("bsc-chapel", &stubBuilder{}, chapel))
+
+	assert.False(t, s.validatorSetConfigured())
+	assert.Empty(t, s.validatorSetChain(bsc))
+}
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/bnb-chain/bsc-mev-sentry/cluster"
+	"github.com/bnb-chain/bsc-mev-sentry/log"
+	"github.com/bnb-chain/bsc-mev-sentry/node"
+)
+
+// clusterTimeout bounds the calls to the shared state, bids go on as if it's disabled once it's exceeded.
+const clusterTimeout = time.Second
+
+// clusterState shares the pay account nonces, the bids sent and the builder bans with the other replicas of the
+// sentry behind the same load balancer.
+type clusterState struct {
+	store        cluster.Store
+	syncInterval time.Duration
+	bidTTL       time.Duration
+	nonceTTL     time.Duration
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func newClusterState(cfg cluster.Config) (*clusterState, error) {
+	store, err := cluster.New(cfg)
+	if err != nil || store == nil {
+		return nil, err
+	}
+
+	syncInterval, bidTTL, nonceTTL := cfg.Intervals()
+
+	return &clusterState{
+		store:        store,
+		syncInterval: syncInterval,
+		bidTTL:       bidTTL,
+		nonceTTL:     nonceTTL,
+		stop:         make(chan struct{}),
+		done:         make(chan struct{}),
+	}, nil
+}
+
+// shared returns the store of the shared state, nil if it's disabled.
+func (c *clusterState) shared() cluster.Store {
+	if c == nil {
+		return nil
+	}
+
+	return c.store
+}
+
+// StartCluster starts syncing the nonces and the bans with the other replicas, if the shared state is enabled.
+func (s *MevSentry) StartCluster() error {
+	if s.cluster == nil {
+		return nil
+	}
+
+	s.syncCluster()
+	go s.clusterLoop()
+
+	return nil
+}
+
+// StopCluster stops syncing with the other replicas.
+func (s *MevSentry) StopCluster(ctx context.Context) error {
+	if s.cluster == nil {
+		return nil
+	}
+
+	close(s.cluster.stop)
+
+	select {
+	case <-s.cluster.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *MevSentry) clusterLoop() {
+	defer close(s.cluster.done)
+
+	ticker := time.NewTicker(s.cluster.syncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.cluster.stop:
+			return
+		case <-ticker.C:
+			s.syncCluster()
+		}
+	}
+}
+
+// syncCluster leases the highest pay account nonce any replica knows of, so that a replica behind the chain doesn't
+// sign pay bid txs with a nonce used already, and takes the bans of all replicas. The nonces are shared per pay
+// account, so validators sharing one lease the same nonce.
+func (s *MevSentry) syncCluster() {
+	ctx, cancel := context.WithTimeout(context.Background(), clusterTimeout)
+	defer cancel()
+
+	// the shared state isn't called under the lock, which would hold up the bids behind a topology change
+	s.mu.RLock()
+	validators := make(map[string]node.Validator, len(s.validators))
+	for hostname, validator := range s.validators {
+		validators[hostname] = validator
+	}
+	s.mu.RUnlock()
+
+	for hostname, validator := range validators {
+		nonce := validator.PayAccountNonce()
+		highest, err := s.cluster.store.SyncNonce(ctx, validator.PayAccount(), nonce, s.cluster.nonceTTL)
+		if err != nil {
+			log.Errorw("failed to sync pay account nonce", "validator", hostname, "store", s.cluster.store.Name(),
+				"err", err)
+			break
+		}
+		if highest > nonce {
+			validator.LeaseNonce(highest)
+		}
+	}
+
+	if err := s.bans.sync(ctx); err != nil {
+		log.Errorw("failed to sync builder bans", "store", s.cluster.store.Name(), "err", err)
+	}
+}
+
+// markBid rejects the bid if any replica has sent it to the validator already, and marks it sent otherwise.
+func (s *MevSentry) markBid(ctx context.Context, hostname string, bid *types.RawBid) error {
+	if s.cluster == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, clusterTimeout)
+	defer cancel()
+
+	first, err := s.cluster.store.MarkBid(ctx, bid.Hash(), hostname, s.cluster.bidTTL)
+	if err != nil {
+		log.Errorw("failed to mark bid sent", "validator", hostname, "store", s.cluster.store.Name(), "err", err)
+		return nil
+	}
+	if !first {
+		return types.NewInvalidBidError("bid is already sent")
+	}
+
+	return nil
+}
+
+// forgetBid removes the mark of the bid failed to be sent, so that it can be sent again.
+func (s *MevSentry) forgetBid(hostname string, bidHash common.Hash) {
+	if s.cluster == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), clusterTimeout)
+	defer cancel()
+
+	if err := s.cluster.store.ForgetBid(ctx, bidHash, hostname); err != nil {
+		log.Errorw("failed to forget bid", "validator", hostname, "store", s.cluster.store.Name(), "err", err)
+	}
+}
+
+func (s *MevSentry) closeCluster() {
+	if s.cluster == nil {
+		return
+	}
+
+	if err := s.cluster.store.Close(); err != nil {
+		log.Errorw("failed to close cluster store", "store", s.cluster.store.Name(), "err", err)
+	}
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/bnb-chain/bsc-mev-sentry/metrics"
+)
+
+// CustomMetricConfig defines a metric over bid attributes evaluated at admission.
+type CustomMetricConfig struct {
+	// Name of the metric, exported as bsc_mev_sentry_custom_<name>
+	Name string
+	// Type counter or histogram
+	Type string
+	// Attribute observed by a histogram, one of builder_fee, gas_fee, gas_used, tx_count, size
+	Attribute string
+	// Unit of the fee attributes, one of wei, gwei, bnb, defaults to wei
+	Unit string
+	// Buckets of a histogram
+	Buckets []float64
+	// Builders only counts the bids of these builders, any if empty
+	Builders []common.Address
+	// To only counts the bids with a tx to one of these addresses, any if empty
+	To []common.Address
+}
+
+type customMetric struct {
+	cfg       CustomMetricConfig
+	counter   *prometheus.CounterVec
+	histogram *prometheus.HistogramVec
+	builders  map[common.Address]struct{}
+	to        map[common.Address]struct{}
+}
+
+func newCustomMetrics(cfgs []CustomMetricConfig) ([]*customMetric, error) {
+	result := make([]*customMetric, 0, len(cfgs))
+
+	for _, cfg := range cfgs {
+		m := &customMetric{
+			cfg:      cfg,
+			builders: make(map[common.Address]struct{}, len(cfg.Builders)),
+			to:       make(map[common.Address]struct{}, len(cfg.To)),
+		}
+
+		for _, b := range cfg.Builders {
+			m.builders[b] = struct{}{}
+		}
+		for _, to := range cfg.To {
+			m.to[to] = struct{}{}
+		}
+
+		switch cfg.Unit {
+		case "", "wei", "gwei", "bnb":
+		default:
+			return nil, fmt.Errorf("custom metric %s: unknown unit %s", cfg.Name, cfg.Unit)
+		}
+
+		switch cfg.Type {
+		case "counter":
+			counter, err := metrics.NewCustomCounter(cfg.Name)
+			if err != nil {
+				return nil, fmt.Errorf("custom metric %s: %w", cfg.Name, err)
+			}
+			m.counter = counter
+		case "histogram":
+			if _, ok := bidAttribute(cfg.Attribute, cfg.Unit, &types.RawBid{}); !ok {
+				return nil, fmt.Errorf("custom metric %s: unknown attribute %s", cfg.Name, cfg.Attribute)
+			}
+
+			histogram, err := metrics.NewCustomHistogram(cfg.Name, cfg.Buckets)
+			if err != nil {
+				return nil, fmt.Errorf("custom metric %s: %w", cfg.Name, err)
+			}
+			m.histogram = histogram
+		default:
+			return nil, fmt.Errorf("custom metric %s: unknown type %s", cfg.Name, cfg.Type)
+		}
+
+		result = append(result, m)
+	}
+
+	return result, nil
+}
+
+// recordCustomMetrics evaluates the custom metrics over an admitted bid.
+func (s *MevSentry) recordCustomMetrics(hostname string, builder common.Address, bid *types.RawBid) {
+	var recipients map[common.Address]struct{} // decoded lazily, only if a metric filters by recipients
+
+	for _, m := range s.customMetrics {
+		if len(m.builders) > 0 {
+			if _, ok := m.builders[builder]; !ok {
+				continue
+			}
+		}
+
+		if len(m.to) > 0 {
+			if recipients == nil {
+				recipients = bidRecipients(bid)
+			}
+
+			if !intersects(m.to, recipients) {
+				continue
+			}
+		}
+
+		if m.counter != nil {
+			m.counter.WithLabelValues(hostname).Inc()
+			continue
+		}
+
+		if value, ok := bidAttribute(m.cfg.Attribute, m.cfg.Unit, bid); ok {
+			m.histogram.WithLabelValues(hostname).Observe(value)
+		}
+	}
+}
+
+func bidAttribute(attribute, unit string, bid *types.RawBid) (float64, bool) {
+	switch attribute {
+	case "builder_fee":
+		return inUnit(bid.BuilderFee, unit), true
+	case "gas_fee":
+		return inUnit(bid.GasFee, unit), true
+	case "gas_used":
+		return float64(bid.GasUsed), true
+	case "tx_count":
+		return float64(len(bid.Txs)), true
+	case "size":
+		return float64(bidSize(bid)), true
+	default:
+		return 0, false
+	}
+}
+
+func inUnit(wei *big.Int, unit string) float64 {
+	if wei == nil {
+		return 0
+	}
+
+	value := new(big.Float).SetInt(wei)
+	switch unit {
+	case "gwei":
+		value.Quo(value, big.NewFloat(params.GWei))
+	case "bnb":
+		value.Quo(value, big.NewFloat(params.Ether))
+	}
+
+	f, _ := value.Float64()
+	return f
+}
+
+func bidRecipients(bid *types.RawBid) map[common.Address]struct{} {
+	recipients := make(map[common.Address]struct{}, len(bid.Txs))
+	for _, raw := range bid.Txs {
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(raw); err != nil || tx.To() == nil {
+			continue
+		}
+		recipients[*tx.To()] = struct{}{}
+	}
+
+	return recipients
+}
+
+func intersects(a, b map[common.Address]struct{}) bool {
+	for k := range a {
+		if _, ok := b[k]; ok {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,36 @@
+package service
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCustomMetrics(t *testing.T) {
+	b1, b2 := common.HexToAddress("0x01"), common.HexToAddress("0x02")
+
+	customMetrics, err := newCustomMetrics([]CustomMetricConfig{
+		{Name: "test_bids_of_b1", Type: "counter", Builders: []common.Address{b1}},
+		{Name: "test_builder_fee", Type: "histogram", Attribute: "builder_fee", Unit: "bnb", Buckets: []float64{0.1, 1}},
+	})
+	require.NoError(t, err)
+
+	s := &MevSentry{customMetrics: customMetrics}
+	bid := &types.RawBid{BuilderFee: new(big.Int).Exp(big.NewInt(10), big.NewInt(17), nil)}
+
+	s.recordCustomMetrics("v", b1, bid)
+	s.recordCustomMetrics("v", b2, bid)
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(customMetrics[0].counter.WithLabelValues("v")))
+	assert.Equal(t, 1, testutil.CollectAndCount(customMetrics[1].histogram))
+
+	_, err = newCustomMetrics([]CustomMetricConfig{{Name: "test_unknown", Type: "histogram", Attribute: "unknown"}})
+	assert.Error(t, err)
+	_, err = newCustomMetrics([]CustomMetricConfig{{Name: "test_bids_of_b1", Type: "counter"}})
+	assert.Error(t, err, "duplicated metric")
+}
@@ -0,0 +1,205 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bnb-chain/bsc-mev-sentry/log"
+)
+
+type DecisionLogConfig struct {
+	// Enabled turns on the decision log
+	Enabled bool
+	// SampleRate fraction of bids logged, from 0 to 1
+	SampleRate float64
+	// Path file the decisions are appended to as JSON lines, the sentry log if empty
+	Path string
+	// BufferSize decisions waiting to be written, more are dropped instead of slowing down bids
+	BufferSize int
+}
+
+// CheckOutcome is the result of one admission check of a bid.
+type CheckOutcome struct {
+	Name     string `json:"name"`
+	Passed   bool   `json:"passed"`
+	Duration int64  `json:"durationUs"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Decision records how the sentry decided on a bid, i.e. the outcome and timing of every check it went through.
+type Decision struct {
+	Time      time.Time      `json:"time"`
+	Builder   common.Address `json:"builder"`
+	Validator string         `json:"validator"`
+	Block     uint64         `json:"block"`
+	BidHash   common.Hash    `json:"bidHash,omitempty"`
+	Result    string         `json:"result"`
+	Error     string         `json:"error,omitempty"`
+	Duration  int64          `json:"durationUs"`
+	Checks    []CheckOutcome `json:"checks"`
+}
+
+// run times the check and records its outcome, a nil decision only runs it.
+func (d *Decision) run(name string, check func() error) error {
+	if d == nil {
+		return check()
+	}
+
+	start := time.Now()
+	err := check()
+
+	outcome := CheckOutcome{Name: name, Passed: err == nil, Duration: time.Since(start).Microseconds()}
+	if err != nil {
+		outcome.Error = err.Error()
+	}
+	d.Checks = append(d.Checks, outcome)
+
+	return err
+}
+
+// decisionLog writes sampled bid decisions to its sink in the background.
+type decisionLog struct {
+	rate      float64
+	decisions chan *Decision
+	file      *os.File
+	done      chan struct{}
+
+	mu     sync.RWMutex // guards closed against emits racing with close
+	closed bool
+}
+
+func newDecisionLog(cfg DecisionLogConfig) (*decisionLog, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = 1024
+	}
+
+	l := &decisionLog{
+		rate:      cfg.SampleRate,
+		decisions: make(chan *Decision, cfg.BufferSize),
+		done:      make(chan struct{}),
+	}
+
+	if cfg.Path != "" {
+		if err := os.MkdirAll(filepath.Dir(cfg.Path), 0700); err != nil {
+			return nil, err
+		}
+
+		file, err := os.OpenFile(cfg.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+		if err != nil {
+			return nil, err
+		}
+		l.file = file
+	}
+
+	go l.loop()
+
+	return l, nil
+}
+
+// sample returns a decision to fill if the bid is sampled, otherwise nil.
+func (l *decisionLog) sample() *Decision {
+	if l == nil || rand.Float64() >= l.rate {
+		return nil
+	}
+
+	return &Decision{Time: time.Now()}
+}
+
+// emit queues the decision, it's dropped if the sink falls behind.
+func (l *decisionLog) emit(d *Decision) {
+	if l == nil || d == nil {
+		return
+	}
+
+	d.Duration = time.Since(d.Time).Microseconds()
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if l.closed {
+		return
+	}
+
+	select {
+	case l.decisions <- d:
+	default:
+		log.Debugw("decision log is full, drop decision", "builder", d.Builder)
+	}
+}
+
+func (l *decisionLog) loop() {
+	defer close(l.done)
+
+	var encoder *json.Encoder
+	if l.file != nil {
+		encoder = json.NewEncoder(l.file)
+	}
+
+	for d := range l.decisions {
+		if encoder == nil {
+			log.Infow("bid decision", "decision", d)
+			continue
+		}
+
+		if err := encoder.Encode(d); err != nil {
+			log.Errorw("failed to write decision log", "err", err)
+		}
+	}
+}
+
+// close flushes the queued decisions, later decisions are dropped.
+func (l *decisionLog) close() {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	if l.closed {
+		l.mu.Unlock()
+		return
+	}
+	l.closed = true
+	close(l.decisions)
+	l.mu.Unlock()
+
+	<-l.done
+
+	if l.file != nil {
+		if err := l.file.Close(); err != nil {
+			log.Errorw("failed to close decision log", "err", err)
+		}
+	}
+}
+
+// decisionResult is accepted, the rejection reason, or error if the bid failed otherwise.
+func decisionResult(reason string, err error) string {
+	switch {
+	case reason != "":
+		return reason
+	case errors.Is(err, errDryRun):
+		return "dry_run"
+	case err != nil:
+		return "error"
+	default:
+		return "accepted"
+	}
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	return err.Error()
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecisionLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "decisions.jsonl")
+	l, err := newDecisionLog(DecisionLogConfig{Enabled: true, SampleRate: 1, Path: path})
+	require.NoError(t, err)
+
+	d := l.sample()
+	require.NotNil(t, d)
+	assert.NoError(t, d.run("signature", func() error { return nil }))
+	assert.Error(t, d.run("fee_ceiling", func() error { return errors.New("too high") }))
+	d.Builder, d.Result = common.HexToAddress("0x01"), decisionResult(rejectFeeCeiling, nil)
+	l.emit(d)
+	l.close()
+
+	// emits after close are dropped
+	l.emit(&Decision{})
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var decisions []Decision
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var got Decision
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &got))
+		decisions = append(decisions, got)
+	}
+
+	require.Len(t, decisions, 1)
+	assert.Equal(t, rejectFeeCeiling, decisions[0].Result)
+	assert.Equal(t, []string{"signature", "fee_ceiling"}, []string{decisions[0].Checks[0].Name, decisions[0].Checks[1].Name})
+	assert.True(t, decisions[0].Checks[0].Passed)
+	assert.False(t, decisions[0].Checks[1].Passed)
+	assert.Equal(t, "too high", decisions[0].Checks[1].Error)
+}
+
+func TestDecisionNotSampled(t *testing.T) {
+	var l *decisionLog
+	d := l.sample()
+	assert.Nil(t, d)
+
+	ran := false
+	assert.NoError(t, d.run("signature", func() error { ran = true; return nil }))
+	assert.True(t, ran)
+}
@@ -0,0 +1,261 @@
+package service
+
+import (
+	"context"
+	"encoding"
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/bnb-chain/bsc-mev-sentry/version"
+)
+
+const openRPCVersion = "1.2.6"
+
+var (
+	contextType       = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errorType         = reflect.TypeOf((*error)(nil)).Elem()
+	bigIntType        = reflect.TypeOf(big.Int{})
+	timeType          = reflect.TypeOf(time.Time{})
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// Discovery describes the JSON-RPC API of the sentry and the features enabled, so that builder clients can
+// negotiate capabilities programmatically.
+type Discovery struct {
+	document *APIDocument
+}
+
+// APIDocument is an OpenRPC style description of the API along with the features of the sentry.
+type APIDocument struct {
+	OpenRPC  string             `json:"openrpc"`
+	Info     APIInfo            `json:"info"`
+	Methods  []MethodDescriptor `json:"methods"`
+	Features map[string]bool    `json:"features"`
+}
+
+type APIInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// MethodDescriptor describes a JSON-RPC method, params in order.
+type MethodDescriptor struct {
+	Name   string              `json:"name"`
+	Params []ContentDescriptor `json:"params"`
+	Result *ContentDescriptor  `json:"result,omitempty"`
+}
+
+// ContentDescriptor is a param or result of a method along with its JSON schema.
+type ContentDescriptor struct {
+	Name   string                 `json:"name"`
+	Schema map[string]interface{} `json:"schema"`
+}
+
+// NewDiscovery describes the methods the receivers serve in their namespaces, the document is built once as
+// neither the methods nor the features change while serving.
+func NewDiscovery(cfg *Config, receivers map[string]interface{}) *Discovery {
+	document := &APIDocument{
+		OpenRPC: openRPCVersion,
+		Info:    APIInfo{Title: "bsc-mev-sentry", Version: version.Get().Version},
+		Methods: make([]MethodDescriptor, 0),
+		Features: map[string]bool{
+			"fanOut":                cfg.FanOut.Enabled,
+			"bundleAdapter":         cfg.BundleAdapter,
+			"restAPI":               cfg.RESTAPI,
+			"hints":                 cfg.Hints.Backend != "",
+			"bidHistory":            cfg.BidStore.Driver != "",
+			"validatorRegistration": cfg.ValidatorRegistration.Enabled,
+			"requireSignature":      cfg.RequireSignature,
+			"requireAPIKey":         cfg.RequireAPIKey,
+			"requireClientCert":     cfg.TLSClientCAFile != "",
+			"jwt":                   cfg.JWT.Enabled,
+			"multiChain":            len(cfg.Chains) > 0,
+		},
+	}
+
+	for namespace, receiver := range receivers {
+		document.Methods = append(document.Methods, describeMethods(namespace, receiver)...)
+	}
+
+	sort.Slice(document.Methods, func(i, j int) bool {
+		return document.Methods[i].Name < document.Methods[j].Name
+	})
+
+	return &Discovery{document: document}
+}
+
+// Discover returns the description of the API and the features of the sentry.
+func (d *Discovery) Discover(_ context.Context) *APIDocument {
+	return d.document
+}
+
+// describeMethods describes the methods of the receiver served over JSON-RPC, the same ones the rpc server
+// registers.
+func describeMethods(namespace string, receiver interface{}) []MethodDescriptor {
+	var methods []MethodDescriptor
+
+	typ := reflect.TypeOf(receiver)
+	for i := 0; i < typ.NumMethod(); i++ {
+		method := typ.Method(i)
+		if method.PkgPath != "" {
+			continue
+		}
+
+		fn := method.Type
+		if fn.NumOut() > 2 || (fn.NumOut() == 2 && fn.Out(1) != errorType) {
+			continue
+		}
+
+		descriptor := MethodDescriptor{
+			Name:   namespace + "_" + lowerFirst(method.Name),
+			Params: make([]ContentDescriptor, 0),
+		}
+
+		// the receiver is the first input
+		for j := 1; j < fn.NumIn(); j++ {
+			if j == 1 && fn.In(j) == contextType {
+				continue
+			}
+
+			descriptor.Params = append(descriptor.Params, ContentDescriptor{
+				Name:   paramName(fn.In(j), len(descriptor.Params)),
+				Schema: schemaOf(fn.In(j), make(map[reflect.Type]bool)),
+			})
+		}
+
+		if fn.NumOut() > 0 && fn.Out(0) != errorType {
+			descriptor.Result = &ContentDescriptor{
+				Name:   "result",
+				Schema: schemaOf(fn.Out(0), make(map[reflect.Type]bool)),
+			}
+		}
+
+		methods = append(methods, descriptor)
+	}
+
+	return methods
+}
+
+// paramName names a param after its type, Go doesn't keep the names of params.
+func paramName(t reflect.Type, i int) string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t.Name() == "" || t.PkgPath() == "" {
+		return fmt.Sprintf("param%d", i)
+	}
+
+	return lowerFirst(t.Name())
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+
+	runes := []rune(s)
+	runes[0] = unicode.ToLower(runes[0])
+	return string(runes)
+}
+
+// schemaOf returns the JSON schema of the type as encoded by encoding/json, types encoded as text, e.g. hashes,
+// addresses and hex quantities, are strings.
+func schemaOf(t reflect.Type, seen map[reflect.Type]bool) map[string]interface{} {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == bigIntType:
+		return map[string]interface{}{"type": "integer"}
+	case t == timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType):
+		return map[string]interface{}{"type": "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string"}
+		}
+		return map[string]interface{}{"type": "array", "items": schemaOf(t.Elem(), seen)}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": schemaOf(t.Elem(), seen)}
+	case reflect.Struct:
+		return structSchema(t, seen)
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+// structSchema returns the schema of the struct, the fields of embedded structs are promoted as encoding/json does.
+func structSchema(t reflect.Type, seen map[reflect.Type]bool) map[string]interface{} {
+	schema := map[string]interface{}{"type": "object"}
+	if t.Name() != "" {
+		schema["title"] = t.Name()
+	}
+
+	// recursive types are described once
+	if seen[t] {
+		return schema
+	}
+	seen[t] = true
+	defer delete(seen, t)
+
+	properties := make(map[string]interface{})
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				if promoted, ok := structSchema(embedded, seen)["properties"].(map[string]interface{}); ok {
+					for k, v := range promoted {
+						properties[k] = v
+					}
+				}
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = schemaOf(field.Type, seen)
+	}
+
+	if len(properties) > 0 {
+		schema["properties"] = properties
+	}
+
+	return schema
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscovery(t *testing.T) {
+	cfg := &Config{BundleAdapter: true}
+	cfg.FanOut.Enabled = true
+
+	sentry := &MevSentry{}
+	document := NewDiscovery(cfg, map[string]interface{}{
+		"mev": sentry,
+		"eth": NewBundleAdapter(sentry),
+	}).Discover(context.Background())
+
+	methods := make(map[string]MethodDescriptor)
+	for _, method := range document.Methods {
+		methods[method.Name] = method
+	}
+
+	sendBid, ok := methods["mev_sendBid"]
+	require.True(t, ok)
+	require.Len(t, sendBid.Params, 1)
+	assert.Equal(t, "bidArgs", sendBid.Params[0].Name)
+	assert.Equal(t, map[string]interface{}{"type": "string"}, sendBid.Result.Schema)
+
+	rawBid := sendBid.Params[0].Schema["properties"].(map[string]interface{})["RawBid"].(map[string]interface{})
+	properties := rawBid["properties"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"type": "integer"}, properties["gasFee"])
+	assert.Equal(t, "array", properties["txs"].(map[string]interface{})["type"])
+
+	// the params of embedded structs are promoted
+	bidHistory := methods["mev_bidHistory"].Params[0].Schema["properties"].(map[string]interface{})
+	assert.Contains(t, bidHistory, "fromBlock")
+	assert.Contains(t, bidHistory, "signature")
+
+	assert.Contains(t, methods, "eth_sendBundle")
+	assert.NotContains(t, methods, "mev_hintStream")
+
+	assert.True(t, document.Features["fanOut"])
+	assert.True(t, document.Features["bundleAdapter"])
+	assert.False(t, document.Features["hints"])
+}
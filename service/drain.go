@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/bnb-chain/bsc-mev-sentry/log"
+)
+
+// Draining tells whether the sentry is draining, new bids are rejected and readiness fails
+// while existing requests finish.
+func (s *MevSentry) Draining() bool {
+	return s.draining.Load()
+}
+
+func (s *MevSentry) drainingError() *sentryError {
+	message := "sentry is draining, try again later"
+	if s.alternateSentry != "" {
+		message = fmt.Sprintf("sentry is draining, try again with %s", s.alternateSentry)
+	}
+
+	return &sentryError{
+		error: errors.New(message),
+		code:  sentryDrainingErrorCode,
+	}
+}
+
+// StartDraining puts the sentry into draining state, for orchestrated cutovers.
+func (a *MevAdmin) StartDraining(_ context.Context) {
+	a.sentry.draining.Store(true)
+	log.Infow("sentry start draining", "alternate", a.sentry.alternateSentry)
+}
+
+// StopDraining puts the sentry back into serving state.
+func (a *MevAdmin) StopDraining(_ context.Context) {
+	a.sentry.draining.Store(false)
+	log.Infow("sentry stop draining")
+}
+
+func (a *MevAdmin) Draining(_ context.Context) bool {
+	return a.sentry.Draining()
+}
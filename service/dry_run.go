@@ -0,0 +1,106 @@
+package service
+
+import (
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/bnb-chain/bsc-mev-sentry/node"
+)
+
+// errDryRun ends a bid that passed every check in dry run, instead of forwarding it.
+var errDryRun = errors.New("dry run, bid is not forwarded")
+
+// DryRunReport tells what the sentry decided on a bid sent in dry run, i.e. every check it went through and the pay
+// bid tx it signed. Result is dry_run if the bid would have been forwarded, otherwise the rejection reason.
+type DryRunReport struct {
+	*Decision
+	PayBidTx *PayBidTxSummary `json:"payBidTx,omitempty"`
+}
+
+// PayBidTxSummary describes a pay bid tx without the signed tx itself, which would be valid on chain.
+type PayBidTxSummary struct {
+	Hash     common.Hash    `json:"hash"`
+	Account  common.Address `json:"account"`
+	Nonce    uint64         `json:"nonce"`
+	Amount   *big.Int       `json:"amount"`
+	GasPrice *big.Int       `json:"gasPrice"`
+	Gas      uint64         `json:"gas"`
+}
+
+// dryRunError carries the report of a bid sent in dry run in the data of the JSON-RPC error.
+type dryRunError struct {
+	error
+	code   int
+	report *DryRunReport
+}
+
+func (e *dryRunError) ErrorCode() int {
+	return e.code
+}
+
+func (e *dryRunError) ErrorData() interface{} {
+	return e.report
+}
+
+// newDryRunError reports the decision on the bid, err is either the rejection of the bid or errDryRun.
+func newDryRunError(decision *Decision, sampled bool, err error, payBidTx hexutil.Bytes) *dryRunError {
+	// a sampled decision is timed once it's emitted to the decision log
+	if !sampled {
+		decision.Duration = time.Since(decision.Time).Microseconds()
+	}
+
+	e := &dryRunError{
+		error:  err,
+		code:   sentryErrorCode,
+		report: &DryRunReport{Decision: decision, PayBidTx: summarizePayBidTx(payBidTx)},
+	}
+
+	var rpcErr rpc.Error
+	switch {
+	case errors.Is(err, errDryRun):
+		e.code = dryRunErrorCode
+	case errors.As(err, &rpcErr):
+		e.code = rpcErr.ErrorCode()
+	}
+
+	return e
+}
+
+func summarizePayBidTx(payBidTx hexutil.Bytes) *PayBidTxSummary {
+	if len(payBidTx) == 0 {
+		return nil
+	}
+
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(payBidTx); err != nil {
+		return nil
+	}
+
+	summary := &PayBidTxSummary{
+		Hash:     tx.Hash(),
+		Nonce:    tx.Nonce(),
+		Amount:   tx.Value(),
+		GasPrice: tx.GasPrice(),
+		Gas:      tx.Gas(),
+	}
+	if account, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx); err == nil {
+		summary.Account = account
+	}
+
+	return summary
+}
+
+// newValidator creates the validator of the config, noting whether any validator runs in dry run.
+func (s *MevSentry) newValidator(cfg node.ValidatorConfig) (node.Validator, error) {
+	if cfg.DryRun {
+		s.dryRunValidators.Store(true)
+	}
+
+	return node.NewValidator(cfg)
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDryRunError(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	chainID := big.NewInt(56)
+	tx, err := types.SignNewTx(key, types.LatestSignerForChainID(chainID), &types.LegacyTx{
+		Nonce:    7,
+		Gas:      25000,
+		GasPrice: big.NewInt(1e9),
+		Value:    big.NewInt(1e15),
+	})
+	require.NoError(t, err)
+	payBidTx, err := tx.MarshalBinary()
+	require.NoError(t, err)
+
+	decision := &Decision{Time: time.Now()}
+	assert.NoError(t, decision.run("signature", func() error { return nil }))
+	decision.Result = decisionResult("", errDryRun)
+
+	e := newDryRunError(decision, false, errDryRun, payBidTx)
+	assert.Equal(t, dryRunErrorCode, e.ErrorCode())
+
+	report, ok := e.ErrorData().(*DryRunReport)
+	require.True(t, ok)
+	assert.Equal(t, "dry_run", report.Result)
+	require.NotNil(t, report.PayBidTx)
+	assert.Equal(t, tx.Hash(), report.PayBidTx.Hash)
+	assert.Equal(t, crypto.PubkeyToAddress(key.PublicKey), report.PayBidTx.Account)
+	assert.Equal(t, uint64(7), report.PayBidTx.Nonce)
+	assert.Equal(t, big.NewInt(1e15), report.PayBidTx.Amount)
+
+	// a rejected bid keeps the code of its rejection, and has no pay bid tx if it's rejected before signing
+	stale := &sentryError{error: errors.New("stale bid"), code: staleBidErrorCode}
+	rejected := newDryRunError(&Decision{Time: time.Now()}, false, stale, nil)
+	assert.Equal(t, staleBidErrorCode, rejected.ErrorCode())
+	assert.Nil(t, rejected.ErrorData().(*DryRunReport).PayBidTx)
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/bnb-chain/bsc-mev-sentry/log"
+	"github.com/bnb-chain/bsc-mev-sentry/metrics"
+	"github.com/bnb-chain/bsc-mev-sentry/notification"
+)
+
+// LeaderStatus is the election state of the sentry.
+type LeaderStatus struct {
+	Identity string `json:"identity"`
+	Leader   bool   `json:"leader"`
+}
+
+// StartElection starts electing the leader of the replicas if the election is enabled, the sentry is a standby
+// until it takes the lock.
+func (s *MevSentry) StartElection() error {
+	if s.elector == nil {
+		return nil
+	}
+
+	return s.elector.Start()
+}
+
+// StopElection stops renewing the leadership and hands it over to a standby.
+func (s *MevSentry) StopElection(ctx context.Context) error {
+	if s.elector == nil {
+		return nil
+	}
+
+	return s.elector.Stop(ctx)
+}
+
+// onLeaderChange is called once the sentry becomes the leader or a standby.
+func (s *MevSentry) onLeaderChange(leader bool) {
+	if leader {
+		metrics.ClusterLeader.Set(1)
+		log.Infow("sentry becomes leader", "identity", s.elector.Identity())
+		notification.Notify(notification.Warning, "sentry becomes leader", "", "identity", s.elector.Identity())
+		return
+	}
+
+	metrics.ClusterLeader.Set(0)
+	log.Infow("sentry becomes standby", "identity", s.elector.Identity())
+	notification.Notify(notification.Info, "sentry becomes standby", "", "identity", s.elector.Identity())
+}
+
+// LeaderStatus returns the election state of the sentry.
+func (a *MevAdmin) LeaderStatus(_ context.Context) (*LeaderStatus, error) {
+	if a.sentry.elector == nil {
+		return nil, errors.New("election is disabled")
+	}
+
+	return &LeaderStatus{Identity: a.sentry.elector.Identity(), Leader: a.sentry.elector.Leader()}, nil
+}
@@ -2,7 +2,14 @@ package service
 
 import "errors"
 
-const sentryErrorCode = -38006
+const (
+	sentryErrorCode         = -38006
+	sentryDrainingErrorCode = -38007
+	chainIDErrorCode        = -38008
+	staleBidErrorCode       = -38009
+	unregisteredErrorCode   = -38010
+	dryRunErrorCode         = -38011
+)
 
 // sentryError is an API error that encompasses an invalid bid with JSON error
 // code and a binary data blob.
@@ -0,0 +1,339 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/bnb-chain/bsc-mev-sentry/log"
+	"github.com/bnb-chain/bsc-mev-sentry/notification"
+	"github.com/bnb-chain/bsc-mev-sentry/utils"
+)
+
+const (
+	failoverPrimary = "primary"
+	failoverBackup  = "backup"
+)
+
+type FailoverConfig struct {
+	// Enabled pairs the sentry with a peer sentry serving the same validators, only one of them is active
+	Enabled bool
+	// Role primary or backup, the backup yields if both are active
+	Role string
+	// PeerURL admin url of the peer sentry
+	PeerURL string
+	// PeerToken admin token of the peer sentry
+	PeerToken string
+	// PingInterval interval of health pings to the peer
+	PingInterval utils.Duration
+	// FailAfter number of consecutive failed pings after which the peer is taken over
+	FailAfter int
+}
+
+// PeerState is the state a sentry exchanges with its peer on each ping.
+type PeerState struct {
+	Role   string `json:"role"`
+	Active bool   `json:"active"`
+	// Nonces public hostname -> next pay bid tx nonce, leased by the peer once it takes over
+	Nonces map[string]uint64 `json:"nonces"`
+}
+
+// FailoverStatus is the failover state reported to operators.
+type FailoverStatus struct {
+	Role          string    `json:"role"`
+	Active        bool      `json:"active"`
+	PeerReachable bool      `json:"peerReachable"`
+	LastPeerPing  time.Time `json:"lastPeerPing"`
+}
+
+// failover coordinates which sentry of a primary/backup pair is active, the standby one rejects
+// bids and fails readiness, like draining. There is no fencing: the pair can only tell each other apart by pinging,
+// so a partition between them leaves both active until they reach each other again and the backup yields. Election
+// is the one for deployments where that's not acceptable.
+type failover struct {
+	cfg    FailoverConfig
+	sentry *MevSentry
+	active atomic.Bool
+
+	mu           sync.Mutex
+	peer         *rpc.Client
+	misses       int
+	lastPeerPing time.Time
+	peerNonces   map[string]uint64
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+func newFailover(cfg FailoverConfig, sentry *MevSentry) *failover {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	if cfg.PingInterval <= 0 {
+		cfg.PingInterval = utils.Duration(time.Second)
+	}
+	if cfg.FailAfter <= 0 {
+		cfg.FailAfter = 3
+	}
+
+	return &failover{
+		cfg:    cfg,
+		sentry: sentry,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+}
+
+// Start dials the peer and starts pinging it, both start as standby until the first ping settles it.
+func (f *failover) Start() error {
+	if f.cfg.Role != failoverPrimary && f.cfg.Role != failoverBackup {
+		return errors.New("failover role must be primary or backup")
+	}
+
+	header := make(http.Header)
+	if f.cfg.PeerToken != "" {
+		header.Set("Authorization", "Bearer "+f.cfg.PeerToken)
+	}
+
+	peer, err := rpc.DialOptions(context.Background(), f.cfg.PeerURL, rpc.WithHeaders(header))
+	if err != nil {
+		return err
+	}
+	f.peer = peer
+
+	go f.loop()
+
+	return nil
+}
+
+// Stop stops pinging the peer, it may be called more than once and before Start.
+func (f *failover) Stop(ctx context.Context) error {
+	f.stopOnce.Do(func() { close(f.stop) })
+
+	// not started, there is no loop to wait for
+	if f.peer == nil {
+		return nil
+	}
+
+	select {
+	case <-f.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	f.peer.Close()
+
+	return nil
+}
+
+func (f *failover) loop() {
+	defer close(f.done)
+
+	ticker := time.NewTicker(time.Duration(f.cfg.PingInterval))
+	defer ticker.Stop()
+
+	for {
+		f.ping()
+
+		select {
+		case <-ticker.C:
+		case <-f.stop:
+			return
+		}
+	}
+}
+
+func (f *failover) ping() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(f.cfg.PingInterval))
+	defer cancel()
+
+	var peer PeerState
+	err := f.peer.CallContext(ctx, &peer, "peer_ping", f.state())
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err != nil {
+		f.misses++
+		log.Errorw("failed to ping peer sentry", "peer", f.cfg.PeerURL, "misses", f.misses, "err", err)
+
+		if f.misses >= f.cfg.FailAfter && !f.active.Load() {
+			f.activate("peer unreachable, both are active if it's a partition")
+		}
+		return
+	}
+
+	f.misses = 0
+	f.lastPeerPing = time.Now()
+	f.peerNonces = peer.Nonces
+
+	switch {
+	case peer.Active && f.active.Load() && f.cfg.Role == failoverBackup:
+		f.deactivate("primary is active")
+	case !peer.Active && !f.active.Load() && f.cfg.Role == failoverPrimary:
+		f.activate("peer is standby")
+	}
+}
+
+// handlePing answers a ping of the peer, the backup yields if both are active.
+func (f *failover) handlePing(peer PeerState) PeerState {
+	f.mu.Lock()
+	f.lastPeerPing = time.Now()
+	f.peerNonces = peer.Nonces
+	if peer.Active && f.active.Load() && f.cfg.Role == failoverBackup {
+		f.deactivate("primary is active")
+	}
+	f.mu.Unlock()
+
+	return f.state()
+}
+
+// activate leases the nonces of the peer so that pay bid txs don't reuse them, mu must be held.
+func (f *failover) activate(reason string) {
+	for hostname, nonce := range f.peerNonces {
+		if validator, ok := f.sentry.validator(hostname); ok {
+			validator.LeaseNonce(nonce)
+		}
+	}
+
+	f.active.Store(true)
+
+	log.Infow("sentry becomes active", "role", f.cfg.Role, "reason", reason)
+	notification.Notify(notification.Warning, "sentry becomes active", reason, "role", f.cfg.Role)
+}
+
+// deactivate makes the sentry standby, mu must be held.
+func (f *failover) deactivate(reason string) {
+	f.active.Store(false)
+
+	log.Infow("sentry becomes standby", "role", f.cfg.Role, "reason", reason)
+	notification.Notify(notification.Info, "sentry becomes standby", reason, "role", f.cfg.Role)
+}
+
+func (f *failover) state() PeerState {
+	f.sentry.mu.RLock()
+	nonces := make(map[string]uint64, len(f.sentry.validators))
+	for hostname, validator := range f.sentry.validators {
+		nonces[hostname] = validator.PayAccountNonce()
+	}
+	f.sentry.mu.RUnlock()
+
+	return PeerState{Role: f.cfg.Role, Active: f.active.Load(), Nonces: nonces}
+}
+
+func (f *failover) status() FailoverStatus {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return FailoverStatus{
+		Role:          f.cfg.Role,
+		Active:        f.active.Load(),
+		PeerReachable: f.misses < f.cfg.FailAfter && !f.lastPeerPing.IsZero(),
+		LastPeerPing:  f.lastPeerPing,
+	}
+}
+
+// Standby tells whether the sentry is the standby one of a failover pair, or not the elected leader of its replicas.
+func (s *MevSentry) Standby() bool {
+	return (s.failover != nil && !s.failover.active.Load()) || (s.elector != nil && !s.elector.Leader())
+}
+
+// StartFailover starts pinging the peer sentry if failover is enabled.
+func (s *MevSentry) StartFailover() error {
+	if s.failover == nil {
+		return nil
+	}
+
+	return s.failover.Start()
+}
+
+// StopFailover stops pinging the peer sentry.
+func (s *MevSentry) StopFailover(ctx context.Context) error {
+	if s.failover == nil {
+		return nil
+	}
+
+	return s.failover.Stop(ctx)
+}
+
+func (s *MevSentry) standbyError() *sentryError {
+	return &sentryError{
+		error: errors.New("sentry is standby, try again with its peer"),
+		code:  sentryDrainingErrorCode,
+	}
+}
+
+// MevPeer serves the peer namespace, which a paired sentry pings.
+type MevPeer struct {
+	sentry *MevSentry
+}
+
+func NewMevPeer(sentry *MevSentry) *MevPeer {
+	return &MevPeer{sentry: sentry}
+}
+
+func (p *MevPeer) Ping(_ context.Context, state PeerState) (*PeerState, error) {
+	if p.sentry.failover == nil {
+		return nil, errors.New("failover is disabled")
+	}
+
+	result := p.sentry.failover.handlePing(state)
+	return &result, nil
+}
+
+// Yield makes the sentry standby on request of the peer taking over, and returns the nonces to lease.
+func (p *MevPeer) Yield(_ context.Context) (*PeerState, error) {
+	f := p.sentry.failover
+	if f == nil {
+		return nil, errors.New("failover is disabled")
+	}
+
+	f.mu.Lock()
+	if f.active.Load() {
+		f.deactivate("peer takes over")
+	}
+	f.mu.Unlock()
+
+	state := f.state()
+	return &state, nil
+}
+
+// FailoverTakeOver makes the sentry active after the peer yields, e.g. to fail back to the primary.
+func (a *MevAdmin) FailoverTakeOver(ctx context.Context) error {
+	f := a.sentry.failover
+	if f == nil {
+		return errors.New("failover is disabled")
+	}
+
+	var peer PeerState
+	if err := f.peer.CallContext(ctx, &peer, "peer_yield"); err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.peerNonces = peer.Nonces
+	if !f.active.Load() {
+		f.activate("taken over by operator")
+	}
+
+	return nil
+}
+
+// FailoverStatus returns the failover state of the sentry.
+func (a *MevAdmin) FailoverStatus(_ context.Context) (*FailoverStatus, error) {
+	if a.sentry.failover == nil {
+		return nil, errors.New("failover is disabled")
+	}
+
+	status := a.sentry.failover.status()
+	return &status, nil
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/bsc-mev-sentry/node"
+)
+
+// failoverPeer is a sentry of a failover pair served over http, unreachable while down.
+type failoverPeer struct {
+	sentry *MevSentry
+	url    string
+	down   atomic.Bool
+}
+
+func newFailoverPeer(t *testing.T, role string) *failoverPeer {
+	s := &MevSentry{validators: map[string]node.Validator{}}
+	s.failover = newFailover(FailoverConfig{Enabled: true, Role: role, FailAfter: 2}, s)
+
+	server := rpc.NewServer()
+	require.NoError(t, server.RegisterName("peer", NewMevPeer(s)))
+
+	p := &failoverPeer{sentry: s}
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if p.down.Load() {
+			http.Error(w, "down", http.StatusServiceUnavailable)
+			return
+		}
+		server.ServeHTTP(w, r)
+	}))
+	t.Cleanup(httpServer.Close)
+	p.url = httpServer.URL
+
+	return p
+}
+
+// pair points the failovers at each other without starting their loops, so that pings are driven by the test.
+func pair(t *testing.T, a, b *failoverPeer) {
+	for _, p := range []struct{ from, to *failoverPeer }{{a, b}, {b, a}} {
+		client, err := rpc.Dial(p.to.url)
+		require.NoError(t, err)
+		t.Cleanup(client.Close)
+		p.from.sentry.failover.peer = client
+	}
+}
+
+func TestFailover(t *testing.T) {
+	primary, backup := newFailoverPeer(t, failoverPrimary), newFailoverPeer(t, failoverBackup)
+	pair(t, primary, backup)
+
+	// both start as standby, the primary becomes active once it sees the backup is standby
+	backup.sentry.failover.ping()
+	assert.True(t, primary.sentry.Standby())
+	assert.True(t, backup.sentry.Standby())
+
+	primary.sentry.failover.ping()
+	assert.False(t, primary.sentry.Standby())
+	assert.True(t, backup.sentry.Standby())
+
+	// the backup takes over once the primary misses FailAfter pings
+	primary.down.Store(true)
+	backup.sentry.failover.ping()
+	assert.True(t, backup.sentry.Standby())
+	backup.sentry.failover.ping()
+	assert.False(t, backup.sentry.Standby())
+	assert.False(t, backup.sentry.failover.status().PeerReachable)
+
+	// the primary was active all along as in a partition, the backup yields once they reach each other again
+	primary.down.Store(false)
+	primary.sentry.failover.ping()
+	assert.False(t, primary.sentry.Standby())
+	assert.True(t, backup.sentry.Standby())
+
+	// the operator fails over to the backup
+	require.NoError(t, NewMevAdmin(backup.sentry).FailoverTakeOver(context.Background()))
+	assert.True(t, primary.sentry.Standby())
+	assert.False(t, backup.sentry.Standby())
+
+	// a recovered primary stays standby while the backup is active
+	primary.sentry.failover.ping()
+	assert.True(t, primary.sentry.Standby())
+}
+
+func TestFailoverStop(t *testing.T) {
+	s := &MevSentry{validators: map[string]node.Validator{}}
+	s.failover = newFailover(FailoverConfig{Enabled: true, Role: failoverPrimary}, s)
+
+	// before Start
+	require.NoError(t, s.StopFailover(context.Background()))
+
+	peer := newFailoverPeer(t, failoverBackup)
+	s = &MevSentry{validators: map[string]node.Validator{}}
+	s.failover = newFailover(FailoverConfig{Enabled: true, Role: failoverPrimary, PeerURL: peer.url}, s)
+	require.NoError(t, s.StartFailover())
+
+	require.NoError(t, s.StopFailover(context.Background()))
+	require.NoError(t, s.StopFailover(context.Background()))
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/bnb-chain/bsc-mev-sentry/log"
+	"github.com/bnb-chain/bsc-mev-sentry/metrics"
+)
+
+type FanOutConfig struct {
+	// Enabled serves mev_sendBidFanOut
+	Enabled bool
+	// Validators public hostnames of the validators a bid is fanned out to, all validators if empty
+	Validators []string
+	// WhitelistedOnly skips the validators which haven't registered the builder of the bid
+	WhitelistedOnly bool
+}
+
+// FanOutResult is the outcome of a fanned out bid at one validator.
+type FanOutResult struct {
+	Validator string       `json:"validator"`
+	BidHash   *common.Hash `json:"bidHash,omitempty"`
+	Error     string       `json:"error,omitempty"`
+	Code      int          `json:"code,omitempty"`
+}
+
+// SendBidFanOut forwards the bid to every validator of the fan out, each with its own pay bid tx. A bid goes
+// through the same checks as with mev_sendBid at every validator, the results are returned per validator.
+func (s *MevSentry) SendBidFanOut(ctx context.Context, args types.BidArgs) (results []FanOutResult, err error) {
+	method := "mev_sendBidFanOut"
+	start := time.Now()
+	defer recordLatency(method, servedFromUpstream, start)
+	// the bids fanned out keep the deadline margin themselves
+	defer s.budget(&ctx, method)()
+	defer func() {
+		if err != nil {
+			if rpcErr, ok := err.(rpc.Error); ok {
+				metrics.ApiErrorCounter.WithLabelValues(method, strconv.Itoa(rpcErr.ErrorCode())).Inc()
+			}
+		}
+	}()
+
+	if !s.fanOut.Enabled {
+		err = newSentryError("fan out is disabled")
+		return
+	}
+
+	if args.RawBid == nil {
+		err = types.NewInvalidBidError("rawBid should not be nil")
+		return
+	}
+
+	targets := s.fanOutTargets(ctx, args)
+	if len(targets) == 0 {
+		err = newSentryError("no validator to fan out to")
+		return
+	}
+
+	results = make([]FanOutResult, len(targets))
+
+	var wg sync.WaitGroup
+	for i, hostname := range targets {
+		wg.Add(1)
+		go func(i int, hostname string) {
+			defer wg.Done()
+
+			result := FanOutResult{Validator: hostname}
+
+			// every validator gets its own copy of the args, the pay bid tx is set on it
+			bidHash, err := s.SendBid(WithTarget(ctx, hostname), args)
+			if err != nil {
+				result.Error = err.Error()
+				if rpcErr, ok := err.(rpc.Error); ok {
+					result.Code = rpcErr.ErrorCode()
+				}
+			} else {
+				result.BidHash = &bidHash
+			}
+
+			results[i] = result
+		}(i, hostname)
+	}
+	wg.Wait()
+
+	return
+}
+
+// fanOutTargets returns the public hostnames of the validators the bid is fanned out to, in order.
+func (s *MevSentry) fanOutTargets(ctx context.Context, args types.BidArgs) []string {
+	var targets []string
+	if len(s.fanOut.Validators) > 0 {
+		targets = append(targets, s.fanOut.Validators...)
+	} else {
+		s.mu.RLock()
+		for hostname := range s.validators {
+			targets = append(targets, hostname)
+		}
+		s.mu.RUnlock()
+		sort.Strings(targets)
+	}
+
+	if !s.fanOut.WhitelistedOnly {
+		return targets
+	}
+
+	// an invalid signature is reported per validator by mev_sendBid
+	builder, err := args.EcrecoverSender()
+	if err != nil {
+		return targets
+	}
+
+	whitelisted := targets[:0]
+	for _, hostname := range targets {
+		validator, ok := s.validator(hostname)
+		if !ok || !validator.Capabilities().HasBuilder {
+			whitelisted = append(whitelisted, hostname)
+			continue
+		}
+
+		// bids are sent if the validator can't tell, as with the has builder check
+		has, err := s.validatorHasBuilder(ctx, hostname, builder, validator)
+		if err != nil {
+			log.Errorw("failed to check if validator has builder, fanning out the bid", "validator", hostname,
+				"builder", builder, "err", err)
+		}
+		if has || err != nil {
+			whitelisted = append(whitelisted, hostname)
+		}
+	}
+
+	return whitelisted
+}
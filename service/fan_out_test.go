@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bnb-chain/bsc-mev-sentry/node"
+)
+
+func TestFanOutTargets(t *testing.T) {
+	builderKey, _ := crypto.GenerateKey()
+	builder := crypto.PubkeyToAddress(builderKey.PublicKey)
+
+	rawBid := &types.RawBid{BlockNumber: 1}
+	sig, _ := crypto.Sign(rawBid.Hash().Bytes(), builderKey)
+	args := types.BidArgs{RawBid: rawBid, Signature: sig}
+
+	s := &MevSentry{
+		validators: map[string]node.Validator{
+			"a": &hasBuilderValidator{registered: map[common.Address]bool{builder: true}},
+			"b": &hasBuilderValidator{},
+			"c": &hasBuilderValidator{err: errors.New("timeout")},
+		},
+		hasBuilder: newHasBuilderCache(),
+	}
+	ctx := context.Background()
+
+	assert.Equal(t, []string{"a", "b", "c"}, s.fanOutTargets(ctx, args))
+
+	s.fanOut.Validators = []string{"c", "b"}
+	assert.Equal(t, []string{"c", "b"}, s.fanOutTargets(ctx, args))
+
+	// validators which can't tell are kept
+	s.fanOut.Validators, s.fanOut.WhitelistedOnly = nil, true
+	assert.Equal(t, []string{"a", "c"}, s.fanOutTargets(ctx, args))
+
+	// fanned out bids are routed to their target whatever the request says
+	assert.Equal(t, "b", s.route(WithTarget(ctx, "b")))
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bnb-chain/bsc-mev-sentry/log"
+	"github.com/bnb-chain/bsc-mev-sentry/node"
+)
+
+const defaultHasBuilderCacheTTL = 30 * time.Second
+
+type hasBuilderKey struct {
+	validator string
+	builder   common.Address
+}
+
+type hasBuilderEntry struct {
+	has     bool
+	expires time.Time
+}
+
+// hasBuilderCache caches whether each validator has registered a builder, so that mev_hasBuilder
+// isn't called on every bid.
+type hasBuilderCache struct {
+	mu      sync.Mutex
+	entries map[hasBuilderKey]hasBuilderEntry
+}
+
+func newHasBuilderCache() *hasBuilderCache {
+	return &hasBuilderCache{entries: make(map[hasBuilderKey]hasBuilderEntry)}
+}
+
+func (c *hasBuilderCache) get(key hasBuilderKey, now time.Time) (has, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok || now.After(entry.expires) {
+		return false, false
+	}
+
+	return entry.has, true
+}
+
+func (c *hasBuilderCache) set(key hasBuilderKey, has bool, now time.Time, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// drop the expired entries once in a while, e.g. of builders or validators long gone
+	if len(c.entries) >= 4096 {
+		for k, entry := range c.entries {
+			if now.After(entry.expires) {
+				delete(c.entries, k)
+			}
+		}
+	}
+
+	c.entries[key] = hasBuilderEntry{has: has, expires: now.Add(ttl)}
+}
+
+// validatorHasBuilder tells whether the validator has registered the builder, the answer of the validator is
+// cached for the CacheTTL of its HasBuilder config.
+func (s *MevSentry) validatorHasBuilder(ctx context.Context, hostname string, builder common.Address,
+	validator node.Validator,
+) (bool, error) {
+	key := hasBuilderKey{validator: hostname, builder: builder}
+	now := time.Now()
+
+	if has, ok := s.hasBuilder.get(key, now); ok {
+		return has, nil
+	}
+
+	has, err := validator.HasBuilder(ctx, builder)
+	if err != nil {
+		return false, err
+	}
+
+	ttl := time.Duration(validator.Config().HasBuilder.CacheTTL)
+	if ttl <= 0 {
+		ttl = defaultHasBuilderCacheTTL
+	}
+	s.hasBuilder.set(key, has, now, ttl)
+
+	return has, nil
+}
+
+// checkHasBuilder rejects the bid if the validator hasn't registered its builder, instead of letting
+// the validator reject it after a round trip. Bids are forwarded if the validator can't tell.
+func (s *MevSentry) checkHasBuilder(ctx context.Context, hostname string, builder common.Address,
+	validator node.Validator,
+) error {
+	cfg := validator.Config().HasBuilder
+	if !cfg.Enabled || !validator.Capabilities().HasBuilder {
+		return nil
+	}
+
+	has, err := s.validatorHasBuilder(ctx, hostname, builder, validator)
+	if err != nil {
+		log.Errorw("failed to check if validator has builder, forwarding the bid", "validator", hostname,
+			"builder", builder, "err", err)
+		return nil
+	}
+
+	if has {
+		return nil
+	}
+
+	log.Errorw("builder not registered by validator", "validator", hostname, "builder", builder)
+
+	return &sentryError{
+		error: fmt.Errorf("builder %s is not registered by the validator", builder),
+		code:  unregisteredErrorCode,
+	}
+}
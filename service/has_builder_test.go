@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bnb-chain/bsc-mev-sentry/node"
+)
+
+type hasBuilderValidator struct {
+	stubValidator
+	registered map[common.Address]bool
+	err        error
+	calls      int
+}
+
+func (v *hasBuilderValidator) Capabilities() node.Capabilities {
+	return node.Capabilities{HasBuilder: true}
+}
+
+func (v *hasBuilderValidator) HasBuilder(_ context.Context, builder common.Address) (bool, error) {
+	v.calls++
+	return v.registered[builder], v.err
+}
+
+func TestCheckHasBuilder(t *testing.T) {
+	registered, unregistered := common.HexToAddress("0x01"), common.HexToAddress("0x02")
+	validator := &hasBuilderValidator{registered: map[common.Address]bool{registered: true}}
+	validator.cfg.HasBuilder.Enabled = true
+
+	s := &MevSentry{hasBuilder: newHasBuilderCache()}
+	ctx := context.Background()
+
+	assert.NoError(t, s.checkHasBuilder(ctx, "v", registered, validator))
+	assert.NoError(t, s.checkHasBuilder(ctx, "v", registered, validator))
+	assert.Equal(t, 1, validator.calls)
+
+	err := s.checkHasBuilder(ctx, "v", unregistered, validator)
+	if assert.Error(t, err) {
+		assert.Equal(t, unregisteredErrorCode, err.(*sentryError).ErrorCode())
+	}
+
+	// bids are forwarded if the validator can't tell
+	validator.err = errors.New("timeout")
+	assert.NoError(t, s.checkHasBuilder(ctx, "other", unregistered, validator))
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/bnb-chain/bsc-mev-sentry/auth"
+	"github.com/bnb-chain/bsc-mev-sentry/log"
+	"github.com/bnb-chain/bsc-mev-sentry/metrics"
+	"github.com/bnb-chain/bsc-mev-sentry/node"
+)
+
+// checkIdentity makes sure the caller is authenticated as the builder signing the bid,
+// by every authentication method the request comes with.
+func (s *MevSentry) checkIdentity(ctx context.Context, address common.Address, builder node.Builder) error {
+	ids := auth.IdentitiesFromContext(ctx)
+
+	if s.requireClientCert && !hasMethod(ids, "mtls") {
+		log.Errorw("bid without client certificate", "builder", address)
+		return newSentryError("client certificate is required")
+	}
+
+	if s.requireSignature && !hasMethod(ids, "signature") {
+		log.Errorw("bid without request signature", "builder", address)
+		return newSentryError("request signature is required")
+	}
+
+	for _, id := range ids {
+		if !id.Matches(address, builder.Config().CertIdentities) {
+			log.Errorw("authenticated identity mismatches bid signer", "builder", address,
+				"method", id.Method, "names", id.Names)
+			return newSentryError(fmt.Sprintf("%s identity mismatches bid signer", id.Method))
+		}
+	}
+
+	return nil
+}
+
+// checkAPIKey makes sure the request comes with an api key of the builder signing the bid, if the
+// builder has api keys or api keys are required.
+func (s *MevSentry) checkAPIKey(ctx context.Context, address common.Address, builder node.Builder) error {
+	hashes := builder.Config().APIKeyHashes
+	if len(hashes) == 0 && !s.requireAPIKey {
+		return nil
+	}
+
+	if !auth.MatchAPIKey(auth.APIKeyFromContext(ctx), hashes) {
+		log.Errorw("invalid api key", "builder", address)
+		return newSentryError("invalid api key")
+	}
+
+	return nil
+}
+
+func hasMethod(ids []*auth.Identity, method string) bool {
+	for _, id := range ids {
+		if id.Method == method {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkSourceIP makes sure the bid comes from an ip range allowed for the builder signing it.
+func (s *MevSentry) checkSourceIP(ctx context.Context, address common.Address, builder node.Builder,
+	bid *types.RawBid,
+) error {
+	if len(builder.Config().AllowedCIDRs) == 0 {
+		return nil
+	}
+
+	remoteAddr := rpc.PeerInfoFromContext(ctx).RemoteAddr
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+
+	if ip := net.ParseIP(host); ip != nil && builder.AllowsIP(ip) {
+		return nil
+	}
+
+	metrics.BuilderIPMismatchCounter.WithLabelValues(address.String()).Inc()
+	log.Errorw("bid from ip not allowed", "builder", address, "ip", host)
+
+	if builder.Config().ReportIPMismatch && s.ipMismatches.allow(address) {
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Duration(s.timeout))
+			defer cancel()
+
+			err := builder.ReportIssue(ctx, types.BidIssue{
+				Builder: address,
+				BidHash: bid.Hash(),
+				Message: fmt.Sprintf("bid from ip %s not allowed", host),
+			}, nil)
+			if err != nil {
+				log.Errorw("failed to report ip mismatch", "builder", address, "err", err)
+			}
+		}()
+	}
+
+	return newSentryError("source ip not allowed")
+}
+
+// ipMismatchReportInterval is the least time between two reports of bids from ips not allowed to a builder, which
+// sends them in bursts once misconfigured
+const ipMismatchReportInterval = time.Minute
+
+// reportThrottle allows a report to each builder every ipMismatchReportInterval.
+type reportThrottle struct {
+	mu   sync.Mutex
+	last map[common.Address]time.Time
+}
+
+func (t *reportThrottle) allow(builder common.Address) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	if last, ok := t.last[builder]; ok && now.Sub(last) < ipMismatchReportInterval {
+		return false
+	}
+
+	if t.last == nil {
+		t.last = make(map[common.Address]time.Time)
+	}
+	t.last[builder] = now
+
+	return true
+}
@@ -0,0 +1,21 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReportThrottle(t *testing.T) {
+	var throttle reportThrottle
+	b1, b2 := common.HexToAddress("0x01"), common.HexToAddress("0x02")
+
+	assert.True(t, throttle.allow(b1))
+	assert.False(t, throttle.allow(b1), "reported within the interval")
+	assert.True(t, throttle.allow(b2), "throttled per builder")
+
+	throttle.last[b1] = time.Now().Add(-ipMismatchReportInterval)
+	assert.True(t, throttle.allow(b1))
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/bnb-chain/bsc-mev-sentry/log"
+	"github.com/bnb-chain/bsc-mev-sentry/node"
+)
+
+// ReportIssueHash returns the hash a validator signs with its consensus key to report an issue, timestamp in unix
+// seconds, so that a signed issue can't be replayed once expired.
+func ReportIssueHash(issue types.BidIssue, timestamp int64) common.Hash {
+	return crypto.Keccak256Hash([]byte(fmt.Sprintf("mev_reportIssue:%s:%s:%s:%s:%d",
+		issue.Validator.Hex(), issue.Builder.Hex(), issue.BidHash.Hex(), issue.Message, timestamp)))
+}
+
+// checkIssue verifies the issue comes from the validator it names, either signed by its consensus key lately or sent
+// from one of its private urls, and is about a bid of the builder sent to that validator. Behind a proxy the remote
+// address is the one of the proxy, so the issue must be signed.
+func (s *MevSentry) checkIssue(ctx context.Context, issue types.BidIssue, signature *hexutil.Bytes,
+	timestamp *int64,
+) error {
+	hostname, validator, ok := s.validatorByConsensusAddress(issue.Validator)
+	if !ok {
+		return newSentryError("validator not found")
+	}
+
+	if signature != nil {
+		if timestamp == nil {
+			return newSentryError("timestamp of the issue signature is required")
+		}
+
+		signer, err := recoverSigner(ReportIssueHash(issue, *timestamp), *timestamp, *signature)
+		if err != nil {
+			return err
+		}
+		if signer != issue.Validator {
+			return newSentryError("invalid issue signature")
+		}
+	} else if s.behindProxy {
+		log.Errorw("unsigned issue behind a proxy", "validator", hostname)
+		return newSentryError("issue should be signed by the validator")
+	} else if !s.fromValidator(ctx, validator.Config()) {
+		log.Errorw("unauthenticated issue", "validator", hostname, "remote", rpc.PeerInfoFromContext(ctx).RemoteAddr)
+		return newSentryError("issue should be signed by the validator or sent from it")
+	}
+
+	if !s.bidSent(issue.BidHash, issue.Builder, hostname) {
+		return newSentryError("bid not found")
+	}
+
+	return nil
+}
+
+// validatorByConsensusAddress returns the validator of the consensus address.
+func (s *MevSentry) validatorByConsensusAddress(address common.Address) (string, node.Validator, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for hostname, validator := range s.validators {
+		if validator.Config().ConsensusAddress == address {
+			return hostname, validator, true
+		}
+	}
+
+	return "", nil, false
+}
+
+// bidSent tells whether the builder has sent the bid to the validator, per the recent bids or else the bid store.
+func (s *MevSentry) bidSent(bidHash common.Hash, builder common.Address, hostname string) bool {
+	if bid := s.recentBids.get(bidHash); bid != nil && bid.builder == builder && bid.validator == hostname {
+		return true
+	}
+
+	if s.bidStore == nil {
+		return false
+	}
+
+	bids, err := s.bidStore.ByHash(bidHash)
+	if err != nil {
+		log.Errorw("failed to look up bid of issue", "bid", bidHash, "err", err)
+		return false
+	}
+
+	for _, bid := range bids {
+		if bid.Builder == builder && bid.Validator == hostname {
+			return true
+		}
+	}
+
+	return false
+}
+
+// fromValidator tells whether the request comes from the host of one of the private urls of the validator.
+func (s *MevSentry) fromValidator(ctx context.Context, cfg node.ValidatorConfig) bool {
+	remoteAddr := rpc.PeerInfoFromContext(ctx).RemoteAddr
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+
+	remote := net.ParseIP(host)
+	if remote == nil {
+		return false
+	}
+
+	for _, privateURL := range append([]string{cfg.PrivateURL}, cfg.BackupPrivateURLs...) {
+		u, err := url.Parse(privateURL)
+		if err != nil || u.Hostname() == "" {
+			continue
+		}
+
+		if ip := net.ParseIP(u.Hostname()); ip != nil {
+			if ip.Equal(remote) {
+				return true
+			}
+			continue
+		}
+
+		for _, ip := range s.validatorHosts.lookup(ctx, u.Hostname()) {
+			if ip.Equal(remote) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// hostResolveTTL how long the ips of a host are cached, failed lookups included
+const hostResolveTTL = time.Minute
+
+// hostResolver caches the ips hosts resolve to, so that unauthenticated requests don't each cost a lookup. The zero
+// value is ready to use.
+type hostResolver struct {
+	mu    sync.Mutex
+	hosts map[string]resolvedHost
+}
+
+type resolvedHost struct {
+	ips        []net.IP
+	resolvedAt time.Time
+}
+
+func (r *hostResolver) lookup(ctx context.Context, host string) []net.IP {
+	r.mu.Lock()
+	resolved, ok := r.hosts[host]
+	r.mu.Unlock()
+
+	if ok && time.Since(resolved.resolvedAt) < hostResolveTTL {
+		return resolved.ips
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		log.Debugw("failed to resolve validator host", "host", host, "err", err)
+		// the request is gone rather than the host unknown
+		if ctx.Err() != nil {
+			return nil
+		}
+	}
+
+	resolved = resolvedHost{ips: make([]net.IP, 0, len(addrs)), resolvedAt: time.Now()}
+	for _, addr := range addrs {
+		resolved.ips = append(resolved.ips, addr.IP)
+	}
+
+	r.mu.Lock()
+	if r.hosts == nil {
+		r.hosts = make(map[string]resolvedHost)
+	}
+	r.hosts[host] = resolved
+	r.mu.Unlock()
+
+	return resolved.ips
+}
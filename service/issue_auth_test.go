@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/bsc-mev-sentry/node"
+)
+
+func TestCheckIssue(t *testing.T) {
+	consensusKey, _ := crypto.GenerateKey()
+	validator := &stubValidator{}
+	validator.cfg.ConsensusAddress = crypto.PubkeyToAddress(consensusKey.PublicKey)
+	validator.cfg.PrivateURL = "http://10.0.0.1:8545"
+
+	s := &MevSentry{
+		validators: map[string]node.Validator{"v": validator},
+		recentBids: newRecentBids(16),
+	}
+	ctx := context.Background()
+
+	builder, bidHash := common.HexToAddress("0x01"), common.HexToHash("0xaa")
+	s.recentBids.add(bidHash, &recentBid{builder: builder, validator: "v", block: 100})
+
+	issue := types.BidIssue{Validator: validator.cfg.ConsensusAddress, Builder: builder, BidHash: bidHash, Message: "m"}
+	timestamp := time.Now().Unix()
+	sig, _ := crypto.Sign(ReportIssueHash(issue, timestamp).Bytes(), consensusKey)
+	signature := hexutil.Bytes(sig)
+
+	assert.NoError(t, s.checkIssue(ctx, issue, &signature, &timestamp))
+
+	// neither signed nor sent from the validator
+	assert.Error(t, s.checkIssue(ctx, issue, nil, nil))
+
+	// the signature covers the message
+	tampered := issue
+	tampered.Message = "spam"
+	assert.Error(t, s.checkIssue(ctx, tampered, &signature, &timestamp))
+
+	// and the timestamp, which is required
+	later := timestamp + 1
+	assert.Error(t, s.checkIssue(ctx, issue, &signature, &later))
+	assert.ErrorContains(t, s.checkIssue(ctx, issue, &signature, nil), "timestamp")
+
+	// a signed issue can't be replayed once expired
+	expired := time.Now().Add(-2 * rejectionStatsMaxAge).Unix()
+	sig, _ = crypto.Sign(ReportIssueHash(issue, expired).Bytes(), consensusKey)
+	expiredSignature := hexutil.Bytes(sig)
+	assert.ErrorContains(t, s.checkIssue(ctx, issue, &expiredSignature, &expired), "expired")
+
+	// the bid isn't of the builder
+	other := issue
+	other.Builder = common.HexToAddress("0x02")
+	sig, _ = crypto.Sign(ReportIssueHash(other, timestamp).Bytes(), consensusKey)
+	signature = sig
+	assert.Error(t, s.checkIssue(ctx, other, &signature, &timestamp))
+
+	unknown := issue
+	unknown.Validator = common.HexToAddress("0x03")
+	assert.Error(t, s.checkIssue(ctx, unknown, &signature, &timestamp))
+
+}
+
+type fromValidatorStub struct {
+	sentry *MevSentry
+	cfg    node.ValidatorConfig
+}
+
+func (f *fromValidatorStub) From(ctx context.Context) bool {
+	return f.sentry.fromValidator(ctx, f.cfg)
+}
+
+// Issue checks an unsigned issue of the validator "v" of the sentry.
+func (f *fromValidatorStub) Issue(ctx context.Context, issue types.BidIssue) bool {
+	return f.sentry.checkIssue(ctx, issue, nil, nil) == nil
+}
+
+func TestFromValidator(t *testing.T) {
+	stub := &fromValidatorStub{sentry: &MevSentry{recentBids: newRecentBids(16)}}
+	server := rpc.NewServer()
+	require.NoError(t, server.RegisterName("test", stub))
+	srv := httptest.NewServer(server)
+	defer srv.Close()
+
+	client, err := rpc.Dial(srv.URL)
+	require.NoError(t, err)
+	defer client.Close()
+
+	for _, tc := range []struct {
+		cfg  node.ValidatorConfig
+		from bool
+	}{
+		{node.ValidatorConfig{PrivateURL: "http://10.0.0.1:8545"}, false},
+		{node.ValidatorConfig{PrivateURL: "http://10.0.0.1:8545", BackupPrivateURLs: []string{"ws://127.0.0.1:8546"}}, true},
+		{node.ValidatorConfig{PrivateURL: "http://localhost:8545"}, true},
+	} {
+		stub.cfg = tc.cfg
+		var from bool
+		require.NoError(t, client.Call(&from, "test_from"))
+		assert.Equal(t, tc.from, from, tc.cfg.PrivateURL)
+	}
+
+	// the ips of localhost are cached
+	require.Contains(t, stub.sentry.validatorHosts.hosts, "localhost")
+
+	// requests not over a connection don't come from anywhere
+	assert.False(t, stub.sentry.fromValidator(context.Background(), stub.cfg))
+
+	// an unsigned issue sent from the validator is accepted unless the sentry is behind a proxy
+	validator := &stubValidator{cfg: node.ValidatorConfig{ConsensusAddress: common.HexToAddress("0x11"),
+		PrivateURL: "http://127.0.0.1:8545"}}
+	stub.sentry.validators = map[string]node.Validator{"v": validator}
+	issue := types.BidIssue{Validator: validator.cfg.ConsensusAddress, Builder: common.HexToAddress("0x01"),
+		BidHash: common.HexToHash("0xaa")}
+	stub.sentry.recentBids.add(issue.BidHash, &recentBid{builder: issue.Builder, validator: "v", block: 100})
+
+	var accepted bool
+	require.NoError(t, client.Call(&accepted, "test_issue", issue))
+	assert.True(t, accepted)
+
+	stub.sentry.behindProxy = true
+	require.NoError(t, client.Call(&accepted, "test_issue", issue))
+	assert.False(t, accepted)
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/bnb-chain/bsc-mev-sentry/store"
+)
+
+// storeIssue persists the issue forwarded to the builder and whether it's delivered, if the bid store is enabled.
+func (s *MevSentry) storeIssue(issue types.BidIssue, reportedAt time.Time, status string, err error) {
+	if s.bidStore == nil {
+		return
+	}
+
+	hostname, _, _ := s.validatorByConsensusAddress(issue.Validator)
+	s.bidStore.RecordIssue(&store.Issue{
+		Tenant:     hostname,
+		Validator:  issue.Validator,
+		Builder:    issue.Builder,
+		BidHash:    issue.BidHash,
+		Message:    issue.Message,
+		ReportedAt: reportedAt,
+		Status:     status,
+		Error:      errString(err),
+	})
+}
+
+// updateIssue records whether the queued issue is delivered once its batch is.
+func (s *MevSentry) updateIssue(issue types.BidIssue, err error) {
+	if s.bidStore == nil {
+		return
+	}
+
+	hostname, _, _ := s.validatorByConsensusAddress(issue.Validator)
+	s.bidStore.UpdateIssue(&store.Issue{
+		Tenant:    hostname,
+		Validator: issue.Validator,
+		Builder:   issue.Builder,
+		BidHash:   issue.BidHash,
+		Status:    issueStatus(err),
+		Error:     errString(err),
+	})
+}
+
+func issueStatus(err error) string {
+	if err != nil {
+		return store.IssueFailed
+	}
+
+	return store.IssueDelivered
+}
+
+// IssueHistoryArgs is the signed query of a builder for the issues reported to it.
+type IssueHistoryArgs struct {
+	store.IssueFilter
+	// Timestamp unix seconds when the query was signed
+	Timestamp int64 `json:"timestamp"`
+	// Signature of IssueHistoryHash by the builder key
+	Signature hexutil.Bytes `json:"signature"`
+}
+
+// IssueHistoryHash returns the hash a builder signs to query the issues reported to it, the builder and
+// validator of the filter aren't signed since a builder only gets its own issues anyway.
+func IssueHistoryHash(f store.IssueFilter, timestamp int64) common.Hash {
+	return crypto.Keccak256Hash([]byte(fmt.Sprintf("mev_issueHistory:%s:%d:%d:%d:%d:%d",
+		f.Status, f.FromTime, f.ToTime, f.Cursor, f.Limit, timestamp)))
+}
+
+// IssueHistory returns a page of the issues reported to the signing builder and whether they're delivered.
+func (s *MevSentry) IssueHistory(_ context.Context, args IssueHistoryArgs) (*store.IssuePage, error) {
+	if s.bidStore == nil {
+		return nil, newSentryError(errBidStoreDisabled.Error())
+	}
+
+	builder, err := s.verifyBuilderQuery(IssueHistoryHash(args.IssueFilter, args.Timestamp), args.Timestamp,
+		args.Signature)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := args.IssueFilter
+	filter.Builder = &builder
+
+	page, err := s.bidStore.QueryIssues(filter)
+	if err != nil {
+		return nil, newSentryError(fmt.Sprintf("failed to query issue history: %v", err))
+	}
+
+	return page, nil
+}
+
+// IssueStatsArgs is the signed query of a builder for the stats of the issues reported to it.
+type IssueStatsArgs struct {
+	// Hours of the window, defaults to 24
+	Hours int `json:"hours"`
+	// Timestamp unix seconds when the query was signed
+	Timestamp int64 `json:"timestamp"`
+	// Signature of IssueStatsHash by the builder key
+	Signature hexutil.Bytes `json:"signature"`
+}
+
+// IssueStatsHash returns the hash a builder signs to query its issue stats.
+func IssueStatsHash(hours int, timestamp int64) common.Hash {
+	return crypto.Keccak256Hash([]byte(fmt.Sprintf("mev_issueStats:%d:%d", hours, timestamp)))
+}
+
+// IssueStats returns the issue stats of the signing builder over the last hours, nil if it has no issues.
+func (s *MevSentry) IssueStats(_ context.Context, args IssueStatsArgs) (*store.IssueStats, error) {
+	if s.bidStore == nil {
+		return nil, newSentryError(errBidStoreDisabled.Error())
+	}
+
+	builder, err := s.verifyBuilderQuery(IssueStatsHash(args.Hours, args.Timestamp), args.Timestamp, args.Signature)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := s.bidStore.IssueStats(statsSince(args.Hours), &builder)
+	if err != nil {
+		return nil, newSentryError(fmt.Sprintf("failed to query issue stats: %v", err))
+	}
+
+	if len(stats) == 0 {
+		return nil, nil
+	}
+
+	return stats[0], nil
+}
+
+// IssueHistory returns a page of the issues reported to any builder.
+func (a *MevAdmin) IssueHistory(_ context.Context, filter store.IssueFilter) (*store.IssuePage, error) {
+	if a.sentry.bidStore == nil {
+		return nil, errBidStoreDisabled
+	}
+
+	return a.sentry.bidStore.QueryIssues(filter)
+}
+
+// IssueStats returns the issue stats of all builders with issues over the last hours.
+func (a *MevAdmin) IssueStats(_ context.Context, hours int) ([]*store.IssueStats, error) {
+	if a.sentry.bidStore == nil {
+		return nil, errBidStoreDisabled
+	}
+
+	return a.sentry.bidStore.IssueStats(statsSince(hours), nil)
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bnb-chain/bsc-mev-sentry/log"
+	"github.com/bnb-chain/bsc-mev-sentry/metrics"
+	"github.com/bnb-chain/bsc-mev-sentry/node"
+)
+
+const (
+	outcomeTrackInterval = 500 * time.Millisecond
+	// outcomeMaxBlocks bounds the blocks settled at once, older blocks are left accepted, e.g. after a long outage
+	outcomeMaxBlocks    = 64
+	outcomeBlockTimeout = 3 * time.Second
+)
+
+// outcomeTracker follows the blocks produced on each validator's chain and settles the bids accepted for
+// them in the bid store, i.e. a bid is won if its pay bid tx is included in its block, otherwise lost, and
+// attributes the blocks proposed by the validator to the builders winning them. A block
+// whose parent isn't the block settled before it reveals a reorg, the blocks replaced are settled again, up to
+// outcomeMaxBlocks back.
+type outcomeTracker struct {
+	sentry  *MevSentry
+	settled map[string]*settledChain // validator hostname -> blocks settled
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// settledChain is the recent blocks settled on the chain of a validator.
+type settledChain struct {
+	last   uint64
+	hashes map[uint64]common.Hash // number -> hash of the blocks settled, unless they couldn't be fetched
+}
+
+func newOutcomeTracker(sentry *MevSentry) *outcomeTracker {
+	t := &outcomeTracker{
+		sentry:  sentry,
+		settled: make(map[string]*settledChain),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+
+	go t.loop()
+
+	return t
+}
+
+func (t *outcomeTracker) loop() {
+	defer close(t.done)
+
+	ticker := time.NewTicker(outcomeTrackInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			t.track()
+		case <-t.stop:
+			return
+		}
+	}
+}
+
+func (t *outcomeTracker) track() {
+	t.sentry.mu.RLock()
+	validators := make(map[string]node.Validator, len(t.sentry.validators))
+	for hostname, validator := range t.sentry.validators {
+		validators[hostname] = validator
+	}
+	t.sentry.mu.RUnlock()
+
+	for hostname, validator := range validators {
+		head := validator.Head()
+		if head == nil {
+			continue
+		}
+
+		chain, ok := t.settled[hostname]
+		if !ok || head.Number-chain.last > outcomeMaxBlocks {
+			// start from the current head, bids of earlier blocks are left accepted
+			chain = &settledChain{last: head.Number, hashes: make(map[uint64]common.Hash)}
+			t.settled[hostname] = chain
+		}
+
+		for number := chain.last + 1; number <= head.Number; number++ {
+			block := t.settle(hostname, validator, number)
+			if block == nil {
+				continue
+			}
+
+			if parent, ok := chain.hashes[number-1]; ok && parent != block.ParentHash {
+				t.reorg(hostname, validator, chain, number-1)
+			}
+			chain.hashes[number] = block.Hash
+		}
+
+		chain.last = head.Number
+		for number := range chain.hashes {
+			if number+outcomeMaxBlocks < chain.last {
+				delete(chain.hashes, number)
+			}
+		}
+	}
+
+	for hostname := range t.settled {
+		if _, ok := validators[hostname]; !ok {
+			delete(t.settled, hostname)
+			t.sentry.winRates.remove(hostname)
+		}
+	}
+}
+
+// settle settles the bids of the block, and returns the block, nil if it couldn't be fetched.
+func (t *outcomeTracker) settle(hostname string, validator node.Validator, number uint64) *node.BlockTxs {
+	ctx, cancel := context.WithTimeout(context.Background(), outcomeBlockTimeout)
+	defer cancel()
+
+	block, err := validator.BlockTxs(ctx, number)
+	if err != nil {
+		log.Errorw("failed to fetch block to settle bids, expire them", "validator", hostname, "block", number, "err", err)
+		if t.sentry.bidStore != nil {
+			t.sentry.bidStore.SettleBlock(hostname, number, nil)
+		}
+		return nil
+	}
+
+	t.settleTxs(hostname, validator, number, block)
+
+	return block
+}
+
+func (t *outcomeTracker) settleTxs(hostname string, validator node.Validator, number uint64, block *node.BlockTxs) {
+	t.sentry.winRates.record(hostname, validator.Config().ConsensusAddress, number, block)
+
+	if t.sentry.bidStore == nil {
+		return
+	}
+
+	txs := block.Txs
+	if txs == nil {
+		txs = []common.Hash{}
+	}
+	t.sentry.bidStore.SettleBlock(hostname, number, txs)
+}
+
+// reorg settles again the blocks from the number back, until the block settled before is still on the chain.
+func (t *outcomeTracker) reorg(hostname string, validator node.Validator, chain *settledChain, number uint64) {
+	depth := 0
+	for ; ; number-- {
+		settled, ok := chain.hashes[number]
+		if !ok {
+			break
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), outcomeBlockTimeout)
+		block, err := validator.BlockTxs(ctx, number)
+		cancel()
+		if err != nil {
+			log.Errorw("failed to fetch block replaced by reorg", "validator", hostname, "block", number, "err", err)
+			break
+		}
+		if block.Hash == settled {
+			break
+		}
+
+		t.settleTxs(hostname, validator, number, block)
+		chain.hashes[number] = block.Hash
+		depth++
+	}
+
+	metrics.ChainReorgCounter.WithLabelValues(hostname).Inc()
+	metrics.ChainReorgDepthHist.WithLabelValues(hostname).Observe(float64(depth))
+	log.Warnw("chain reorg, bids settled again", "validator", hostname, "depth", depth, "from", number+1)
+}
+
+func (t *outcomeTracker) close() {
+	if t == nil {
+		return
+	}
+
+	close(t.stop)
+	<-t.done
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/bsc-mev-sentry/metrics"
+	"github.com/bnb-chain/bsc-mev-sentry/node"
+	"github.com/bnb-chain/bsc-mev-sentry/store"
+)
+
+// chainValidator serves the blocks of its chain, which the test reorgs by replacing them.
+type chainValidator struct {
+	stubValidator
+	blocks map[uint64]*node.BlockTxs
+}
+
+func (v *chainValidator) BlockTxs(_ context.Context, number uint64) (*node.BlockTxs, error) {
+	return v.blocks[number], nil
+}
+
+// extend appends a block including the txs to the chain at the number, replacing the blocks from there.
+func (v *chainValidator) extend(number uint64, fork byte, txs ...common.Hash) {
+	block := &node.BlockTxs{
+		Hash:       common.Hash{byte(number), fork},
+		ParentHash: v.blocks[number-1].Hash,
+		Txs:        txs,
+	}
+	v.blocks[number] = block
+	for n := range v.blocks {
+		if n > number {
+			delete(v.blocks, n)
+		}
+	}
+	v.head = &node.ChainHead{Hash: block.Hash, Number: number}
+}
+
+func TestOutcomeTrackerReorg(t *testing.T) {
+	dsn := filepath.Join(t.TempDir(), "bids.db")
+	bidStore, err := store.OpenBidStore(store.BidStoreConfig{Driver: "sqlite", DSN: dsn}, nil)
+	require.NoError(t, err)
+
+	payTx := common.HexToHash("0x01ff")
+	bidStore.Record(&store.Bid{
+		Hash:        common.HexToHash("0x01"),
+		Validator:   "reorg",
+		BlockNumber: 11,
+		PayTxHash:   payTx,
+		Outcome:     store.BidAccepted,
+	})
+
+	validator := &chainValidator{blocks: map[uint64]*node.BlockTxs{10: {Hash: common.Hash{10}}}}
+	validator.head = &node.ChainHead{Hash: common.Hash{10}, Number: 10}
+
+	s := &MevSentry{validators: map[string]node.Validator{"reorg": validator}, bidStore: bidStore}
+	tracker := &outcomeTracker{sentry: s, settled: make(map[string]*settledChain)}
+
+	tracker.track()
+	validator.extend(11, 0, payTx)
+	validator.extend(12, 0)
+	tracker.track()
+
+	// blocks 11 and 12 are replaced, the pay bid tx isn't included anymore
+	validator.extend(11, 1)
+	validator.extend(12, 1)
+	validator.extend(13, 1)
+	tracker.track()
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.ChainReorgCounter.WithLabelValues("reorg")))
+
+	require.NoError(t, bidStore.Close())
+	bidStore, err = store.OpenBidStore(store.BidStoreConfig{Driver: "sqlite", DSN: dsn}, nil)
+	require.NoError(t, err)
+	defer bidStore.Close()
+
+	page, err := bidStore.Query(store.BidFilter{})
+	require.NoError(t, err)
+	require.Len(t, page.Bids, 1)
+	assert.Equal(t, store.BidLost, page.Bids[0].Outcome)
+}
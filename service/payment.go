@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/bnb-chain/bsc-mev-sentry/log"
+	"github.com/bnb-chain/bsc-mev-sentry/store"
+)
+
+var errPaymentStoreDisabled = errors.New("payment store is disabled")
+
+// recordPayment stores the pay bid tx of a forwarded bid as evidence for disputes.
+func (s *MevSentry) recordPayment(hostname string, builder common.Address, bidHash common.Hash, payBidTx hexutil.Bytes) {
+	if s.payments == nil {
+		return
+	}
+
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(payBidTx); err != nil {
+		log.Errorw("failed to decode pay bid tx", "bidHash", bidHash, "err", err)
+		return
+	}
+
+	err := s.payments.Put(&store.Payment{
+		BidHash:   bidHash,
+		TxHash:    tx.Hash(),
+		Nonce:     tx.Nonce(),
+		Amount:    tx.Value(),
+		Builder:   builder,
+		Validator: hostname,
+		Time:      time.Now().Unix(),
+	})
+	if err != nil {
+		log.Errorw("failed to store payment", "bidHash", bidHash, "txHash", tx.Hash(), "err", err)
+	}
+}
+
+// PaymentByBid returns the pay bid tx signed for the bid.
+func (a *MevAdmin) PaymentByBid(_ context.Context, bidHash common.Hash) (*store.Payment, error) {
+	if a.sentry.payments == nil {
+		return nil, errPaymentStoreDisabled
+	}
+
+	return a.sentry.payments.ByBid(bidHash)
+}
+
+// PaymentByTx returns the bid the pay bid tx is signed for.
+func (a *MevAdmin) PaymentByTx(_ context.Context, txHash common.Hash) (*store.Payment, error) {
+	if a.sentry.payments == nil {
+		return nil, errPaymentStoreDisabled
+	}
+
+	return a.sentry.payments.ByTx(txHash)
+}
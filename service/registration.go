@@ -0,0 +1,357 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/bnb-chain/bsc-mev-sentry/log"
+	"github.com/bnb-chain/bsc-mev-sentry/node"
+	"github.com/bnb-chain/bsc-mev-sentry/store"
+)
+
+var errRegistrationDisabled = errors.New("validator registration is disabled")
+
+type ValidatorRegistrationConfig struct {
+	// Enabled lets validators register themselves via mev_registerValidator
+	Enabled bool
+	// Operators addresses of the consensus or operator keys allowed to register validators
+	Operators []common.Address
+	// KeystoreDir the keystores and password files of an operator's validators must be under the directory named
+	// after the operator address in lower case hex, e.g. <KeystoreDir>/0xabc...
+	KeystoreDir string
+	// Path file the registrations are persisted to
+	Path string
+	// Identity of the sentry operators sign their registrations for, so that they can't be replayed to another sentry
+	Identity string
+}
+
+// RegisterValidatorArgs is the registration of a validator signed by its operator. The pay account must be
+// a keystore on the sentry host, private keys are never accepted over the api.
+type RegisterValidatorArgs struct {
+	// Sentry the identity of the sentry the registration is meant for
+	Sentry string `json:"sentry"`
+	// Nonce above the last one used by the operator
+	Nonce             uint64 `json:"nonce"`
+	PublicHostName    string `json:"publicHostName"`
+	PrivateURL        string `json:"privateURL"`
+	PayAccountAddress string `json:"payAccountAddress"`
+	KeystorePath      string `json:"keystorePath"`
+	PasswordFilePath  string `json:"passwordFilePath"`
+	// Timestamp unix seconds when the registration was signed
+	Timestamp int64 `json:"timestamp"`
+	// Signature of RegisterValidatorHash by the operator key
+	Signature hexutil.Bytes `json:"signature"`
+}
+
+// RegisterValidatorHash returns the hash an operator signs to register a validator.
+func RegisterValidatorHash(args RegisterValidatorArgs) common.Hash {
+	return crypto.Keccak256Hash([]byte(fmt.Sprintf("mev_registerValidator:%s:%d:%s:%s:%s:%s:%s:%d", args.Sentry,
+		args.Nonce, args.PublicHostName, args.PrivateURL, args.PayAccountAddress, args.KeystorePath,
+		args.PasswordFilePath, args.Timestamp)))
+}
+
+// DeregisterValidatorArgs is the request of an operator to remove a validator it registered.
+type DeregisterValidatorArgs struct {
+	// Sentry the identity of the sentry the request is meant for
+	Sentry string `json:"sentry"`
+	// Nonce above the last one used by the operator
+	Nonce          uint64 `json:"nonce"`
+	PublicHostName string `json:"publicHostName"`
+	// Timestamp unix seconds when the request was signed
+	Timestamp int64 `json:"timestamp"`
+	// Signature of DeregisterValidatorHash by the operator key
+	Signature hexutil.Bytes `json:"signature"`
+}
+
+// DeregisterValidatorHash returns the hash an operator signs to remove a validator.
+func DeregisterValidatorHash(args DeregisterValidatorArgs) common.Hash {
+	return crypto.Keccak256Hash([]byte(fmt.Sprintf("mev_deregisterValidator:%s:%d:%s:%d", args.Sentry, args.Nonce,
+		args.PublicHostName, args.Timestamp)))
+}
+
+// registrations holds the validators registered at runtime and who may change them.
+type registrations struct {
+	identity    string
+	operators   map[common.Address]struct{}
+	keystoreDir string
+	store       *store.RegistrationStore
+}
+
+func newRegistrations(cfg ValidatorRegistrationConfig) (*registrations, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	path := cfg.Path
+	if path == "" {
+		path = "./data/registrations.json"
+	}
+
+	regStore, err := store.OpenRegistrationStore(path)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &registrations{
+		identity:    cfg.Identity,
+		operators:   make(map[common.Address]struct{}, len(cfg.Operators)),
+		keystoreDir: cfg.KeystoreDir,
+		store:       regStore,
+	}
+	for _, operator := range cfg.Operators {
+		r.operators[operator] = struct{}{}
+	}
+
+	return r, nil
+}
+
+// all returns the registrations, none if registration is disabled.
+func (r *registrations) all() []store.ValidatorRegistration {
+	if r == nil {
+		return nil
+	}
+
+	return r.store.All()
+}
+
+func registrationConfig(reg store.ValidatorRegistration) node.ValidatorConfig {
+	return node.ValidatorConfig{
+		PrivateURL:        reg.PrivateURL,
+		PublicHostName:    reg.PublicHostName,
+		PayAccountMode:    "keystore",
+		KeystorePath:      reg.KeystorePath,
+		PasswordFilePath:  reg.PasswordFilePath,
+		PayAccountAddress: reg.PayAccountAddress,
+	}
+}
+
+// check checks the registration only uses the keystore directory of its operator, and neither the keystore nor the
+// pay account of the other validators, so that an operator can't make the sentry sign with the pay account of
+// another validator.
+func (r *registrations) check(reg store.ValidatorRegistration, validators map[string]node.Validator) error {
+	if err := r.checkPaths(reg); err != nil {
+		return err
+	}
+
+	keystore, err := filepath.Abs(reg.KeystorePath)
+	if err != nil {
+		return err
+	}
+	payAccount := common.HexToAddress(reg.PayAccountAddress)
+
+	for hostname, validator := range validators {
+		if hostname == reg.PublicHostName {
+			continue
+		}
+
+		if validator.PayAccount() == payAccount {
+			return fmt.Errorf("pay account is used by validator %s", hostname)
+		}
+		if path := validator.Config().KeystorePath; path != "" {
+			if abs, err := filepath.Abs(path); err == nil && abs == keystore {
+				return fmt.Errorf("keystore is used by validator %s", hostname)
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkPaths checks the keystore and the password file of the registration are under the directory of its operator.
+func (r *registrations) checkPaths(reg store.ValidatorRegistration) error {
+	dir, err := filepath.Abs(filepath.Join(r.keystoreDir, strings.ToLower(reg.Operator.Hex())))
+	if err != nil {
+		return err
+	}
+
+	for _, path := range []string{reg.KeystorePath, reg.PasswordFilePath} {
+		if path == "" {
+			continue
+		}
+
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return err
+		}
+		if rel, err := filepath.Rel(dir, abs); err != nil || rel == ".." || strings.HasPrefix(rel, "../") {
+			return fmt.Errorf("%s is outside the keystore directory of the operator", path)
+		}
+	}
+
+	return nil
+}
+
+// verifyOperator recovers the operator signing the hash for this sentry, it must be allowed to register validators
+// and own the registration of the hostname if any. The nonce is used up once the operator is verified.
+func (r *registrations) verifyOperator(hash common.Hash, sentry string, nonce uint64, timestamp int64,
+	signature hexutil.Bytes, hostname string,
+) (common.Address, error) {
+	if sentry != r.identity {
+		return common.Address{}, newSentryError("signed for another sentry")
+	}
+
+	operator, err := recoverSigner(hash, timestamp, signature)
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	if _, ok := r.operators[operator]; !ok {
+		return common.Address{}, newSentryError("operator not allowed")
+	}
+
+	if reg, ok := r.store.Get(hostname); ok && reg.Operator != operator {
+		return common.Address{}, newSentryError("validator is registered by another operator")
+	}
+
+	if err = r.store.UseNonce(operator, nonce); errors.Is(err, store.ErrNonceUsed) {
+		return common.Address{}, newSentryError("nonce already used")
+	} else if err != nil {
+		log.Errorw("failed to persist operator nonce", "operator", operator, "err", err)
+		return common.Address{}, newSentryError("failed to persist nonce")
+	}
+
+	return operator, nil
+}
+
+// startRegisteredValidators creates the validators registered earlier, ones configured statically are kept.
+func (s *MevSentry) startRegisteredValidators() {
+	for _, reg := range s.registrations.all() {
+		cfg := registrationConfig(reg)
+		if _, ok := s.validators[cfg.PublicHostName]; ok {
+			log.Errorw("registered validator is configured statically, ignore the registration",
+				"hostname", cfg.PublicHostName)
+			continue
+		}
+
+		if err := s.registrations.check(reg, s.validators); err != nil {
+			log.Errorw("ignore the validator registration", "hostname", cfg.PublicHostName, "err", err)
+			continue
+		}
+
+		validator, err := s.newValidator(cfg)
+		if err != nil {
+			log.Errorw("failed to create registered validator", "hostname", cfg.PublicHostName, "err", err)
+			continue
+		}
+
+		s.validators[cfg.PublicHostName] = validator
+	}
+}
+
+// RegisterValidator adds or updates a validator registered by its operator, the registration is persisted so
+// that it survives restarts.
+func (s *MevSentry) RegisterValidator(_ context.Context, args RegisterValidatorArgs) error {
+	if s.registrations == nil {
+		return newSentryError(errRegistrationDisabled.Error())
+	}
+
+	if args.PublicHostName == "" || args.PrivateURL == "" || args.KeystorePath == "" || args.PayAccountAddress == "" {
+		return newSentryError("public hostname, private url, pay account address and keystore path are required")
+	}
+
+	operator, err := s.registrations.verifyOperator(RegisterValidatorHash(args), args.Sentry, args.Nonce,
+		args.Timestamp, args.Signature, args.PublicHostName)
+	if err != nil {
+		return err
+	}
+
+	s.topologyMu.Lock()
+	defer s.topologyMu.Unlock()
+
+	if _, registered := s.registrations.store.Get(args.PublicHostName); !registered {
+		if _, ok := s.validator(args.PublicHostName); ok {
+			return newSentryError("validator is configured by the sentry operator")
+		}
+	}
+
+	reg := store.ValidatorRegistration{
+		Operator:          operator,
+		PublicHostName:    args.PublicHostName,
+		PrivateURL:        args.PrivateURL,
+		PayAccountAddress: args.PayAccountAddress,
+		KeystorePath:      args.KeystorePath,
+		PasswordFilePath:  args.PasswordFilePath,
+		RegisteredAt:      time.Now(),
+	}
+
+	if err = s.registrations.check(reg, s.validators); err != nil {
+		return newSentryError(err.Error())
+	}
+
+	validator, err := s.newValidator(registrationConfig(reg))
+	if err != nil {
+		return newSentryError(fmt.Sprintf("failed to create validator: %v", err))
+	}
+
+	if err = s.registrations.store.Put(reg); err != nil {
+		validator.Stop()
+		log.Errorw("failed to persist validator registration", "hostname", reg.PublicHostName, "err", err)
+		return newSentryError("failed to persist registration")
+	}
+
+	s.mu.Lock()
+	old := s.validators[reg.PublicHostName]
+	s.validators[reg.PublicHostName] = validator
+	s.mu.Unlock()
+
+	if old != nil {
+		old.Stop()
+	}
+
+	log.Infow("validator registered", "hostname", reg.PublicHostName, "operator", operator, "replaced", old != nil)
+
+	return nil
+}
+
+// DeregisterValidator removes a validator registered by the signing operator.
+func (s *MevSentry) DeregisterValidator(_ context.Context, args DeregisterValidatorArgs) error {
+	if s.registrations == nil {
+		return newSentryError(errRegistrationDisabled.Error())
+	}
+
+	operator, err := s.registrations.verifyOperator(DeregisterValidatorHash(args), args.Sentry, args.Nonce,
+		args.Timestamp, args.Signature, args.PublicHostName)
+	if err != nil {
+		return err
+	}
+
+	s.topologyMu.Lock()
+	defer s.topologyMu.Unlock()
+
+	if err = s.registrations.store.Delete(args.PublicHostName); errors.Is(err, store.ErrNotFound) {
+		return newSentryError("validator not registered")
+	} else if err != nil {
+		log.Errorw("failed to persist validator deregistration", "hostname", args.PublicHostName, "err", err)
+		return newSentryError("failed to persist registration")
+	}
+
+	s.mu.Lock()
+	validator := s.validators[args.PublicHostName]
+	delete(s.validators, args.PublicHostName)
+	s.mu.Unlock()
+
+	if validator != nil {
+		validator.Stop()
+	}
+
+	log.Infow("validator deregistered", "hostname", args.PublicHostName, "operator", operator)
+
+	return nil
+}
+
+// ValidatorRegistrations returns the validators registered by their operators.
+func (a *MevAdmin) ValidatorRegistrations(_ context.Context) ([]store.ValidatorRegistration, error) {
+	if a.sentry.registrations == nil {
+		return nil, errRegistrationDisabled
+	}
+
+	return a.sentry.registrations.store.All(), nil
+}
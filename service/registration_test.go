@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/bsc-mev-sentry/node"
+	"github.com/bnb-chain/bsc-mev-sentry/store"
+)
+
+func TestValidatorRegistrationVerification(t *testing.T) {
+	operatorKey, _ := crypto.GenerateKey()
+	otherKey, _ := crypto.GenerateKey()
+	strangerKey, _ := crypto.GenerateKey()
+	operator, other := crypto.PubkeyToAddress(operatorKey.PublicKey), crypto.PubkeyToAddress(otherKey.PublicKey)
+
+	registrations, err := newRegistrations(ValidatorRegistrationConfig{
+		Enabled:   true,
+		Operators: []common.Address{operator, other},
+		Path:      filepath.Join(t.TempDir(), "registrations.json"),
+		Identity:  "sentry-1",
+	})
+	require.NoError(t, err)
+	s := &MevSentry{validators: map[string]node.Validator{}, registrations: registrations}
+	ctx := context.Background()
+
+	register := RegisterValidatorArgs{
+		Sentry:            "sentry-1",
+		Nonce:             1,
+		PublicHostName:    "validator",
+		PrivateURL:        "http://127.0.0.1:8545",
+		PayAccountAddress: "0x01",
+		KeystorePath:      "./keystore",
+		Timestamp:         time.Now().Unix(),
+	}
+	sign := func(key *ecdsa.PrivateKey, hash common.Hash) []byte {
+		sig, err := crypto.Sign(hash.Bytes(), key)
+		require.NoError(t, err)
+		return sig
+	}
+
+	// operators not allowed are rejected
+	register.Signature = sign(strangerKey, RegisterValidatorHash(register))
+	assert.ErrorContains(t, s.RegisterValidator(ctx, register), "operator not allowed")
+
+	// so is a registration changed after it's signed, its signer isn't the operator anymore
+	register.Signature = sign(operatorKey, RegisterValidatorHash(register))
+	changed := register
+	changed.PrivateURL = "http://10.0.0.1:8545"
+	assert.ErrorContains(t, s.RegisterValidator(ctx, changed), "operator not allowed")
+
+	// and one signed for another sentry
+	register.Sentry = "sentry-2"
+	register.Signature = sign(operatorKey, RegisterValidatorHash(register))
+	assert.ErrorContains(t, s.RegisterValidator(ctx, register), "another sentry")
+
+	require.NoError(t, registrations.store.Put(store.ValidatorRegistration{Operator: operator,
+		PublicHostName: "validator"}))
+	deregister := DeregisterValidatorArgs{Sentry: "sentry-1", Nonce: 1, PublicHostName: "validator",
+		Timestamp: time.Now().Unix()}
+
+	// only the operator who registered the validator removes it
+	deregister.Signature = sign(otherKey, DeregisterValidatorHash(deregister))
+	assert.ErrorContains(t, s.DeregisterValidator(ctx, deregister), "another operator")
+
+	deregister.Signature = sign(operatorKey, DeregisterValidatorHash(deregister))
+	require.NoError(t, s.DeregisterValidator(ctx, deregister))
+	_, registered := registrations.store.Get("validator")
+	assert.False(t, registered)
+
+	// a signed request can't be replayed
+	assert.ErrorContains(t, s.DeregisterValidator(ctx, deregister), "nonce already used")
+}
+
+func TestValidatorRegistrationKeystore(t *testing.T) {
+	operatorKey, _ := crypto.GenerateKey()
+	operator := crypto.PubkeyToAddress(operatorKey.PublicKey)
+	dir := t.TempDir()
+	operatorDir := filepath.Join(dir, strings.ToLower(operator.Hex()))
+
+	registrations, err := newRegistrations(ValidatorRegistrationConfig{
+		Enabled:     true,
+		Operators:   []common.Address{operator},
+		KeystoreDir: dir,
+		Path:        filepath.Join(dir, "registrations.json"),
+		Identity:    "sentry-1",
+	})
+	require.NoError(t, err)
+	static := &stubValidator{cfg: node.ValidatorConfig{PublicHostName: "static", PayAccountMode: "keystore",
+		KeystorePath: filepath.Join(operatorDir, "static"), PayAccountAddress: "0x01"}}
+	s := &MevSentry{validators: map[string]node.Validator{"static": static}, registrations: registrations}
+
+	var nonce uint64
+	register := func(payAccount, keystorePath, passwordFilePath string) error {
+		nonce++
+		args := RegisterValidatorArgs{Sentry: "sentry-1", Nonce: nonce, PublicHostName: "validator",
+			PrivateURL: "http://127.0.0.1:8545", PayAccountAddress: payAccount, KeystorePath: keystorePath,
+			PasswordFilePath: passwordFilePath, Timestamp: time.Now().Unix()}
+		sig, err := crypto.Sign(RegisterValidatorHash(args).Bytes(), operatorKey)
+		require.NoError(t, err)
+		args.Signature = sig
+
+		return s.RegisterValidator(context.Background(), args)
+	}
+
+	// the keystore and the password file must be under the directory of the operator
+	assert.ErrorContains(t, register("0x02", filepath.Join(dir, "keystore"), ""), "outside the keystore directory")
+	assert.ErrorContains(t, register("0x02", filepath.Join(operatorDir, "..", "keystore"), ""),
+		"outside the keystore directory")
+	assert.ErrorContains(t, register("0x02", filepath.Join(operatorDir, "keystore"), filepath.Join(dir, "password")),
+		"outside the keystore directory")
+
+	// and can't use the keystore or the pay account of another validator
+	assert.ErrorContains(t, register("0x02", filepath.Join(operatorDir, "static"), ""), "keystore is used")
+	assert.ErrorContains(t, register("0x01", filepath.Join(operatorDir, "keystore"), ""), "pay account is used")
+}
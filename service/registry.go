@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/bnb-chain/bsc-mev-sentry/log"
+	"github.com/bnb-chain/bsc-mev-sentry/node"
+)
+
+// checkOnChainRegistry rejects the bid if its builder isn't registered on chain and the registry is
+// enforced, otherwise it's only logged. Bids pass until the registered builders are read.
+func (s *MevSentry) checkOnChainRegistry(builder common.Address) error {
+	if s.registry == nil {
+		return nil
+	}
+
+	if has, known := s.registry.Has(builder); has || !known {
+		return nil
+	}
+
+	log.Errorw("builder not registered on chain", "address", builder, "enforced", s.registry.Enforced())
+
+	if !s.registry.Enforced() {
+		return nil
+	}
+
+	return types.NewInvalidBidError("builder not registered on chain")
+}
+
+// OnChainBuilders returns the builders registered on chain as of the last refresh of the registry.
+func (a *MevAdmin) OnChainBuilders(_ context.Context) (*node.RegisteredBuilders, error) {
+	if a.sentry.registry == nil {
+		return nil, newSentryError("builder registry is not configured")
+	}
+
+	builders := a.sentry.registry.Builders()
+	return &builders, nil
+}
+
+// checkStake rejects the bid if the on chain stake of its builder is below the minimum, or not read yet.
+func (s *MevSentry) checkStake(builder common.Address) error {
+	if s.stakes == nil {
+		return nil
+	}
+
+	sufficient, known := s.stakes.Sufficient(builder)
+	if !known {
+		return newSentryError("builder stake is not verified yet, try again later")
+	}
+
+	if !sufficient {
+		log.Errorw("builder stake below the minimum", "address", builder, "minStake", s.stakes.MinStake())
+		return types.NewInvalidBidError(fmt.Sprintf("builder stake is below the minimum %v wei", s.stakes.MinStake()))
+	}
+
+	return nil
+}
+
+// builderAddresses returns the addresses of the registered builders.
+func (s *MevSentry) builderAddresses() []common.Address {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	addresses := make([]common.Address, 0, len(s.builders))
+	for address := range s.builders {
+		addresses = append(addresses, address)
+	}
+
+	return addresses
+}
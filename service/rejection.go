@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/bnb-chain/bsc-mev-sentry/node"
+)
+
+// reasons a bid is rejected, either by the sentry or by the validator
+const (
+	rejectDraining           = "draining"
+	rejectStandby            = "standby"
+	rejectInvalidSignature   = "invalid_signature"
+	rejectBuilderUnknown     = "builder_not_registered"
+	rejectNotOnChain         = "builder_not_on_chain"
+	rejectInsufficientStake  = "insufficient_stake"
+	rejectCancelled          = "cancelled"
+	rejectBanned             = "banned"
+	rejectDuplicate          = "duplicate_bid"
+	rejectUnauthenticated    = "unauthenticated"
+	rejectIPNotAllowed       = "ip_not_allowed"
+	rejectValidatorNotFound  = "validator_not_found"
+	rejectValidatorNotActive = "validator_not_active"
+	rejectChainNotAllowed    = "chain_not_allowed"
+	rejectNotWhitelisted     = "builder_not_whitelisted"
+	rejectFeeCeiling         = "fee_exceeds_ceiling"
+	rejectBidWindow          = "outside_bid_window"
+	rejectChainID            = "chain_id_mismatch"
+	rejectStaleBid           = "stale_bid"
+	rejectStaleParams        = "stale_params"
+	rejectBidTooLarge        = "bid_too_large"
+	rejectGasPrice           = "gas_price_too_low"
+	rejectGasUsed            = "gas_used_invalid"
+	rejectGasFee             = "gas_fee_invalid"
+	rejectPayBidTx           = "pay_bid_tx_failed"
+	rejectPreflight          = "pay_bid_tx_simulation_failed"
+	rejectInvalidBid         = "invalid_bid"
+	rejectInvalidPayBidTx    = "invalid_pay_bid_tx"
+	rejectMevNotRunning      = "mev_not_running"
+	rejectMevBusy            = "mev_busy"
+	rejectMevNotInTurn       = "mev_not_in_turn"
+	rejectCircuitOpen        = "circuit_open"
+	rejectDeadline           = "deadline_exceeded"
+	rejectUpstream           = "upstream_error"
+)
+
+// rejectionStatsMaxAge limits how old a signed stats query of a builder may be
+const rejectionStatsMaxAge = time.Minute
+
+// upstreamRejectReason maps an error returned by the validator to a rejection reason.
+func upstreamRejectReason(err error) string {
+	if errors.Is(err, node.ErrCircuitOpen) {
+		return rejectCircuitOpen
+	}
+
+	var rpcErr rpc.Error
+	if !errors.As(err, &rpcErr) {
+		return rejectUpstream
+	}
+
+	switch rpcErr.ErrorCode() {
+	case types.InvalidBidParamError:
+		return rejectInvalidBid
+	case types.InvalidPayBidTxError:
+		return rejectInvalidPayBidTx
+	case types.MevNotRunningError:
+		return rejectMevNotRunning
+	case types.MevBusyError:
+		return rejectMevBusy
+	case types.MevNotInTurnError:
+		return rejectMevNotInTurn
+	default:
+		return rejectUpstream
+	}
+}
+
+type rejectionBucket struct {
+	hour   int64
+	counts map[string]uint64
+}
+
+// rejectionTracker counts bid rejection reasons per builder in hourly buckets.
+type rejectionTracker struct {
+	mu      sync.Mutex
+	hours   int
+	buckets map[common.Address][]rejectionBucket // ring of hourly buckets, indexed by hour % hours
+}
+
+func newRejectionTracker(hours int) *rejectionTracker {
+	if hours <= 0 {
+		hours = 24
+	}
+
+	return &rejectionTracker{
+		hours:   hours,
+		buckets: make(map[common.Address][]rejectionBucket),
+	}
+}
+
+func (t *rejectionTracker) record(builder common.Address, reason string, now time.Time) {
+	hour := now.Unix() / 3600
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	ring, ok := t.buckets[builder]
+	if !ok {
+		ring = make([]rejectionBucket, t.hours)
+		t.buckets[builder] = ring
+	}
+
+	bucket := &ring[hour%int64(t.hours)]
+	if bucket.hour != hour || bucket.counts == nil {
+		bucket.hour = hour
+		bucket.counts = make(map[string]uint64)
+	}
+
+	bucket.counts[reason]++
+}
+
+// summary sums up the rejection reasons of the builder over the last hours.
+func (t *rejectionTracker) summary(builder common.Address, hours int, now time.Time) map[string]uint64 {
+	if hours <= 0 || hours > t.hours {
+		hours = t.hours
+	}
+
+	oldest := now.Unix()/3600 - int64(hours) + 1
+	result := make(map[string]uint64)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, bucket := range t.buckets[builder] {
+		if bucket.hour < oldest {
+			continue
+		}
+
+		for reason, count := range bucket.counts {
+			result[reason] += count
+		}
+	}
+
+	return result
+}
+
+// BidRejectionsArgs is the signed query of a builder for its own bid rejections.
+type BidRejectionsArgs struct {
+	// Hours of history to summarize
+	Hours int `json:"hours"`
+	// Timestamp unix seconds when the query was signed
+	Timestamp int64 `json:"timestamp"`
+	// Signature of BidRejectionsHash by the builder key
+	Signature hexutil.Bytes `json:"signature"`
+}
+
+// BidRejectionsHash returns the hash a builder signs to query its bid rejections.
+func BidRejectionsHash(hours int, timestamp int64) common.Hash {
+	return crypto.Keccak256Hash([]byte(fmt.Sprintf("mev_bidRejections:%d:%d", hours, timestamp)))
+}
+
+// BidRejections returns the rejection reason counts of the signing builder over the last hours.
+func (s *MevSentry) BidRejections(_ context.Context, args BidRejectionsArgs) (map[string]uint64, error) {
+	builder, err := s.verifyBuilderQuery(BidRejectionsHash(args.Hours, args.Timestamp), args.Timestamp, args.Signature)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.rejections.summary(builder, args.Hours, time.Now()), nil
+}
+
+// verifyBuilderQuery recovers the registered builder signing the query hash, the query must be signed recently.
+func (s *MevSentry) verifyBuilderQuery(hash common.Hash, timestamp int64, signature hexutil.Bytes) (common.Address, error) {
+	builder, err := recoverSigner(hash, timestamp, signature)
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	if _, ok := s.builder(builder); !ok {
+		return common.Address{}, newSentryError("builder not registered")
+	}
+
+	return builder, nil
+}
+
+// recoverSigner recovers the address signing the hash, which must be signed recently.
+func recoverSigner(hash common.Hash, timestamp int64, signature hexutil.Bytes) (common.Address, error) {
+	signedAt := time.Unix(timestamp, 0)
+	if time.Since(signedAt).Abs() > rejectionStatsMaxAge {
+		return common.Address{}, newSentryError("signature expired")
+	}
+
+	pk, err := crypto.SigToPub(hash.Bytes(), signature)
+	if err != nil {
+		return common.Address{}, newSentryError(fmt.Sprintf("invalid signature:%v", err))
+	}
+
+	return crypto.PubkeyToAddress(*pk), nil
+}
@@ -0,0 +1,24 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRejectionTracker(t *testing.T) {
+	tracker := newRejectionTracker(3)
+	builder := common.HexToAddress("0x01")
+	now := time.Unix(1700000000, 0)
+
+	tracker.record(builder, rejectFeeCeiling, now.Add(-5*time.Hour))
+	tracker.record(builder, rejectFeeCeiling, now.Add(-time.Hour))
+	tracker.record(builder, rejectFeeCeiling, now)
+	tracker.record(builder, rejectMevNotInTurn, now)
+
+	assert.Equal(t, map[string]uint64{rejectFeeCeiling: 1, rejectMevNotInTurn: 1}, tracker.summary(builder, 1, now))
+	assert.Equal(t, map[string]uint64{rejectFeeCeiling: 2, rejectMevNotInTurn: 1}, tracker.summary(builder, 0, now))
+	assert.Empty(t, tracker.summary(common.HexToAddress("0x02"), 0, now))
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/bnb-chain/bsc-mev-sentry/log"
+	"github.com/bnb-chain/bsc-mev-sentry/node"
+)
+
+// checkReplayWindow rejects the bid if its parent block is more than the allowed number of blocks behind the
+// validator's latest block, or is the latest block by number but not by hash, e.g. a reorged one, so replayed bids
+// don't reach the validator. The latest block is refreshed periodically, so a bid on the block after it, which the
+// sentry hasn't seen yet, is tolerated.
+func checkReplayWindow(hostname string, validator node.Validator, bid *types.RawBid) error {
+	window := validator.Config().ReplayWindow
+	if !window.Enabled {
+		return nil
+	}
+
+	head := validator.Head()
+	if head == nil {
+		return nil
+	}
+
+	if bid.BlockNumber == 0 {
+		return types.NewInvalidBidError("block number should not be 0")
+	}
+
+	parent := bid.BlockNumber - 1
+	if parent < head.Number && head.Number-parent > window.MaxParentAge {
+		log.Errorw("bid on a stale parent block", "validator", hostname, "block", bid.BlockNumber,
+			"head", head.Number, "maxParentAge", window.MaxParentAge)
+
+		return &sentryError{
+			error: fmt.Errorf("parent block %d is %d blocks behind the latest block %d, at most %d allowed",
+				parent, head.Number-parent, head.Number, window.MaxParentAge),
+			code: staleBidErrorCode,
+		}
+	}
+
+	if next := head.Number + 1; bid.BlockNumber > next+1 {
+		log.Errorw("bid on a future block", "validator", hostname, "block", bid.BlockNumber, "head", head.Number)
+
+		return &sentryError{
+			error: fmt.Errorf("block %d is not the next expected height %d", bid.BlockNumber, next),
+			code:  staleBidErrorCode,
+		}
+	}
+
+	if parent == head.Number && bid.ParentHash != head.Hash {
+		log.Errorw("bid not on the latest block", "validator", hostname, "block", bid.BlockNumber,
+			"parentHash", bid.ParentHash, "head", head.Hash)
+
+		return &sentryError{
+			error: fmt.Errorf("parent hash %s is not the latest block %s", bid.ParentHash, head.Hash),
+			code:  staleBidErrorCode,
+		}
+	}
+
+	return nil
+}
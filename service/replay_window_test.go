@@ -0,0 +1,49 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bnb-chain/bsc-mev-sentry/node"
+)
+
+func TestCheckReplayWindow(t *testing.T) {
+	head := &node.ChainHead{Hash: common.HexToHash("0x01"), Number: 100}
+	validator := &stubValidator{head: head}
+
+	for maxParentAge, blocks := range map[uint64]map[uint64]bool{
+		0: {0: false, 99: false, 100: false, 101: true, 102: true, 103: false},
+		1: {0: false, 99: false, 100: true, 101: true, 102: true, 103: false},
+		2: {98: false, 99: true, 100: true, 101: true, 102: true, 103: false},
+	} {
+		validator.cfg.ReplayWindow = node.ReplayWindowConfig{Enabled: true, MaxParentAge: maxParentAge}
+
+		for block, ok := range blocks {
+			err := checkReplayWindow("bsc-fuji", validator, &types.RawBid{BlockNumber: block, ParentHash: head.Hash})
+			if ok {
+				assert.NoError(t, err, "maxParentAge %d block %d", maxParentAge, block)
+				continue
+			}
+
+			if assert.Error(t, err, "maxParentAge %d block %d", maxParentAge, block) && block > 0 {
+				assert.Equal(t, staleBidErrorCode, err.(*sentryError).ErrorCode())
+			}
+		}
+	}
+
+	// the next block on a parent other than the latest block, e.g. a reorged one
+	err := checkReplayWindow("bsc-fuji", validator, &types.RawBid{BlockNumber: 101, ParentHash: common.HexToHash("0x02")})
+	if assert.Error(t, err) {
+		assert.Equal(t, staleBidErrorCode, err.(*sentryError).ErrorCode())
+	}
+
+	// the hash of a parent other than the latest block isn't known to the sentry
+	assert.NoError(t, checkReplayWindow("bsc-fuji", validator,
+		&types.RawBid{BlockNumber: 100, ParentHash: common.HexToHash("0x02")}))
+
+	validator.cfg.ReplayWindow.Enabled = false
+	assert.NoError(t, checkReplayWindow("bsc-fuji", validator, &types.RawBid{BlockNumber: 1}))
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/bnb-chain/bsc-mev-sentry/log"
+	"github.com/bnb-chain/bsc-mev-sentry/routing"
+)
+
+// targetKey is the context key of the validator a request is routed to, whatever the request says.
+type targetKey struct{}
+
+// WithTarget routes the requests of the context to the validator, e.g. the fanned out bids or the REST queries
+// naming their validator in the path.
+func WithTarget(ctx context.Context, hostname string) context.Context {
+	return context.WithValue(ctx, targetKey{}, hostname)
+}
+
+// route returns the public hostname of the validator the request is routed to, i.e. the value of the first
+// source naming a validator, or else the first value given for the not found error. Requests with a target go to
+// their target validator.
+func (s *MevSentry) route(ctx context.Context) string {
+	if target, ok := ctx.Value(targetKey{}).(string); ok {
+		return target
+	}
+
+	var first string
+	for _, source := range s.routing.Sources() {
+		var hostname string
+		switch source {
+		case routing.Header:
+			hostname = routing.HeaderFromContext(ctx)
+		case routing.SNI:
+			hostname = routing.SNIFromContext(ctx)
+		case routing.Host:
+			hostname = rpc.PeerInfoFromContext(ctx).HTTP.Host
+			if strings.Contains(hostname, ":") {
+				hostname = hostname[:strings.Index(hostname, ":")]
+			}
+		}
+
+		if hostname == "" {
+			continue
+		}
+
+		if hostname == s.routing.ProposerHostName {
+			if proposer, ok := s.inTurnValidator(); ok {
+				return proposer
+			}
+		}
+
+		if _, ok := s.validator(hostname); ok {
+			return hostname
+		}
+
+		if first == "" {
+			first = hostname
+		}
+	}
+
+	return first
+}
+
+// inTurnValidator returns the public hostname of the validator proposing the next block, if it's connected.
+func (s *MevSentry) inTurnValidator() (string, bool) {
+	next, ok := s.proposers.Next()
+	if !ok {
+		return "", false
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for hostname, validator := range s.validators {
+		if validator.Config().ConsensusAddress == next.Address {
+			return hostname, true
+		}
+	}
+
+	log.Debugw("in turn proposer not connected", "proposer", next.Address, "block", next.Block)
+
+	return "", false
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/bsc-mev-sentry/node"
+	"github.com/bnb-chain/bsc-mev-sentry/routing"
+)
+
+// routeProbe answers the validator a request is routed to.
+type routeProbe struct {
+	sentry *MevSentry
+}
+
+func (p *routeProbe) Route(ctx context.Context) string {
+	return p.sentry.route(ctx)
+}
+
+func TestRoute(t *testing.T) {
+	s := &MevSentry{validators: map[string]node.Validator{
+		"127.0.0.1": &stubValidator{},
+		"target":    &stubValidator{},
+	}}
+
+	server := rpc.NewServer()
+	require.NoError(t, server.RegisterName("test", &routeProbe{sentry: s}))
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if value := r.Header.Get("X-Target-Validator"); value != "" {
+			r = r.WithContext(routing.WithHeader(r.Context(), value))
+		}
+		// stands in for the server name of a tls handshake
+		if value := r.Header.Get("X-Test-SNI"); value != "" {
+			r = r.WithContext(routing.WithSNI(r.Context(), value))
+		}
+		server.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	route := func(cfg routing.Config, header string, sni ...string) string {
+		s.routing = cfg
+
+		cli, err := rpc.Dial(srv.URL)
+		require.NoError(t, err)
+		defer cli.Close()
+
+		if header != "" {
+			cli.SetHeader("X-Target-Validator", header)
+		}
+		if len(sni) > 0 {
+			cli.SetHeader("X-Test-SNI", sni[0])
+		}
+
+		var hostname string
+		require.NoError(t, cli.Call(&hostname, "test_route"))
+		return hostname
+	}
+
+	headerFirst := routing.Config{Header: "X-Target-Validator"}
+	hostFirst := routing.Config{Header: "X-Target-Validator", Order: []string{routing.Host, routing.Header}}
+
+	assert.Equal(t, "127.0.0.1", route(routing.Config{}, "target"), "header routing disabled")
+	assert.Equal(t, "target", route(headerFirst, "target"))
+	assert.Equal(t, "127.0.0.1", route(headerFirst, "unknown"), "falls back to the host")
+	assert.Equal(t, "127.0.0.1", route(headerFirst, ""))
+	assert.Equal(t, "127.0.0.1", route(hostFirst, "target"))
+
+	sniFirst := routing.Config{Order: []string{routing.SNI, routing.Host}}
+	assert.Equal(t, "target", route(sniFirst, "", "target"))
+	assert.Equal(t, "127.0.0.1", route(sniFirst, "", "unknown"), "falls back to the host")
+	assert.Equal(t, "127.0.0.1", route(sniFirst, ""))
+
+	delete(s.validators, "127.0.0.1")
+	assert.Equal(t, "target", route(hostFirst, "target"), "falls back to the header")
+	assert.Equal(t, "unknown", route(headerFirst, "unknown"), "the first value is named if none is found")
+}
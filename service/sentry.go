@@ -5,34 +5,194 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"net/http"
 	"strconv"
-	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/rpc"
-	"github.com/tredeske/u/ustrings"
 
+	"github.com/bnb-chain/bsc-mev-sentry/archive"
+	"github.com/bnb-chain/bsc-mev-sentry/auth"
+	"github.com/bnb-chain/bsc-mev-sentry/cluster"
+	"github.com/bnb-chain/bsc-mev-sentry/events"
+	"github.com/bnb-chain/bsc-mev-sentry/hints"
 	"github.com/bnb-chain/bsc-mev-sentry/log"
 	"github.com/bnb-chain/bsc-mev-sentry/metrics"
 	"github.com/bnb-chain/bsc-mev-sentry/node"
+	"github.com/bnb-chain/bsc-mev-sentry/routing"
+	"github.com/bnb-chain/bsc-mev-sentry/store"
+	"github.com/bnb-chain/bsc-mev-sentry/utils"
+	"github.com/bnb-chain/bsc-mev-sentry/version"
 )
 
+// DefaultMaxBodySize is the body limit of the validator rpc, larger bids can't be forwarded anyway.
+const DefaultMaxBodySize = 5 * 1024 * 1024
+
 type Config struct {
 	// HTTPListenAddr define the address sentry service listen on
 	HTTPListenAddr string
+	// TLSCertFile certificate file of the service listener, serve https if set along with TLSKeyFile
+	TLSCertFile string
+	// TLSKeyFile private key file of the service listener
+	TLSKeyFile string
+	// TLSClientCAFile CA bundle verifying client certificates, bids must come with a builder's certificate if set
+	TLSClientCAFile string
+	// JWT bearer token authentication of requests
+	JWT auth.JWTConfig
+	// RequireSignature requires every bid to come with a signature of the request body by its builder key
+	RequireSignature bool
+	// TrustedProxies ip ranges of the load balancers or proxies in front of the sentry, the sender of the requests from
+	// them is taken from their X-Forwarded-For header
+	TrustedProxies []string
+	// RequireAPIKey requires every bid to come with an api key of its builder, otherwise only builders with api keys
+	RequireAPIKey bool
+	// MaxBodySize limits the request body size in bytes, defaults to DefaultMaxBodySize
+	MaxBodySize int64
 	// RPCConcurrency limits simultaneous requests
 	RPCConcurrency int64
-	// RPCTimeout rpc request timeout
-	RPCTimeout Duration
+	// RPCQueueSize number of requests waiting beyond RPCConcurrency, the others are rejected with 429
+	RPCQueueSize int64
+	// RPCQueueTimeout how long a request waits in the queue before rejected with 429
+	RPCQueueTimeout utils.Duration
+	// RPCTimeout rpc request timeout of the methods without their own in RPCTimeouts
+	RPCTimeout utils.Duration
+	// RPCTimeouts method name -> request timeout of the method, e.g. a tight one for mev_sendBid
+	RPCTimeouts map[string]utils.Duration
+	// DeadlineMargin time left for answering before the deadline of a request, upstream calls get the rest
+	DeadlineMargin utils.Duration
+	// AdminListenAddr define the address admin service listen on, admin service is disabled if empty
+	AdminListenAddr string
+	// AdminToken bearer token required by admin service, no auth if empty
+	AdminToken string
+	// RejectionStatsHours hours of bid rejection history kept per builder
+	RejectionStatsHours int
+	// ArrivalHeatmapBlocks blocks of bid arrival history kept per validator
+	ArrivalHeatmapBlocks int
+	// WinRateBlocks blocks proposed by each validator kept to attribute to their winning builders, disabled if 0
+	WinRateBlocks int
+	// BestBidGasFeeCacheTTL how long the best bid gas fee of a parent block is cached per validator, disabled if 0
+	BestBidGasFeeCacheTTL utils.Duration
+	// DryRun runs every check of bids and signs their pay bid txs, but reports the outcome to the builder instead of
+	// forwarding them, for all validators
+	DryRun bool
+	// AlternateSentry url of an alternate sentry named in errors while draining
+	AlternateSentry string
+	// PaymentStorePath directory of the bid to pay bid tx mapping store, disabled if empty
+	PaymentStorePath string
+	// AuditLogPath file of the append-only, hash chained log of the signed pay bid txs, disabled if empty
+	AuditLogPath string
+	// APIKeyStorePath file the api keys added via the admin API are persisted to, they are lost on reload or restart
+	// if empty
+	APIKeyStorePath string
+	// CustomMetrics operator defined metrics over bid attributes
+	CustomMetrics []CustomMetricConfig
+	// AutoBan bans builders temporarily once their offenses exceed the thresholds
+	AutoBan AutoBanConfig
+	// Failover pairs the sentry with a peer sentry as primary and backup
+	Failover FailoverConfig
+	// EncryptionKeyFiles validator public hostname -> file of the key encrypting its persisted data
+	EncryptionKeyFiles map[string]string
+	// BidStore persists every bid of registered builders to SQLite or Postgres, disabled if Driver is empty
+	BidStore store.BidStoreConfig
+	// Archive periodically exports the stored bids to object storage, requires BidStore, disabled if Backend is empty
+	Archive archive.Config
+	// ValidatorRegistration lets validators register themselves at runtime, signed by their operator key
+	ValidatorRegistration ValidatorRegistrationConfig
+	// ValidatorSet reads the active validators from the validator set contract on chain
+	ValidatorSet node.ValidatorSetConfig
+	// BuilderRegistry cross-checks bid senders against the builders registered in a contract on chain
+	BuilderRegistry node.BuilderRegistryConfig
+	// BuilderStake requires the on chain stake, or balance, of builders to reach a minimum
+	BuilderStake node.BuilderStakeConfig
+	// Events publishes bid events of registered builders to NATS or a Redis stream, disabled if Backend is empty
+	Events events.Config
+	// DecisionLog sampled log of the admission check outcomes and timings of bids
+	DecisionLog DecisionLogConfig
+	// Routing how requests are routed to validators, by the host they're sent to by default
+	Routing routing.Config
+	// FanOut forwards a bid sent via mev_sendBidFanOut to several validators
+	FanOut FanOutConfig
+	// Hints publishes privacy preserving hints of pending bids for searchers, disabled if Backend is empty
+	Hints hints.Config
+	// BundleAdapter serves Flashbots style eth_sendBundle and eth_callBundle, translating bundles into bids
+	BundleAdapter bool
+	// RESTAPI serves the read-only REST API under /v1, for dashboards and scripts not speaking JSON-RPC
+	RESTAPI bool
+	// BuilderWatchInterval how often the config file is polled for changes, the builders are reloaded once it's
+	// changed, disabled if 0
+	BuilderWatchInterval utils.Duration
+	// Chains groups the validators by network to serve several networks in one process, validators join a chain
+	// with their Chain
+	Chains []ChainConfig
+	// Cluster shares the pay account nonces, the bids sent and the builder bans with the other replicas behind the
+	// same load balancer, disabled if Backend is empty
+	Cluster cluster.Config
+	// Election elects one of the replicas as the leader signing pay bid txs, the others are standbys, disabled if
+	// Backend is empty
+	Election cluster.ElectionConfig
 }
 
 type MevSentry struct {
-	timeout Duration
+	timeout  utils.Duration
+	timeouts map[string]utils.Duration // method -> timeout
+	margin   time.Duration
 
+	topologyMu sync.Mutex // serializes changes of validators and builders
+	mu         sync.RWMutex
 	validators map[string]node.Validator       // hostname -> validator
 	builders   map[common.Address]node.Builder // address -> builder
+
+	rejections *rejectionTracker
+	arrivals   *arrivalHeatmap
+	winRates   *winRates
+	recentBids *recentBids
+	hasBuilder *hasBuilderCache
+	bans       *banList
+	cluster    *clusterState
+
+	bestBidGasFees *bestBidGasFeeCache
+
+	requireClientCert bool
+	requireSignature  bool
+	requireAPIKey     bool
+	behindProxy       bool
+	validatorHosts    hostResolver // the ips of the private urls of validators, issues are sent from
+	ipMismatches      reportThrottle
+
+	draining        atomic.Bool
+	alternateSentry string
+
+	dryRun           bool
+	dryRunValidators atomic.Bool // any validator has DryRun, so that bids are reported once routed to it
+
+	payments  *store.PaymentStore
+	audit     *store.AuditLog
+	apiKeys   *store.APIKeyStore
+	bidStore  *store.BidStore
+	archive   *archive.Exporter
+	outcomes  *outcomeTracker
+	failover  *failover
+	elector   *cluster.Elector
+	events    *events.Bus
+	hints     *hints.Publisher
+	registry  *node.BuilderRegistry
+	stakes    *node.StakeChecker
+	proposers *node.ProposerSchedule
+
+	validatorSet *node.ValidatorSet
+	chains       map[string]*chain // name -> chain
+
+	registrations *registrations
+	routing       routing.Config
+	fanOut        FanOutConfig
+
+	customMetrics []*customMetric
+	decisions     *decisionLog
 }
 
 func NewMevSentry(cfg *Config,
@@ -41,18 +201,196 @@ func NewMevSentry(cfg *Config,
 ) *MevSentry {
 	s := &MevSentry{
 		timeout:    cfg.RPCTimeout,
+		timeouts:   cfg.RPCTimeouts,
+		margin:     time.Duration(cfg.DeadlineMargin),
 		validators: validators,
 		builders:   builders,
+		rejections: newRejectionTracker(cfg.RejectionStatsHours),
+		arrivals:   newArrivalHeatmap(cfg.ArrivalHeatmapBlocks),
+		winRates:   newWinRates(cfg.WinRateBlocks),
+		recentBids: newRecentBids(recentBidsCapacity),
+		hasBuilder: newHasBuilderCache(),
+
+		requireClientCert: cfg.TLSClientCAFile != "",
+		requireSignature:  cfg.RequireSignature,
+		requireAPIKey:     cfg.RequireAPIKey,
+		behindProxy:       len(cfg.TrustedProxies) > 0,
+
+		alternateSentry: cfg.AlternateSentry,
+		dryRun:          cfg.DryRun,
+		routing:         cfg.Routing,
+		fanOut:          cfg.FanOut,
+	}
+	s.bestBidGasFees = newBestBidGasFeeCache(time.Duration(cfg.BestBidGasFeeCacheTTL),
+		time.Duration(s.timeoutOf("mev_bestBidGasFee")))
+	for _, validator := range validators {
+		if validator.Config().DryRun {
+			s.dryRunValidators.Store(true)
+		}
+	}
+
+	keyring, err := store.LoadKeyring(cfg.EncryptionKeyFiles)
+	if err != nil {
+		log.Panicw("failed to load encryption keys", "err", err)
+	}
+
+	if s.cluster, err = newClusterState(cfg.Cluster); err != nil {
+		log.Panicw("failed to connect cluster store", "backend", cfg.Cluster.Backend, "err", err)
+	}
+	s.bans = newBanList(cfg.AutoBan, s.cluster.shared())
+
+	if s.registrations, err = newRegistrations(cfg.ValidatorRegistration); err != nil {
+		log.Panicw("failed to open validator registrations", "path", cfg.ValidatorRegistration.Path, "err", err)
+	}
+	s.startRegisteredValidators()
+
+	if cfg.PaymentStorePath != "" {
+		payments, err := store.OpenPaymentStore(cfg.PaymentStorePath, keyring)
+		if err != nil {
+			log.Panicw("failed to open payment store", "path", cfg.PaymentStorePath, "err", err)
+		}
+		s.payments = payments
+	}
+
+	if cfg.APIKeyStorePath != "" {
+		if s.apiKeys, err = store.OpenAPIKeyStore(cfg.APIKeyStorePath); err != nil {
+			log.Panicw("failed to open api key store", "path", cfg.APIKeyStorePath, "err", err)
+		}
+
+		cfgs := make([]node.BuilderConfig, 0, len(builders))
+		for _, builder := range builders {
+			cfgs = append(cfgs, builder.Config())
+		}
+		if s.builders, err = s.newBuilders(builders, cfgs); err != nil {
+			log.Panicw("failed to add stored api keys to builders", "err", err)
+		}
+		stopReplacedBuilders(builders, s.builders)
+	}
+
+	if cfg.AuditLogPath != "" {
+		if s.audit, err = store.OpenAuditLog(cfg.AuditLogPath); err != nil {
+			log.Panicw("failed to open audit log", "path", cfg.AuditLogPath, "err", err)
+		}
+	}
+
+	if cfg.BidStore.Driver != "" {
+		if s.bidStore, err = store.OpenBidStore(cfg.BidStore, keyring); err != nil {
+			log.Panicw("failed to open bid store", "driver", cfg.BidStore.Driver, "err", err)
+		}
+		if s.archive, err = archive.NewExporter(cfg.Archive, s.bidStore); err != nil {
+			log.Panicw("failed to create bid archive", "backend", cfg.Archive.Backend, "err", err)
+		}
+	}
+
+	if s.bidStore != nil || s.winRates != nil {
+		s.outcomes = newOutcomeTracker(s)
+	}
+
+	if s.registry, err = node.NewBuilderRegistry(cfg.BuilderRegistry); err != nil {
+		log.Panicw("failed to create builder registry", "rpc", cfg.BuilderRegistry.ChainRPC, "err", err)
+	}
+
+	if s.proposers, err = node.NewProposerSchedule(cfg.Routing.ProposerSchedule); err != nil {
+		log.Panicw("failed to create proposer schedule", "rpc", cfg.Routing.ProposerSchedule.ChainRPC, "err", err)
+	}
+
+	if err = s.startChains(cfg.Chains); err != nil {
+		log.Panicw("failed to create chains", "err", err)
+	}
+
+	if s.validatorSet, err = node.NewValidatorSet(cfg.ValidatorSet, func() map[common.Address]string {
+		return s.consensusAddresses("")
+	}); err != nil {
+		log.Panicw("failed to create validator set", "rpc", cfg.ValidatorSet.ChainRPC, "err", err)
+	}
+
+	if s.stakes, err = node.NewStakeChecker(cfg.BuilderStake, s.builderAddresses); err != nil {
+		log.Panicw("failed to create builder stake checker", "rpc", cfg.BuilderStake.ChainRPC, "err", err)
+	}
+
+	if s.events, err = events.NewBus(cfg.Events); err != nil {
+		log.Panicw("failed to connect event publisher", "backend", cfg.Events.Backend, "err", err)
+	}
+
+	if s.hints, err = hints.New(cfg.Hints); err != nil {
+		log.Panicw("failed to create hint publisher", "backend", cfg.Hints.Backend, "err", err)
+	}
+
+	s.failover = newFailover(cfg.Failover, s)
+
+	if s.elector, err = cluster.NewElector(cfg.Election, s.onLeaderChange); err != nil {
+		log.Panicw("failed to create leader elector", "backend", cfg.Election.Backend, "err", err)
+	}
+
+	if s.customMetrics, err = newCustomMetrics(cfg.CustomMetrics); err != nil {
+		log.Panicw("failed to create custom metrics", "err", err)
+	}
+
+	if s.decisions, err = newDecisionLog(cfg.DecisionLog); err != nil {
+		log.Panicw("failed to open decision log", "path", cfg.DecisionLog.Path, "err", err)
 	}
 
 	return s
 }
 
+// StopValidators stops the background refresh of all validators, and delivers the batched issues of the builders.
+func (s *MevSentry) StopValidators() {
+	s.topologyMu.Lock()
+	defer s.topologyMu.Unlock()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, validator := range s.validators {
+		validator.Stop()
+	}
+
+	for _, builder := range s.builders {
+		builder.Stop()
+	}
+}
+
+// Close releases the storage held by the sentry.
+func (s *MevSentry) Close() {
+	s.decisions.close()
+
+	s.events.Close()
+	s.hints.Close()
+	s.closeCluster()
+
+	s.registry.Close()
+
+	s.stakes.Close()
+	s.proposers.Close()
+	s.validatorSet.Close()
+	s.closeChains()
+
+	s.outcomes.close()
+
+	if s.bidStore != nil {
+		if err := s.bidStore.Close(); err != nil {
+			log.Errorw("failed to close bid store", "err", err)
+		}
+	}
+
+	if s.payments != nil {
+		if err := s.payments.Close(); err != nil {
+			log.Errorw("failed to close payment store", "err", err)
+		}
+	}
+
+	if s.audit != nil {
+		if err := s.audit.Close(); err != nil {
+			log.Errorw("failed to close audit log", "err", err)
+		}
+	}
+}
+
 func (s *MevSentry) SendBid(ctx context.Context, args types.BidArgs) (bidHash common.Hash, err error) {
 	method := "mev_sendBid"
 	start := time.Now()
-	defer recordLatency(method, start)
-	defer timeoutCancel(&ctx, s.timeout)()
+	defer recordLatency(method, servedFromUpstream, start)
+	defer s.budget(&ctx, method)()
 	defer func() {
 		if err != nil {
 			if rpcErr, ok := err.(rpc.Error); ok {
@@ -61,57 +399,342 @@ func (s *MevSentry) SendBid(ctx context.Context, args types.BidArgs) (bidHash co
 		}
 	}()
 
-	hostname := rpc.PeerInfoFromContext(ctx).HTTP.Host
-	if strings.Contains(hostname, ":") {
-		hostname = hostname[:strings.Index(hostname, ":")]
+	metrics.BidLifecycleCounter.WithLabelValues("received").Inc()
+
+	var (
+		builder    common.Address
+		registered bool
+		hostname   string
+		reason     string
+		decision   = s.decisions.sample()
+		sampled    = decision != nil
+		dryRun     = s.dryRun
+	)
+	// bids in dry run are reported whether sampled or not
+	if decision == nil && (dryRun || s.dryRunValidators.Load()) {
+		decision = &Decision{Time: time.Now()}
 	}
+	defer func() {
+		if decision != nil {
+			decision.Builder, decision.Validator, decision.BidHash = builder, hostname, bidHash
+			decision.Result, decision.Error = decisionResult(reason, err), errString(err)
+			if args.RawBid != nil {
+				decision.Block = args.RawBid.BlockNumber
+			}
+			if sampled {
+				s.decisions.emit(decision)
+			}
+		}
 
-	validator, ok := s.validators[hostname]
-	if !ok {
-		log.Errorw("validator not found", "hostname", hostname)
-		err = types.NewInvalidBidError("validator hostname not found")
-		return
-	}
+		if reason != "" {
+			metrics.BidRejectedCounter.WithLabelValues(reason).Inc()
+		}
 
-	bidFeeCeil := validator.BuilderFeeCeil()
+		// the bid is reported to the builder rather than tracked, it's never forwarded
+		if dryRun {
+			err = newDryRunError(decision, sampled, err, args.PayBidTx)
+			return
+		}
 
-	if args.RawBid.BuilderFee != nil && bidFeeCeil != nil {
-		if args.RawBid.BuilderFee.Cmp(bidFeeCeil) > 0 {
-			log.Errorw("bid fee exceeds the ceiling", "fee", args.RawBid.BuilderFee, "ceiling", bidFeeCeil.Uint64())
-			err = types.NewInvalidBidError(fmt.Sprintf("bid fee exceeds the ceiling %v", bidFeeCeil))
+		// only registered builders are tracked, anyone can send bids of arbitrary addresses
+		if !registered {
 			return
 		}
+
+		s.storeBid(builder, hostname, args, start, reason, err)
+		s.publishBid(builder, hostname, args.RawBid, bidHash, start, reason, err)
+
+		if reason != "" {
+			if reason != rejectDuplicate || !s.bans.retry(builder, args.RawBid.Hash(), time.Now()) {
+				s.bans.record(builder, offense(reason), time.Now())
+			}
+			s.rejections.record(builder, reason, time.Now())
+			metrics.BidCounter.WithLabelValues(hostname, builder.String(), reason).Inc()
+		} else if err == nil {
+			metrics.BidCounter.WithLabelValues(hostname, builder.String(), "accepted").Inc()
+			s.hints.Publish(builder, args.RawBid, bidHash)
+		}
+	}()
+
+	if s.Draining() {
+		err = s.drainingError()
+		reason = rejectDraining
+		return
+	}
+
+	if s.Standby() {
+		err = s.standbyError()
+		reason = rejectStandby
+		return
 	}
 
-	builder, err := args.EcrecoverSender()
+	if args.RawBid == nil {
+		err = types.NewInvalidBidError("rawBid should not be nil")
+		reason = rejectInvalidBid
+		return
+	}
+
+	err = decision.run("signature", func() (err error) {
+		builder, err = args.EcrecoverSender()
+		return err
+	})
 	if err != nil {
 		log.Errorw("failed to parse bid signature", "err", err)
 		err = types.NewInvalidBidError(fmt.Sprintf("invalid signature:%v", err))
+		reason = rejectInvalidSignature
 		return
-	} else if _, ok = s.builders[builder]; !ok {
-		log.Errorw("builder not registered", "address", builder)
-		err = types.NewInvalidBidError("builder not registered")
+	}
+
+	var b node.Builder
+	if err = decision.run("builder", func() error {
+		var ok bool
+		if b, ok = s.builder(builder); !ok {
+			log.Errorw("builder not registered", "address", builder)
+			return types.NewInvalidBidError("builder not registered")
+		}
+		return nil
+	}); err != nil {
+		reason = rejectBuilderUnknown
 		return
 	}
+	registered = true
 
-	payBidTx, err := validator.GeneratePayBidTx(ctx, builder, args.RawBid.BuilderFee)
-	if err != nil {
+	if ban, banned := s.bans.banned(builder, time.Now()); banned {
+		err = newSentryError(fmt.Sprintf("builder is banned until %s for %s", ban.Until.Format(time.RFC3339), ban.Reason))
+		reason = rejectBanned
+		return
+	}
+
+	if err = decision.run("on_chain_registry", func() error { return s.checkOnChainRegistry(builder) }); err != nil {
+		reason = rejectNotOnChain
+		return
+	}
+
+	if err = decision.run("stake", func() error { return s.checkStake(builder) }); err != nil {
+		reason = rejectInsufficientStake
+		return
+	}
+
+	if s.recentBids.cancelled(args.RawBid.Hash()) {
+		err = types.NewInvalidBidError("bid is cancelled")
+		reason = rejectCancelled
+		return
+	}
+
+	if err = decision.run("identity", func() error { return s.checkIdentity(ctx, builder, b) }); err != nil {
+		reason = rejectUnauthenticated
+		return
+	}
+
+	if err = decision.run("api_key", func() error { return s.checkAPIKey(ctx, builder, b) }); err != nil {
+		reason = rejectUnauthenticated
+		return
+	}
+
+	if err = decision.run("source_ip", func() error { return s.checkSourceIP(ctx, builder, b, args.RawBid) }); err != nil {
+		reason = rejectIPNotAllowed
+		return
+	}
+
+	hostname = s.route(ctx)
+
+	var validator node.Validator
+	if err = decision.run("validator", func() error {
+		var ok bool
+		if validator, ok = s.validator(hostname); !ok {
+			log.Errorw("validator not found", "hostname", hostname)
+			return types.NewInvalidBidError("validator hostname not found")
+		}
+		return nil
+	}); err != nil {
+		reason = rejectValidatorNotFound
+		// the host header is up to the client, keep it out of metric labels
+		hostname = ""
+		return
+	}
+
+	if dryRun = dryRun || validator.Config().DryRun; dryRun && decision == nil {
+		// the validator is added in dry run after the bid is received
+		decision = &Decision{Time: start}
+	}
+
+	if err = decision.run("chain", func() error { return checkBuilderChain(hostname, b, validator) }); err != nil {
+		reason = rejectChainNotAllowed
+		return
+	}
+
+	if err = decision.run("validator_set", func() error { return s.checkValidatorSet(hostname, validator) }); err != nil {
+		reason = rejectValidatorNotActive
+		return
+	}
+
+	if err = decision.run("mev_params", func() (err error) {
+		_, err = validator.MevParams(ctx)
+		return err
+	}); err != nil {
+		err = newSentryError(err.Error())
+		reason = rejectStaleParams
+		return
+	}
+
+	if prev := s.recentBids.get(args.RawBid.Hash()); prev != nil && prev.validator == hostname {
+		err = types.NewInvalidBidError("bid is already sent")
+		reason = rejectDuplicate
+		return
+	}
+
+	if err = decision.run("has_builder", func() error {
+		return s.checkHasBuilder(ctx, hostname, builder, validator)
+	}); err != nil {
+		reason = rejectNotWhitelisted
+		return
+	}
+
+	if err = decision.run("bid_window", func() error {
+		return s.checkBidWindow(hostname, builder, validator, args.RawBid)
+	}); err != nil {
+		reason = rejectBidWindow
+		return
+	}
+
+	if err = decision.run("replay_window", func() error {
+		return checkReplayWindow(hostname, validator, args.RawBid)
+	}); err != nil {
+		reason = rejectStaleBid
+		return
+	}
+
+	if err = decision.run("chain_id", func() error { return s.checkChainID(hostname, validator, args.RawBid) }); err != nil {
+		reason = rejectChainID
+		return
+	}
+
+	if err = decision.run("bid_size", func() error { return checkBidSize(hostname, validator, args.RawBid) }); err != nil {
+		reason = rejectBidTooLarge
+		return
+	}
+
+	if err = decision.run("fee_ceiling", func() error { return checkFeeCeiling(validator, args.RawBid) }); err != nil {
+		reason = rejectFeeCeiling
+		return
+	}
+
+	if err = decision.run("gas", func() (err error) {
+		reason, err = checkGas(hostname, validator, args.RawBid)
+		return err
+	}); err != nil {
+		return
+	}
+
+	if err = decision.run("gas_price", func() error { return checkGasPrice(validator, args.RawBid) }); err != nil {
+		reason = rejectGasPrice
+		return
+	}
+
+	// the builder has given up on the bid, its pay bid tx would be wasted
+	if err = decision.run("deadline", func() error { return ctx.Err() }); err != nil {
+		err = newSentryError("request deadline exceeded")
+		reason = rejectDeadline
+		return
+	}
+
+	recordBidStats(hostname, args.RawBid)
+	s.recordCustomMetrics(hostname, builder, args.RawBid)
+
+	var payBidTx hexutil.Bytes
+	// the balance reserved for the pay bid tx is released unless the bid is forwarded
+	defer func() {
+		if err != nil && payBidTx != nil {
+			validator.ReleasePayBidTx(payBidTx)
+		}
+	}()
+	if err = decision.run("pay_bid_tx", func() (err error) {
+		payBidTx, err = validator.GeneratePayBidTx(ctx, builder, args.RawBid.BuilderFee, args.RawBid.BlockNumber)
+		if err != nil {
+			return err
+		}
+		return s.auditPayBidTx(hostname, builder, args.RawBid.Hash(), payBidTx)
+	}); err != nil {
 		log.Errorw("failed to create pay bid tx", "err", err)
+		// the simulation tells the builder why precisely, e.g. its address reverts on the transfer
+		var preflightErr *node.PreflightError
+		if errors.As(err, &preflightErr) {
+			err, reason = newSentryError(preflightErr.Error()), rejectPreflight
+			return
+		}
 		err = newSentryError("failed to create pay bid tx")
+		reason = rejectPayBidTx
 		return
 	}
 
 	args.PayBidTx = payBidTx
 	args.PayBidTxGasUsed = node.PayBidTxGasUsed
 
-	return validator.SendBid(ctx, args)
+	if dryRun {
+		metrics.BidLifecycleCounter.WithLabelValues("dry_run").Inc()
+		err = errDryRun
+		return
+	}
+
+	// the other replicas may have sent the bid, the local check above only knows of the bids sent by this one
+	if err = decision.run("cluster_duplicate", func() error {
+		return s.markBid(ctx, hostname, args.RawBid)
+	}); err != nil {
+		reason = rejectDuplicate
+		return
+	}
+
+	metrics.BidLifecycleCounter.WithLabelValues("forwarded").Inc()
+
+	if err = decision.run("forward", func() (err error) {
+		bidHash, err = validator.SendBid(ctx, args)
+		return err
+	}); err != nil {
+		s.forgetBid(hostname, args.RawBid.Hash())
+		reason = upstreamRejectReason(err)
+		return
+	}
+
+	metrics.BidLifecycleCounter.WithLabelValues("accepted").Inc()
+	s.recentBids.add(bidHash, &recentBid{builder: builder, validator: hostname, block: args.RawBid.BlockNumber})
+	s.recordPayment(hostname, builder, bidHash, payBidTx)
+
+	return
+}
+
+// checkFeeCeiling rejects the bid if its builder fee exceeds the ceiling of the validator.
+func checkFeeCeiling(validator node.Validator, bid *types.RawBid) error {
+	bidFeeCeil := validator.BuilderFeeCeil()
+	if bid.BuilderFee == nil || bidFeeCeil == nil || bid.BuilderFee.Cmp(bidFeeCeil) <= 0 {
+		return nil
+	}
+
+	log.Errorw("bid fee exceeds the ceiling", "fee", bid.BuilderFee, "ceiling", bidFeeCeil)
+	return types.NewInvalidBidError(fmt.Sprintf("bid fee exceeds the ceiling %v", bidFeeCeil))
+}
+
+// checkGasPrice rejects the bid if its average gas price is below the minimum of the validator.
+func checkGasPrice(validator node.Validator, bid *types.RawBid) error {
+	minGasPrice := validator.MinBidGasPrice()
+	if minGasPrice == nil || bid.GasUsed == 0 || bid.GasFee == nil {
+		return nil
+	}
+
+	gasPrice := new(big.Int).Div(bid.GasFee, new(big.Int).SetUint64(bid.GasUsed))
+	if gasPrice.Cmp(minGasPrice) >= 0 {
+		return nil
+	}
+
+	log.Errorw("bid gas price is too low", "gasPrice", gasPrice, "minGasPrice", minGasPrice)
+	return types.NewInvalidBidError(fmt.Sprintf("bid gas price is lower than %v", minGasPrice))
 }
 
 func (s *MevSentry) BestBidGasFee(ctx context.Context, parentHash common.Hash) (fee *big.Int, err error) {
 	method := "mev_bestBidGasFee"
 	start := time.Now()
-	defer recordLatency(method, start)
-	defer timeoutCancel(&ctx, s.timeout)()
+	servedFrom := servedFromUpstream
+	defer func() { recordLatency(method, servedFrom, start) }()
+	defer s.budget(&ctx, method)()
 	defer func() {
 		if err != nil {
 			if rpcErr, ok := err.(rpc.Error); ok {
@@ -120,27 +743,32 @@ func (s *MevSentry) BestBidGasFee(ctx context.Context, parentHash common.Hash) (
 		}
 	}()
 
-	hostname := rpc.PeerInfoFromContext(ctx).HTTP.Host
-	if strings.Contains(hostname, ":") {
-		hostname = hostname[:strings.Index(hostname, ":")]
-	}
+	hostname := s.route(ctx)
 
-	validator, ok := s.validators[hostname]
+	validator, ok := s.validator(hostname)
 	if !ok {
 		log.Errorw("validator not found", "hostname", hostname)
 		err = types.NewInvalidBidError("validator hostname not found")
 		return
 	}
 
-	fee, err = validator.BestBidGasFee(ctx, parentHash)
+	if !validator.Capabilities().BestBidGasFee {
+		err = newSentryError("mev_bestBidGasFee is not supported by the validator")
+		return
+	}
+
+	var cached bool
+	if fee, cached, err = s.bestBidGasFees.get(ctx, hostname, validator, parentHash); cached {
+		servedFrom = servedFromCache
+	}
 	return
 }
 
 func (s *MevSentry) Params(ctx context.Context) (param *types.MevParams, err error) {
 	method := "mev_params"
 	start := time.Now()
-	defer recordLatency(method, start)
-	defer timeoutCancel(&ctx, s.timeout)()
+	defer recordLatency(method, servedFromCache, start)
+	defer s.budget(&ctx, method)()
 	defer func() {
 		if err != nil {
 			if rpcErr, ok := err.(rpc.Error); ok {
@@ -149,27 +777,26 @@ func (s *MevSentry) Params(ctx context.Context) (param *types.MevParams, err err
 		}
 	}()
 
-	hostname := rpc.PeerInfoFromContext(ctx).HTTP.Host
-	if strings.Contains(hostname, ":") {
-		hostname = hostname[:strings.Index(hostname, ":")]
-	}
+	hostname := s.route(ctx)
 
-	validator, ok := s.validators[hostname]
+	validator, ok := s.validator(hostname)
 	if !ok {
 		log.Errorw("validator not found", "hostname", hostname)
 		err = types.NewInvalidBidError("validator hostname not found")
 		return
 	}
 
-	param, err = validator.MevParams(ctx)
+	if param, err = validator.MevParams(ctx); err != nil {
+		err = newSentryError(err.Error())
+	}
 	return
 }
 
 func (s *MevSentry) Running(ctx context.Context) (running bool, err error) {
 	method := "mev_running"
 	start := time.Now()
-	defer recordLatency(method, start)
-	defer timeoutCancel(&ctx, s.timeout)()
+	defer recordLatency(method, servedFromCache, start)
+	defer s.budget(&ctx, method)()
 	defer func() {
 		if err != nil {
 			if rpcErr, ok := err.(rpc.Error); ok {
@@ -178,26 +805,29 @@ func (s *MevSentry) Running(ctx context.Context) (running bool, err error) {
 		}
 	}()
 
-	hostname := rpc.PeerInfoFromContext(ctx).HTTP.Host
-	if strings.Contains(hostname, ":") {
-		hostname = hostname[:strings.Index(hostname, ":")]
-	}
+	hostname := s.route(ctx)
 
-	validator, ok := s.validators[hostname]
+	validator, ok := s.validator(hostname)
 	if !ok {
 		log.Errorw("validator not found", "hostname", hostname)
 		err = types.NewInvalidBidError("validator hostname not found")
 		return
 	}
 
+	// builders shouldn't waste bids if the sentry knows forwarding them will fail
+	if s.Draining() || s.Standby() || !validator.Health().OK() ||
+		(s.validatorSetEnforced(validator) && !s.validatorActive(validator)) {
+		return false, nil
+	}
+
 	return validator.MevRunning(), nil
 }
 
 func (s *MevSentry) HasBuilder(ctx context.Context, builder common.Address) (has bool, err error) {
 	method := "mev_hasBuilder"
 	start := time.Now()
-	defer recordLatency(method, start)
-	defer timeoutCancel(&ctx, s.timeout)()
+	defer recordLatency(method, servedFromUpstream, start)
+	defer s.budget(&ctx, method)()
 	defer func() {
 		if err != nil {
 			if rpcErr, ok := err.(rpc.Error); ok {
@@ -206,26 +836,32 @@ func (s *MevSentry) HasBuilder(ctx context.Context, builder common.Address) (has
 		}
 	}()
 
-	hostname := rpc.PeerInfoFromContext(ctx).HTTP.Host
-	if strings.Contains(hostname, ":") {
-		hostname = hostname[:strings.Index(hostname, ":")]
-	}
+	hostname := s.route(ctx)
 
-	validator, ok := s.validators[hostname]
+	validator, ok := s.validator(hostname)
 	if !ok {
 		log.Errorw("validator not found", "hostname", hostname)
 		err = types.NewInvalidBidError("validator hostname not found")
 		return
 	}
 
+	if !validator.Capabilities().HasBuilder {
+		err = newSentryError("mev_hasBuilder is not supported by the validator")
+		return
+	}
+
 	return validator.HasBuilder(ctx, builder)
 }
 
-func (s *MevSentry) ReportIssue(ctx context.Context, issue types.BidIssue) (err error) {
+// ReportIssue relays the issue of the validator to the builder of the bid, the validator signs the issue with its
+// consensus key along with the timestamp it's signed at, or sends it from one of its private urls.
+func (s *MevSentry) ReportIssue(ctx context.Context, issue types.BidIssue, signature *hexutil.Bytes,
+	timestamp *int64,
+) (err error) {
 	method := "mev_reportIssue"
 	start := time.Now()
-	defer recordLatency(method, start)
-	defer timeoutCancel(&ctx, s.timeout)()
+	defer recordLatency(method, servedFromUpstream, start)
+	defer s.budget(&ctx, method)()
 	defer func() {
 		if err != nil {
 			if rpcErr, ok := err.(rpc.Error); ok {
@@ -234,10 +870,15 @@ func (s *MevSentry) ReportIssue(ctx context.Context, issue types.BidIssue) (err
 		}
 	}()
 
+	if err = s.checkIssue(ctx, issue, signature, timestamp); err != nil {
+		log.Errorw("issue rejected", "issue", issue, "err", err)
+		return
+	}
+
 	var builder node.Builder
 	var ok bool
 
-	builder, ok = s.builders[issue.Builder]
+	builder, ok = s.builder(issue.Builder)
 	if !ok {
 		log.Errorw("builder url not found", "address", issue.Builder, "issue", issue)
 		err = errors.New("builder not found")
@@ -246,35 +887,109 @@ func (s *MevSentry) ReportIssue(ctx context.Context, issue types.BidIssue) (err
 
 	log.Debugw("report issue", "builder", builder, "issue", issue)
 
-	err = builder.ReportIssue(ctx, issue)
+	// a queued issue is recorded before its batch may be delivered, and updated once it is
+	if builder.Config().IssueBatchWindow > 0 {
+		s.storeIssue(issue, start, store.IssueQueued, nil)
+		return builder.ReportIssue(ctx, issue, func(err error) {
+			s.updateIssue(issue, err)
+		})
+	}
+
+	err = builder.ReportIssue(ctx, issue, nil)
+	s.storeIssue(issue, start, issueStatus(err), err)
 	return
 }
 
-func recordLatency(method string, start time.Time) {
-	metrics.ApiLatencyHist.WithLabelValues(method).Observe(float64(time.Since(start).Milliseconds()))
+func (s *MevSentry) Version(_ context.Context) version.Info {
+	method := "mev_version"
+	start := time.Now()
+	defer recordLatency(method, servedFromCache, start)
+
+	return version.Get()
+}
+
+func (s *MevSentry) validator(hostname string) (node.Validator, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	v, ok := s.validators[hostname]
+	return v, ok
+}
+
+func (s *MevSentry) builder(address common.Address) (node.Builder, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	b, ok := s.builders[address]
+	return b, ok
+}
+
+// HintStream returns the path and handler of the websocket stream of hints, false unless hints are streamed.
+func (s *MevSentry) HintStream() (string, http.Handler, bool) {
+	return s.hints.Stream()
+}
+
+// servedFrom tells whether a method is answered from the state cached by the sentry,
+// by calling the validator/builder upstream, or by the sentry alone.
+const (
+	servedFromCache    = "cache"
+	servedFromUpstream = "upstream"
+	servedLocally      = "sentry"
+)
+
+func recordLatency(method, servedFrom string, start time.Time) {
+	metrics.ApiRequestCounter.WithLabelValues(method, servedFrom).Inc()
+	metrics.ApiLatencyHist.WithLabelValues(method, servedFrom).Observe(float64(time.Since(start).Milliseconds()))
 }
 
 func nilCancel() {
 }
 
-func timeoutCancel(ctx *context.Context, timeout Duration) func() {
-	if timeout > 0 {
-		var cancel func()
-		*ctx, cancel = context.WithTimeout(*ctx, time.Duration(timeout))
+// MaxRPCTimeout returns the longest request timeout of the methods.
+func (c *Config) MaxRPCTimeout() utils.Duration {
+	timeout := c.RPCTimeout
+	for _, t := range c.RPCTimeouts {
+		timeout = max(timeout, t)
+	}
+
+	return timeout
+}
+
+// budget bounds the request context by the timeout of the method, and keeps the deadline margin of the request
+// for answering. Upstream calls made with the context end before the builder gives up on the request, as told by
+// its X-Request-Timeout header or by disconnecting.
+func (s *MevSentry) budget(ctx *context.Context, method string) func() {
+	cancel := timeoutCancel(ctx, s.timeoutOf(method))
+
+	deadline, ok := (*ctx).Deadline()
+	if !ok || s.margin <= 0 {
 		return cancel
 	}
 
-	return nilCancel
+	var cancelMargin func()
+	*ctx, cancelMargin = context.WithDeadline(*ctx, deadline.Add(-s.margin))
+
+	return func() {
+		cancelMargin()
+		cancel()
+	}
 }
 
-type Duration time.Duration
+// timeoutOf returns the request timeout of the method.
+func (s *MevSentry) timeoutOf(method string) utils.Duration {
+	if timeout, ok := s.timeouts[method]; ok {
+		return timeout
+	}
 
-func (d Duration) MarshalText() ([]byte, error) {
-	return ustrings.UnsafeStringToBytes(time.Duration(d).String()), nil
+	return s.timeout
 }
 
-func (d *Duration) UnmarshalText(text []byte) error {
-	dd, err := time.ParseDuration(ustrings.UnsafeBytesToString(text))
-	*d = Duration(dd)
-	return err
+func timeoutCancel(ctx *context.Context, timeout utils.Duration) func() {
+	if timeout > 0 {
+		var cancel func()
+		*ctx, cancel = context.WithTimeout(*ctx, time.Duration(timeout))
+		return cancel
+	}
+
+	return nilCancel
 }
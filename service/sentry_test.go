@@ -0,0 +1,32 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bnb-chain/bsc-mev-sentry/utils"
+)
+
+func TestBudget(t *testing.T) {
+	s := &MevSentry{
+		timeout:  utils.Duration(10 * time.Second),
+		timeouts: map[string]utils.Duration{"mev_sendBid": utils.Duration(time.Second)},
+		margin:   100 * time.Millisecond,
+	}
+
+	ctx := context.Background()
+	defer s.budget(&ctx, "mev_sendBid")()
+	deadline, ok := ctx.Deadline()
+	assert.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(900*time.Millisecond), deadline, 50*time.Millisecond)
+
+	// the builder waits shorter than the timeout of the method
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	defer s.budget(&ctx, "mev_params")()
+	deadline, _ = ctx.Deadline()
+	assert.WithinDuration(t, time.Now().Add(200*time.Millisecond), deadline, 50*time.Millisecond)
+}
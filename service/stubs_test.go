@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"math/big"
+	"net"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/bnb-chain/bsc-mev-sentry/node"
+)
+
+// stubValidator serves the cached state read on admission and accepts every bid, other methods aren't expected to be
+// called.
+type stubValidator struct {
+	node.Validator
+	cfg     node.ValidatorConfig
+	head    *node.ChainHead
+	chainID *big.Int
+	params  *types.MevParams
+
+	sent atomic.Uint64
+}
+
+func (v *stubValidator) Config() node.ValidatorConfig { return v.cfg }
+func (v *stubValidator) Head() *node.ChainHead        { return v.head }
+func (v *stubValidator) ChainID() *big.Int            { return v.chainID }
+func (v *stubValidator) BuilderFeeCeil() *big.Int     { return big.NewInt(1e18) }
+func (v *stubValidator) MinBidGasPrice() *big.Int     { return big.NewInt(1) }
+
+func (v *stubValidator) PayAccount() common.Address {
+	return common.HexToAddress(v.cfg.PayAccountAddress)
+}
+
+func (v *stubValidator) MevParams(context.Context) (*types.MevParams, error) {
+	return v.params, nil
+}
+
+func (v *stubValidator) GeneratePayBidTx(context.Context, common.Address, *big.Int, uint64) (hexutil.Bytes, error) {
+	return hexutil.Bytes{0x01}, nil
+}
+
+func (v *stubValidator) ReleasePayBidTx(hexutil.Bytes) {}
+
+// SendBid returns a hash of its own for each bid, so that the bids sent aren't taken for duplicates of each other.
+func (v *stubValidator) SendBid(context.Context, types.BidArgs) (common.Hash, error) {
+	return common.BigToHash(new(big.Int).SetUint64(v.sent.Add(1))), nil
+}
+
+// stubBuilder is a builder accepting bids from any ip.
+type stubBuilder struct {
+	node.Builder
+	cfg node.BuilderConfig
+}
+
+func (b *stubBuilder) Config() node.BuilderConfig { return b.cfg }
+func (b *stubBuilder) AllowsIP(net.IP) bool       { return true }
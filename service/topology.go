@@ -0,0 +1,173 @@
+package service
+
+import (
+	"reflect"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bnb-chain/bsc-mev-sentry/log"
+	"github.com/bnb-chain/bsc-mev-sentry/node"
+	"github.com/bnb-chain/bsc-mev-sentry/store"
+)
+
+// UpdateTopology swaps in the given validators and builders. Only the ones whose config
+// changed are recreated, and nothing is changed if any of them fails to be created. The
+// validators registered by their operators are kept unless configured statically.
+func (s *MevSentry) UpdateTopology(validatorCfgs []node.ValidatorConfig, builderCfgs []node.BuilderConfig) error {
+	s.topologyMu.Lock()
+	defer s.topologyMu.Unlock()
+
+	configured := make(map[string]struct{}, len(validatorCfgs))
+	for _, cfg := range validatorCfgs {
+		configured[cfg.PublicHostName] = struct{}{}
+	}
+	registered := make(map[string]store.ValidatorRegistration)
+	for _, reg := range s.registrations.all() {
+		if _, ok := configured[reg.PublicHostName]; !ok {
+			registered[reg.PublicHostName] = reg
+			validatorCfgs = append(validatorCfgs, registrationConfig(reg))
+		}
+	}
+
+	oldValidators, oldBuilders := s.validators, s.builders
+
+	validators := make(map[string]node.Validator, len(validatorCfgs))
+	var created []node.Validator
+	for _, cfg := range validatorCfgs {
+		if old, ok := oldValidators[cfg.PublicHostName]; ok && reflect.DeepEqual(old.Config(), cfg) {
+			validators[cfg.PublicHostName] = old
+			continue
+		}
+
+		validator, err := s.newValidator(cfg)
+		if err != nil {
+			for _, v := range created {
+				v.Stop()
+			}
+			return err
+		}
+
+		created = append(created, validator)
+		validators[cfg.PublicHostName] = validator
+	}
+
+	// a registration is ignored once a validator configured statically uses its keystore or pay account
+	static := make(map[string]node.Validator, len(validators))
+	for hostname, validator := range validators {
+		if _, ok := registered[hostname]; !ok {
+			static[hostname] = validator
+		}
+	}
+	for hostname, reg := range registered {
+		if err := s.registrations.check(reg, static); err != nil {
+			log.Errorw("ignore the validator registration", "hostname", hostname, "err", err)
+			delete(validators, hostname)
+		}
+	}
+
+	builders, err := s.newBuilders(oldBuilders, builderCfgs)
+	if err != nil {
+		for _, v := range created {
+			v.Stop()
+		}
+		return err
+	}
+
+	s.mu.Lock()
+	s.validators, s.builders = validators, builders
+	s.mu.Unlock()
+
+	for hostname, old := range oldValidators {
+		if validators[hostname] != old {
+			old.Stop()
+		}
+	}
+	for _, validator := range created {
+		if validators[validator.Config().PublicHostName] != validator {
+			validator.Stop()
+		}
+	}
+	stopReplacedBuilders(oldBuilders, builders)
+
+	log.Infow("topology updated", "validator_count", len(validators), "validator_created", len(created),
+		"builder_count", len(builders))
+
+	return nil
+}
+
+// UpdateBuilders swaps in the given builders and keeps the validators, e.g. to onboard a builder without a restart.
+// Only the builders whose config changed are recreated, and nothing is changed if any of them fails to be created.
+func (s *MevSentry) UpdateBuilders(builderCfgs []node.BuilderConfig) error {
+	s.topologyMu.Lock()
+	defer s.topologyMu.Unlock()
+
+	builders, err := s.newBuilders(s.builders, builderCfgs)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	oldBuilders := s.builders
+	s.builders = builders
+	s.mu.Unlock()
+
+	stopReplacedBuilders(oldBuilders, builders)
+
+	log.Infow("builders updated", "builder_count", len(builders))
+
+	return nil
+}
+
+// newBuilders creates the builders of the configs along with the api keys added via the admin API, reusing the old
+// ones whose config is unchanged.
+func (s *MevSentry) newBuilders(oldBuilders map[common.Address]node.Builder, cfgs []node.BuilderConfig,
+) (map[common.Address]node.Builder, error) {
+	builders := make(map[common.Address]node.Builder, len(cfgs))
+	for _, cfg := range cfgs {
+		cfg = s.withStoredAPIKeys(cfg)
+		if old, ok := oldBuilders[cfg.Address]; ok && reflect.DeepEqual(old.Config(), cfg) {
+			builders[cfg.Address] = old
+			continue
+		}
+
+		builder, err := node.NewBuilder(cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		builders[cfg.Address] = builder
+	}
+
+	return builders, nil
+}
+
+// stopReplacedBuilders stops the old builders which are not kept, delivering their batched issues.
+func stopReplacedBuilders(oldBuilders, builders map[common.Address]node.Builder) {
+	for address, old := range oldBuilders {
+		if builders[address] != old {
+			old.Stop()
+		}
+	}
+}
+
+// withStoredAPIKeys adds the api keys added via the admin API to the ones configured for the builder.
+func (s *MevSentry) withStoredAPIKeys(cfg node.BuilderConfig) node.BuilderConfig {
+	if s.apiKeys == nil {
+		return cfg
+	}
+
+	configured := make(map[string]struct{}, len(cfg.APIKeyHashes))
+	for _, hash := range cfg.APIKeyHashes {
+		configured[hash] = struct{}{}
+	}
+
+	hashes := cfg.APIKeyHashes[:len(cfg.APIKeyHashes):len(cfg.APIKeyHashes)]
+	for _, hash := range s.apiKeys.Hashes(cfg.Address) {
+		if _, ok := configured[hash]; !ok {
+			hashes = append(hashes, hash)
+		}
+	}
+	cfg.APIKeyHashes = hashes
+
+	return cfg
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/bnb-chain/bsc-mev-sentry/log"
+	"github.com/bnb-chain/bsc-mev-sentry/node"
+)
+
+// consensusAddresses returns the public hostnames of the validators checked against the validator set of the
+// chain, the one of the service if empty, by their consensus addresses, validators without one are left out.
+func (s *MevSentry) consensusAddresses(chain string) map[common.Address]string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	addresses := make(map[common.Address]string, len(s.validators))
+	for hostname, validator := range s.validators {
+		if s.validatorSetChain(validator) != chain {
+			continue
+		}
+		if address := validator.Config().ConsensusAddress; address != (common.Address{}) {
+			addresses[address] = hostname
+		}
+	}
+
+	return addresses
+}
+
+// validatorActive tells whether the validator is in the active validator set, validators without a consensus
+// address count as active, as do all of them until the validator set is read.
+func (s *MevSentry) validatorActive(validator node.Validator) bool {
+	address := validator.Config().ConsensusAddress
+	validatorSet := s.validatorSetOf(validator)
+	if validatorSet == nil || address == (common.Address{}) {
+		return true
+	}
+
+	active, known := validatorSet.Active(address)
+	return active || !known
+}
+
+// validatorSetEnforced tells whether bids are rejected if the validator isn't in its active validator set.
+func (s *MevSentry) validatorSetEnforced(validator node.Validator) bool {
+	validatorSet := s.validatorSetOf(validator)
+	return validatorSet != nil && validatorSet.Enforced()
+}
+
+// checkValidatorSet rejects the bid if its validator isn't in the active validator set and the set is
+// enforced.
+func (s *MevSentry) checkValidatorSet(hostname string, validator node.Validator) error {
+	if s.validatorActive(validator) || !s.validatorSetEnforced(validator) {
+		return nil
+	}
+
+	log.Errorw("validator not in the active validator set", "hostname", hostname,
+		"address", validator.Config().ConsensusAddress)
+
+	return types.NewInvalidBidError("validator is not in the active validator set")
+}
+
+// ValidatorSet returns whether the validators are in the active validator set on chain.
+func (a *MevAdmin) ValidatorSet(_ context.Context) ([]node.ValidatorStatus, error) {
+	if !a.sentry.validatorSetConfigured() {
+		return nil, newSentryError("validator set is not configured")
+	}
+
+	a.sentry.mu.RLock()
+	statuses := make([]node.ValidatorStatus, 0, len(a.sentry.validators))
+	for hostname, validator := range a.sentry.validators {
+		status := node.ValidatorStatus{PublicHostName: hostname, ConsensusAddress: validator.Config().ConsensusAddress}
+		validatorSet := a.sentry.validatorSetOf(validator)
+		if validatorSet != nil && status.ConsensusAddress != (common.Address{}) {
+			if active, known := validatorSet.Active(status.ConsensusAddress); known {
+				status.Active = &active
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	a.sentry.mu.RUnlock()
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].PublicHostName < statuses[j].PublicHostName
+	})
+
+	return statuses, nil
+}
+
+// validatorSetConfigured tells whether the validator set of the service or of any chain is read.
+func (s *MevSentry) validatorSetConfigured() bool {
+	if s.validatorSet != nil {
+		return true
+	}
+
+	for _, c := range s.chains {
+		if c.validatorSet != nil {
+			return true
+		}
+	}
+
+	return false
+}
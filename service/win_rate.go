@@ -0,0 +1,202 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bnb-chain/bsc-mev-sentry/metrics"
+	"github.com/bnb-chain/bsc-mev-sentry/node"
+)
+
+var errWinRatesDisabled = errors.New("win rates are disabled")
+
+// localBuilder labels the blocks proposed without a pay bid tx, i.e. built by the validator itself
+const localBuilder = "local"
+
+// BlockWins is the builders winning the blocks proposed by a validator over its recent blocks.
+type BlockWins struct {
+	Validator string `json:"validator"`
+	// Proposed blocks of the validator kept, the most recent ones
+	Proposed int `json:"proposed"`
+	// Local blocks built by the validator itself, i.e. without a pay bid tx
+	Local    int           `json:"local"`
+	Builders []BuilderWins `json:"builders"`
+}
+
+// BuilderWins is the blocks of a validator won by a builder.
+type BuilderWins struct {
+	Builder common.Address `json:"builder"`
+	Wins    int            `json:"wins"`
+	WinRate float64        `json:"winRate"`
+}
+
+type validatorWins struct {
+	numbers  []uint64                  // ascending
+	builders map[uint64]common.Address // number -> winning builder, zero if built locally
+	labels   map[string]struct{}       // builders of the win rate gauges set
+}
+
+// winRates attributes the blocks proposed by each validator to the builder whose bid won them, via the recipient
+// of their pay bid tx, over the most recent blocks of each validator.
+type winRates struct {
+	mu         sync.Mutex
+	blocks     int
+	validators map[string]*validatorWins
+}
+
+func newWinRates(blocks int) *winRates {
+	if blocks <= 0 {
+		return nil
+	}
+
+	return &winRates{blocks: blocks, validators: make(map[string]*validatorWins)}
+}
+
+// record attributes the block of the number, replacing the block recorded before if reorged. A block of another
+// proposer is dropped, i.e. its coinbase isn't the consensus address or, if unknown, it has no pay bid tx.
+func (w *winRates) record(hostname string, consensus common.Address, number uint64, block *node.BlockTxs) {
+	if w == nil {
+		return
+	}
+
+	proposed := block.Coinbase == consensus
+	if consensus == (common.Address{}) {
+		proposed = block.Builder != common.Address{}
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	vw, ok := w.validators[hostname]
+	if !ok {
+		vw = &validatorWins{builders: make(map[uint64]common.Address), labels: make(map[string]struct{})}
+		w.validators[hostname] = vw
+	}
+
+	_, recorded := vw.builders[number]
+	switch {
+	case proposed && !recorded:
+		i := sort.Search(len(vw.numbers), func(i int) bool { return vw.numbers[i] > number })
+		vw.numbers = append(vw.numbers, 0)
+		copy(vw.numbers[i+1:], vw.numbers[i:])
+		vw.numbers[i] = number
+		vw.builders[number] = block.Builder
+	case proposed:
+		vw.builders[number] = block.Builder
+	case recorded:
+		i := sort.Search(len(vw.numbers), func(i int) bool { return vw.numbers[i] >= number })
+		vw.numbers = append(vw.numbers[:i], vw.numbers[i+1:]...)
+		delete(vw.builders, number)
+	default:
+		return
+	}
+
+	for len(vw.numbers) > w.blocks {
+		delete(vw.builders, vw.numbers[0])
+		vw.numbers = vw.numbers[1:]
+	}
+
+	w.setGauges(hostname, vw)
+}
+
+// setGauges sets the win rate of the builders winning the blocks kept, and deletes those of the others.
+func (w *winRates) setGauges(hostname string, vw *validatorWins) {
+	wins := vw.summary(hostname)
+
+	labels := make(map[string]struct{}, len(wins.Builders)+1)
+	if wins.Local > 0 {
+		labels[localBuilder] = struct{}{}
+		metrics.BlockWinRateGauge.WithLabelValues(hostname, localBuilder).Set(
+			float64(wins.Local) / float64(wins.Proposed))
+	}
+	for _, b := range wins.Builders {
+		labels[b.Builder.String()] = struct{}{}
+		metrics.BlockWinRateGauge.WithLabelValues(hostname, b.Builder.String()).Set(b.WinRate)
+	}
+
+	for label := range vw.labels {
+		if _, ok := labels[label]; !ok {
+			metrics.BlockWinRateGauge.DeleteLabelValues(hostname, label)
+		}
+	}
+	vw.labels = labels
+}
+
+// remove drops the blocks of a validator removed, along with its gauges.
+func (w *winRates) remove(hostname string) {
+	if w == nil {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	vw, ok := w.validators[hostname]
+	if !ok {
+		return
+	}
+	for label := range vw.labels {
+		metrics.BlockWinRateGauge.DeleteLabelValues(hostname, label)
+	}
+	delete(w.validators, hostname)
+}
+
+func (vw *validatorWins) summary(hostname string) *BlockWins {
+	wins := &BlockWins{Validator: hostname, Proposed: len(vw.numbers), Builders: []BuilderWins{}}
+
+	counts := make(map[common.Address]int)
+	for _, builder := range vw.builders {
+		if builder == (common.Address{}) {
+			wins.Local++
+		} else {
+			counts[builder]++
+		}
+	}
+
+	for builder, count := range counts {
+		wins.Builders = append(wins.Builders, BuilderWins{
+			Builder: builder,
+			Wins:    count,
+			WinRate: float64(count) / float64(wins.Proposed),
+		})
+	}
+	sort.Slice(wins.Builders, func(i, j int) bool {
+		if wins.Builders[i].Wins != wins.Builders[j].Wins {
+			return wins.Builders[i].Wins > wins.Builders[j].Wins
+		}
+		return wins.Builders[i].Builder.Cmp(wins.Builders[j].Builder) < 0
+	})
+
+	return wins
+}
+
+// summaries returns the wins of every validator, sorted by hostname.
+func (w *winRates) summaries() []*BlockWins {
+	if w == nil {
+		return nil
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	summaries := make([]*BlockWins, 0, len(w.validators))
+	for hostname, vw := range w.validators {
+		summaries = append(summaries, vw.summary(hostname))
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].Validator < summaries[j].Validator })
+
+	return summaries
+}
+
+// BlockWins returns the builders winning the recent blocks proposed by each validator, see Service.WinRateBlocks.
+func (a *MevAdmin) BlockWins(_ context.Context) ([]*BlockWins, error) {
+	if a.sentry.winRates == nil {
+		return nil, errWinRatesDisabled
+	}
+
+	return a.sentry.winRates.summaries(), nil
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/bsc-mev-sentry/metrics"
+	"github.com/bnb-chain/bsc-mev-sentry/node"
+)
+
+func TestWinRates(t *testing.T) {
+	var (
+		consensus = common.HexToAddress("0xc0")
+		other     = common.HexToAddress("0xc1")
+		builder1  = common.HexToAddress("0xb1")
+		builder2  = common.HexToAddress("0xb2")
+	)
+	w := newWinRates(4)
+
+	record := func(number uint64, coinbase, builder common.Address) {
+		w.record("wins", consensus, number, &node.BlockTxs{Coinbase: coinbase, Builder: builder})
+	}
+	record(1, consensus, builder1)
+	record(2, consensus, builder1)
+	record(3, other, builder2) // proposed by another validator
+	record(4, consensus, builder2)
+	record(5, consensus, common.Address{})
+
+	summaries := w.summaries()
+	require.Len(t, summaries, 1)
+	assert.Equal(t, 4, summaries[0].Proposed)
+	assert.Equal(t, 1, summaries[0].Local)
+	assert.Equal(t, []BuilderWins{
+		{Builder: builder1, Wins: 2, WinRate: 0.5},
+		{Builder: builder2, Wins: 1, WinRate: 0.25},
+	}, summaries[0].Builders)
+	assert.Equal(t, 0.25, testutil.ToFloat64(metrics.BlockWinRateGauge.WithLabelValues("wins", localBuilder)))
+
+	// block 4 is reorged into a block of another validator, and block 1 is pushed out of the window
+	record(4, other, common.Address{})
+	record(6, consensus, builder2)
+	record(7, consensus, builder2)
+
+	summaries = w.summaries()
+	assert.Equal(t, 4, summaries[0].Proposed)
+	assert.Equal(t, []BuilderWins{
+		{Builder: builder2, Wins: 2, WinRate: 0.5},
+		{Builder: builder1, Wins: 1, WinRate: 0.25},
+	}, summaries[0].Builders)
+	assert.Equal(t, 0.5, testutil.ToFloat64(metrics.BlockWinRateGauge.WithLabelValues("wins", builder2.String())))
+
+	w.remove("wins")
+	assert.Empty(t, w.summaries())
+}
@@ -0,0 +1,115 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// APIKeyStore persists the hashes of the builder api keys added via the admin API to a JSON file, which is rewritten
+// on every change, so that they survive reloads and restarts.
+type APIKeyStore struct {
+	path string
+
+	mu     sync.Mutex
+	hashes map[common.Address][]string // builder -> api key hashes
+}
+
+func OpenAPIKeyStore(path string) (*APIKeyStore, error) {
+	s := &APIKeyStore{
+		path:   path,
+		hashes: make(map[common.Address][]string),
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, os.MkdirAll(filepath.Dir(path), 0700)
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err = json.Unmarshal(data, &s.hashes); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Hashes returns the api key hashes of the builder.
+func (s *APIKeyStore) Hashes(builder common.Address) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string(nil), s.hashes[builder]...)
+}
+
+// Add adds the api key hash to the builder.
+func (s *APIKeyStore) Add(builder common.Address, hash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old := s.hashes[builder]
+	s.hashes[builder] = append(old[:len(old):len(old)], hash)
+
+	if err := s.save(); err != nil {
+		s.set(builder, old)
+		return err
+	}
+
+	return nil
+}
+
+// Revoke removes the api key hash from the builder, ErrNotFound is returned if it's not stored.
+func (s *APIKeyStore) Revoke(builder common.Address, hash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old := s.hashes[builder]
+	hashes := make([]string, 0, len(old))
+	for _, h := range old {
+		if h != hash {
+			hashes = append(hashes, h)
+		}
+	}
+
+	if len(hashes) == len(old) {
+		return ErrNotFound
+	}
+
+	s.set(builder, hashes)
+
+	if err := s.save(); err != nil {
+		s.set(builder, old)
+		return err
+	}
+
+	return nil
+}
+
+func (s *APIKeyStore) set(builder common.Address, hashes []string) {
+	if len(hashes) == 0 {
+		delete(s.hashes, builder)
+		return
+	}
+
+	s.hashes[builder] = hashes
+}
+
+// save writes the hashes to a temporary file renamed over the store, so a crash never leaves it partial.
+func (s *APIKeyStore) save() error {
+	data, err := json.MarshalIndent(s.hashes, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := s.path + ".tmp"
+	if err = os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, s.path)
+}
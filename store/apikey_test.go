@@ -0,0 +1,34 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKeyStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", "api_keys.json")
+	builder := common.HexToAddress("0x01")
+
+	s, err := OpenAPIKeyStore(path)
+	require.NoError(t, err)
+	assert.Empty(t, s.Hashes(builder))
+
+	require.NoError(t, s.Add(builder, "a"))
+	require.NoError(t, s.Add(builder, "b"))
+
+	// reopen to make sure the hashes are persisted
+	s, err = OpenAPIKeyStore(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, s.Hashes(builder))
+
+	require.NoError(t, s.Revoke(builder, "a"))
+	assert.ErrorIs(t, s.Revoke(builder, "a"), ErrNotFound)
+
+	s, err = OpenAPIKeyStore(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b"}, s.Hashes(builder))
+}
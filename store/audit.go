@@ -0,0 +1,261 @@
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/bnb-chain/bsc-mev-sentry/log"
+)
+
+// AuditEntry is a pay bid tx signed by the sentry. Hash is the keccak256 of the json of the entry with a zero Hash,
+// chaining it to the entry before via PrevHash, so that an entry changed, removed or reordered breaks the chain.
+type AuditEntry struct {
+	Seq       uint64         `json:"seq"`
+	Time      int64          `json:"time"`
+	Validator string         `json:"validator"`
+	Account   common.Address `json:"account"`
+	Builder   common.Address `json:"builder"`
+	BidHash   common.Hash    `json:"bidHash"`
+	TxHash    common.Hash    `json:"txHash"`
+	Nonce     uint64         `json:"nonce"`
+	Amount    *big.Int       `json:"amount"`
+	GasPrice  *big.Int       `json:"gasPrice"`
+	PrevHash  common.Hash    `json:"prevHash"`
+	Hash      common.Hash    `json:"hash"`
+}
+
+func (e *AuditEntry) digest() (common.Hash, error) {
+	unhashed := *e
+	unhashed.Hash = common.Hash{}
+
+	data, err := json.Marshal(&unhashed)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	return crypto.Keccak256Hash(data), nil
+}
+
+// AuditHead is the last entry of the audit log, zero if it's empty. Recording it elsewhere now and then lets the
+// operator tell the log apart from one rewritten from scratch.
+type AuditHead struct {
+	Seq  uint64      `json:"seq"`
+	Hash common.Hash `json:"hash"`
+}
+
+// AuditLog is an append-only file of the signed pay bid txs, one json entry per line, chained by hash. Entries are
+// flushed to disk before Append returns, the appends waiting on the same fsync sharing it.
+type AuditLog struct {
+	mu   sync.Mutex
+	file *os.File
+	size int64 // of the complete entries
+	head AuditHead
+
+	// syncMu serializes the fsyncs, an append waiting on it finds its entry flushed already if the fsync before
+	// started after it was written
+	syncMu sync.Mutex
+	synced int64 // size flushed to disk
+}
+
+// OpenAuditLog opens the audit log at the path, created if missing, after verifying the chain of its entries. A last
+// entry torn by a crash while it's written is truncated.
+func OpenAuditLog(path string) (*AuditLog, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	head, size, err := verifyAuditLog(file)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("audit log %s: %w", path, err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	if info.Size() > size {
+		log.Warnw("truncate torn audit log entry", "path", path, "size", info.Size()-size)
+		if err = file.Truncate(size); err != nil {
+			file.Close()
+			return nil, err
+		}
+	}
+	if _, err = file.Seek(size, io.SeekStart); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return &AuditLog{
+		file:   file,
+		size:   size,
+		head:   head,
+		synced: size,
+	}, nil
+}
+
+// VerifyAuditLog verifies the chain of the entries of the audit log at the path, and returns its last entry.
+func VerifyAuditLog(path string) (AuditHead, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return AuditHead{}, err
+	}
+	defer file.Close()
+
+	head, size, err := verifyAuditLog(file)
+	if err != nil {
+		return head, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return head, err
+	}
+	if info.Size() > size {
+		return head, fmt.Errorf("torn entry after seq %d", head.Seq)
+	}
+
+	return head, nil
+}
+
+// verifyAuditLog reads the entries from the start, and returns the last one and the size of the complete lines.
+func verifyAuditLog(r io.Reader) (AuditHead, int64, error) {
+	var (
+		head AuditHead
+		size int64
+	)
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			// a line without the newline is torn
+			return head, size, nil
+		}
+		if err != nil {
+			return head, size, err
+		}
+
+		var entry AuditEntry
+		if err = json.Unmarshal(bytes.TrimSpace(line), &entry); err != nil {
+			return head, size, fmt.Errorf("invalid entry after seq %d, %w", head.Seq, err)
+		}
+		if entry.Seq != head.Seq+1 || entry.PrevHash != head.Hash {
+			return head, size, fmt.Errorf("entry seq %d doesn't follow seq %d", entry.Seq, head.Seq)
+		}
+
+		hash, err := entry.digest()
+		if err != nil {
+			return head, size, err
+		}
+		if hash != entry.Hash {
+			return head, size, fmt.Errorf("entry seq %d is altered", entry.Seq)
+		}
+
+		head = AuditHead{Seq: entry.Seq, Hash: entry.Hash}
+		size += int64(len(line))
+	}
+}
+
+// Append chains the entry to the last one and writes it, setting its Seq, PrevHash and Hash, and returns once it's
+// flushed to disk.
+func (l *AuditLog) Append(entry *AuditEntry) error {
+	size, err := l.write(entry)
+	if err != nil {
+		return err
+	}
+
+	return l.syncTo(size)
+}
+
+// write writes the entry, and returns the size of the log up to its end.
+func (l *AuditLog) write(entry *AuditEntry) (int64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entry.Seq, entry.PrevHash = l.head.Seq+1, l.head.Hash
+
+	hash, err := entry.digest()
+	if err != nil {
+		return 0, err
+	}
+	entry.Hash = hash
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return 0, err
+	}
+	line = append(line, '\n')
+	if _, err = l.file.Write(line); err != nil {
+		// drop what's written of the entry, so that the next one isn't appended to a torn line
+		if truncErr := l.file.Truncate(l.size); truncErr == nil {
+			_, _ = l.file.Seek(l.size, io.SeekStart)
+		}
+		return 0, err
+	}
+	l.size += int64(len(line))
+
+	l.head = AuditHead{Seq: entry.Seq, Hash: entry.Hash}
+
+	return l.size, nil
+}
+
+// Head returns the last entry appended.
+func (l *AuditLog) Head() AuditHead {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.head
+}
+
+// syncTo flushes the file to disk unless the log is flushed up to the size already.
+func (l *AuditLog) syncTo(size int64) error {
+	l.syncMu.Lock()
+	defer l.syncMu.Unlock()
+
+	if l.synced >= size {
+		return nil
+	}
+
+	// the entries written by now are flushed too
+	l.mu.Lock()
+	size = l.size
+	l.mu.Unlock()
+
+	if err := l.file.Sync(); err != nil {
+		return err
+	}
+	l.synced = size
+
+	return nil
+}
+
+// Close flushes the entries to disk and closes the file.
+func (l *AuditLog) Close() error {
+	l.mu.Lock()
+	size := l.size
+	l.mu.Unlock()
+
+	if err := l.syncTo(size); err != nil {
+		l.file.Close()
+		return err
+	}
+
+	return l.file.Close()
+}
@@ -0,0 +1,89 @@
+package store
+
+import (
+	"bytes"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", "audit.log")
+
+	l, err := OpenAuditLog(path)
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, l.Append(&AuditEntry{
+			Builder: common.HexToAddress("0x01"),
+			BidHash: common.BigToHash(big.NewInt(int64(i))),
+			Nonce:   uint64(i),
+			Amount:  big.NewInt(1e15),
+		}))
+	}
+	head := l.Head()
+	require.NoError(t, l.Close())
+	assert.Equal(t, uint64(3), head.Seq)
+
+	verified, err := VerifyAuditLog(path)
+	require.NoError(t, err)
+	assert.Equal(t, head, verified)
+
+	// a torn last entry is truncated, and the chain goes on
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"seq":4,`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	_, err = VerifyAuditLog(path)
+	assert.Error(t, err)
+
+	l, err = OpenAuditLog(path)
+	require.NoError(t, err)
+	require.NoError(t, l.Append(&AuditEntry{Nonce: 3, Amount: big.NewInt(0)}))
+	require.NoError(t, l.Close())
+	verified, err = VerifyAuditLog(path)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4), verified.Seq)
+
+	// an altered entry breaks the chain
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, bytes.Replace(data, []byte(`"nonce":1,`), []byte(`"nonce":9,`), 1), 0600))
+	_, err = VerifyAuditLog(path)
+	assert.ErrorContains(t, err, "entry seq 2 is altered")
+	_, err = OpenAuditLog(path)
+	assert.Error(t, err)
+}
+
+func TestAuditLogConcurrentAppend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+
+	l, err := OpenAuditLog(path)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			assert.NoError(t, l.Append(&AuditEntry{Nonce: uint64(i), Amount: big.NewInt(0)}))
+		}(i)
+	}
+	wg.Wait()
+
+	// every entry is flushed once its Append returns
+	l.syncMu.Lock()
+	assert.Equal(t, l.size, l.synced)
+	l.syncMu.Unlock()
+	require.NoError(t, l.Close())
+
+	verified, err := VerifyAuditLog(path)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(16), verified.Seq)
+}
@@ -0,0 +1,750 @@
+package store
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	_ "github.com/lib/pq"           // postgres driver
+	_ "github.com/mattn/go-sqlite3" // sqlite driver
+
+	"github.com/bnb-chain/bsc-mev-sentry/log"
+	"github.com/bnb-chain/bsc-mev-sentry/metrics"
+	"github.com/bnb-chain/bsc-mev-sentry/utils"
+)
+
+// outcomes of a bid
+const (
+	// BidAccepted the validator accepted the bid, its block isn't settled yet
+	BidAccepted = "accepted"
+	BidRejected = "rejected"
+	// BidWon the pay bid tx of the bid is included in its block
+	BidWon = "won"
+	// BidLost another bid won the block
+	BidLost = "lost"
+	// BidExpired the block of the bid couldn't be fetched to settle it
+	BidExpired = "expired"
+)
+
+// bidStoreFlushInterval bounds how long a received bid waits to be written
+const bidStoreFlushInterval = 100 * time.Millisecond
+
+type BidStoreConfig struct {
+	// Driver sqlite or postgres, the bid store is disabled if empty
+	Driver string
+	// DSN data source name, e.g. the file path of sqlite or the connection url of postgres
+	DSN string
+	// BufferSize bids waiting to be written, more are dropped instead of slowing down bids
+	BufferSize int
+	// MaxAge bids and issues older than it are pruned, kept forever if 0
+	MaxAge utils.Duration
+	// MaxRows bids kept at most, the oldest are pruned beyond it, unlimited if 0, and so are the issues
+	MaxRows int64
+	// PruneInterval how often the bids are pruned and the store size is measured, defaults to 10m
+	PruneInterval utils.Duration
+	// KeepArgs keeps the bids as signed by their builders, txs included, so that they can be replayed
+	KeepArgs bool
+}
+
+// Bid is a bid received by the sentry and what became of it.
+type Bid struct {
+	ID          int64          `json:"id"`
+	Hash        common.Hash    `json:"hash"`
+	Builder     common.Address `json:"builder"`
+	Validator   string         `json:"validator"`
+	BlockNumber uint64         `json:"blockNumber"`
+	ParentHash  common.Hash    `json:"parentHash"`
+	GasUsed     uint64         `json:"gasUsed"`
+	GasFee      *big.Int       `json:"gasFee"`
+	BuilderFee  *big.Int       `json:"builderFee"`
+	TxCount     int            `json:"txCount"`
+	PayTxHash   common.Hash    `json:"payTxHash"`
+	ReceivedAt  time.Time      `json:"receivedAt"`
+	Outcome     string         `json:"outcome"`
+	Reason      string         `json:"reason,omitempty"`
+	Error       string         `json:"error,omitempty"`
+	// Args the bid as signed by the builder without the pay bid tx, i.e. the json of the bid args, if KeepArgs
+	Args json.RawMessage `json:"args,omitempty"`
+}
+
+type dialect struct {
+	driver string
+	// migrations of the schema in order, version n is migrations[n-1], see migrate
+	migrations [][]string
+	// numbered placeholders, i.e. $1 instead of ?
+	numbered bool
+	// size queries the bytes taken by the store
+	size string
+}
+
+var dialects = map[string]dialect{
+	"sqlite": {
+		driver: "sqlite3",
+		size:   `SELECT page_count * page_size FROM pragma_page_count(), pragma_page_size()`,
+		migrations: [][]string{append([]string{
+			`CREATE TABLE IF NOT EXISTS bids (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				hash TEXT NOT NULL,
+				builder TEXT NOT NULL,
+				validator TEXT NOT NULL,
+				block_number INTEGER NOT NULL,
+				parent_hash TEXT NOT NULL,
+				gas_used INTEGER NOT NULL,
+				gas_fee TEXT NOT NULL,
+				builder_fee TEXT NOT NULL,
+				tx_count INTEGER NOT NULL,
+				pay_tx_hash TEXT NOT NULL,
+				received_at INTEGER NOT NULL,
+				outcome TEXT NOT NULL,
+				reason TEXT NOT NULL,
+				error TEXT NOT NULL
+			)`,
+			`CREATE TABLE IF NOT EXISTS issues (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				validator TEXT NOT NULL,
+				builder TEXT NOT NULL,
+				bid_hash TEXT NOT NULL,
+				message TEXT NOT NULL,
+				reported_at INTEGER NOT NULL,
+				status TEXT NOT NULL,
+				error TEXT NOT NULL
+			)`,
+			`CREATE TABLE IF NOT EXISTS export_cursors (
+				name TEXT PRIMARY KEY,
+				last_id INTEGER NOT NULL
+			)`,
+			`CREATE TABLE IF NOT EXISTS bid_args (
+				id INTEGER PRIMARY KEY,
+				args TEXT NOT NULL
+			)`,
+		}, bidIndexes...)},
+	},
+	"postgres": {
+		driver:   "postgres",
+		numbered: true,
+		size:     `SELECT pg_total_relation_size('bids') + pg_total_relation_size('issues')`,
+		migrations: [][]string{append([]string{
+			`CREATE TABLE IF NOT EXISTS bids (
+				id BIGSERIAL PRIMARY KEY,
+				hash TEXT NOT NULL,
+				builder TEXT NOT NULL,
+				validator TEXT NOT NULL,
+				block_number BIGINT NOT NULL,
+				parent_hash TEXT NOT NULL,
+				gas_used BIGINT NOT NULL,
+				gas_fee TEXT NOT NULL,
+				builder_fee TEXT NOT NULL,
+				tx_count INTEGER NOT NULL,
+				pay_tx_hash TEXT NOT NULL,
+				received_at BIGINT NOT NULL,
+				outcome TEXT NOT NULL,
+				reason TEXT NOT NULL,
+				error TEXT NOT NULL
+			)`,
+			`CREATE TABLE IF NOT EXISTS issues (
+				id BIGSERIAL PRIMARY KEY,
+				validator TEXT NOT NULL,
+				builder TEXT NOT NULL,
+				bid_hash TEXT NOT NULL,
+				message TEXT NOT NULL,
+				reported_at BIGINT NOT NULL,
+				status TEXT NOT NULL,
+				error TEXT NOT NULL
+			)`,
+			`CREATE TABLE IF NOT EXISTS export_cursors (
+				name TEXT PRIMARY KEY,
+				last_id BIGINT NOT NULL
+			)`,
+			`CREATE TABLE IF NOT EXISTS bid_args (
+				id BIGINT PRIMARY KEY,
+				args TEXT NOT NULL
+			)`,
+		}, bidIndexes...)},
+	},
+}
+
+// common to the dialects, created by the first migration
+var bidIndexes = []string{
+	`CREATE INDEX IF NOT EXISTS bids_hash ON bids (hash)`,
+	`CREATE INDEX IF NOT EXISTS bids_builder_received_at ON bids (builder, received_at)`,
+	`CREATE INDEX IF NOT EXISTS bids_block_number ON bids (block_number, validator)`,
+	`CREATE INDEX IF NOT EXISTS issues_builder_reported_at ON issues (builder, reported_at)`,
+}
+
+// rebind replaces the ? placeholders of the query with $1, $2... if the dialect requires.
+func (d dialect) rebind(query string) string {
+	if !d.numbered {
+		return query
+	}
+
+	var sb strings.Builder
+	n := 0
+	for _, c := range query {
+		if c == '?' {
+			n++
+			sb.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		sb.WriteRune(c)
+	}
+
+	return sb.String()
+}
+
+// BidStore persists every bid of registered builders to SQLite or Postgres. Bids are written
+// in batches in the background, so the database never slows down forwarding.
+type BidStore struct {
+	db      *sql.DB
+	dialect dialect
+
+	writes chan bidWrite
+	done   chan struct{}
+
+	retention retention
+	keepArgs  bool
+	keyring   *Keyring
+	keepMu    sync.Mutex
+	keep      []string // exporters whose unexported bids aren't pruned
+	stop      chan struct{}
+	pruned    chan struct{}
+
+	mu     sync.RWMutex // guards closed against records racing with close
+	closed bool
+}
+
+// OpenBidStore opens the bid store of the config, the args of the bids are encrypted with the key of their validator if
+// the keyring has one.
+func OpenBidStore(cfg BidStoreConfig, keyring *Keyring) (*BidStore, error) {
+	d, ok := dialects[cfg.Driver]
+	if !ok {
+		return nil, fmt.Errorf("unsupported bid store driver %s", cfg.Driver)
+	}
+
+	if d.driver == "sqlite3" && !strings.HasPrefix(cfg.DSN, "file:") && cfg.DSN != ":memory:" {
+		if err := os.MkdirAll(filepath.Dir(cfg.DSN), 0700); err != nil {
+			return nil, err
+		}
+	}
+
+	db, err := sql.Open(d.driver, cfg.DSN)
+	if err != nil {
+		return nil, err
+	}
+
+	if d.driver == "sqlite3" {
+		// sqlite doesn't support concurrent writers
+		db.SetMaxOpenConns(1)
+	}
+
+	if err = migrate(db, d); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to migrate bid store schema, %w", err)
+	}
+
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = 10000
+	}
+
+	s := &BidStore{
+		db:        db,
+		dialect:   d,
+		writes:    make(chan bidWrite, cfg.BufferSize),
+		done:      make(chan struct{}),
+		retention: newRetention(cfg),
+		keepArgs:  cfg.KeepArgs,
+		keyring:   keyring,
+		stop:      make(chan struct{}),
+		pruned:    make(chan struct{}),
+	}
+
+	go s.loop()
+	go s.pruneLoop()
+
+	return s, nil
+}
+
+// KeepsArgs tells whether the bids are stored as signed by their builders.
+func (s *BidStore) KeepsArgs() bool {
+	return s.keepArgs
+}
+
+// Record queues the bid to be written, it's dropped if the database falls behind.
+func (s *BidStore) Record(bid *Bid) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.closed {
+		return
+	}
+
+	select {
+	case s.writes <- bidWrite{bid: bid}:
+	default:
+		metrics.BidStoreDroppedCounter.Inc()
+		log.Errorw("bid store is full, drop bid", "hash", bid.Hash, "builder", bid.Builder)
+	}
+}
+
+// SettleBlock queues settling the accepted bids of the block sent to the validator, they're won if their pay bid
+// tx is one of the txs of the block, otherwise lost. The bids are expired instead if txs is nil, i.e. the block
+// couldn't be fetched. A block settled again, e.g. replaced by a reorg, settles its bids again whatever their
+// outcome. Settlements are written after the bids queued earlier.
+func (s *BidStore) SettleBlock(validator string, block uint64, txs []common.Hash) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.closed {
+		return
+	}
+
+	select {
+	case s.writes <- bidWrite{settle: &blockSettlement{validator: validator, block: block, txs: txs}}:
+	default:
+		log.Errorw("bid store is full, drop block settlement", "validator", validator, "block", block)
+	}
+}
+
+func (s *BidStore) loop() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(bidStoreFlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]bidWrite, 0, 256)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+
+		if err := s.write(batch); err != nil {
+			metrics.BidStoreDroppedCounter.Add(float64(len(batch)))
+			log.Errorw("failed to write bids", "count", len(batch), "err", err)
+		}
+
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case w, ok := <-s.writes:
+			if !ok {
+				flush()
+				return
+			}
+
+			batch = append(batch, w)
+			if len(batch) == cap(batch) {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// bidWrite is either a bid or an issue to insert, an issue to update, or a block to settle
+type bidWrite struct {
+	bid         *Bid
+	issue       *Issue
+	issueUpdate *Issue
+	settle      *blockSettlement
+}
+
+type blockSettlement struct {
+	validator string
+	block     uint64
+	txs       []common.Hash
+}
+
+func (s *BidStore) write(writes []bidWrite) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	stmt, err := tx.Prepare(s.dialect.rebind(`INSERT INTO bids (hash, builder, validator, block_number, parent_hash,
+		gas_used, gas_fee, builder_fee, tx_count, pay_tx_hash, received_at, outcome, reason, error)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) RETURNING id`))
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+
+	for _, w := range writes {
+		switch {
+		case w.bid != nil:
+			b := w.bid
+			var id int64
+			err = stmt.QueryRow(b.Hash.Hex(), b.Builder.Hex(), b.Validator, b.BlockNumber, b.ParentHash.Hex(),
+				b.GasUsed, bigString(b.GasFee), bigString(b.BuilderFee), b.TxCount, b.PayTxHash.Hex(),
+				b.ReceivedAt.UnixMilli(), b.Outcome, b.Reason, b.Error).Scan(&id)
+			if err == nil && len(b.Args) > 0 {
+				var args string
+				if args, err = s.keyring.sealText(b.Validator, string(b.Args)); err == nil {
+					_, err = tx.Exec(s.dialect.rebind(`INSERT INTO bid_args (id, args) VALUES (?, ?)`), id, args)
+				}
+			}
+		case w.issue != nil:
+			err = s.insertIssue(tx, w.issue)
+		case w.issueUpdate != nil:
+			err = s.updateIssue(tx, w.issueUpdate)
+		default:
+			err = s.settle(tx, w.settle)
+		}
+
+		if err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (s *BidStore) settle(tx *sql.Tx, settlement *blockSettlement) error {
+	// the settled bids are settled again, the block may be replaced by a reorg
+	rows, err := tx.Query(s.dialect.rebind(`SELECT id, pay_tx_hash, outcome FROM bids
+		WHERE block_number = ? AND validator = ? AND outcome <> ?`), settlement.block, settlement.validator, BidRejected)
+	if err != nil {
+		return err
+	}
+
+	type change struct{ from, to string }
+	outcomes := make(map[int64]change)
+	included := make(map[common.Hash]struct{}, len(settlement.txs))
+	for _, hash := range settlement.txs {
+		included[hash] = struct{}{}
+	}
+
+	for rows.Next() {
+		var (
+			id        int64
+			payTxHash string
+			outcome   string
+		)
+		if err = rows.Scan(&id, &payTxHash, &outcome); err != nil {
+			rows.Close()
+			return err
+		}
+
+		c := change{from: outcome}
+		switch _, ok := included[common.HexToHash(payTxHash)]; {
+		case settlement.txs == nil:
+			c.to = BidExpired
+		case ok:
+			c.to = BidWon
+		default:
+			c.to = BidLost
+		}
+		if c.to != c.from {
+			outcomes[id] = c
+		}
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	for id, c := range outcomes {
+		if _, err = tx.Exec(s.dialect.rebind(`UPDATE bids SET outcome = ? WHERE id = ?`), c.to, id); err != nil {
+			return err
+		}
+		if c.from != BidAccepted {
+			metrics.BidStoreResettledCounter.WithLabelValues(c.from, c.to).Inc()
+		}
+	}
+
+	return nil
+}
+
+const bidColumns = `id, hash, builder, validator, block_number, parent_hash, gas_used, gas_fee, builder_fee, tx_count,
+	pay_tx_hash, received_at, outcome, reason, error`
+
+// ByHash returns the bids of the hash, a bid sent more than once is stored each time.
+func (s *BidStore) ByHash(hash common.Hash) ([]*Bid, error) {
+	return s.query(`SELECT `+bidColumns+` FROM bids WHERE hash = ? ORDER BY id`, hash.Hex())
+}
+
+// MaxBidQueryLimit bounds the bids returned by one query
+const MaxBidQueryLimit = 1000
+
+// BidFilter selects bids, zero fields don't filter. Bids are returned in the order received,
+// pass NextCursor of the last page as Cursor to get the next one.
+type BidFilter struct {
+	Builder   *common.Address `json:"builder,omitempty"`
+	Validator string          `json:"validator,omitempty"`
+	FromBlock uint64          `json:"fromBlock,omitempty"`
+	ToBlock   uint64          `json:"toBlock,omitempty"`
+	Outcome   string          `json:"outcome,omitempty"`
+	// FromTime and ToTime unix seconds of when bids are received, inclusive
+	FromTime int64 `json:"fromTime,omitempty"`
+	ToTime   int64 `json:"toTime,omitempty"`
+	Cursor   int64 `json:"cursor,omitempty"`
+	// Limit of bids returned, defaults to 100 and at most MaxBidQueryLimit
+	Limit int `json:"limit,omitempty"`
+	// WithArgs returns the bids as signed by their builders too, if they're kept
+	WithArgs bool `json:"withArgs,omitempty"`
+}
+
+// BidPage is a page of bids, NextCursor is zero on the last page.
+type BidPage struct {
+	Bids       []*Bid `json:"bids"`
+	NextCursor int64  `json:"nextCursor,omitempty"`
+}
+
+// Query returns a page of the bids selected by the filter.
+func (s *BidStore) Query(f BidFilter) (*BidPage, error) {
+	var (
+		conds = []string{"id > ?"}
+		args  = []interface{}{f.Cursor}
+	)
+	if f.Builder != nil {
+		conds, args = append(conds, "builder = ?"), append(args, f.Builder.Hex())
+	}
+	if f.Validator != "" {
+		conds, args = append(conds, "validator = ?"), append(args, f.Validator)
+	}
+	if f.FromBlock > 0 {
+		conds, args = append(conds, "block_number >= ?"), append(args, f.FromBlock)
+	}
+	if f.ToBlock > 0 {
+		conds, args = append(conds, "block_number <= ?"), append(args, f.ToBlock)
+	}
+	if f.Outcome != "" {
+		conds, args = append(conds, "outcome = ?"), append(args, f.Outcome)
+	}
+	if f.FromTime > 0 {
+		conds, args = append(conds, "received_at >= ?"), append(args, f.FromTime*1000)
+	}
+	if f.ToTime > 0 {
+		conds, args = append(conds, "received_at < ?"), append(args, (f.ToTime+1)*1000)
+	}
+
+	limit := f.Limit
+	if limit <= 0 {
+		limit = 100
+	} else if limit > MaxBidQueryLimit {
+		limit = MaxBidQueryLimit
+	}
+
+	// one more bid tells if there's a next page
+	bids, err := s.query(`SELECT `+bidColumns+` FROM bids WHERE `+strings.Join(conds, " AND ")+
+		` ORDER BY id LIMIT `+strconv.Itoa(limit+1), args...)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &BidPage{Bids: bids}
+	if len(bids) > limit {
+		page.Bids = bids[:limit]
+		page.NextCursor = bids[limit-1].ID
+	}
+	if f.WithArgs {
+		if err = s.withArgs(page.Bids); err != nil {
+			return nil, err
+		}
+	}
+	if page.Bids == nil {
+		page.Bids = []*Bid{}
+	}
+
+	return page, nil
+}
+
+// BuilderStats summarizes the bids of a builder over a window.
+type BuilderStats struct {
+	Builder common.Address `json:"builder"`
+	// Submitted bids, including the rejected ones
+	Submitted uint64 `json:"submitted"`
+	// Accepted bids by the validator, including the settled ones
+	Accepted uint64 `json:"accepted"`
+	Won      uint64 `json:"won"`
+	// FeesPaid total builder fee of the won bids, in wei
+	FeesPaid *big.Int `json:"feesPaid"`
+	// AvgGasFee average gas fee of the submitted bids, in wei
+	AvgGasFee *big.Int `json:"avgGasFee"`
+}
+
+// BuilderStats summarizes the bids of each builder received since the time, or only the given builder if not nil.
+func (s *BidStore) BuilderStats(since time.Time, builder *common.Address) ([]*BuilderStats, error) {
+	cond, args := "received_at >= ?", []interface{}{since.UnixMilli()}
+	if builder != nil {
+		cond, args = cond+" AND builder = ?", append(args, builder.Hex())
+	}
+
+	stats := make(map[common.Address]*BuilderStats)
+	get := func(address string) *BuilderStats {
+		addr := common.HexToAddress(address)
+		if _, ok := stats[addr]; !ok {
+			stats[addr] = &BuilderStats{Builder: addr, FeesPaid: new(big.Int), AvgGasFee: new(big.Int)}
+		}
+		return stats[addr]
+	}
+
+	// the fees are stored as decimal text to keep their precision, an approximate average is fine
+	gasFeeSums := make(map[common.Address]*big.Float)
+	err := s.each(`SELECT builder, outcome, COUNT(*), AVG(CAST(gas_fee AS DOUBLE PRECISION))
+		FROM bids WHERE `+cond+` GROUP BY builder, outcome`, args, func(rows *sql.Rows) error {
+		var (
+			address, outcome string
+			count            uint64
+			avgGasFee        float64
+		)
+		if err := rows.Scan(&address, &outcome, &count, &avgGasFee); err != nil {
+			return err
+		}
+
+		st := get(address)
+		st.Submitted += count
+		switch outcome {
+		case BidAccepted, BidLost, BidExpired:
+			st.Accepted += count
+		case BidWon:
+			st.Accepted += count
+			st.Won += count
+		}
+
+		if gasFeeSums[st.Builder] == nil {
+			gasFeeSums[st.Builder] = new(big.Float)
+		}
+		gasFeeSums[st.Builder].Add(gasFeeSums[st.Builder], new(big.Float).SetFloat64(avgGasFee*float64(count)))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for address, sum := range gasFeeSums {
+		st := stats[address]
+		sum.Quo(sum, new(big.Float).SetUint64(st.Submitted)).Int(st.AvgGasFee)
+	}
+
+	err = s.each(`SELECT builder, builder_fee FROM bids WHERE `+cond+` AND outcome = ?`, append(args, BidWon),
+		func(rows *sql.Rows) error {
+			var address, fee string
+			if err := rows.Scan(&address, &fee); err != nil {
+				return err
+			}
+
+			amount, err := parseBig(fee)
+			if err != nil {
+				return err
+			}
+
+			st := get(address)
+			st.FeesPaid.Add(st.FeesPaid, amount)
+			return nil
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*BuilderStats, 0, len(stats))
+	for _, st := range stats {
+		result = append(result, st)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return bytes.Compare(result[i].Builder[:], result[j].Builder[:]) < 0
+	})
+
+	return result, nil
+}
+
+// each calls fn on each row of the query, the rows are closed before it returns.
+func (s *BidStore) each(query string, args []interface{}, fn func(*sql.Rows) error) error {
+	rows, err := s.db.Query(s.dialect.rebind(query), args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err = fn(rows); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+func (s *BidStore) query(query string, args ...interface{}) ([]*Bid, error) {
+	rows, err := s.db.Query(s.dialect.rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var bids []*Bid
+	for rows.Next() {
+		var (
+			b                                                    Bid
+			hash, builder, parentHash, gasFee, bdrFee, payTxHash string
+			receivedAt                                           int64
+		)
+		if err = rows.Scan(&b.ID, &hash, &builder, &b.Validator, &b.BlockNumber, &parentHash, &b.GasUsed, &gasFee,
+			&bdrFee, &b.TxCount, &payTxHash, &receivedAt, &b.Outcome, &b.Reason, &b.Error); err != nil {
+			return nil, err
+		}
+
+		b.Hash, b.Builder, b.ParentHash = common.HexToHash(hash), common.HexToAddress(builder), common.HexToHash(parentHash)
+		b.PayTxHash = common.HexToHash(payTxHash)
+		b.ReceivedAt = time.UnixMilli(receivedAt)
+		if b.GasFee, err = parseBig(gasFee); err != nil {
+			return nil, err
+		}
+		if b.BuilderFee, err = parseBig(bdrFee); err != nil {
+			return nil, err
+		}
+
+		bids = append(bids, &b)
+	}
+
+	return bids, rows.Err()
+}
+
+// Close writes the queued bids and closes the database, later bids are dropped.
+func (s *BidStore) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	close(s.writes)
+	close(s.stop)
+	s.mu.Unlock()
+
+	<-s.done
+	<-s.pruned
+
+	return s.db.Close()
+}
+
+func bigString(x *big.Int) string {
+	if x == nil {
+		return "0"
+	}
+
+	return x.String()
+}
+
+func parseBig(s string) (*big.Int, error) {
+	x, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid amount %q", s)
+	}
+
+	return x, nil
+}
@@ -0,0 +1,387 @@
+package store
+
+import (
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/bsc-mev-sentry/metrics"
+	"github.com/bnb-chain/bsc-mev-sentry/utils"
+)
+
+func TestBidStore(t *testing.T) {
+	dsn := filepath.Join(t.TempDir(), "data", "bids.db")
+
+	s, err := OpenBidStore(BidStoreConfig{Driver: "sqlite", DSN: dsn}, nil)
+	require.NoError(t, err)
+
+	bid := &Bid{
+		Hash:        common.HexToHash("0x01"),
+		Builder:     common.HexToAddress("0x02"),
+		Validator:   "validator",
+		BlockNumber: 100,
+		ParentHash:  common.HexToHash("0x03"),
+		GasUsed:     21000,
+		GasFee:      new(big.Int).Exp(big.NewInt(10), big.NewInt(20), nil),
+		BuilderFee:  big.NewInt(1e15),
+		TxCount:     1,
+		ReceivedAt:  time.UnixMilli(1700000000123),
+		Outcome:     BidRejected,
+		Reason:      "fee_exceeds_ceiling",
+		Error:       "bid fee exceeds the ceiling",
+	}
+	s.Record(bid)
+	require.NoError(t, s.Close())
+
+	// records after close are dropped
+	s.Record(bid)
+
+	// reopen to make sure bids are persisted
+	s, err = OpenBidStore(BidStoreConfig{Driver: "sqlite", DSN: dsn}, nil)
+	require.NoError(t, err)
+	defer s.Close()
+
+	bids, err := s.ByHash(bid.Hash)
+	require.NoError(t, err)
+	require.Len(t, bids, 1)
+
+	bid.ID = 1
+	assert.Equal(t, bid, bids[0])
+}
+
+func TestRebind(t *testing.T) {
+	assert.Equal(t, "SELECT * FROM bids WHERE hash = ? AND id > ?",
+		dialects["sqlite"].rebind("SELECT * FROM bids WHERE hash = ? AND id > ?"))
+	assert.Equal(t, "SELECT * FROM bids WHERE hash = $1 AND id > $2",
+		dialects["postgres"].rebind("SELECT * FROM bids WHERE hash = ? AND id > ?"))
+}
+
+func TestBidStoreQuery(t *testing.T) {
+	dsn := filepath.Join(t.TempDir(), "bids.db")
+	s, err := OpenBidStore(BidStoreConfig{Driver: "sqlite", DSN: dsn}, nil)
+	require.NoError(t, err)
+
+	b1, b2 := common.HexToAddress("0x01"), common.HexToAddress("0x02")
+	for i := 0; i < 5; i++ {
+		outcome := BidAccepted
+		if i%2 == 1 {
+			outcome = BidRejected
+		}
+
+		for _, builder := range []common.Address{b1, b2} {
+			s.Record(&Bid{
+				Hash:        common.BigToHash(big.NewInt(int64(i))),
+				Builder:     builder,
+				Validator:   "validator",
+				BlockNumber: uint64(100 + i),
+				ReceivedAt:  time.Unix(int64(1700000000+i), 0),
+				Outcome:     outcome,
+			})
+		}
+	}
+
+	// wait for the background writes
+	require.NoError(t, s.Close())
+	s, err = OpenBidStore(BidStoreConfig{Driver: "sqlite", DSN: dsn}, nil)
+	require.NoError(t, err)
+	defer s.Close()
+
+	blocks := func(page *BidPage) []uint64 {
+		var numbers []uint64
+		for _, b := range page.Bids {
+			numbers = append(numbers, b.BlockNumber)
+		}
+		return numbers
+	}
+
+	page, err := s.Query(BidFilter{Builder: &b1, Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{100, 101}, blocks(page))
+	assert.NotZero(t, page.NextCursor)
+
+	page, err = s.Query(BidFilter{Builder: &b1, Limit: 2, Cursor: page.NextCursor})
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{102, 103}, blocks(page))
+
+	page, err = s.Query(BidFilter{Builder: &b1, Limit: 2, Cursor: page.NextCursor})
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{104}, blocks(page))
+	assert.Zero(t, page.NextCursor)
+
+	page, err = s.Query(BidFilter{Builder: &b2, FromBlock: 101, ToBlock: 103, Outcome: BidAccepted})
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{102}, blocks(page))
+
+	page, err = s.Query(BidFilter{FromTime: 1700000003, ToTime: 1700000003})
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{103, 103}, blocks(page))
+}
+
+func TestBidStoreSettleBlock(t *testing.T) {
+	dsn := filepath.Join(t.TempDir(), "bids.db")
+	s, err := OpenBidStore(BidStoreConfig{Driver: "sqlite", DSN: dsn}, nil)
+	require.NoError(t, err)
+
+	record := func(hash string, validator string, block uint64, outcome string) {
+		s.Record(&Bid{
+			Hash:        common.HexToHash(hash),
+			Validator:   validator,
+			BlockNumber: block,
+			PayTxHash:   common.HexToHash(hash + "ff"),
+			Outcome:     outcome,
+		})
+	}
+	record("0x01", "v1", 100, BidAccepted)
+	record("0x02", "v1", 100, BidAccepted)
+	record("0x03", "v1", 100, BidRejected)
+	record("0x04", "v2", 100, BidAccepted)
+	record("0x05", "v1", 101, BidAccepted)
+
+	s.SettleBlock("v1", 100, []common.Hash{common.HexToHash("0x0aff"), common.HexToHash("0x02ff")})
+	s.SettleBlock("v1", 101, nil)
+
+	require.NoError(t, s.Close())
+	s, err = OpenBidStore(BidStoreConfig{Driver: "sqlite", DSN: dsn}, nil)
+	require.NoError(t, err)
+	defer s.Close()
+
+	page, err := s.Query(BidFilter{})
+	require.NoError(t, err)
+
+	outcomes := make(map[common.Hash]string)
+	for _, b := range page.Bids {
+		outcomes[b.Hash] = b.Outcome
+	}
+	assert.Equal(t, map[common.Hash]string{
+		common.HexToHash("0x01"): BidLost,
+		common.HexToHash("0x02"): BidWon,
+		common.HexToHash("0x03"): BidRejected,
+		common.HexToHash("0x04"): BidAccepted,
+		common.HexToHash("0x05"): BidExpired,
+	}, outcomes)
+}
+
+func TestBidStoreResettle(t *testing.T) {
+	dsn := filepath.Join(t.TempDir(), "bids.db")
+	s, err := OpenBidStore(BidStoreConfig{Driver: "sqlite", DSN: dsn}, nil)
+	require.NoError(t, err)
+
+	for _, hash := range []string{"0x01", "0x02"} {
+		s.Record(&Bid{
+			Hash:        common.HexToHash(hash),
+			Validator:   "v1",
+			BlockNumber: 100,
+			PayTxHash:   common.HexToHash(hash + "ff"),
+			Outcome:     BidAccepted,
+		})
+	}
+
+	// the block is replaced by a reorg, the pay bid tx of another bid is included instead
+	s.SettleBlock("v1", 100, []common.Hash{common.HexToHash("0x01ff")})
+	s.SettleBlock("v1", 100, []common.Hash{common.HexToHash("0x02ff")})
+
+	require.NoError(t, s.Close())
+	s, err = OpenBidStore(BidStoreConfig{Driver: "sqlite", DSN: dsn}, nil)
+	require.NoError(t, err)
+	defer s.Close()
+
+	page, err := s.Query(BidFilter{})
+	require.NoError(t, err)
+
+	outcomes := make(map[common.Hash]string)
+	for _, b := range page.Bids {
+		outcomes[b.Hash] = b.Outcome
+	}
+	assert.Equal(t, map[common.Hash]string{
+		common.HexToHash("0x01"): BidLost,
+		common.HexToHash("0x02"): BidWon,
+	}, outcomes)
+	assert.Equal(t, 2.0, testutil.ToFloat64(metrics.BidStoreResettledCounter.WithLabelValues(BidWon, BidLost))+
+		testutil.ToFloat64(metrics.BidStoreResettledCounter.WithLabelValues(BidLost, BidWon)))
+}
+
+func TestBidStoreBuilderStats(t *testing.T) {
+	dsn := filepath.Join(t.TempDir(), "bids.db")
+	s, err := OpenBidStore(BidStoreConfig{Driver: "sqlite", DSN: dsn}, nil)
+	require.NoError(t, err)
+
+	b1, b2 := common.HexToAddress("0x01"), common.HexToAddress("0x02")
+	now := time.Now()
+	record := func(builder common.Address, outcome string, gasFee, builderFee int64, receivedAt time.Time) {
+		s.Record(&Bid{
+			Builder:    builder,
+			GasFee:     big.NewInt(gasFee),
+			BuilderFee: big.NewInt(builderFee),
+			ReceivedAt: receivedAt,
+			Outcome:    outcome,
+		})
+	}
+	record(b1, BidWon, 100, 10, now)
+	record(b1, BidWon, 200, 20, now)
+	record(b1, BidLost, 300, 30, now)
+	record(b1, BidRejected, 400, 40, now)
+	record(b1, BidWon, 1000, 1000, now.Add(-2*time.Hour))
+	record(b2, BidAccepted, 500, 50, now)
+
+	require.NoError(t, s.Close())
+	s, err = OpenBidStore(BidStoreConfig{Driver: "sqlite", DSN: dsn}, nil)
+	require.NoError(t, err)
+	defer s.Close()
+
+	stats, err := s.BuilderStats(now.Add(-time.Hour), nil)
+	require.NoError(t, err)
+	assert.Equal(t, []*BuilderStats{
+		{Builder: b1, Submitted: 4, Accepted: 3, Won: 2, FeesPaid: big.NewInt(30), AvgGasFee: big.NewInt(250)},
+		{Builder: b2, Submitted: 1, Accepted: 1, Won: 0, FeesPaid: big.NewInt(0), AvgGasFee: big.NewInt(500)},
+	}, stats)
+
+	stats, err = s.BuilderStats(now.Add(-3*time.Hour), &b1)
+	require.NoError(t, err)
+	require.Len(t, stats, 1)
+	assert.Equal(t, uint64(5), stats[0].Submitted)
+	assert.Equal(t, big.NewInt(1030), stats[0].FeesPaid)
+}
+
+func TestBidStoreSealed(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "fuji.key")
+	require.NoError(t, os.WriteFile(keyFile, []byte(strings.Repeat("ab", 32)), 0600))
+	keyring, err := LoadKeyring(map[string]string{"fuji": keyFile})
+	require.NoError(t, err)
+
+	s, err := OpenBidStore(BidStoreConfig{Driver: "sqlite", DSN: filepath.Join(t.TempDir(), "bids.db"),
+		KeepArgs: true}, keyring)
+	require.NoError(t, err)
+	defer s.Close()
+
+	args := json.RawMessage(`{"rawBid":{"txs":["0x01"]}}`)
+	s.Record(&Bid{Hash: common.HexToHash("0x01"), Validator: "fuji", ReceivedAt: time.Now(), Args: args})
+	s.Record(&Bid{Hash: common.HexToHash("0x02"), Validator: "other", ReceivedAt: time.Now(), Args: args})
+
+	require.Eventually(t, func() bool {
+		page, err := s.Query(BidFilter{WithArgs: true})
+		return err == nil && len(page.Bids) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	var stored string
+	require.NoError(t, s.db.QueryRow(`SELECT args FROM bid_args WHERE id = 1`).Scan(&stored))
+	assert.NotContains(t, stored, "rawBid", "the args of a validator with a key are encrypted")
+
+	page, err := s.Query(BidFilter{WithArgs: true})
+	require.NoError(t, err)
+	assert.JSONEq(t, string(args), string(page.Bids[0].Args))
+	assert.JSONEq(t, string(args), string(page.Bids[1].Args))
+
+	// so are the messages of the issues reported by the validator
+	s.RecordIssue(&Issue{Tenant: "fuji", Message: "invalid bid", ReportedAt: time.Now(), Status: IssueFailed,
+		Error: "connection refused"})
+	require.Eventually(t, func() bool {
+		page, err := s.QueryIssues(IssueFilter{})
+		return err == nil && len(page.Issues) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, s.db.QueryRow(`SELECT message FROM issues WHERE id = 1`).Scan(&stored))
+	assert.NotContains(t, stored, "invalid bid")
+
+	issues, err := s.QueryIssues(IssueFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, "invalid bid", issues.Issues[0].Message)
+	assert.Equal(t, "connection refused", issues.Issues[0].Error)
+}
+
+func TestBidStoreIssues(t *testing.T) {
+	s, err := OpenBidStore(BidStoreConfig{Driver: "sqlite", DSN: filepath.Join(t.TempDir(), "bids.db")}, nil)
+	require.NoError(t, err)
+	defer s.Close()
+
+	b1, b2 := common.HexToAddress("0x01"), common.HexToAddress("0x02")
+	v1, v2 := common.HexToAddress("0x11"), common.HexToAddress("0x12")
+	now := time.Now()
+
+	s.RecordIssue(&Issue{Validator: v1, Builder: b1, Message: "invalid bid", ReportedAt: now, Status: IssueDelivered})
+	s.RecordIssue(&Issue{Validator: v2, Builder: b1, Message: "invalid bid", ReportedAt: now, Status: IssueFailed,
+		Error: "connection refused"})
+	s.RecordIssue(&Issue{Validator: v1, Builder: b2, Message: "invalid bid", ReportedAt: now, Status: IssueQueued})
+	s.RecordIssue(&Issue{Validator: v1, Builder: b1, Message: "old", ReportedAt: now.Add(-48 * time.Hour),
+		Status: IssueDelivered})
+
+	require.Eventually(t, func() bool {
+		page, err := s.QueryIssues(IssueFilter{})
+		return err == nil && len(page.Issues) == 4
+	}, 5*time.Second, 10*time.Millisecond)
+
+	page, err := s.QueryIssues(IssueFilter{Builder: &b1, Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, page.Issues, 2)
+	require.NotZero(t, page.NextCursor)
+	require.Equal(t, "connection refused", page.Issues[1].Error)
+
+	page, err = s.QueryIssues(IssueFilter{Builder: &b1, Cursor: page.NextCursor})
+	require.NoError(t, err)
+	require.Len(t, page.Issues, 1)
+	require.Zero(t, page.NextCursor)
+
+	stats, err := s.IssueStats(now.Add(-24*time.Hour), nil)
+	require.NoError(t, err)
+	require.Len(t, stats, 2)
+	require.Equal(t, &IssueStats{Builder: b1, Reported: 2, Delivered: 1, Failed: 1,
+		Validators: map[common.Address]uint64{v1: 1, v2: 1}}, stats[0])
+	require.Equal(t, uint64(1), stats[1].Reported)
+	require.Zero(t, stats[1].Delivered)
+
+	// the queued issue fails once its batch is delivered
+	s.UpdateIssue(&Issue{Validator: v1, Builder: b2, Status: IssueFailed, Error: "timeout"})
+	require.Eventually(t, func() bool {
+		page, err := s.QueryIssues(IssueFilter{Builder: &b2})
+		return err == nil && len(page.Issues) == 1 && page.Issues[0].Status == IssueFailed
+	}, 5*time.Second, 10*time.Millisecond)
+
+	page, err = s.QueryIssues(IssueFilter{Builder: &b2})
+	require.NoError(t, err)
+	require.Equal(t, "timeout", page.Issues[0].Error)
+}
+
+func TestBidStorePrune(t *testing.T) {
+	s, err := OpenBidStore(BidStoreConfig{Driver: "sqlite", DSN: filepath.Join(t.TempDir(), "bids.db"),
+		MaxAge: utils.Duration(time.Hour), MaxRows: 6}, nil)
+	require.NoError(t, err)
+	defer s.Close()
+
+	now := time.Unix(1700000000, 0)
+	for i := 0; i < 10; i++ {
+		// the first two are past the max age
+		receivedAt := now.Add(-2 * time.Hour)
+		if i >= 2 {
+			receivedAt = now
+		}
+		s.Record(&Bid{Hash: common.BigToHash(big.NewInt(int64(i))), ReceivedAt: receivedAt, Outcome: BidWon})
+	}
+	require.Eventually(t, func() bool {
+		bids, err := s.BidsAfter(0, now.Add(time.Second), 100)
+		return err == nil && len(bids) == 10
+	}, time.Second, 10*time.Millisecond)
+
+	// unexported bids are kept
+	s.KeepUnexported("archive")
+	require.NoError(t, s.SetExportCursor("archive", 1))
+	require.NoError(t, s.Prune(now))
+	bids, err := s.BidsAfter(0, now.Add(time.Second), 100)
+	require.NoError(t, err)
+	assert.Len(t, bids, 9)
+
+	// the bids past the max age, then the oldest beyond the max rows
+	require.NoError(t, s.SetExportCursor("archive", 10))
+	require.NoError(t, s.Prune(now))
+	bids, err = s.BidsAfter(0, now.Add(time.Second), 100)
+	require.NoError(t, err)
+	require.Len(t, bids, 6)
+	assert.Equal(t, int64(5), bids[0].ID)
+}
@@ -0,0 +1,76 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/bnb-chain/bsc-mev-sentry/log"
+)
+
+// ExportCursor returns the id of the last bid exported by the named exporter, zero if it hasn't exported any.
+func (s *BidStore) ExportCursor(name string) (int64, error) {
+	var id int64
+	err := s.db.QueryRow(s.dialect.rebind(`SELECT last_id FROM export_cursors WHERE name = ?`), name).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+
+	return id, err
+}
+
+// SetExportCursor records the id of the last bid exported by the named exporter.
+func (s *BidStore) SetExportCursor(name string, id int64) error {
+	_, err := s.db.Exec(s.dialect.rebind(`INSERT INTO export_cursors (name, last_id) VALUES (?, ?)
+		ON CONFLICT (name) DO UPDATE SET last_id = excluded.last_id`), name, id)
+	return err
+}
+
+// BidsAfter returns at most limit bids following the cursor in the order received, received before the time, along
+// with their args if they're kept.
+func (s *BidStore) BidsAfter(cursor int64, before time.Time, limit int) ([]*Bid, error) {
+	bids, err := s.query(`SELECT `+bidColumns+` FROM bids WHERE id > ? AND received_at < ? ORDER BY id LIMIT `+
+		strconv.Itoa(limit), cursor, before.UnixMilli())
+	if err != nil {
+		return nil, err
+	}
+
+	return bids, s.withArgs(bids)
+}
+
+// withArgs sets the args of the bids in the order received, if they're kept.
+func (s *BidStore) withArgs(bids []*Bid) error {
+	if len(bids) == 0 {
+		return nil
+	}
+
+	byID := make(map[int64]*Bid, len(bids))
+	for _, b := range bids {
+		byID[b.ID] = b
+	}
+
+	return s.each(`SELECT id, args FROM bid_args WHERE id >= ? AND id <= ?`,
+		[]interface{}{bids[0].ID, bids[len(bids)-1].ID}, func(rows *sql.Rows) error {
+			var (
+				id   int64
+				args string
+			)
+			if err := rows.Scan(&id, &args); err != nil {
+				return err
+			}
+			b, ok := byID[id]
+			if !ok {
+				return nil
+			}
+			opened, err := s.keyring.openText(args)
+			if err != nil {
+				// e.g. the key of the validator is gone, the bid is still returned
+				log.Errorw("failed to decrypt bid args", "id", id, "validator", b.Validator, "err", err)
+				return nil
+			}
+			b.Args = json.RawMessage(opened)
+			return nil
+		})
+}
@@ -0,0 +1,252 @@
+package store
+
+import (
+	"bytes"
+	"database/sql"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bnb-chain/bsc-mev-sentry/log"
+)
+
+// statuses of an issue reported to a builder
+const (
+	IssueDelivered = "delivered"
+	// IssueQueued the issue is batched with others, it's delivered or failed once the batch is delivered
+	IssueQueued = "queued"
+	IssueFailed = "failed"
+)
+
+// Issue is an issue of a bid reported by a validator and forwarded to the builder.
+type Issue struct {
+	ID         int64          `json:"id"`
+	Validator  common.Address `json:"validator"`
+	Builder    common.Address `json:"builder"`
+	BidHash    common.Hash    `json:"bidHash"`
+	Message    string         `json:"message"`
+	ReportedAt time.Time      `json:"reportedAt"`
+	Status     string         `json:"status"`
+	Error      string         `json:"error,omitempty"`
+	// Tenant the public hostname of the validator, whose key encrypts the message and the error, not stored
+	Tenant string `json:"-"`
+}
+
+// RecordIssue queues the issue to be written, it's dropped if the database falls behind.
+func (s *BidStore) RecordIssue(issue *Issue) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.closed {
+		return
+	}
+
+	select {
+	case s.writes <- bidWrite{issue: issue}:
+	default:
+		log.Errorw("bid store is full, drop issue", "builder", issue.Builder, "bidHash", issue.BidHash)
+	}
+}
+
+// UpdateIssue queues setting the status and error of the queued issue of the same builder, validator and bid once its
+// batch is delivered, it's dropped if the database falls behind. It's written after the issues recorded earlier.
+func (s *BidStore) UpdateIssue(issue *Issue) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.closed {
+		return
+	}
+
+	select {
+	case s.writes <- bidWrite{issueUpdate: issue}:
+	default:
+		log.Errorw("bid store is full, drop issue update", "builder", issue.Builder, "bidHash", issue.BidHash)
+	}
+}
+
+func (s *BidStore) updateIssue(tx *sql.Tx, i *Issue) error {
+	issueErr, err := s.keyring.sealText(i.Tenant, i.Error)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(s.dialect.rebind(`UPDATE issues SET status = ?, error = ?
+		WHERE builder = ? AND validator = ? AND bid_hash = ? AND status = ?`), i.Status, issueErr, i.Builder.Hex(),
+		i.Validator.Hex(), i.BidHash.Hex(), IssueQueued)
+	return err
+}
+
+func (s *BidStore) insertIssue(tx *sql.Tx, i *Issue) error {
+	message, err := s.keyring.sealText(i.Tenant, i.Message)
+	if err != nil {
+		return err
+	}
+	issueErr, err := s.keyring.sealText(i.Tenant, i.Error)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(s.dialect.rebind(`INSERT INTO issues (validator, builder, bid_hash, message, reported_at,
+		status, error) VALUES (?, ?, ?, ?, ?, ?, ?)`), i.Validator.Hex(), i.Builder.Hex(), i.BidHash.Hex(), message,
+		i.ReportedAt.UnixMilli(), i.Status, issueErr)
+	return err
+}
+
+// IssueFilter selects issues, zero fields don't filter. Issues are returned in the order reported,
+// pass NextCursor of the last page as Cursor to get the next one.
+type IssueFilter struct {
+	Builder   *common.Address `json:"builder,omitempty"`
+	Validator *common.Address `json:"validator,omitempty"`
+	Status    string          `json:"status,omitempty"`
+	// FromTime and ToTime unix seconds of when issues are reported, inclusive
+	FromTime int64 `json:"fromTime,omitempty"`
+	ToTime   int64 `json:"toTime,omitempty"`
+	Cursor   int64 `json:"cursor,omitempty"`
+	// Limit of issues returned, defaults to 100 and at most MaxBidQueryLimit
+	Limit int `json:"limit,omitempty"`
+}
+
+// IssuePage is a page of issues, NextCursor is zero on the last page.
+type IssuePage struct {
+	Issues     []*Issue `json:"issues"`
+	NextCursor int64    `json:"nextCursor,omitempty"`
+}
+
+// QueryIssues returns a page of the issues selected by the filter.
+func (s *BidStore) QueryIssues(f IssueFilter) (*IssuePage, error) {
+	var (
+		conds = []string{"id > ?"}
+		args  = []interface{}{f.Cursor}
+	)
+	if f.Builder != nil {
+		conds, args = append(conds, "builder = ?"), append(args, f.Builder.Hex())
+	}
+	if f.Validator != nil {
+		conds, args = append(conds, "validator = ?"), append(args, f.Validator.Hex())
+	}
+	if f.Status != "" {
+		conds, args = append(conds, "status = ?"), append(args, f.Status)
+	}
+	if f.FromTime > 0 {
+		conds, args = append(conds, "reported_at >= ?"), append(args, f.FromTime*1000)
+	}
+	if f.ToTime > 0 {
+		conds, args = append(conds, "reported_at < ?"), append(args, (f.ToTime+1)*1000)
+	}
+
+	limit := f.Limit
+	if limit <= 0 {
+		limit = 100
+	} else if limit > MaxBidQueryLimit {
+		limit = MaxBidQueryLimit
+	}
+
+	page := &IssuePage{Issues: []*Issue{}}
+
+	// one more issue tells if there's a next page
+	err := s.each(`SELECT id, validator, builder, bid_hash, message, reported_at, status, error FROM issues
+		WHERE `+strings.Join(conds, " AND ")+` ORDER BY id LIMIT `+strconv.Itoa(limit+1), args,
+		func(rows *sql.Rows) error {
+			var (
+				i                           Issue
+				validator, builder, bidHash string
+				reportedAt                  int64
+			)
+			if err := rows.Scan(&i.ID, &validator, &builder, &bidHash, &i.Message, &reportedAt, &i.Status,
+				&i.Error); err != nil {
+				return err
+			}
+
+			i.Validator, i.Builder = common.HexToAddress(validator), common.HexToAddress(builder)
+			i.BidHash, i.ReportedAt = common.HexToHash(bidHash), time.UnixMilli(reportedAt)
+			// e.g. the key of the validator is gone, the issue is still returned as stored
+			if message, err := s.keyring.openText(i.Message); err == nil {
+				i.Message = message
+			} else {
+				log.Errorw("failed to decrypt issue message", "id", i.ID, "err", err)
+			}
+			if issueErr, err := s.keyring.openText(i.Error); err == nil {
+				i.Error = issueErr
+			} else {
+				log.Errorw("failed to decrypt issue error", "id", i.ID, "err", err)
+			}
+			page.Issues = append(page.Issues, &i)
+			return nil
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(page.Issues) > limit {
+		page.Issues = page.Issues[:limit]
+		page.NextCursor = page.Issues[limit-1].ID
+	}
+
+	return page, nil
+}
+
+// IssueStats summarizes the issues reported to a builder over a window.
+type IssueStats struct {
+	Builder  common.Address `json:"builder"`
+	Reported uint64         `json:"reported"`
+	// Delivered issues the builder acknowledged, batched ones are only counted as reported
+	Delivered uint64 `json:"delivered"`
+	Failed    uint64 `json:"failed"`
+	// Validators number of issues by the address of the reporting validator
+	Validators map[common.Address]uint64 `json:"validators"`
+}
+
+// IssueStats summarizes the issues of each builder reported since the time, or only the given builder if not nil.
+func (s *BidStore) IssueStats(since time.Time, builder *common.Address) ([]*IssueStats, error) {
+	cond, args := "reported_at >= ?", []interface{}{since.UnixMilli()}
+	if builder != nil {
+		cond, args = cond+" AND builder = ?", append(args, builder.Hex())
+	}
+
+	stats := make(map[common.Address]*IssueStats)
+	err := s.each(`SELECT builder, validator, status, COUNT(*) FROM issues WHERE `+cond+
+		` GROUP BY builder, validator, status`, args, func(rows *sql.Rows) error {
+		var (
+			address, validator, status string
+			count                      uint64
+		)
+		if err := rows.Scan(&address, &validator, &status, &count); err != nil {
+			return err
+		}
+
+		addr := common.HexToAddress(address)
+		st, ok := stats[addr]
+		if !ok {
+			st = &IssueStats{Builder: addr, Validators: make(map[common.Address]uint64)}
+			stats[addr] = st
+		}
+
+		st.Reported += count
+		st.Validators[common.HexToAddress(validator)] += count
+		switch status {
+		case IssueDelivered:
+			st.Delivered += count
+		case IssueFailed:
+			st.Failed += count
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*IssueStats, 0, len(stats))
+	for _, st := range stats {
+		result = append(result, st)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return bytes.Compare(result[i].Builder[:], result[j].Builder[:]) < 0
+	})
+
+	return result, nil
+}
@@ -0,0 +1,139 @@
+package store
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// sealedMagic prefixes values encrypted by a keyring, values written in plaintext are json and never start with it.
+const sealedMagic = 0x01
+
+// sealedTextPrefix prefixes the values sealed into text columns, which are base64 encoded
+const sealedTextPrefix = "sealed:"
+
+// Keyring encrypts persisted values at rest with the key of the tenant, i.e. validator, they belong to,
+// so that the values of a tenant can't be read with the key of another.
+type Keyring struct {
+	aeads map[string]cipher.AEAD
+}
+
+// LoadKeyring loads the hex encoded 32 bytes AES keys from the files of each tenant.
+func LoadKeyring(keyFiles map[string]string) (*Keyring, error) {
+	k := &Keyring{aeads: make(map[string]cipher.AEAD, len(keyFiles))}
+
+	for tenant, file := range keyFiles {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		key, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(string(data)), "0x"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid key of tenant %s: %w", tenant, err)
+		}
+
+		if len(key) != 32 {
+			return nil, fmt.Errorf("key of tenant %s must be 32 bytes", tenant)
+		}
+
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+
+		k.aeads[tenant] = aead
+	}
+
+	return k, nil
+}
+
+// seal encrypts the value with the key of the tenant, the value is kept in plaintext if the tenant has no key.
+func (k *Keyring) seal(tenant string, value []byte) ([]byte, error) {
+	if k == nil || k.aeads[tenant] == nil {
+		return value, nil
+	}
+
+	if len(tenant) > 255 {
+		return nil, errors.New("tenant name too long")
+	}
+
+	aead := k.aeads[tenant]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	// magic | tenant length | tenant | nonce | ciphertext
+	sealed := make([]byte, 0, 2+len(tenant)+len(nonce)+len(value)+aead.Overhead())
+	sealed = append(sealed, sealedMagic, byte(len(tenant)))
+	sealed = append(sealed, tenant...)
+	sealed = append(sealed, nonce...)
+
+	return aead.Seal(sealed, nonce, value, []byte(tenant)), nil
+}
+
+// open decrypts the value sealed by seal, plaintext values are returned as is.
+func (k *Keyring) open(value []byte) ([]byte, error) {
+	if len(value) == 0 || value[0] != sealedMagic {
+		return value, nil
+	}
+
+	if len(value) < 2 || len(value) < 2+int(value[1]) {
+		return nil, errors.New("malformed sealed value")
+	}
+
+	tenant := string(value[2 : 2+int(value[1])])
+	if k == nil || k.aeads[tenant] == nil {
+		return nil, fmt.Errorf("no key of tenant %s", tenant)
+	}
+
+	aead := k.aeads[tenant]
+	rest := value[2+len(tenant):]
+	if len(rest) < aead.NonceSize() {
+		return nil, errors.New("malformed sealed value")
+	}
+
+	return aead.Open(nil, rest[:aead.NonceSize()], rest[aead.NonceSize():], []byte(tenant))
+}
+
+// sealText seals the value of a text column with the key of the tenant, see seal.
+func (k *Keyring) sealText(tenant, value string) (string, error) {
+	if value == "" {
+		return value, nil
+	}
+
+	sealed, err := k.seal(tenant, []byte(value))
+	if err != nil || sealed[0] != sealedMagic {
+		return string(sealed), err
+	}
+
+	return sealedTextPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// openText decrypts the value sealed by sealText, plaintext values are returned as is.
+func (k *Keyring) openText(value string) (string, error) {
+	encoded, ok := strings.CutPrefix(value, sealedTextPrefix)
+	if !ok {
+		return value, nil
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", err
+	}
+
+	opened, err := k.open(sealed)
+	return string(opened), err
+}
@@ -0,0 +1,49 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyring(t *testing.T) {
+	dir := t.TempDir()
+	fujiKey := filepath.Join(dir, "fuji.key")
+	otherKey := filepath.Join(dir, "other.key")
+	require.NoError(t, os.WriteFile(fujiKey, []byte("0x000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f\n"), 0600))
+	require.NoError(t, os.WriteFile(otherKey, []byte("1f1e1d1c1b1a191817161514131211100f0e0d0c0b0a09080706050403020100"), 0600))
+
+	k, err := LoadKeyring(map[string]string{"fuji": fujiKey, "other": otherKey})
+	require.NoError(t, err)
+
+	value := []byte(`{"builder":"0x01"}`)
+	sealed, err := k.seal("fuji", value)
+	require.NoError(t, err)
+	assert.NotContains(t, string(sealed), "builder")
+
+	opened, err := k.open(sealed)
+	require.NoError(t, err)
+	assert.Equal(t, value, opened)
+
+	// the sealed value can't be opened under another tenant
+	tampered := append([]byte{}, sealed...)
+	copy(tampered[2:], "othe")
+	tampered[1] = 4
+	_, err = k.open(tampered)
+	assert.Error(t, err)
+
+	// tenants without key are kept in plaintext
+	plain, err := k.seal("unknown", value)
+	require.NoError(t, err)
+	assert.Equal(t, value, plain)
+
+	// sealed values can't be opened without the key
+	_, err = (*Keyring)(nil).open(sealed)
+	assert.Error(t, err)
+
+	_, err = LoadKeyring(map[string]string{"fuji": filepath.Join(dir, "missing")})
+	assert.Error(t, err)
+}
@@ -0,0 +1,64 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/bnb-chain/bsc-mev-sentry/log"
+)
+
+// migrate applies the migrations of the dialect the database hasn't applied yet, each in a transaction along with
+// recording its version, so a failed one is applied again on the next start. The first migration only creates what
+// doesn't exist, so a database created before the schema was versioned is taken as version 1. A database migrated
+// by a newer sentry is refused rather than written with a schema the sentry doesn't know.
+func migrate(db *sql.DB, d dialect) error {
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		applied_at BIGINT NOT NULL
+	)`); err != nil {
+		return err
+	}
+
+	var current int
+	if err := db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&current); err != nil {
+		return err
+	}
+
+	if current > len(d.migrations) {
+		return fmt.Errorf("schema version %d is newer than %d, the latest this sentry knows", current,
+			len(d.migrations))
+	}
+
+	for version := current + 1; version <= len(d.migrations); version++ {
+		if err := applyMigration(db, d, version); err != nil {
+			return fmt.Errorf("migration %d, %w", version, err)
+		}
+
+		log.Infow("bid store schema migrated", "version", version)
+	}
+
+	return nil
+}
+
+func applyMigration(db *sql.DB, d dialect, version int) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	for _, stmt := range d.migrations[version-1] {
+		if _, err = tx.Exec(stmt); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+
+	if _, err = tx.Exec(d.rebind(`INSERT INTO schema_migrations (version, applied_at) VALUES (?, ?)`), version,
+		time.Now().UnixMilli()); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
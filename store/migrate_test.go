@@ -0,0 +1,49 @@
+package store
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrate(t *testing.T) {
+	dsn := filepath.Join(t.TempDir(), "bids.db")
+	d := dialects["sqlite"]
+
+	db, err := sql.Open(d.driver, dsn)
+	require.NoError(t, err)
+	defer db.Close()
+
+	// a database created before the schema was versioned
+	for _, stmt := range d.migrations[0] {
+		_, err = db.Exec(stmt)
+		require.NoError(t, err)
+	}
+
+	version := func() (v int) {
+		require.NoError(t, db.QueryRow(`SELECT MAX(version) FROM schema_migrations`).Scan(&v))
+		return v
+	}
+
+	require.NoError(t, migrate(db, d))
+	require.Equal(t, len(d.migrations), version())
+
+	// applied once
+	require.NoError(t, migrate(db, d))
+	var count int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM schema_migrations`).Scan(&count))
+	require.Equal(t, len(d.migrations), count)
+
+	// a migration the sentry doesn't know yet is applied
+	d.migrations = append(d.migrations[:len(d.migrations):len(d.migrations)],
+		[]string{`ALTER TABLE issues ADD COLUMN note TEXT NOT NULL DEFAULT ''`})
+	require.NoError(t, migrate(db, d))
+	require.Equal(t, len(d.migrations), version())
+	_, err = db.Exec(`UPDATE issues SET note = 'migrated'`)
+	require.NoError(t, err)
+
+	// a database migrated by a newer sentry is refused
+	require.Error(t, migrate(db, dialects["sqlite"]))
+}
@@ -0,0 +1,101 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+var ErrNotFound = errors.New("not found")
+
+var (
+	paymentBidPrefix = []byte("pb") // bid hash -> payment
+	paymentTxPrefix  = []byte("pt") // pay bid tx hash -> bid hash
+)
+
+func storeKey(prefix, key []byte) []byte {
+	k := make([]byte, 0, len(prefix)+len(key))
+	return append(append(k, prefix...), key...)
+}
+
+// Payment is the evidence of a pay bid tx signed by the sentry for a bid.
+type Payment struct {
+	BidHash   common.Hash    `json:"bidHash"`
+	TxHash    common.Hash    `json:"txHash"`
+	Nonce     uint64         `json:"nonce"`
+	Amount    *big.Int       `json:"amount"`
+	Builder   common.Address `json:"builder"`
+	Validator string         `json:"validator"`
+	Time      int64          `json:"time"`
+}
+
+// PaymentStore durably maps bids to the pay bid txs paying for them, lookup by either hash.
+// Payments are encrypted with the key of their validator if the keyring has one.
+type PaymentStore struct {
+	db      *leveldb.DB
+	keyring *Keyring
+}
+
+func OpenPaymentStore(path string, keyring *Keyring) (*PaymentStore, error) {
+	db, err := leveldb.OpenFile(path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PaymentStore{db: db, keyring: keyring}, nil
+}
+
+func (s *PaymentStore) Put(p *Payment) error {
+	value, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+
+	if value, err = s.keyring.seal(p.Validator, value); err != nil {
+		return err
+	}
+
+	batch := new(leveldb.Batch)
+	batch.Put(storeKey(paymentBidPrefix, p.BidHash.Bytes()), value)
+	batch.Put(storeKey(paymentTxPrefix, p.TxHash.Bytes()), p.BidHash.Bytes())
+
+	return s.db.Write(batch, nil)
+}
+
+func (s *PaymentStore) ByBid(bidHash common.Hash) (*Payment, error) {
+	value, err := s.db.Get(storeKey(paymentBidPrefix, bidHash.Bytes()), nil)
+	if errors.Is(err, leveldb.ErrNotFound) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	if value, err = s.keyring.open(value); err != nil {
+		return nil, err
+	}
+
+	p := new(Payment)
+	if err = json.Unmarshal(value, p); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+func (s *PaymentStore) ByTx(txHash common.Hash) (*Payment, error) {
+	bidHash, err := s.db.Get(storeKey(paymentTxPrefix, txHash.Bytes()), nil)
+	if errors.Is(err, leveldb.ErrNotFound) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return s.ByBid(common.BytesToHash(bidHash))
+}
+
+func (s *PaymentStore) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,45 @@
+package store
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaymentStore(t *testing.T) {
+	path := t.TempDir()
+
+	s, err := OpenPaymentStore(path, nil)
+	require.NoError(t, err)
+
+	p := &Payment{
+		BidHash:   common.HexToHash("0x01"),
+		TxHash:    common.HexToHash("0x02"),
+		Nonce:     7,
+		Amount:    big.NewInt(100),
+		Builder:   common.HexToAddress("0x03"),
+		Validator: "validator",
+		Time:      1700000000,
+	}
+	require.NoError(t, s.Put(p))
+	require.NoError(t, s.Close())
+
+	// reopen to make sure payments are persisted
+	s, err = OpenPaymentStore(path, nil)
+	require.NoError(t, err)
+	defer s.Close()
+
+	got, err := s.ByBid(p.BidHash)
+	require.NoError(t, err)
+	assert.Equal(t, p, got)
+
+	got, err = s.ByTx(p.TxHash)
+	require.NoError(t, err)
+	assert.Equal(t, p, got)
+
+	_, err = s.ByBid(common.HexToHash("0x04"))
+	assert.ErrorIs(t, err, ErrNotFound)
+}
@@ -0,0 +1,185 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ValidatorRegistration is a validator registered at runtime by its operator.
+type ValidatorRegistration struct {
+	Operator          common.Address `json:"operator"`
+	PublicHostName    string         `json:"publicHostName"`
+	PrivateURL        string         `json:"privateURL"`
+	PayAccountAddress string         `json:"payAccountAddress"`
+	KeystorePath      string         `json:"keystorePath"`
+	PasswordFilePath  string         `json:"passwordFilePath"`
+	RegisteredAt      time.Time      `json:"registeredAt"`
+}
+
+// ErrNonceUsed is returned for a nonce not above the last one used by the operator.
+var ErrNonceUsed = errors.New("nonce already used")
+
+// RegistrationStore persists the validator registrations, along with the last nonce used by each operator, to a JSON
+// file, which is rewritten on every change.
+type RegistrationStore struct {
+	path string
+
+	mu     sync.Mutex
+	regs   map[string]ValidatorRegistration // public hostname -> registration
+	nonces map[common.Address]uint64        // operator -> last nonce used
+}
+
+type registrationFile struct {
+	Registrations []ValidatorRegistration   `json:"registrations"`
+	Nonces        map[common.Address]uint64 `json:"nonces"`
+}
+
+func OpenRegistrationStore(path string) (*RegistrationStore, error) {
+	s := &RegistrationStore{
+		path:   path,
+		regs:   make(map[string]ValidatorRegistration),
+		nonces: make(map[common.Address]uint64),
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, os.MkdirAll(filepath.Dir(path), 0700)
+	} else if err != nil {
+		return nil, err
+	}
+
+	var file registrationFile
+	// the registrations were stored as a bare list before the nonces
+	if len(data) > 0 && data[0] == '[' {
+		err = json.Unmarshal(data, &file.Registrations)
+	} else {
+		err = json.Unmarshal(data, &file)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, reg := range file.Registrations {
+		s.regs[reg.PublicHostName] = reg
+	}
+	for operator, nonce := range file.Nonces {
+		s.nonces[operator] = nonce
+	}
+
+	return s, nil
+}
+
+// UseNonce records the nonce of the operator, which must be above the last one it used, so that its signed requests
+// can't be replayed.
+func (s *RegistrationStore) UseNonce(operator common.Address, nonce uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	last, used := s.nonces[operator]
+	if used && nonce <= last {
+		return ErrNonceUsed
+	}
+
+	s.nonces[operator] = nonce
+
+	if err := s.save(); err != nil {
+		if used {
+			s.nonces[operator] = last
+		} else {
+			delete(s.nonces, operator)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// All returns the registrations ordered by public hostname.
+func (s *RegistrationStore) All() []ValidatorRegistration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.sorted()
+}
+
+func (s *RegistrationStore) sorted() []ValidatorRegistration {
+	regs := make([]ValidatorRegistration, 0, len(s.regs))
+	for _, reg := range s.regs {
+		regs = append(regs, reg)
+	}
+
+	sort.Slice(regs, func(i, j int) bool {
+		return regs[i].PublicHostName < regs[j].PublicHostName
+	})
+
+	return regs
+}
+
+func (s *RegistrationStore) Get(hostname string) (ValidatorRegistration, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	reg, ok := s.regs[hostname]
+	return reg, ok
+}
+
+// Put adds the registration, replacing the one of the same public hostname.
+func (s *RegistrationStore) Put(reg ValidatorRegistration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old, existed := s.regs[reg.PublicHostName]
+	s.regs[reg.PublicHostName] = reg
+
+	if err := s.save(); err != nil {
+		if existed {
+			s.regs[reg.PublicHostName] = old
+		} else {
+			delete(s.regs, reg.PublicHostName)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (s *RegistrationStore) Delete(hostname string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old, ok := s.regs[hostname]
+	if !ok {
+		return ErrNotFound
+	}
+
+	delete(s.regs, hostname)
+
+	if err := s.save(); err != nil {
+		s.regs[hostname] = old
+		return err
+	}
+
+	return nil
+}
+
+// save writes the registrations to a temporary file renamed over the store, so a crash never leaves it partial.
+func (s *RegistrationStore) save() error {
+	data, err := json.MarshalIndent(registrationFile{Registrations: s.sorted(), Nonces: s.nonces}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := s.path + ".tmp"
+	if err = os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, s.path)
+}
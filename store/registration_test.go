@@ -0,0 +1,51 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistrationStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", "registrations.json")
+
+	s, err := OpenRegistrationStore(path)
+	require.NoError(t, err)
+	assert.Empty(t, s.All())
+
+	reg := ValidatorRegistration{
+		Operator:          common.HexToAddress("0x01"),
+		PublicHostName:    "validator",
+		PrivateURL:        "http://127.0.0.1:8545",
+		PayAccountAddress: common.HexToAddress("0x02").Hex(),
+		KeystorePath:      "./keystore",
+		PasswordFilePath:  "./password.txt",
+		RegisteredAt:      time.Unix(1700000000, 0).UTC(),
+	}
+	require.NoError(t, s.Put(reg))
+
+	// reopen to make sure registrations are persisted
+	s, err = OpenRegistrationStore(path)
+	require.NoError(t, err)
+	assert.Equal(t, []ValidatorRegistration{reg}, s.All())
+
+	got, ok := s.Get(reg.PublicHostName)
+	require.True(t, ok)
+	assert.Equal(t, reg, got)
+
+	require.NoError(t, s.UseNonce(reg.Operator, 1))
+	assert.ErrorIs(t, s.UseNonce(reg.Operator, 1), ErrNonceUsed)
+
+	require.NoError(t, s.Delete(reg.PublicHostName))
+	assert.ErrorIs(t, s.Delete(reg.PublicHostName), ErrNotFound)
+
+	s, err = OpenRegistrationStore(path)
+	require.NoError(t, err)
+	assert.Empty(t, s.All())
+	assert.ErrorIs(t, s.UseNonce(reg.Operator, 1), ErrNonceUsed, "the nonces are persisted")
+	assert.NoError(t, s.UseNonce(reg.Operator, 2))
+}
@@ -0,0 +1,188 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/bnb-chain/bsc-mev-sentry/log"
+	"github.com/bnb-chain/bsc-mev-sentry/metrics"
+)
+
+const (
+	defaultPruneInterval = 10 * time.Minute
+	// pruneBatchSize rows deleted per statement, so that writes aren't held up for long
+	pruneBatchSize = 10000
+)
+
+// retention bounds the rows kept by age and by count.
+type retention struct {
+	interval time.Duration
+	maxAge   time.Duration
+	maxRows  int64
+}
+
+func newRetention(cfg BidStoreConfig) retention {
+	r := retention{
+		interval: time.Duration(cfg.PruneInterval),
+		maxAge:   time.Duration(cfg.MaxAge),
+		maxRows:  cfg.MaxRows,
+	}
+	if r.interval <= 0 {
+		r.interval = defaultPruneInterval
+	}
+
+	return r
+}
+
+// prunedTable is a table pruned by the id and the time column.
+type prunedTable struct {
+	name   string
+	time   string
+	export bool     // rows kept until exported
+	with   []string // tables of rows sharing the ids, pruned along
+}
+
+var prunedTables = []prunedTable{
+	{name: "bids", time: "received_at", export: true, with: []string{"bid_args"}},
+	{name: "issues", time: "reported_at"},
+}
+
+// KeepUnexported keeps the bids not exported yet by the named exporter from being pruned.
+func (s *BidStore) KeepUnexported(name string) {
+	s.keepMu.Lock()
+	defer s.keepMu.Unlock()
+
+	s.keep = append(s.keep, name)
+}
+
+func (s *BidStore) pruneLoop() {
+	defer close(s.pruned)
+
+	s.measure()
+
+	ticker := time.NewTicker(s.retention.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			if err := s.Prune(time.Now()); err != nil {
+				log.Errorw("failed to prune bid store", "err", err)
+			}
+			s.measure()
+		}
+	}
+}
+
+// Prune deletes the bids and the issues past the retention at now, the oldest first.
+func (s *BidStore) Prune(now time.Time) error {
+	exported, err := s.exportedID()
+	if err != nil {
+		return err
+	}
+
+	for _, table := range prunedTables {
+		// the rows up to the id are pruned, -1 for none
+		maxID := int64(-1)
+		if s.retention.maxAge > 0 {
+			err = s.db.QueryRow(s.dialect.rebind(`SELECT COALESCE(MAX(id), -1) FROM `+table.name+` WHERE `+
+				table.time+` < ?`), now.Add(-s.retention.maxAge).UnixMilli()).Scan(&maxID)
+			if err != nil {
+				return err
+			}
+		}
+		if s.retention.maxRows > 0 {
+			id := int64(-1)
+			err = s.db.QueryRow(`SELECT id FROM ` + table.name + ` ORDER BY id DESC LIMIT 1 OFFSET ` +
+				strconv.FormatInt(s.retention.maxRows, 10)).Scan(&id)
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				return err
+			}
+			maxID = max(maxID, id)
+		}
+		if table.export && exported >= 0 {
+			maxID = min(maxID, exported)
+		}
+		if maxID < 0 {
+			continue
+		}
+
+		for _, name := range append([]string{table.name}, table.with...) {
+			if err = s.deleteUpTo(name, maxID); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// exportedID returns the id of the last bid exported by all the exporters kept for, -1 if there're none.
+func (s *BidStore) exportedID() (int64, error) {
+	s.keepMu.Lock()
+	names := s.keep
+	s.keepMu.Unlock()
+
+	exported := int64(-1)
+	for i, name := range names {
+		id, err := s.ExportCursor(name)
+		if err != nil {
+			return 0, err
+		}
+		if i == 0 || id < exported {
+			exported = id
+		}
+	}
+
+	return exported, nil
+}
+
+// deleteUpTo deletes the rows of the table up to the id in batches.
+func (s *BidStore) deleteUpTo(table string, maxID int64) error {
+	for {
+		res, err := s.db.Exec(s.dialect.rebind(`DELETE FROM `+table+` WHERE id IN (SELECT id FROM `+table+
+			` WHERE id <= ? ORDER BY id LIMIT `+strconv.Itoa(pruneBatchSize)+`)`), maxID)
+		if err != nil {
+			return err
+		}
+
+		n, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		metrics.BidStorePrunedCounter.WithLabelValues(table).Add(float64(n))
+
+		if n < pruneBatchSize {
+			return nil
+		}
+
+		select {
+		case <-s.stop:
+			return nil
+		default:
+		}
+	}
+}
+
+// measure exports the rows of each table and the bytes taken by the store.
+func (s *BidStore) measure() {
+	for _, table := range prunedTables {
+		var rows int64
+		if err := s.db.QueryRow(`SELECT COUNT(*) FROM ` + table.name).Scan(&rows); err != nil {
+			log.Errorw("failed to count bid store rows", "table", table.name, "err", err)
+			continue
+		}
+		metrics.BidStoreRows.WithLabelValues(table.name).Set(float64(rows))
+	}
+
+	var size int64
+	if err := s.db.QueryRow(s.dialect.size).Scan(&size); err != nil {
+		log.Errorw("failed to measure bid store size", "err", err)
+		return
+	}
+	metrics.BidStoreSizeBytes.Set(float64(size))
+}
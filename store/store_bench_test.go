@@ -0,0 +1,82 @@
+package store
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func benchPayment(i int) *Payment {
+	return &Payment{
+		BidHash:   common.BigToHash(big.NewInt(int64(i))),
+		TxHash:    common.BigToHash(big.NewInt(int64(-i - 1))),
+		Nonce:     uint64(i),
+		Amount:    big.NewInt(1e15),
+		Builder:   common.HexToAddress("0x01"),
+		Validator: "validator",
+		Time:      1700000000,
+	}
+}
+
+func benchKeyring(b *testing.B) *Keyring {
+	keyFile := filepath.Join(b.TempDir(), "validator.key")
+	if err := os.WriteFile(keyFile, []byte("000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f"), 0600); err != nil {
+		b.Fatal(err)
+	}
+
+	k, err := LoadKeyring(map[string]string{"validator": keyFile})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	return k
+}
+
+func benchmarkPaymentStorePut(b *testing.B, keyring *Keyring) {
+	s, err := OpenPaymentStore(b.TempDir(), keyring)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer s.Close()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if err = s.Put(benchPayment(i)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkPaymentStorePut(b *testing.B) {
+	b.Run("plain", func(b *testing.B) { benchmarkPaymentStorePut(b, nil) })
+	b.Run("encrypted", func(b *testing.B) { benchmarkPaymentStorePut(b, benchKeyring(b)) })
+}
+
+func BenchmarkPaymentStoreByBid(b *testing.B) {
+	s, err := OpenPaymentStore(b.TempDir(), benchKeyring(b))
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer s.Close()
+
+	const payments = 1000
+	for i := 0; i < payments; i++ {
+		if err = s.Put(benchPayment(i)); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err = s.ByBid(benchPayment(i % payments).BidHash); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
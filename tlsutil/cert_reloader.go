@@ -0,0 +1,214 @@
+package tlsutil
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/bnb-chain/bsc-mev-sentry/log"
+	"github.com/bnb-chain/bsc-mev-sentry/metrics"
+	"github.com/bnb-chain/bsc-mev-sentry/utils"
+)
+
+// reloadCheckInterval limits how often the files are checked for changes
+const reloadCheckInterval = time.Second
+
+// nextProtos are negotiated by ALPN, http.Server doesn't add them to the configs of GetConfigForClient or of a tls
+// listener
+var nextProtos = []string{"h2", "http/1.1"}
+
+// CertReloader serves a certificate pair and an optional CA bundle loaded from files, and reloads them once
+// the files change. The CA bundle verifies the peer, i.e. clients of a server or the server of a client. A client
+// may have no certificate of its own.
+type CertReloader struct {
+	certFile string
+	keyFile  string
+	caFile   string
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	cas       *x509.CertPool
+	modTime   time.Time
+	checkedAt time.Time
+}
+
+func NewCertReloader(certFile, keyFile, caFile string) (*CertReloader, error) {
+	r := &CertReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		caFile:   caFile,
+	}
+
+	modTime, err := utils.LatestModTime(r.files()...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = r.load(modTime); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// TLSConfig returns a tls config always serving the latest certificate and client CAs, negotiating h2 or http/1.1.
+// Client certificates are verified if given, it's up to the application to require them.
+func (r *CertReloader) TLSConfig() *tls.Config {
+	return &tls.Config{
+		NextProtos: nextProtos,
+		// required by http.Server.ListenAndServeTLS without files, though GetConfigForClient takes precedence
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			r.mu.RLock()
+			defer r.mu.RUnlock()
+
+			return r.cert, nil
+		},
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			r.maybeReload()
+
+			r.mu.RLock()
+			defer r.mu.RUnlock()
+
+			cfg := &tls.Config{Certificates: []tls.Certificate{*r.cert}, NextProtos: nextProtos}
+			if r.cas != nil {
+				cfg.ClientCAs = r.cas
+				cfg.ClientAuth = tls.VerifyClientCertIfGiven
+			}
+
+			return cfg, nil
+		},
+	}
+}
+
+// ClientTLSConfig returns a tls config of the connections to the host always presenting the latest certificate, if
+// any, as a client certificate. The server certificate is verified against the latest CA bundle, or the system
+// roots without one, and must be issued for the host, a name or an ip, unless insecure.
+func (r *CertReloader) ClientTLSConfig(host string, insecure bool) *tls.Config {
+	return &tls.Config{
+		ServerName: host,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			r.maybeReload()
+
+			r.mu.RLock()
+			defer r.mu.RUnlock()
+
+			if r.cert == nil {
+				return &tls.Certificate{}, nil
+			}
+			return r.cert, nil
+		},
+		// verified by VerifyConnection instead, the CA bundle may be reloaded
+		InsecureSkipVerify: true,
+		VerifyConnection: func(state tls.ConnectionState) error {
+			if insecure {
+				return nil
+			}
+
+			r.maybeReload()
+
+			r.mu.RLock()
+			cas := r.cas
+			r.mu.RUnlock()
+
+			return verifyServer(state.PeerCertificates, host, cas)
+		},
+	}
+}
+
+// verifyServer verifies the server certificate chain against the roots, the system roots if nil, and the host, a
+// name or an ip. The host is the one dialed rather than the server name of the connection state, which is empty for
+// ip hosts as no server name is sent for them.
+func verifyServer(certs []*x509.Certificate, host string, roots *x509.CertPool) error {
+	if len(certs) == 0 {
+		return errors.New("no server certificate")
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	if _, err := certs[0].Verify(x509.VerifyOptions{Roots: roots, Intermediates: intermediates}); err != nil {
+		return err
+	}
+
+	return certs[0].VerifyHostname(host)
+}
+
+func (r *CertReloader) files() []string {
+	var files []string
+	for _, file := range []string{r.certFile, r.keyFile, r.caFile} {
+		if file != "" {
+			files = append(files, file)
+		}
+	}
+
+	return files
+}
+
+func (r *CertReloader) maybeReload() {
+	r.mu.Lock()
+	if time.Since(r.checkedAt) < reloadCheckInterval {
+		r.mu.Unlock()
+		return
+	}
+	r.checkedAt = time.Now()
+	lastModTime := r.modTime
+	r.mu.Unlock()
+
+	modTime, err := utils.LatestModTime(r.files()...)
+	if err != nil {
+		metrics.SecretRotationCounter.WithLabelValues("tls", "failure").Inc()
+		log.Errorw("failed to stat tls files", "files", r.files(), "err", err)
+		return
+	}
+
+	if !modTime.After(lastModTime) {
+		return
+	}
+
+	if err = r.load(modTime); err != nil {
+		metrics.SecretRotationCounter.WithLabelValues("tls", "failure").Inc()
+		log.Errorw("failed to reload tls files, keep the old ones", "files", r.files(), "err", err)
+		return
+	}
+
+	metrics.SecretRotationCounter.WithLabelValues("tls", "success").Inc()
+	log.Infow("tls files reloaded", "files", r.files())
+}
+
+func (r *CertReloader) load(modTime time.Time) error {
+	var cert *tls.Certificate
+	if r.certFile != "" {
+		pair, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+		if err != nil {
+			return err
+		}
+		cert = &pair
+	}
+
+	var pool *x509.CertPool
+	if r.caFile != "" {
+		pem, err := os.ReadFile(r.caFile)
+		if err != nil {
+			return err
+		}
+
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificate found in %s", r.caFile)
+		}
+	}
+
+	r.mu.Lock()
+	r.cert = cert
+	r.cas = pool
+	r.modTime = modTime
+	r.mu.Unlock()
+
+	return nil
+}
@@ -0,0 +1,116 @@
+// benchgate compares go benchmark results against a baseline and fails if any
+// benchmark got slower than the allowed threshold. The baseline must be run on the
+// same machine, e.g. of the base revision by make bench, absolute numbers of another
+// machine don't compare.
+//
+//	go run ./tools/benchgate -baseline .build/bench-base.txt -threshold 0.2 .build/bench.txt
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// gomaxprocsSuffix is stripped so results are comparable between machines of different core counts
+var gomaxprocsSuffix = regexp.MustCompile(`-\d+$`)
+
+// parse returns the median ns/op of each benchmark in a go test -bench output.
+func parse(path string) (map[string]float64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	samples := make(map[string][]float64)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 || !strings.HasPrefix(fields[0], "Benchmark") {
+			continue
+		}
+
+		for i := 2; i+1 < len(fields); i += 2 {
+			if fields[i+1] != "ns/op" {
+				continue
+			}
+
+			ns, err := strconv.ParseFloat(fields[i], 64)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", fields[0], err)
+			}
+
+			name := gomaxprocsSuffix.ReplaceAllString(fields[0], "")
+			samples[name] = append(samples[name], ns)
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	medians := make(map[string]float64, len(samples))
+	for name, s := range samples {
+		sort.Float64s(s)
+		medians[name] = s[len(s)/2]
+	}
+
+	return medians, nil
+}
+
+func main() {
+	baselinePath := flag.String("baseline", "", "benchmark output of the base revision on this machine")
+	threshold := flag.Float64("threshold", 0.2, "allowed slowdown ratio before a benchmark is a regression")
+	flag.Parse()
+
+	if flag.NArg() != 1 || *baselinePath == "" {
+		fmt.Fprintln(os.Stderr, "usage: benchgate -baseline file [-threshold ratio] <bench output>")
+		os.Exit(2)
+	}
+
+	baseline, err := parse(*baselinePath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to read baseline:", err)
+		os.Exit(2)
+	}
+
+	current, err := parse(flag.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to read benchmark output:", err)
+		os.Exit(2)
+	}
+
+	names := make([]string, 0, len(current))
+	for name := range current {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	regressions := 0
+	for _, name := range names {
+		old, ok := baseline[name]
+		if !ok {
+			fmt.Printf("%-50s %12.1f ns/op  (no baseline)\n", name, current[name])
+			continue
+		}
+
+		delta := current[name]/old - 1
+		status := "ok"
+		if delta > *threshold {
+			status = "REGRESSION"
+			regressions++
+		}
+
+		fmt.Printf("%-50s %12.1f ns/op  %+7.1f%%  %s\n", name, current[name], delta*100, status)
+	}
+
+	if regressions > 0 {
+		fmt.Printf("%d benchmark(s) regressed more than %.0f%%\n", regressions, *threshold*100)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,20 @@
+package utils
+
+import (
+	"time"
+
+	"github.com/tredeske/u/ustrings"
+)
+
+// Duration is a time.Duration which is (un)marshaled as text like "500ms" in config files.
+type Duration time.Duration
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return ustrings.UnsafeStringToBytes(time.Duration(d).String()), nil
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	dd, err := time.ParseDuration(ustrings.UnsafeBytesToString(text))
+	*d = Duration(dd)
+	return err
+}
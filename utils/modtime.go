@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// LatestModTime returns the latest modification time of the given files, the files
+// right under a directory are also taken into account.
+func LatestModTime(paths ...string) (time.Time, error) {
+	var latest time.Time
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+
+		if !info.IsDir() {
+			continue
+		}
+
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		for _, entry := range entries {
+			info, err := os.Stat(filepath.Join(path, entry.Name()))
+			if err != nil {
+				return time.Time{}, err
+			}
+
+			if info.ModTime().After(latest) {
+				latest = info.ModTime()
+			}
+		}
+	}
+
+	return latest, nil
+}
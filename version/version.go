@@ -0,0 +1,27 @@
+package version
+
+import "runtime"
+
+// These are set at link time, see Makefile.
+var (
+	Version   = "unknown"
+	Commit    = "unknown"
+	BuildDate = "unknown"
+)
+
+// Info is the build information of the running sentry.
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildDate string `json:"buildDate"`
+	GoVersion string `json:"goVersion"`
+}
+
+func Get() Info {
+	return Info{
+		Version:   Version,
+		Commit:    Commit,
+		BuildDate: BuildDate,
+		GoVersion: runtime.Version(),
+	}
+}